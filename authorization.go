@@ -0,0 +1,86 @@
+package goa
+
+import (
+	"context"
+	"net/url"
+)
+
+type (
+	// Policy decides whether requests are allowed to run the actions that define authorization
+	// policies in the design with the Authorize DSL. Returning nil allows the request, returning
+	// an error denies it. Errors that are not goa errors are wrapped with ErrForbidden.
+	//
+	// The generated code calls the Policy of the service after the security middleware
+	// authenticated the request and after the params and payload have been decoded and
+	// validated, before the controller action runs.
+	Policy interface {
+		Authorize(ctx context.Context, req *AuthorizationRequest) error
+	}
+
+	// PolicyFunc is an adapter that allows the use of ordinary functions as policies.
+	PolicyFunc func(ctx context.Context, req *AuthorizationRequest) error
+
+	// AuthorizationRequest describes the request being authorized.
+	AuthorizationRequest struct {
+		// Resource is the name of the resource as defined in the design.
+		Resource string
+		// Action is the name of the action as defined in the design.
+		Action string
+		// Policies are the names of the policies listed with the Authorize DSL.
+		Policies []string
+		// Principal is the identity authenticated by the security middleware, see
+		// ContextPrincipal.
+		Principal interface{}
+		// Params contains the raw values of the action parameters.
+		Params url.Values
+		// Payload is the decoded request payload if any.
+		Payload interface{}
+		// Context is the generated action context that exposes the decoded params, e.g.
+		// *app.ShowBottleContext.
+		Context interface{}
+	}
+)
+
+// Authorize calls fn.
+func (fn PolicyFunc) Authorize(ctx context.Context, req *AuthorizationRequest) error {
+	return fn(ctx, req)
+}
+
+// Authorize calls the service policy with the given request. It sets the request principal and
+// params from the context. Authorize is called by the generated code, it returns an ErrNoPolicy
+// error if the service has no policy.
+func (service *Service) Authorize(ctx context.Context, req *AuthorizationRequest) error {
+	if service.Policy == nil {
+		return ErrNoPolicy("no authorization policy set on service", "resource", req.Resource, "action", req.Action)
+	}
+	req.Principal = ContextPrincipal(ctx)
+	if r := ContextRequest(ctx); r != nil {
+		req.Params = r.Params
+	}
+	if err := service.Policy.Authorize(ctx, req); err != nil {
+		if _, ok := err.(ServiceError); !ok {
+			err = ErrForbidden(err, "resource", req.Resource, "action", req.Action)
+		}
+		return err
+	}
+	return nil
+}
+
+// WithPrincipal creates a child context containing the given principal. Security middlewares
+// call WithPrincipal with the identity they authenticate so that it can be used by the
+// authorization policy.
+func WithPrincipal(ctx context.Context, principal interface{}) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// ContextPrincipal returns the principal authenticated by the security middleware, nil if
+// none. The goa security middlewares set the principal as follows:
+//
+//   - basicauth: the username
+//   - apikey: the value returned by the validator
+//   - jwt: the *jwt.Claims of the token
+//   - oauth2: the *oauth2.Introspection of the token
+//   - mtls: the *goa.PeerIdentity of the client certificate
+func ContextPrincipal(ctx context.Context) interface{} {
+	return ctx.Value(principalKey)
+}
@@ -0,0 +1,86 @@
+package goa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Authorize", func() {
+	var (
+		service *goa.Service
+		ctx     context.Context
+		req     *goa.AuthorizationRequest
+		actual  *goa.AuthorizationRequest
+		err     error
+	)
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		rw := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/bottles/1", nil)
+		ctx = goa.NewContext(context.Background(), rw, r, url.Values{"id": {"1"}})
+		ctx = goa.WithPrincipal(ctx, "alice")
+		req = &goa.AuthorizationRequest{Resource: "bottle", Action: "show", Policies: []string{"owner"}}
+		actual = nil
+	})
+
+	JustBeforeEach(func() {
+		err = service.Authorize(ctx, req)
+	})
+
+	Context("without a policy", func() {
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Context("with a policy allowing the request", func() {
+		BeforeEach(func() {
+			service.Policy = goa.PolicyFunc(func(ctx context.Context, req *goa.AuthorizationRequest) error {
+				actual = req
+				return nil
+			})
+		})
+
+		It("sets the principal and params", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(actual).ShouldNot(BeNil())
+			Ω(actual.Principal).Should(Equal("alice"))
+			Ω(actual.Params.Get("id")).Should(Equal("1"))
+			Ω(actual.Policies).Should(Equal([]string{"owner"}))
+		})
+	})
+
+	Context("with a policy denying the request", func() {
+		BeforeEach(func() {
+			service.Policy = goa.PolicyFunc(func(ctx context.Context, req *goa.AuthorizationRequest) error {
+				return errors.New("not the owner")
+			})
+		})
+
+		It("returns a forbidden error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusForbidden))
+		})
+	})
+
+	Context("with a policy returning a goa error", func() {
+		BeforeEach(func() {
+			service.Policy = goa.PolicyFunc(func(ctx context.Context, req *goa.AuthorizationRequest) error {
+				return goa.ErrNotFound("no such bottle")
+			})
+		})
+
+		It("returns the error as is", func() {
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusNotFound))
+		})
+	})
+})
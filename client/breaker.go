@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// BreakerPolicy configures the CircuitBreaker decorator. The zero value is a valid policy.
+	BreakerPolicy struct {
+		// FailureThreshold is the number of consecutive failures that opens the circuit,
+		// defaults to 5.
+		FailureThreshold int
+		// OpenTimeout is the time the circuit stays open before letting probe requests
+		// through, defaults to 30s.
+		OpenTimeout time.Duration
+		// HalfOpenRequests is the maximum number of concurrent probe requests let through
+		// while the circuit is half-open, defaults to 1.
+		HalfOpenRequests int
+		// Failure decides whether a request failed given its response or error. Defaults to
+		// errors and responses with a 5xx status.
+		Failure func(resp *http.Response, err error) bool
+	}
+
+	// breaker is the state of the circuit of a single host.
+	breaker struct {
+		sync.Mutex
+		state    breakerState
+		failures int
+		openedAt time.Time
+		probes   int
+	}
+
+	// breakerState is the state of a circuit.
+	breakerState int
+)
+
+const (
+	closed breakerState = iota
+	open
+	halfOpen
+)
+
+// ErrCircuitOpen is the error returned by the CircuitBreaker decorator when it rejects a request
+// because the circuit of the target host is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker returns a decorator that stops sending requests to a host after it fails
+// repeatedly. The circuit of a host opens after FailureThreshold consecutive failures, requests
+// made while it is open fail immediately with ErrCircuitOpen. After OpenTimeout the circuit turns
+// half-open and lets up to HalfOpenRequests probe requests through: the circuit closes if a probe
+// succeeds and opens again if it fails.
+func CircuitBreaker(p BreakerPolicy) Decorator {
+	if p.FailureThreshold <= 0 {
+		p.FailureThreshold = 5
+	}
+	if p.OpenTimeout <= 0 {
+		p.OpenTimeout = 30 * time.Second
+	}
+	if p.HalfOpenRequests <= 0 {
+		p.HalfOpenRequests = 1
+	}
+	if p.Failure == nil {
+		p.Failure = failure
+	}
+	var (
+		mu       sync.Mutex
+		breakers = make(map[string]*breaker)
+	)
+	return func(d Doer) Doer {
+		return doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			b, ok := breakers[req.URL.Host]
+			if !ok {
+				b = &breaker{}
+				breakers[req.URL.Host] = b
+			}
+			mu.Unlock()
+			probe, err := b.allow(&p)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := d.Do(ctx, req)
+			b.done(&p, probe, p.Failure(resp, err))
+			return resp, err
+		})
+	}
+}
+
+// allow returns nil if the request may proceed and whether it is a probe.
+func (b *breaker) allow(p *BreakerPolicy) (bool, error) {
+	b.Lock()
+	defer b.Unlock()
+	if b.state == open {
+		if time.Since(b.openedAt) < p.OpenTimeout {
+			return false, ErrCircuitOpen
+		}
+		b.state = halfOpen
+		b.probes = 0
+	}
+	if b.state == halfOpen {
+		if b.probes >= p.HalfOpenRequests {
+			return false, ErrCircuitOpen
+		}
+		b.probes++
+		return true, nil
+	}
+	return false, nil
+}
+
+// done records the outcome of a request.
+func (b *breaker) done(p *BreakerPolicy, probe, failed bool) {
+	b.Lock()
+	defer b.Unlock()
+	if probe {
+		b.probes--
+		if b.state != halfOpen {
+			return
+		}
+		if failed {
+			b.state = open
+			b.openedAt = time.Now()
+		} else {
+			b.state = closed
+			b.failures = 0
+		}
+		return
+	}
+	if b.state != closed {
+		return
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= p.FailureThreshold {
+		b.state = open
+		b.openedAt = time.Now()
+	}
+}
+
+// failure is the default BreakerPolicy Failure function.
+func failure(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= 500
+}
@@ -94,3 +94,34 @@ func WSRead(ws *websocket.Conn) {
 		fmt.Printf("<< %s\n", msg[:n])
 	}
 }
+
+// WSSend reads STDIN lines and calls send with each line. send typically decodes the line into a
+// message and writes it to a typed websocket stream. Lines that send fails to process are reported
+// on STDERR.
+func WSSend(send func(line []byte) error) {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		t := scanner.Text()
+		if err := send([]byte(t)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to send %q: %s\n", t, err)
+			continue
+		}
+		fmt.Printf(">> %s\n", t)
+	}
+}
+
+// WSReceive calls receive until it fails and prints the JSON representation of the returned
+// messages to STDOUT.
+func WSReceive(receive func() (interface{}, error)) error {
+	for {
+		msg, err := receive()
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("<< %s\n", b)
+	}
+}
@@ -21,6 +21,9 @@ type (
 		Do(context.Context, *http.Request) (*http.Response, error)
 	}
 
+	// Decorator wraps a Doer to add behavior such as retries or circuit breaking.
+	Decorator func(Doer) Doer
+
 	// Client is the common client data structure for all goa service clients.
 	Client struct {
 		// Doer is the underlying http client.
@@ -38,11 +41,24 @@ type (
 
 // New creates a new API client that wraps c.
 // If c is nil, the returned client wraps http.DefaultClient.
-func New(c Doer) *Client {
+// The decorators wrap c in order so that the first decorator sees the requests first, e.g.:
+//
+//	New(nil, Retry(RetryPolicy{}), CircuitBreaker(BreakerPolicy{}))
+//
+// retries the requests rejected by the circuit breaker.
+func New(c Doer, decorators ...Decorator) *Client {
 	if c == nil {
 		c = HTTPClientDoer(http.DefaultClient)
 	}
-	return &Client{Doer: c}
+	return &Client{Doer: Wrap(c, decorators...)}
+}
+
+// Wrap applies the decorators to d, the first decorator is the outermost.
+func Wrap(d Doer, decorators ...Decorator) Doer {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		d = decorators[i](d)
+	}
+	return d
 }
 
 // HTTPClientDoer turns a stdlib http.Client into a Doer. Use it to enable to call New() with an http.Client.
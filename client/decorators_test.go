@@ -0,0 +1,212 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeDoer returns the scripted statuses in order and records the request bodies.
+type fakeDoer struct {
+	sync.Mutex
+	statuses []int
+	bodies   []string
+	calls    int
+}
+
+func (f *fakeDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	f.Lock()
+	defer f.Unlock()
+	if req.Body != nil {
+		b, _ := ioutil.ReadAll(req.Body)
+		f.bodies = append(f.bodies, string(b))
+	}
+	status := f.statuses[len(f.statuses)-1]
+	if f.calls < len(f.statuses) {
+		status = f.statuses[f.calls]
+	}
+	f.calls++
+	if status == 0 {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: status, Header: make(http.Header), Body: ioutil.NopCloser(bytes.NewBufferString("body"))}, nil
+}
+
+var _ = Describe("Retry", func() {
+	var doer *fakeDoer
+	var policy client.RetryPolicy
+	var ctx context.Context
+
+	BeforeEach(func() {
+		doer = &fakeDoer{}
+		policy = client.RetryPolicy{BaseDelay: time.Millisecond}
+		ctx = context.Background()
+	})
+
+	do := func(method string) (*http.Response, error) {
+		req, err := http.NewRequest(method, "http://example.com/foo", ioutil.NopCloser(bytes.NewBufferString("payload")))
+		Ω(err).ShouldNot(HaveOccurred())
+		return client.Wrap(doer, client.Retry(policy)).Do(ctx, req)
+	}
+
+	It("retries idempotent requests and rewinds the body", func() {
+		doer.statuses = []int{503, 0, 200}
+		resp, err := do("PUT")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+		Ω(doer.bodies).Should(Equal([]string{"payload", "payload", "payload"}))
+	})
+
+	It("gives up after MaxAttempts", func() {
+		doer.statuses = []int{503}
+		policy.MaxAttempts = 2
+		resp, err := do("GET")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(503))
+		Ω(doer.calls).Should(Equal(2))
+	})
+
+	It("does not retry non idempotent requests", func() {
+		doer.statuses = []int{503, 200}
+		resp, err := do("POST")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(503))
+		Ω(doer.calls).Should(Equal(1))
+	})
+
+	It("retries requests marked idempotent", func() {
+		doer.statuses = []int{503, 200}
+		ctx = client.WithIdempotent(ctx)
+		resp, err := do("POST")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+	})
+
+	It("does not retry client errors", func() {
+		doer.statuses = []int{404, 200}
+		resp, err := do("GET")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(404))
+	})
+
+	It("does not wait longer than MaxDelay", func() {
+		policy.MaxDelay = time.Second
+		doer.statuses = []int{429, 200}
+		limited := &retryAfterDoer{doer: doer, after: "120"}
+		req, _ := http.NewRequest("GET", "http://example.com/foo", nil)
+		resp, err := client.Wrap(limited, client.Retry(policy)).Do(ctx, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(429))
+		Ω(doer.calls).Should(Equal(1))
+	})
+
+	It("stops when the context is done", func() {
+		doer.statuses = []int{503}
+		policy.BaseDelay = time.Hour
+		policy.MaxDelay = time.Hour
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err := do("GET")
+		Ω(err).Should(Equal(context.DeadlineExceeded))
+	})
+})
+
+// retryAfterDoer sets the Retry-After header on the responses of doer.
+type retryAfterDoer struct {
+	doer  client.Doer
+	after string
+}
+
+func (r *retryAfterDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	resp, err := r.doer.Do(ctx, req)
+	if resp != nil {
+		resp.Header.Set("Retry-After", r.after)
+	}
+	return resp, err
+}
+
+var _ = Describe("CircuitBreaker", func() {
+	var doer *fakeDoer
+	var d client.Doer
+
+	BeforeEach(func() {
+		doer = &fakeDoer{}
+		d = client.Wrap(doer, client.CircuitBreaker(client.BreakerPolicy{
+			FailureThreshold: 2,
+			OpenTimeout:      20 * time.Millisecond,
+		}))
+	})
+
+	do := func(host string) (*http.Response, error) {
+		req, err := http.NewRequest("GET", "http://"+host+"/foo", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		return d.Do(context.Background(), req)
+	}
+
+	It("opens after consecutive failures", func() {
+		doer.statuses = []int{500, 0, 200}
+		do("a")
+		do("a")
+		_, err := do("a")
+		Ω(err).Should(Equal(client.ErrCircuitOpen))
+		Ω(doer.calls).Should(Equal(2))
+
+		By("keeping a circuit per host")
+		resp, err := do("b")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+	})
+
+	It("closes after a successful probe", func() {
+		doer.statuses = []int{500, 500, 200}
+		do("a")
+		do("a")
+		time.Sleep(30 * time.Millisecond)
+		_, err := do("a")
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = do("a")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("opens again after a failed probe", func() {
+		doer.statuses = []int{500}
+		do("a")
+		do("a")
+		time.Sleep(30 * time.Millisecond)
+		_, err := do("a")
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = do("a")
+		Ω(err).Should(Equal(client.ErrCircuitOpen))
+		Ω(doer.calls).Should(Equal(3))
+	})
+})
+
+var _ = Describe("LimitConcurrency", func() {
+	It("blocks until a response body is closed", func() {
+		doer := &fakeDoer{statuses: []int{200}}
+		d := client.Wrap(doer, client.LimitConcurrency(1))
+		req, _ := http.NewRequest("GET", "http://a/foo", nil)
+		resp, err := d.Do(context.Background(), req)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = d.Do(ctx, req)
+		Ω(err).Should(Equal(context.DeadlineExceeded))
+
+		resp.Body.Close()
+		resp.Body.Close()
+		_, err = d.Do(context.Background(), req)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Event is a server-sent event.
+	Event struct {
+		// ID is the event ID if any.
+		ID string
+		// Name is the event type if any.
+		Name string
+		// Data is the event data, multiple data lines are joined with "\n".
+		Data []byte
+		// Retry is the reconnection time in milliseconds requested by the server if any.
+		Retry int
+	}
+
+	// EventReader reads server-sent events (text/event-stream) from a response body.
+	EventReader struct {
+		body io.ReadCloser
+		r    *bufio.Reader
+	}
+)
+
+// NewEventReader returns a reader for the server-sent events contained in body.
+func NewEventReader(body io.ReadCloser) *EventReader {
+	return &EventReader{body: body, r: bufio.NewReader(body)}
+}
+
+// Next blocks until the next event is received and returns it. Comments such as heartbeats are
+// skipped. Next returns io.EOF once the server closes the stream.
+func (r *EventReader) Next() (*Event, error) {
+	var (
+		event   Event
+		data    bytes.Buffer
+		hasData bool
+	)
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil {
+			// Incomplete events at the end of the stream are discarded.
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if !hasData {
+				continue
+			}
+			event.Data = data.Bytes()
+			return &event, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i > -1 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "event":
+			event.Name = value
+		case "id":
+			event.ID = value
+		case "retry":
+			if retry, err := strconv.Atoi(value); err == nil {
+				event.Retry = retry
+			}
+		}
+	}
+}
+
+// Close closes the underlying response body.
+func (r *EventReader) Close() error {
+	return r.body.Close()
+}
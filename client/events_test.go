@@ -0,0 +1,66 @@
+package client_test
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventReader", func() {
+	var stream string
+	var reader *client.EventReader
+
+	JustBeforeEach(func() {
+		reader = client.NewEventReader(ioutil.NopCloser(strings.NewReader(stream)))
+	})
+
+	Context("with a single event", func() {
+		BeforeEach(func() {
+			stream = "data: {\"id\":1}\n\n"
+		})
+
+		It("reads the event and then io.EOF", func() {
+			event, err := reader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(event.Data)).To(Equal(`{"id":1}`))
+			_, err = reader.Next()
+			Expect(err).To(Equal(io.EOF))
+		})
+	})
+
+	Context("with named events, comments and multiline data", func() {
+		BeforeEach(func() {
+			stream = ": heartbeat\n\nid: 42\nevent: update\nretry: 1000\ndata: foo\ndata: bar\n\n: heartbeat\n\ndata:baz\r\n\r\n"
+		})
+
+		It("reads all the events", func() {
+			event, err := reader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(event.ID).To(Equal("42"))
+			Expect(event.Name).To(Equal("update"))
+			Expect(event.Retry).To(Equal(1000))
+			Expect(string(event.Data)).To(Equal("foo\nbar"))
+
+			event, err = reader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(event.Name).To(BeEmpty())
+			Expect(string(event.Data)).To(Equal("baz"))
+		})
+	})
+
+	Context("with an incomplete event", func() {
+		BeforeEach(func() {
+			stream = "data: foo\n"
+		})
+
+		It("discards the event", func() {
+			_, err := reader.Next()
+			Expect(err).To(Equal(io.EOF))
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// releaser is a response body that runs a function once closed.
+type releaser struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+// LimitConcurrency returns a decorator that limits the number of concurrent requests made to each
+// host to n. Requests made once the limit is reached block until a previous request completes or
+// their context is done. A request completes when its response body is closed so that streaming
+// responses hold their slot until fully consumed.
+func LimitConcurrency(n int) Decorator {
+	var (
+		mu    sync.Mutex
+		slots = make(map[string]chan struct{})
+	)
+	return func(d Doer) Doer {
+		return doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			sem, ok := slots[req.URL.Host]
+			if !ok {
+				sem = make(chan struct{}, n)
+				slots[req.URL.Host] = sem
+			}
+			mu.Unlock()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			release := func() { <-sem }
+			resp, err := d.Do(ctx, req)
+			if err != nil || resp.Body == nil {
+				release()
+				return resp, err
+			}
+			resp.Body = &releaser{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		})
+	}
+}
+
+// Close closes the body and releases the concurrency slot.
+func (r *releaser) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTokenExpiryDelta is the duration before the expiry of an access token at which
+// ClientCredentialsTokenSource requests a new token.
+const DefaultTokenExpiryDelta = 10 * time.Second
+
+type (
+	// ClientCredentialsTokenSource is a token source that retrieves access tokens from an OAuth2
+	// authorization server using the client credentials grant (RFC 6749 section 4.4). Tokens are
+	// cached and a new token is requested shortly before the current one expires. Use it with
+	// OAuth2Signer to sign requests made to services protected by the OAuth2Security DSL:
+	//
+	//	c.SetOAuth2Signer(&client.OAuth2Signer{TokenSource: &client.ClientCredentialsTokenSource{
+	//		TokenURL:     "https://idp.example.com/oauth2/token",
+	//		ClientID:     "my-client",
+	//		ClientSecret: "secret",
+	//		Scopes:       []string{"api:read"},
+	//	}})
+	ClientCredentialsTokenSource struct {
+		// TokenURL is the URL of the authorization server token endpoint.
+		TokenURL string
+		// ClientID is the client identifier.
+		ClientID string
+		// ClientSecret is the client secret.
+		ClientSecret string
+		// Scopes are the scopes requested for the access token.
+		Scopes []string
+		// EndpointParams are additional parameters sent to the token endpoint, e.g.
+		// "audience".
+		EndpointParams url.Values
+		// Client is the HTTP client used to make the token requests, http.DefaultClient if
+		// nil.
+		Client *http.Client
+		// ExpiryDelta is the duration before the token expiry at which a new token is
+		// requested, DefaultTokenExpiryDelta if zero.
+		ExpiryDelta time.Duration
+
+		mu    sync.Mutex
+		token *OAuth2Token
+	}
+
+	// OAuth2Token is an OAuth2 access token returned by an authorization server.
+	OAuth2Token struct {
+		// AccessToken is the token used to sign the requests.
+		AccessToken string
+		// TokenType is the type of the token, "Bearer" if empty.
+		TokenType string
+		// Expiry is the expiration time of the token, zero if the token does not expire.
+		Expiry time.Time
+	}
+
+	// tokenResponse is the JSON response of the token endpoint.
+	tokenResponse struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+)
+
+// Token returns the cached access token if it is still valid or requests a new one.
+func (s *ClientCredentialsTokenSource) Token() (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delta := s.ExpiryDelta
+	if delta == 0 {
+		delta = DefaultTokenExpiryDelta
+	}
+	if s.token != nil && (s.token.Expiry.IsZero() || time.Now().Add(delta).Before(s.token.Expiry)) {
+		return s.token, nil
+	}
+	token, err := s.retrieve(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// retrieve requests a new access token from the token endpoint.
+func (s *ClientCredentialsTokenSource) retrieve(ctx context.Context) (*OAuth2Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.Scopes, " "))
+	}
+	for k, v := range s.EndpointParams {
+		form[k] = v
+	}
+	req, err := http.NewRequest("POST", s.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.ClientID), url.QueryEscape(s.ClientSecret))
+	c := s.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: cannot fetch token: %s", err)
+	}
+	defer resp.Body.Close()
+	var tr tokenResponse
+	if ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); ct == "application/json" {
+		if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+			return nil, fmt.Errorf("oauth2: cannot decode token response: %s", err)
+		}
+	}
+	if resp.StatusCode != http.StatusOK || tr.Error != "" {
+		if tr.Error != "" {
+			return nil, fmt.Errorf("oauth2: token request failed: %s %s", tr.Error, tr.ErrorDescription)
+		}
+		return nil, fmt.Errorf("oauth2: token request failed with status %d", resp.StatusCode)
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: server response missing access_token")
+	}
+	token := &OAuth2Token{AccessToken: tr.AccessToken, TokenType: tr.TokenType}
+	if tr.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// SetAuthHeader sets the Authorization header to r.
+func (t *OAuth2Token) SetAuthHeader(r *http.Request) {
+	typ := t.TokenType
+	if typ == "" || strings.EqualFold(typ, "bearer") {
+		typ = "Bearer"
+	}
+	r.Header.Set("Authorization", typ+" "+t.AccessToken)
+}
+
+// Valid reports whether the token is set and not expired.
+func (t *OAuth2Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Before(t.Expiry))
+}
@@ -0,0 +1,95 @@
+package client_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/goadesign/goa/client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientCredentialsTokenSource", func() {
+	var (
+		server    *httptest.Server
+		mu        sync.Mutex
+		hits      int
+		expiresIn int
+		form      map[string]string
+		source    *client.ClientCredentialsTokenSource
+	)
+
+	BeforeEach(func() {
+		hits = 0
+		expiresIn = 3600
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			hits++
+			r.ParseForm()
+			id, secret, _ := r.BasicAuth()
+			form = map[string]string{"grant_type": r.PostForm.Get("grant_type"), "scope": r.PostForm.Get("scope"), "id": id, "secret": secret}
+			w.Header().Set("Content-Type", "application/json")
+			if secret != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "token_type": "bearer", "expires_in": expiresIn})
+		}))
+		source = &client.ClientCredentialsTokenSource{
+			TokenURL:     server.URL,
+			ClientID:     "client",
+			ClientSecret: "secret",
+			Scopes:       []string{"api:read", "api:write"},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("requests a token with the client credentials grant", func() {
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		signer := &client.OAuth2Signer{TokenSource: source}
+		Expect(signer.Sign(req)).To(Succeed())
+		Expect(req.Header.Get("Authorization")).To(Equal("Bearer token"))
+		Expect(form).To(Equal(map[string]string{"grant_type": "client_credentials", "scope": "api:read api:write", "id": "client", "secret": "secret"}))
+	})
+
+	It("caches the token until it expires", func() {
+		_, err := source.Token()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = source.Token()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hits).To(Equal(1))
+	})
+
+	Context("with a token about to expire", func() {
+		BeforeEach(func() {
+			expiresIn = 5
+		})
+
+		It("requests a new token", func() {
+			_, err := source.Token()
+			Expect(err).NotTo(HaveOccurred())
+			_, err = source.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hits).To(Equal(2))
+		})
+	})
+
+	Context("with invalid credentials", func() {
+		BeforeEach(func() {
+			source.ClientSecret = "wrong"
+		})
+
+		It("returns the authorization server error", func() {
+			_, err := source.Token()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid_client"))
+		})
+	})
+})
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// RetryPolicy configures the Retry decorator. The zero value is a valid policy.
+	RetryPolicy struct {
+		// MaxAttempts is the maximum number of attempts including the first one, defaults
+		// to 3.
+		MaxAttempts int
+		// BaseDelay is the delay before the first retry, the delay doubles with each retry.
+		// Defaults to 100ms.
+		BaseDelay time.Duration
+		// MaxDelay caps the delay between two attempts, defaults to 10s. Responses whose
+		// Retry-After header exceeds MaxDelay are not retried.
+		MaxDelay time.Duration
+		// Retryable decides whether an attempt should be retried given its response or
+		// error. Defaults to retrying network errors and responses with status 429, 502, 503
+		// or 504.
+		Retryable func(resp *http.Response, err error) bool
+	}
+
+	// private type used to mark contexts of idempotent requests.
+	idempotentKey struct{}
+)
+
+var (
+	// idempotentMethods lists the HTTP methods that are idempotent by definition.
+	idempotentMethods = map[string]bool{
+		"GET":     true,
+		"HEAD":    true,
+		"OPTIONS": true,
+		"TRACE":   true,
+		"PUT":     true,
+		"DELETE":  true,
+	}
+
+	// jitter is the source of randomness used to compute the delays.
+	jitter   = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterMu sync.Mutex
+)
+
+// WithIdempotent returns a context that marks the request made with it as idempotent so that it
+// may be retried regardless of its HTTP method. The clients generated by goagen use it for the
+// actions marked with the Idempotent DSL.
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// IsIdempotent returns true if the request may be safely retried: either its method is
+// idempotent or ctx was created with WithIdempotent.
+func IsIdempotent(ctx context.Context, req *http.Request) bool {
+	if idempotentMethods[req.Method] {
+		return true
+	}
+	idempotent, _ := ctx.Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+// Retry returns a decorator that retries failed idempotent requests with exponential backoff and
+// jitter. The delay honors the Retry-After header of the response if any. The request body is
+// rewound before each retry using the request GetBody function, bodies without GetBody are
+// buffered in memory. Retry stops as soon as the request context is done.
+func Retry(p RetryPolicy) Decorator {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 100 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 10 * time.Second
+	}
+	if p.Retryable == nil {
+		p.Retryable = retryable
+	}
+	return func(d Doer) Doer {
+		return doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if !IsIdempotent(ctx, req) {
+				return d.Do(ctx, req)
+			}
+			if err := rewindable(req); err != nil {
+				return nil, err
+			}
+			for attempt := 1; ; attempt++ {
+				resp, err := d.Do(ctx, req)
+				if attempt == p.MaxAttempts || ctx.Err() != nil || !p.Retryable(resp, err) {
+					return resp, err
+				}
+				delay := p.delay(attempt)
+				if resp != nil {
+					if after, ok := retryAfter(resp); ok {
+						if after > p.MaxDelay {
+							return resp, err
+						}
+						delay = after
+					}
+					io.Copy(ioutil.Discard, resp.Body)
+					resp.Body.Close()
+				}
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, ctx.Err()
+				case <-timer.C:
+				}
+				if req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					req.Body = body
+				}
+			}
+		})
+	}
+}
+
+// delay returns the delay before the given retry attempt. The delay is picked randomly between
+// half and all of the exponential backoff.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << uint(attempt-1)
+	if d > p.MaxDelay || d <= 0 {
+		d = p.MaxDelay
+	}
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return d/2 + time.Duration(jitter.Int63n(int64(d/2)+1))
+}
+
+// retryable is the default RetryPolicy Retryable function.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header of resp which may contain a number of seconds or a
+// HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	h := resp.Header.Get("Retry-After")
+	if h == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(h); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// rewindable makes sure the request body can be read again by setting GetBody.
+func rewindable(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
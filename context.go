@@ -19,6 +19,7 @@ const (
 	logContextKey
 	errKey
 	securityScopesKey
+	principalKey
 )
 
 type (
@@ -45,6 +46,8 @@ type (
 		Status int
 		// Length is the response body length.
 		Length int
+
+		stream *EventStream // Server-sent events stream if any
 	}
 
 	// key is the type used to store internal values in the context.
@@ -155,7 +158,7 @@ func (r *ResponseData) Written() bool {
 
 // WriteHeader records the response status code and calls the underlying writer.
 func (r *ResponseData) WriteHeader(status int) {
-	go IncrCounter([]string{"goa", "response", strconv.Itoa(status)}, 1.0)
+	go IncrCounterWithLabels([]string{"goa", "response"}, 1.0, []Label{{Name: "status", Value: strconv.Itoa(status)}})
 	r.Status = status
 	r.ResponseWriter.WriteHeader(status)
 }
@@ -86,6 +86,7 @@ func Files(path, filename string, dsls ...func()) {
 //            Scope("api:read")
 //            Scope("api:write")
 //        })
+//        Authorize("owner", "admin")          // Authorize lists the policies checked before the action runs
 //        Headers(func() {                     // Headers describe relevant action headers
 //            Header("Authorization", String)
 //            Header("X-Account", Integer)
@@ -489,6 +490,100 @@ func MultipartForm() {
 	}
 }
 
+// Idempotent can be used in: Action
+//
+// Idempotent indicates that making the same request multiple times has the same effect as making
+// it once. Requests made with the GET, HEAD, OPTIONS, TRACE, PUT or DELETE HTTP methods are
+// idempotent by definition, use Idempotent for actions using other methods so that the generated
+// client lets the client.Retry decorator retry them. Example:
+//
+//	Action("create", func() {
+//		Routing(POST("/"))
+//		Payload(BottlePayload)	// Payload includes a client generated ID
+//		Idempotent()
+//		Response(Created)
+//	})
+//
+func Idempotent() {
+	if a, ok := actionDefinition(); ok {
+		a.Idempotent = true
+	}
+}
+
+// IdempotencyKey can be used in: Action
+//
+// IdempotencyKey causes the code generated by "goagen app" to apply the
+// github.com/goadesign/goa/middleware/idempotency middleware to the action. The middleware
+// records the response of requests that have an Idempotency-Key header and replays it when the
+// same client retries the request so that it is processed at most once. Use
+// idempotency.Configure to configure the middleware. Example:
+//
+//	Action("pay", func() {
+//		Routing(POST("/payments"))
+//		Payload(PaymentPayload)
+//		IdempotencyKey()
+//		Response(Created)
+//	})
+//
+func IdempotencyKey() {
+	if a, ok := actionDefinition(); ok {
+		a.IdempotencyKey = true
+	}
+}
+
+// InboundMessage can be used in: Action
+//
+// InboundMessage defines the type of the messages sent by the clients of a websocket action. The
+// function accepts a user type, a media type or the name of a user type or the identifier of a
+// media type. The generated stream decodes and validates each message received by the server.
+// Example:
+//
+//	Action("chat", func() {
+//		Scheme("ws")
+//		Routing(GET("/chat"))
+//		InboundMessage(ChatMessage)	// Messages sent by clients are ChatMessage instances
+//		OutboundMessage(ChatMedia)	// Messages sent to clients are ChatMedia instances
+//		Response(SwitchingProtocols)
+//	})
+//
+func InboundMessage(t interface{}) {
+	if a, ok := actionDefinition(); ok {
+		a.InboundMessage = messageType(t)
+	}
+}
+
+// OutboundMessage can be used in: Action
+//
+// OutboundMessage defines the type of the messages sent to the clients of a websocket action. It
+// accepts the same arguments as InboundMessage. Media types are rendered using their default view.
+func OutboundMessage(t interface{}) {
+	if a, ok := actionDefinition(); ok {
+		a.OutboundMessage = messageType(t)
+	}
+}
+
+// messageType returns the data type described by the argument given to InboundMessage or
+// OutboundMessage.
+func messageType(t interface{}) design.DataType {
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		return actual
+	case *design.UserTypeDefinition:
+		return actual
+	case string:
+		if ut, ok := design.Design.Types[actual]; ok {
+			return ut
+		}
+		if mt := design.Design.MediaTypeWithIdentifier(actual); mt != nil {
+			return mt
+		}
+		dslengine.ReportError("unknown message type %s", actual)
+	default:
+		dslengine.ReportError("invalid message type, must be a user type, a media type or the name of one")
+	}
+	return nil
+}
+
 // newAttribute creates a new attribute definition using the media type with the given identifier
 // as base type.
 func newAttribute(baseMT string) *design.AttributeDefinition {
@@ -112,6 +112,78 @@ var _ = Describe("Action", func() {
 		})
 	})
 
+	Context("with Idempotent", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(POST("/"))
+				Idempotent()
+			}
+		})
+
+		It("marks the action idempotent", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action).ShouldNot(BeNil())
+			Ω(action.Idempotent).Should(BeTrue())
+		})
+	})
+
+	Context("with IdempotencyKey", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(POST("/"))
+				IdempotencyKey()
+			}
+		})
+
+		It("deduplicates the action requests", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action).ShouldNot(BeNil())
+			Ω(action.IdempotencyKey).Should(BeTrue())
+			Ω(action.Idempotent).Should(BeFalse())
+		})
+	})
+
+	Context("with websocket messages", func() {
+		var msg *UserTypeDefinition
+		var schemes []string
+
+		BeforeEach(func() {
+			name = "foo"
+			schemes = []string{"ws"}
+			msg = Type("Message", func() {
+				Attribute("body", String)
+				Required("body")
+			})
+			dsl = func() {
+				Scheme(schemes...)
+				Routing(GET("/chat"))
+				InboundMessage(msg)
+				OutboundMessage("Message")
+			}
+		})
+
+		It("produces a valid action with the message types", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action).ShouldNot(BeNil())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.InboundMessage).Should(Equal(msg))
+			Ω(action.OutboundMessage).Should(Equal(msg))
+		})
+
+		Context("on an action that does not use websockets", func() {
+			BeforeEach(func() {
+				schemes = []string{"http"}
+			})
+
+			It("produces an invalid action", func() {
+				Ω(action).ShouldNot(BeNil())
+				Ω(action.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
 	Context("with a name and DSL defining a description, route, headers, payload and responses", func() {
 		const typeName = "typeName"
 		const description = "description"
@@ -40,6 +40,7 @@ import (
 //			Param("param")
 //		})
 //		Security("JWT")
+//		HealthCheck("/healthz")			// Mount health, readiness and liveness endpoints
 //		Origin("http://swagger.goa.design", func() { // Define CORS policy, may be prefixed with "*" wildcard
 //			Headers("X-Shared-Secret")           // One or more authorized headers, use "*" to authorize all
 //			Methods("GET", "POST")               // One or more authorized HTTP methods
@@ -154,6 +155,23 @@ func BasePath(val string) {
 	}
 }
 
+// HealthCheck can be used in: API
+//
+// HealthCheck mounts the service health endpoints under the given path. The generated code calls
+// Service.MountHealth which serves the readiness report on the path itself and on path/ready and
+// the liveness report on path/live. The endpoints run the checks registered with
+// Service.AddCheck and respond with status 200 or 503. The path is absolute, it is not prefixed
+// with the API base path. Example:
+//
+//	API("cellar", func() {
+//		HealthCheck("/healthz")
+//	})
+func HealthCheck(path string) {
+	if a, ok := apiDefinition(); ok {
+		a.HealthCheckPath = path
+	}
+}
+
 // Origin can be used in: Resource, API
 //
 // Origin defines the CORS policy for a given origin. The origin can use a wildcard prefix
@@ -56,6 +56,20 @@ var _ = Describe("API", func() {
 		})
 	})
 
+	Context("with an invalid health check path", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				HealthCheck("/health/:id")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("must not contain wildcards"))
+		})
+	})
+
 	Context("with valid DSL", func() {
 		JustBeforeEach(func() {
 			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
@@ -214,6 +228,20 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with a HealthCheck", func() {
+			const path = "/healthz"
+
+			BeforeEach(func() {
+				dsl = func() {
+					HealthCheck(path)
+				}
+			})
+
+			It("sets the API health check path", func() {
+				Ω(Design.HealthCheckPath).Should(Equal(path))
+			})
+		})
+
 		Context("with Params", func() {
 			const param1Name = "accountID"
 			const param1Type = Integer
@@ -67,6 +67,22 @@ import (
 //
 //        Metadata("swagger:extension:x-api", `{"foo":"bar"}`)
 //
+// `ratelimit`: limits the number of requests each client may make to the action. The value is the
+// number of requests followed by the period expressed as a Go duration. The period defaults to one
+// unit if it has no number. Action values override resource values. See the
+// github.com/goadesign/goa/middleware/ratelimit package for configuring how clients are identified.
+// Applicable to resources and actions.
+//
+//        Metadata("ratelimit", "100/1m")
+//        Metadata("ratelimit", "10/s")
+//
+// `log:redact`: marks the attribute as sensitive. The code generated by goagen app implements the
+// RedactedFields method on the payload and user types that contain such attributes so that the
+// github.com/goadesign/goa/middleware/accesslog middleware does not log their values.
+// Applicable to attributes.
+//
+//        Metadata("log:redact")
+//
 // The special key names listed above may be used as follows:
 //
 //        var Account = Type("Account", func() {
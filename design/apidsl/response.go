@@ -122,6 +122,28 @@ func Status(status int) {
 	}
 }
 
+// ServerSentEvents can be used in: Response, ResponseTemplate
+//
+// ServerSentEvents indicates that the response body is a stream of server-sent events
+// (text/event-stream). Each event carries an instance of the response media type rendered with
+// the response view. The generated action context exposes a Send method that writes and flushes
+// the events and the generated client exposes a typed reader:
+//
+//	Action("updates", func() {
+//		Routing(GET("/updates"))
+//		Response(OK, BottleMedia, func() {
+//			ServerSentEvents()
+//		})
+//	})
+//
+// An action may define only one server-sent events response and the response media type must be
+// defined in the design.
+func ServerSentEvents() {
+	if r, ok := responseDefinition(); ok {
+		r.ServerSentEvents = true
+	}
+}
+
 func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.ResponseDefinition {
 	var params []string
 	var dsl func()
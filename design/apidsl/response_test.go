@@ -177,6 +177,54 @@ var _ = Describe("Response", func() {
 		})
 	})
 
+	Context("with server-sent events", func() {
+		const status = 200
+
+		BeforeEach(func() {
+			name = "foo"
+			MediaType("application/vnd.event", func() {
+				Attributes(func() {
+					Attribute("id", String)
+				})
+				View("default", func() {
+					Attribute("id")
+				})
+			})
+		})
+
+		Context("and a media type defined in the design", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Status(status)
+					Media("application/vnd.event")
+					ServerSentEvents()
+				}
+			})
+
+			It("produces a valid streaming response", func() {
+				Ω(res).ShouldNot(BeNil())
+				Ω(res.Validate()).ShouldNot(HaveOccurred())
+				Ω(res.ServerSentEvents).Should(BeTrue())
+				Ω(res.Parent.(*ActionDefinition).EventStream()).Should(Equal(res))
+			})
+		})
+
+		Context("and an unknown media type", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Status(status)
+					Media("text/plain")
+					ServerSentEvents()
+				}
+			})
+
+			It("produces an invalid response definition", func() {
+				Ω(res).ShouldNot(BeNil())
+				Ω(res.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
 	Context("not from the goa default definitions", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -12,7 +12,7 @@ import (
 // API level, it will apply to all resources by default, following the same logic.
 //
 // The scheme refers to previous definitions of either OAuth2Security, BasicAuthSecurity,
-// APIKeySecurity or JWTSecurity.  It can be a string, corresponding to the first parameter of
+// APIKeySecurity, JWTSecurity or MutualTLSSecurity.  It can be a string, corresponding to the first parameter of
 // those definitions, or a SecuritySchemeDefinition, returned by those same functions. Examples:
 //
 //    Security(BasicAuth)
@@ -87,6 +87,42 @@ func NoSecurity() {
 	}
 }
 
+// Authorize can be used in: Action, Resource
+//
+// Authorize lists the authorization policies that must allow a request before the action runs.
+// When defined on a Resource, it applies to all Actions, unless overriden by individual actions.
+// The generated code calls the goa.Policy set on the service with the policy names, the principal
+// authenticated by the security middleware and the decoded params and payload. How the policy
+// names are interpreted (e.g. as roles where any of them grants access) is up to the Policy.
+// Requests that are not allowed are rejected with goa.ErrForbidden. Example:
+//
+//    Action("update", func() {
+//        Security(JWT)
+//        Authorize("owner", "admin")
+//    })
+//
+func Authorize(policies ...string) {
+	if len(policies) == 0 {
+		dslengine.ReportError("Authorize requires at least one policy name")
+		return
+	}
+	for _, p := range policies {
+		if p == "" {
+			dslengine.ReportError("policy names must not be empty")
+			return
+		}
+	}
+	def := &design.AuthorizationDefinition{Policies: policies}
+	switch parent := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		parent.Authorization = def
+	case *design.ResourceDefinition:
+		parent.Authorization = def
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
 // BasicAuthSecurity is a top level DSL.
 // BasicAuthSecurity defines a "basic" security scheme for the API.
 //
@@ -264,6 +300,47 @@ func JWTSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
 	return def
 }
 
+// MutualTLSSecurity is a top level DSL.
+// MutualTLSSecurity defines a "mutualTLS" security scheme where clients authenticate with a
+// certificate verified during the TLS handshake. The service must be configured to verify client
+// certificates, see goa.TLSConfig.
+//
+// Swagger 2.0 does not support mutual TLS so the swagger generator describes the requirement in
+// the operation description instead.
+//
+// Example:
+//
+//    MutualTLSSecurity("client_cert", func() {
+//        Description("Client certificate issued by the internal CA")
+//    })
+//
+func MutualTLSSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition, *dslengine.TopLevelDefinition:
+	default:
+		dslengine.IncompatibleDSL()
+		return nil
+	}
+
+	if securitySchemeRedefined(name) {
+		return nil
+	}
+
+	def := &design.SecuritySchemeDefinition{
+		SchemeName: name,
+		Kind:       design.MutualTLSSecurityKind,
+		Type:       "mutualTLS",
+	}
+
+	if len(dsl) != 0 {
+		def.DSLFunc = dsl[0]
+	}
+
+	design.Design.SecuritySchemes = append(design.Design.SecuritySchemes, def)
+
+	return def
+}
+
 // Scope can be used in: Security, JWTSecurity, OAuth2Security
 //
 // Scope defines an authorization scope. Used within SecurityScheme, a description may be provided
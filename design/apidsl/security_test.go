@@ -237,4 +237,47 @@ var _ = Describe("Security", func() {
 			Ω(Design.Resources["auth"].Actions["refresh"].Security.Scheme.SchemeName).Should(Equal("jwt"))
 		})
 	})
+
+	Context("with authorization policies", func() {
+		It("should fallback to the resource policies", func() {
+			API("", nil)
+			Resource("bottle", func() {
+				Authorize("owner", "admin")
+
+				Action("show", func() {
+					Routing(GET("/"))
+				})
+				Action("delete", func() {
+					Routing(DELETE("/"))
+					Authorize("admin")
+				})
+			})
+			Resource("public", func() {
+				Action("list", func() {
+					Routing(GET("/public"))
+				})
+			})
+
+			dslengine.Run()
+
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["bottle"].Actions["show"].Authorization.Policies).Should(Equal([]string{"owner", "admin"}))
+			Ω(Design.Resources["bottle"].Actions["delete"].Authorization.Policies).Should(Equal([]string{"admin"}))
+			Ω(Design.Resources["public"].Actions["list"].Authorization).Should(BeNil())
+		})
+
+		It("should require policy names", func() {
+			API("", nil)
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/"))
+					Authorize()
+				})
+			})
+
+			dslengine.Run()
+
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
 })
@@ -74,6 +74,9 @@ type (
 		Security *SecurityDefinition
 		// NoExamples indicates whether to bypass automatic example generation.
 		NoExamples bool
+		// HealthCheckPath is the path of the health endpoints mounted by the generated code,
+		// empty if the API does not expose health endpoints.
+		HealthCheckPath string
 
 		// rand is the random generator used to generate examples.
 		rand *RandomGenerator
@@ -144,6 +147,9 @@ type (
 		// Security defines security requirements for the Resource,
 		// for actions that don't define one themselves.
 		Security *SecurityDefinition
+		// Authorization lists the authorization policies of the Resource,
+		// for actions that don't define them themselves.
+		Authorization *AuthorizationDefinition
 	}
 
 	// CORSDefinition contains the definition for a specific origin CORS policy.
@@ -204,6 +210,9 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Standard is true if the response definition comes from the goa default responses
 		Standard bool
+		// ServerSentEvents is true if the response body is a stream of server-sent events
+		// (text/event-stream) where each event is an instance of the response media type.
+		ServerSentEvents bool
 	}
 
 	// ResponseTemplateDefinition defines a response template.
@@ -247,12 +256,25 @@ type (
 		PayloadOptional bool
 		// PayloadOptional is true if the request payload is multipart, false otherwise.
 		PayloadMultipart bool
+		// Idempotent is true if the action was explicitly declared idempotent.
+		Idempotent bool
+		// IdempotencyKey is true if the action requests are deduplicated using their
+		// Idempotency-Key header.
+		IdempotencyKey bool
+		// InboundMessage is the type of the messages sent by the clients of a websocket
+		// action if any, either a user type or a media type.
+		InboundMessage DataType
+		// OutboundMessage is the type of the messages sent to the clients of a websocket
+		// action if any, either a user type or a media type.
+		OutboundMessage DataType
 		// Request headers that need to be made available to action
 		Headers *AttributeDefinition
 		// Metadata is a list of key/value pairs
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the action
 		Security *SecurityDefinition
+		// Authorization lists the authorization policies checked before the action runs
+		Authorization *AuthorizationDefinition
 	}
 
 	// FileServerDefinition defines an endpoint that servers static assets.
@@ -1419,11 +1441,12 @@ func (r *ResponseDefinition) Finalize() {
 // Dup returns a copy of the response definition.
 func (r *ResponseDefinition) Dup() *ResponseDefinition {
 	res := ResponseDefinition{
-		Name:        r.Name,
-		Status:      r.Status,
-		Description: r.Description,
-		MediaType:   r.MediaType,
-		ViewName:    r.ViewName,
+		Name:             r.Name,
+		Status:           r.Status,
+		Description:      r.Description,
+		MediaType:        r.MediaType,
+		ViewName:         r.ViewName,
+		ServerSentEvents: r.ServerSentEvents,
 	}
 	if r.Headers != nil {
 		res.Headers = DupAtt(r.Headers)
@@ -1449,6 +1472,9 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 		r.MediaType = other.MediaType
 		r.ViewName = other.ViewName
 	}
+	if !r.ServerSentEvents {
+		r.ServerSentEvents = other.ServerSentEvents
+	}
 	if other.Headers != nil {
 		otherHeaders := other.Headers.Type.ToObject()
 		if len(otherHeaders) > 0 {
@@ -1584,7 +1610,45 @@ func (a *ActionDefinition) WebSocket() bool {
 	return true
 }
 
-// Finalize inherits security scheme and action responses from parent and top level design.
+// WebSocketMessages returns the types of the messages exchanged by a websocket action. User
+// types are returned as is while media types are projected onto their default view. The returned
+// types are nil if the action does not declare the corresponding messages.
+func (a *ActionDefinition) WebSocketMessages() (in, out DataType, err error) {
+	if in, err = messageType(a.InboundMessage); err != nil {
+		return
+	}
+	out, err = messageType(a.OutboundMessage)
+	return
+}
+
+// messageType projects media types used to describe websocket messages onto their default view.
+func messageType(dt DataType) (DataType, error) {
+	if mt, ok := dt.(*MediaTypeDefinition); ok {
+		p, _, err := mt.Project(DefaultView)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+	return dt, nil
+}
+
+// EventStream returns the action response that streams server-sent events, nil if the action
+// does not stream events. Responses are looked up in alphabetical order so that the same
+// response is returned if the design (invalidly) defines more than one.
+func (a *ActionDefinition) EventStream() *ResponseDefinition {
+	var stream *ResponseDefinition
+	a.IterateResponses(func(r *ResponseDefinition) error {
+		if stream == nil && r.ServerSentEvents {
+			stream = r
+		}
+		return nil
+	})
+	return stream
+}
+
+// Finalize inherits security scheme, authorization policies and action responses from parent and
+// top level design.
 func (a *ActionDefinition) Finalize() {
 	// Inherit security scheme
 	if a.Security == nil {
@@ -1598,6 +1662,11 @@ func (a *ActionDefinition) Finalize() {
 		a.Security = nil
 	}
 
+	// Inherit authorization policies
+	if a.Authorization == nil {
+		a.Authorization = a.Parent.Authorization
+	}
+
 	if a.Payload != nil {
 		a.Payload.Finalize()
 	}
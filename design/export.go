@@ -0,0 +1,1201 @@
+package design
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/goadesign/goa/dslengine"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// ExportFormat identifies documents produced by ExportDesign.
+	ExportFormat = "goa-design"
+
+	// ExportVersion is the version of the document format produced by ExportDesign.
+	ExportVersion = "1"
+)
+
+// The types below describe the serialized design document. Parent definitions are not
+// serialized, they are restored when the document is loaded. Attribute types are serialized
+// using their kind name and user types and media types are serialized by reference when they
+// are defined at the top level of the design.
+type (
+	designDoc struct {
+		Format  string  `json:"format"`
+		Version string  `json:"version"`
+		API     *apiDoc `json:"api"`
+	}
+
+	apiDoc struct {
+		Name            string                       `json:"name"`
+		Title           string                       `json:"title,omitempty"`
+		Description     string                       `json:"description,omitempty"`
+		Version         string                       `json:"version,omitempty"`
+		Host            string                       `json:"host,omitempty"`
+		Schemes         []string                     `json:"schemes,omitempty"`
+		BasePath        string                       `json:"base_path,omitempty"`
+		Params          *attributeDoc                `json:"params,omitempty"`
+		Consumes        []*encodingDoc               `json:"consumes,omitempty"`
+		Produces        []*encodingDoc               `json:"produces,omitempty"`
+		Origins         map[string]*corsDoc          `json:"origins,omitempty"`
+		TermsOfService  string                       `json:"terms_of_service,omitempty"`
+		Contact         *ContactDefinition           `json:"contact,omitempty"`
+		License         *LicenseDefinition           `json:"license,omitempty"`
+		Docs            *DocsDefinition              `json:"docs,omitempty"`
+		Types           map[string]*attributeDoc     `json:"types,omitempty"`
+		MediaTypes      map[string]*mediaTypeDoc     `json:"media_types,omitempty"`
+		Resources       map[string]*resourceDoc      `json:"resources,omitempty"`
+		Responses       map[string]*responseDoc      `json:"responses,omitempty"`
+		SecuritySchemes []*securitySchemeDoc         `json:"security_schemes,omitempty"`
+		Security        *securityDoc                 `json:"security,omitempty"`
+		NoExamples      bool                         `json:"no_examples,omitempty"`
+		HealthCheckPath string                       `json:"health_check_path,omitempty"`
+		Metadata        dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	attributeDoc struct {
+		Type        string                       `json:"type"`
+		TypeName    string                       `json:"type_name,omitempty"`
+		Inline      *attributeDoc                `json:"inline,omitempty"`
+		Attributes  map[string]*attributeDoc     `json:"attributes,omitempty"`
+		Key         *attributeDoc                `json:"key,omitempty"`
+		Elem        *attributeDoc                `json:"elem,omitempty"`
+		Description string                       `json:"description,omitempty"`
+		Validation  *validationDoc               `json:"validation,omitempty"`
+		Metadata    dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		Default     interface{}                  `json:"default,omitempty"`
+		Example     interface{}                  `json:"example,omitempty"`
+		View        string                       `json:"view,omitempty"`
+		NonZero     []string                     `json:"non_zero,omitempty"`
+	}
+
+	validationDoc struct {
+		Values    []interface{} `json:"enum,omitempty"`
+		Format    string        `json:"format,omitempty"`
+		Pattern   string        `json:"pattern,omitempty"`
+		Minimum   *float64      `json:"minimum,omitempty"`
+		Maximum   *float64      `json:"maximum,omitempty"`
+		MinLength *int          `json:"min_length,omitempty"`
+		MaxLength *int          `json:"max_length,omitempty"`
+		Required  []string      `json:"required,omitempty"`
+	}
+
+	mediaTypeDoc struct {
+		Identifier  string                   `json:"identifier"`
+		ContentType string                   `json:"content_type,omitempty"`
+		TypeName    string                   `json:"type_name"`
+		Resource    string                   `json:"resource,omitempty"`
+		Attribute   *attributeDoc            `json:"attribute"`
+		Links       map[string]*linkDoc      `json:"links,omitempty"`
+		Views       map[string]*attributeDoc `json:"views,omitempty"`
+	}
+
+	linkDoc struct {
+		View        string `json:"view,omitempty"`
+		URITemplate string `json:"uri_template,omitempty"`
+	}
+
+	resourceDoc struct {
+		Schemes             []string                     `json:"schemes,omitempty"`
+		BasePath            string                       `json:"base_path,omitempty"`
+		Params              *attributeDoc                `json:"params,omitempty"`
+		ParentName          string                       `json:"parent,omitempty"`
+		Description         string                       `json:"description,omitempty"`
+		MediaType           string                       `json:"media_type,omitempty"`
+		DefaultViewName     string                       `json:"default_view,omitempty"`
+		Actions             map[string]*actionDoc        `json:"actions,omitempty"`
+		FileServers         []*fileServerDoc             `json:"file_servers,omitempty"`
+		CanonicalActionName string                       `json:"canonical_action,omitempty"`
+		Responses           map[string]*responseDoc      `json:"responses,omitempty"`
+		Headers             *attributeDoc                `json:"headers,omitempty"`
+		Origins             map[string]*corsDoc          `json:"origins,omitempty"`
+		Metadata            dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		Security            *securityDoc                 `json:"security,omitempty"`
+		Authorization       *AuthorizationDefinition     `json:"authorization,omitempty"`
+	}
+
+	actionDoc struct {
+		Description      string                       `json:"description,omitempty"`
+		Docs             *DocsDefinition              `json:"docs,omitempty"`
+		Schemes          []string                     `json:"schemes,omitempty"`
+		Routes           []*routeDoc                  `json:"routes,omitempty"`
+		Responses        map[string]*responseDoc      `json:"responses,omitempty"`
+		Params           *attributeDoc                `json:"params,omitempty"`
+		QueryParams      *attributeDoc                `json:"query_params,omitempty"`
+		Payload          *attributeDoc                `json:"payload,omitempty"`
+		PayloadOptional  bool                         `json:"payload_optional,omitempty"`
+		PayloadMultipart bool                         `json:"payload_multipart,omitempty"`
+		Idempotent       bool                         `json:"idempotent,omitempty"`
+		IdempotencyKey   bool                         `json:"idempotency_key,omitempty"`
+		InboundMessage   *attributeDoc                `json:"inbound_message,omitempty"`
+		OutboundMessage  *attributeDoc                `json:"outbound_message,omitempty"`
+		Headers          *attributeDoc                `json:"headers,omitempty"`
+		Metadata         dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		Security         *securityDoc                 `json:"security,omitempty"`
+		Authorization    *AuthorizationDefinition     `json:"authorization,omitempty"`
+	}
+
+	routeDoc struct {
+		Verb     string                       `json:"verb"`
+		Path     string                       `json:"path"`
+		Metadata dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	responseDoc struct {
+		Status      int                          `json:"status"`
+		Description string                       `json:"description,omitempty"`
+		Type        *attributeDoc                `json:"type,omitempty"`
+		MediaType   string                       `json:"media_type,omitempty"`
+		ViewName    string                       `json:"view,omitempty"`
+		Headers     *attributeDoc                `json:"headers,omitempty"`
+		Metadata    dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		Standard    bool                         `json:"standard,omitempty"`
+		Stream      bool                         `json:"server_sent_events,omitempty"`
+	}
+
+	fileServerDoc struct {
+		Description string                       `json:"description,omitempty"`
+		Docs        *DocsDefinition              `json:"docs,omitempty"`
+		FilePath    string                       `json:"file_path"`
+		RequestPath string                       `json:"request_path"`
+		Metadata    dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		Security    *securityDoc                 `json:"security,omitempty"`
+	}
+
+	encodingDoc struct {
+		MIMETypes   []string `json:"mime_types"`
+		PackagePath string   `json:"package_path,omitempty"`
+		Function    string   `json:"function,omitempty"`
+		Encoder     bool     `json:"encoder,omitempty"`
+	}
+
+	corsDoc struct {
+		Headers     []string `json:"headers,omitempty"`
+		Methods     []string `json:"methods,omitempty"`
+		Exposed     []string `json:"exposed,omitempty"`
+		MaxAge      uint     `json:"max_age,omitempty"`
+		Credentials bool     `json:"credentials,omitempty"`
+		Regexp      bool     `json:"regexp,omitempty"`
+	}
+
+	securitySchemeDoc struct {
+		Kind             string                       `json:"kind"`
+		SchemeName       string                       `json:"scheme"`
+		Type             string                       `json:"type"`
+		Description      string                       `json:"description,omitempty"`
+		In               string                       `json:"in,omitempty"`
+		Name             string                       `json:"name,omitempty"`
+		Scopes           map[string]string            `json:"scopes,omitempty"`
+		Flow             string                       `json:"flow,omitempty"`
+		TokenURL         string                       `json:"token_url,omitempty"`
+		AuthorizationURL string                       `json:"authorization_url,omitempty"`
+		Metadata         dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	securityDoc struct {
+		Scheme string   `json:"scheme,omitempty"`
+		None   bool     `json:"none,omitempty"`
+		Scopes []string `json:"scopes,omitempty"`
+	}
+
+	// exporter builds a design document from an API definition.
+	exporter struct {
+		api *APIDefinition
+	}
+
+	// importer builds an API definition from a design document.
+	importer struct {
+		api *APIDefinition
+		// fixups convert the default, example and enum values once all the types are
+		// loaded.
+		fixups []func()
+	}
+)
+
+// kindNames lists the names used to serialize data type kinds.
+var kindNames = map[Kind]string{
+	BooleanKind:   "boolean",
+	IntegerKind:   "integer",
+	NumberKind:    "number",
+	StringKind:    "string",
+	DateTimeKind:  "datetime",
+	UUIDKind:      "uuid",
+	AnyKind:       "any",
+	FileKind:      "file",
+	ArrayKind:     "array",
+	ObjectKind:    "object",
+	HashKind:      "hash",
+	UserTypeKind:  "user",
+	MediaTypeKind: "media",
+}
+
+// securityKindNames lists the names used to serialize security scheme kinds.
+var securityKindNames = map[SecuritySchemeKind]string{
+	OAuth2SecurityKind:    "oauth2",
+	BasicAuthSecurityKind: "basic",
+	APIKeySecurityKind:    "apiKey",
+	JWTSecurityKind:       "jwt",
+	NoSecurityKind:        "none",
+	MutualTLSSecurityKind: "mutualTLS",
+}
+
+// ExportDesign serializes the given API definition into a JSON document. The API definition must
+// be finalized, that is the DSL must have run. The document can be loaded back with ImportDesign
+// or LoadDesign.
+func ExportDesign(api *APIDefinition) ([]byte, error) {
+	e := &exporter{api: api}
+	doc := &designDoc{Format: ExportFormat, Version: ExportVersion, API: e.exportAPI()}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// ImportDesign rebuilds an API definition from a JSON document produced by ExportDesign. The
+// returned definition is finalized, the DSL does not need to run. Note that most of the design
+// package logic relies on the global Design variable so that callers typically need to assign
+// the returned definition to it.
+func ImportDesign(data []byte) (*APIDefinition, error) {
+	var doc designDoc
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Format != ExportFormat {
+		return nil, fmt.Errorf("not a design document, format is %#v", doc.Format)
+	}
+	if doc.Version != ExportVersion {
+		return nil, fmt.Errorf("unsupported design document version %#v", doc.Version)
+	}
+	if doc.API == nil {
+		return nil, fmt.Errorf("design document is missing the API definition")
+	}
+	i := &importer{api: NewAPIDefinition()}
+	if err := i.importAPI(doc.API); err != nil {
+		return nil, err
+	}
+	return i.api, nil
+}
+
+// LoadDesign reads the design document at the given path and rebuilds the corresponding API
+// definition. Files with a ".yaml" or ".yml" extension are decoded as YAML, other files as JSON.
+func LoadDesign(path string) (*APIDefinition, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %s", path, err)
+		}
+	}
+	api, err := ImportDesign(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %s", path, err)
+	}
+	return api, nil
+}
+
+func (e *exporter) exportAPI() *apiDoc {
+	a := e.api
+	doc := &apiDoc{
+		Name:            a.Name,
+		Title:           a.Title,
+		Description:     a.Description,
+		Version:         a.Version,
+		Host:            a.Host,
+		Schemes:         a.Schemes,
+		BasePath:        a.BasePath,
+		Params:          e.exportAttribute(a.Params),
+		Consumes:        exportEncodings(a.Consumes),
+		Produces:        exportEncodings(a.Produces),
+		Origins:         exportOrigins(a.Origins),
+		TermsOfService:  a.TermsOfService,
+		Contact:         a.Contact,
+		License:         a.License,
+		Docs:            a.Docs,
+		Responses:       e.exportResponses(a.Responses),
+		Security:        exportSecurity(a.Security),
+		NoExamples:      a.NoExamples,
+		HealthCheckPath: a.HealthCheckPath,
+		Metadata:        a.Metadata,
+	}
+	if len(a.Types) > 0 {
+		doc.Types = make(map[string]*attributeDoc, len(a.Types))
+		for n, ut := range a.Types {
+			doc.Types[n] = e.exportAttribute(ut.AttributeDefinition)
+		}
+	}
+	if len(a.MediaTypes) > 0 {
+		doc.MediaTypes = make(map[string]*mediaTypeDoc, len(a.MediaTypes))
+		for id, mt := range a.MediaTypes {
+			doc.MediaTypes[id] = e.exportMediaType(mt)
+		}
+	}
+	if len(a.Resources) > 0 {
+		doc.Resources = make(map[string]*resourceDoc, len(a.Resources))
+		for n, r := range a.Resources {
+			doc.Resources[n] = e.exportResource(r)
+		}
+	}
+	for _, s := range a.SecuritySchemes {
+		doc.SecuritySchemes = append(doc.SecuritySchemes, &securitySchemeDoc{
+			Kind:             securityKindNames[s.Kind],
+			SchemeName:       s.SchemeName,
+			Type:             s.Type,
+			Description:      s.Description,
+			In:               s.In,
+			Name:             s.Name,
+			Scopes:           s.Scopes,
+			Flow:             s.Flow,
+			TokenURL:         s.TokenURL,
+			AuthorizationURL: s.AuthorizationURL,
+			Metadata:         s.Metadata,
+		})
+	}
+	return doc
+}
+
+func (e *exporter) exportMediaType(mt *MediaTypeDefinition) *mediaTypeDoc {
+	doc := &mediaTypeDoc{
+		Identifier:  mt.Identifier,
+		ContentType: mt.ContentType,
+		TypeName:    mt.TypeName,
+		Attribute:   e.exportAttribute(mt.AttributeDefinition),
+	}
+	if mt.Resource != nil {
+		doc.Resource = mt.Resource.Name
+	}
+	if len(mt.Links) > 0 {
+		doc.Links = make(map[string]*linkDoc, len(mt.Links))
+		for n, l := range mt.Links {
+			doc.Links[n] = &linkDoc{View: l.View, URITemplate: l.URITemplate}
+		}
+	}
+	if len(mt.Views) > 0 {
+		doc.Views = make(map[string]*attributeDoc, len(mt.Views))
+		for n, v := range mt.Views {
+			doc.Views[n] = e.exportAttribute(v.AttributeDefinition)
+		}
+	}
+	return doc
+}
+
+func (e *exporter) exportResource(r *ResourceDefinition) *resourceDoc {
+	doc := &resourceDoc{
+		Schemes:             r.Schemes,
+		BasePath:            r.BasePath,
+		Params:              e.exportAttribute(r.Params),
+		ParentName:          r.ParentName,
+		Description:         r.Description,
+		MediaType:           r.MediaType,
+		DefaultViewName:     r.DefaultViewName,
+		CanonicalActionName: r.CanonicalActionName,
+		Responses:           e.exportResponses(r.Responses),
+		Headers:             e.exportAttribute(r.Headers),
+		Origins:             exportOrigins(r.Origins),
+		Metadata:            r.Metadata,
+		Security:            exportSecurity(r.Security),
+		Authorization:       r.Authorization,
+	}
+	if len(r.Actions) > 0 {
+		doc.Actions = make(map[string]*actionDoc, len(r.Actions))
+		for n, a := range r.Actions {
+			doc.Actions[n] = e.exportAction(a)
+		}
+	}
+	for _, f := range r.FileServers {
+		doc.FileServers = append(doc.FileServers, &fileServerDoc{
+			Description: f.Description,
+			Docs:        f.Docs,
+			FilePath:    f.FilePath,
+			RequestPath: f.RequestPath,
+			Metadata:    f.Metadata,
+			Security:    exportSecurity(f.Security),
+		})
+	}
+	return doc
+}
+
+func (e *exporter) exportAction(a *ActionDefinition) *actionDoc {
+	doc := &actionDoc{
+		Description:      a.Description,
+		Docs:             a.Docs,
+		Schemes:          a.Schemes,
+		Responses:        e.exportResponses(a.Responses),
+		Params:           e.exportAttribute(a.Params),
+		QueryParams:      e.exportAttribute(a.QueryParams),
+		PayloadOptional:  a.PayloadOptional,
+		PayloadMultipart: a.PayloadMultipart,
+		Idempotent:       a.Idempotent,
+		IdempotencyKey:   a.IdempotencyKey,
+		Headers:          e.exportAttribute(a.Headers),
+		Metadata:         a.Metadata,
+		Security:         exportSecurity(a.Security),
+		Authorization:    a.Authorization,
+	}
+	for _, r := range a.Routes {
+		doc.Routes = append(doc.Routes, &routeDoc{Verb: r.Verb, Path: r.Path, Metadata: r.Metadata})
+	}
+	if a.Payload != nil {
+		doc.Payload = e.exportType(a.Payload)
+	}
+	if a.InboundMessage != nil {
+		doc.InboundMessage = e.exportType(a.InboundMessage)
+	}
+	if a.OutboundMessage != nil {
+		doc.OutboundMessage = e.exportType(a.OutboundMessage)
+	}
+	return doc
+}
+
+func (e *exporter) exportResponses(responses map[string]*ResponseDefinition) map[string]*responseDoc {
+	if len(responses) == 0 {
+		return nil
+	}
+	res := make(map[string]*responseDoc, len(responses))
+	for n, r := range responses {
+		doc := &responseDoc{
+			Status:      r.Status,
+			Description: r.Description,
+			MediaType:   r.MediaType,
+			ViewName:    r.ViewName,
+			Headers:     e.exportAttribute(r.Headers),
+			Metadata:    r.Metadata,
+			Standard:    r.Standard,
+			Stream:      r.ServerSentEvents,
+		}
+		if r.Type != nil {
+			doc.Type = e.exportType(r.Type)
+		}
+		res[n] = doc
+	}
+	return res
+}
+
+// exportAttribute serializes the given attribute, it returns nil if att is nil.
+func (e *exporter) exportAttribute(att *AttributeDefinition) *attributeDoc {
+	if att == nil || att.Type == nil {
+		return nil
+	}
+	doc := e.exportType(att.Type)
+	doc.Description = att.Description
+	doc.Validation = exportValidation(att.Validation)
+	doc.Metadata = att.Metadata
+	doc.Default = exportValue(att.DefaultValue)
+	doc.Example = exportValue(att.Example)
+	doc.View = att.View
+	for n, ok := range att.NonZeroAttributes {
+		if ok {
+			doc.NonZero = append(doc.NonZero, n)
+		}
+	}
+	sort.Strings(doc.NonZero)
+	return doc
+}
+
+// exportType serializes the given data type. User types and media types defined at the top
+// level of the design are serialized by reference, other user types are inlined.
+func (e *exporter) exportType(dt DataType) *attributeDoc {
+	doc := &attributeDoc{Type: kindNames[dt.Kind()]}
+	switch actual := dt.(type) {
+	case *Array:
+		doc.Elem = e.exportAttribute(actual.ElemType)
+	case *Hash:
+		doc.Key = e.exportAttribute(actual.KeyType)
+		doc.Elem = e.exportAttribute(actual.ElemType)
+	case Object:
+		doc.Attributes = make(map[string]*attributeDoc, len(actual))
+		for n, att := range actual {
+			doc.Attributes[n] = e.exportAttribute(att)
+		}
+	case *UserTypeDefinition:
+		doc.TypeName = actual.TypeName
+		if e.api.Types[actual.TypeName] != actual {
+			doc.Inline = e.exportAttribute(actual.AttributeDefinition)
+		}
+	case *MediaTypeDefinition:
+		id := CanonicalIdentifier(actual.Identifier)
+		if e.api.MediaTypes[id] == actual {
+			doc.TypeName = id
+		} else {
+			// Media types that are not part of the design (e.g. projected media types)
+			// are inlined as user types.
+			doc.Type = kindNames[UserTypeKind]
+			doc.TypeName = actual.TypeName
+			doc.Inline = e.exportAttribute(actual.AttributeDefinition)
+		}
+	}
+	return doc
+}
+
+func exportValidation(val *dslengine.ValidationDefinition) *validationDoc {
+	if val == nil {
+		return nil
+	}
+	doc := &validationDoc{
+		Format:    val.Format,
+		Pattern:   val.Pattern,
+		Minimum:   val.Minimum,
+		Maximum:   val.Maximum,
+		MinLength: val.MinLength,
+		MaxLength: val.MaxLength,
+		Required:  val.Required,
+	}
+	for _, v := range val.Values {
+		doc.Values = append(doc.Values, exportValue(v))
+	}
+	return doc
+}
+
+// exportValue converts the given default, example or enum value into a value that can be
+// serialized to JSON.
+func exportValue(val interface{}) interface{} {
+	switch actual := val.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			res[fmt.Sprintf("%v", k)] = exportValue(v)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			res[k] = exportValue(v)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(actual))
+		for i, v := range actual {
+			res[i] = exportValue(v)
+		}
+		return res
+	case time.Time:
+		return actual.Format(time.RFC3339)
+	case fmt.Stringer:
+		return actual.String()
+	default:
+		return val
+	}
+}
+
+func exportEncodings(encs []*EncodingDefinition) []*encodingDoc {
+	var res []*encodingDoc
+	for _, enc := range encs {
+		res = append(res, &encodingDoc{
+			MIMETypes:   enc.MIMETypes,
+			PackagePath: enc.PackagePath,
+			Function:    enc.Function,
+			Encoder:     enc.Encoder,
+		})
+	}
+	return res
+}
+
+func exportOrigins(origins map[string]*CORSDefinition) map[string]*corsDoc {
+	if len(origins) == 0 {
+		return nil
+	}
+	res := make(map[string]*corsDoc, len(origins))
+	for o, c := range origins {
+		res[o] = &corsDoc{
+			Headers:     c.Headers,
+			Methods:     c.Methods,
+			Exposed:     c.Exposed,
+			MaxAge:      c.MaxAge,
+			Credentials: c.Credentials,
+			Regexp:      c.Regexp,
+		}
+	}
+	return res
+}
+
+func exportSecurity(sec *SecurityDefinition) *securityDoc {
+	if sec == nil || sec.Scheme == nil {
+		return nil
+	}
+	if sec.Scheme.Kind == NoSecurityKind {
+		return &securityDoc{None: true}
+	}
+	return &securityDoc{Scheme: sec.Scheme.SchemeName, Scopes: sec.Scopes}
+}
+
+func (i *importer) importAPI(doc *apiDoc) error {
+	a := i.api
+	a.Name = doc.Name
+	a.Title = doc.Title
+	a.Description = doc.Description
+	a.Version = doc.Version
+	a.Host = doc.Host
+	a.Schemes = doc.Schemes
+	a.BasePath = doc.BasePath
+	a.TermsOfService = doc.TermsOfService
+	a.Contact = doc.Contact
+	a.License = doc.License
+	a.Docs = doc.Docs
+	a.NoExamples = doc.NoExamples
+	a.HealthCheckPath = doc.HealthCheckPath
+	a.Metadata = doc.Metadata
+	a.Consumes = importEncodings(doc.Consumes)
+	a.Produces = importEncodings(doc.Produces)
+	a.Origins = importOrigins(doc.Origins, a)
+	a.Types = make(map[string]*UserTypeDefinition, len(doc.Types))
+	a.MediaTypes = make(map[string]*MediaTypeDefinition, len(doc.MediaTypes))
+	a.Resources = make(map[string]*ResourceDefinition, len(doc.Resources))
+
+	for _, s := range doc.SecuritySchemes {
+		var kind SecuritySchemeKind
+		for k, n := range securityKindNames {
+			if n == s.Kind {
+				kind = k
+			}
+		}
+		if kind == 0 {
+			return fmt.Errorf("unknown security scheme kind %#v", s.Kind)
+		}
+		a.SecuritySchemes = append(a.SecuritySchemes, &SecuritySchemeDefinition{
+			Kind:             kind,
+			SchemeName:       s.SchemeName,
+			Type:             s.Type,
+			Description:      s.Description,
+			In:               s.In,
+			Name:             s.Name,
+			Scopes:           s.Scopes,
+			Flow:             s.Flow,
+			TokenURL:         s.TokenURL,
+			AuthorizationURL: s.AuthorizationURL,
+			Metadata:         s.Metadata,
+		})
+	}
+	var err error
+	if a.Security, err = i.importSecurity(doc.Security); err != nil {
+		return err
+	}
+
+	// Create the user types and media types first so that references can be resolved.
+	for n := range doc.Types {
+		a.Types[n] = &UserTypeDefinition{TypeName: n, AttributeDefinition: &AttributeDefinition{}}
+	}
+	for id, mdoc := range doc.MediaTypes {
+		a.MediaTypes[id] = &MediaTypeDefinition{
+			UserTypeDefinition: &UserTypeDefinition{
+				TypeName:            mdoc.TypeName,
+				AttributeDefinition: &AttributeDefinition{},
+			},
+			Identifier:  mdoc.Identifier,
+			ContentType: mdoc.ContentType,
+		}
+	}
+	for n, tdoc := range doc.Types {
+		att, err := i.importAttribute(tdoc)
+		if err != nil {
+			return fmt.Errorf("type %s: %s", n, err)
+		}
+		a.Types[n].AttributeDefinition = att
+	}
+	for id, mdoc := range doc.MediaTypes {
+		if err := i.importMediaType(a.MediaTypes[id], mdoc); err != nil {
+			return fmt.Errorf("media type %s: %s", id, err)
+		}
+	}
+
+	if a.Params, err = i.importAttribute(doc.Params); err != nil {
+		return fmt.Errorf("API params: %s", err)
+	}
+	if a.Responses, err = i.importResponses(doc.Responses, a); err != nil {
+		return err
+	}
+	for n, rdoc := range doc.Resources {
+		r, err := i.importResource(n, rdoc)
+		if err != nil {
+			return fmt.Errorf("resource %s: %s", n, err)
+		}
+		a.Resources[n] = r
+	}
+	for id, mdoc := range doc.MediaTypes {
+		if mdoc.Resource != "" {
+			a.MediaTypes[id].Resource = a.Resources[mdoc.Resource]
+		}
+	}
+	for _, fixup := range i.fixups {
+		fixup()
+	}
+	return nil
+}
+
+func (i *importer) importMediaType(mt *MediaTypeDefinition, doc *mediaTypeDoc) error {
+	att, err := i.importAttribute(doc.Attribute)
+	if err != nil {
+		return err
+	}
+	if att != nil {
+		mt.AttributeDefinition = att
+	}
+	if len(doc.Links) > 0 {
+		mt.Links = make(map[string]*LinkDefinition, len(doc.Links))
+		for n, l := range doc.Links {
+			mt.Links[n] = &LinkDefinition{Name: n, View: l.View, URITemplate: l.URITemplate, Parent: mt}
+		}
+	}
+	if len(doc.Views) > 0 {
+		mt.Views = make(map[string]*ViewDefinition, len(doc.Views))
+		for n, vdoc := range doc.Views {
+			vatt, err := i.importAttribute(vdoc)
+			if err != nil {
+				return fmt.Errorf("view %s: %s", n, err)
+			}
+			mt.Views[n] = &ViewDefinition{AttributeDefinition: vatt, Name: n, Parent: mt}
+		}
+	}
+	return nil
+}
+
+func (i *importer) importResource(name string, doc *resourceDoc) (*ResourceDefinition, error) {
+	r := &ResourceDefinition{
+		Name:                name,
+		Schemes:             doc.Schemes,
+		BasePath:            doc.BasePath,
+		ParentName:          doc.ParentName,
+		Description:         doc.Description,
+		MediaType:           doc.MediaType,
+		DefaultViewName:     doc.DefaultViewName,
+		CanonicalActionName: doc.CanonicalActionName,
+		Metadata:            doc.Metadata,
+		Authorization:       doc.Authorization,
+		Actions:             make(map[string]*ActionDefinition, len(doc.Actions)),
+	}
+	r.Origins = importOrigins(doc.Origins, r)
+	var err error
+	if r.Params, err = i.importAttribute(doc.Params); err != nil {
+		return nil, err
+	}
+	if r.Headers, err = i.importAttribute(doc.Headers); err != nil {
+		return nil, err
+	}
+	if r.Security, err = i.importSecurity(doc.Security); err != nil {
+		return nil, err
+	}
+	if r.Responses, err = i.importResponses(doc.Responses, r); err != nil {
+		return nil, err
+	}
+	for n, adoc := range doc.Actions {
+		a, err := i.importAction(n, adoc, r)
+		if err != nil {
+			return nil, fmt.Errorf("action %s: %s", n, err)
+		}
+		r.Actions[n] = a
+	}
+	for _, fdoc := range doc.FileServers {
+		f := &FileServerDefinition{
+			Parent:      r,
+			Description: fdoc.Description,
+			Docs:        fdoc.Docs,
+			FilePath:    fdoc.FilePath,
+			RequestPath: fdoc.RequestPath,
+			Metadata:    fdoc.Metadata,
+		}
+		if f.Security, err = i.importSecurity(fdoc.Security); err != nil {
+			return nil, err
+		}
+		r.FileServers = append(r.FileServers, f)
+	}
+	return r, nil
+}
+
+func (i *importer) importAction(name string, doc *actionDoc, parent *ResourceDefinition) (*ActionDefinition, error) {
+	a := &ActionDefinition{
+		Name:             name,
+		Description:      doc.Description,
+		Docs:             doc.Docs,
+		Parent:           parent,
+		Schemes:          doc.Schemes,
+		PayloadOptional:  doc.PayloadOptional,
+		PayloadMultipart: doc.PayloadMultipart,
+		Idempotent:       doc.Idempotent,
+		IdempotencyKey:   doc.IdempotencyKey,
+		Metadata:         doc.Metadata,
+		Authorization:    doc.Authorization,
+	}
+	for _, r := range doc.Routes {
+		a.Routes = append(a.Routes, &RouteDefinition{Verb: r.Verb, Path: r.Path, Parent: a, Metadata: r.Metadata})
+	}
+	var err error
+	if a.Params, err = i.importAttribute(doc.Params); err != nil {
+		return nil, err
+	}
+	if a.QueryParams, err = i.importAttribute(doc.QueryParams); err != nil {
+		return nil, err
+	}
+	if a.Headers, err = i.importAttribute(doc.Headers); err != nil {
+		return nil, err
+	}
+	if a.Security, err = i.importSecurity(doc.Security); err != nil {
+		return nil, err
+	}
+	if a.Responses, err = i.importResponses(doc.Responses, a); err != nil {
+		return nil, err
+	}
+	if doc.Payload != nil {
+		dt, err := i.importType(doc.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload: %s", err)
+		}
+		ut, ok := dt.(*UserTypeDefinition)
+		if !ok {
+			return nil, fmt.Errorf("payload must be a user type")
+		}
+		a.Payload = ut
+	}
+	if doc.InboundMessage != nil {
+		if a.InboundMessage, err = i.importType(doc.InboundMessage); err != nil {
+			return nil, fmt.Errorf("inbound message: %s", err)
+		}
+	}
+	if doc.OutboundMessage != nil {
+		if a.OutboundMessage, err = i.importType(doc.OutboundMessage); err != nil {
+			return nil, fmt.Errorf("outbound message: %s", err)
+		}
+	}
+	return a, nil
+}
+
+func (i *importer) importResponses(docs map[string]*responseDoc, parent dslengine.Definition) (map[string]*ResponseDefinition, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	res := make(map[string]*ResponseDefinition, len(docs))
+	for n, doc := range docs {
+		r := &ResponseDefinition{
+			Name:             n,
+			Status:           doc.Status,
+			Description:      doc.Description,
+			MediaType:        doc.MediaType,
+			ViewName:         doc.ViewName,
+			Parent:           parent,
+			Metadata:         doc.Metadata,
+			Standard:         doc.Standard,
+			ServerSentEvents: doc.Stream,
+		}
+		var err error
+		if doc.Type != nil {
+			if r.Type, err = i.importType(doc.Type); err != nil {
+				return nil, fmt.Errorf("response %s: %s", n, err)
+			}
+		}
+		if r.Headers, err = i.importAttribute(doc.Headers); err != nil {
+			return nil, fmt.Errorf("response %s: %s", n, err)
+		}
+		res[n] = r
+	}
+	return res, nil
+}
+
+func (i *importer) importSecurity(doc *securityDoc) (*SecurityDefinition, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	if doc.None {
+		return &SecurityDefinition{Scheme: &SecuritySchemeDefinition{Kind: NoSecurityKind}}, nil
+	}
+	for _, s := range i.api.SecuritySchemes {
+		if s.SchemeName == doc.Scheme {
+			return &SecurityDefinition{Scheme: s, Scopes: doc.Scopes}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown security scheme %#v", doc.Scheme)
+}
+
+// importAttribute rebuilds the attribute described by doc, it returns nil if doc is nil.
+func (i *importer) importAttribute(doc *attributeDoc) (*AttributeDefinition, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	dt, err := i.importType(doc)
+	if err != nil {
+		return nil, err
+	}
+	att := &AttributeDefinition{
+		Type:        dt,
+		Description: doc.Description,
+		Metadata:    doc.Metadata,
+		View:        doc.View,
+	}
+	i.fixups = append(i.fixups, func() {
+		att.DefaultValue = importValue(doc.Default, dt)
+		att.Example = importValue(doc.Example, dt)
+	})
+	if v := doc.Validation; v != nil {
+		att.Validation = &dslengine.ValidationDefinition{
+			Format:    v.Format,
+			Pattern:   v.Pattern,
+			Minimum:   v.Minimum,
+			Maximum:   v.Maximum,
+			MinLength: v.MinLength,
+			MaxLength: v.MaxLength,
+			Required:  v.Required,
+		}
+		val := att.Validation
+		i.fixups = append(i.fixups, func() {
+			for _, value := range v.Values {
+				val.Values = append(val.Values, importValue(value, dt))
+			}
+		})
+	}
+	if len(doc.NonZero) > 0 {
+		att.NonZeroAttributes = make(map[string]bool, len(doc.NonZero))
+		for _, n := range doc.NonZero {
+			att.NonZeroAttributes[n] = true
+		}
+	}
+	return att, nil
+}
+
+// importType rebuilds the data type described by doc.
+func (i *importer) importType(doc *attributeDoc) (DataType, error) {
+	switch doc.Type {
+	case "boolean":
+		return Boolean, nil
+	case "integer":
+		return Integer, nil
+	case "number":
+		return Number, nil
+	case "string":
+		return String, nil
+	case "datetime":
+		return DateTime, nil
+	case "uuid":
+		return UUID, nil
+	case "any":
+		return Any, nil
+	case "file":
+		return File, nil
+	case "array":
+		elem, err := i.importAttribute(doc.Elem)
+		if err != nil {
+			return nil, err
+		}
+		if elem == nil {
+			return nil, fmt.Errorf("missing array element type")
+		}
+		return &Array{ElemType: elem}, nil
+	case "hash":
+		key, err := i.importAttribute(doc.Key)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := i.importAttribute(doc.Elem)
+		if err != nil {
+			return nil, err
+		}
+		if key == nil || elem == nil {
+			return nil, fmt.Errorf("missing hash key or element type")
+		}
+		return &Hash{KeyType: key, ElemType: elem}, nil
+	case "object":
+		obj := make(Object, len(doc.Attributes))
+		for n, adoc := range doc.Attributes {
+			att, err := i.importAttribute(adoc)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s: %s", n, err)
+			}
+			obj[n] = att
+		}
+		return obj, nil
+	case "user":
+		if doc.Inline != nil {
+			att, err := i.importAttribute(doc.Inline)
+			if err != nil {
+				return nil, err
+			}
+			return &UserTypeDefinition{TypeName: doc.TypeName, AttributeDefinition: att}, nil
+		}
+		ut, ok := i.api.Types[doc.TypeName]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %#v", doc.TypeName)
+		}
+		return ut, nil
+	case "media":
+		mt, ok := i.api.MediaTypes[doc.TypeName]
+		if !ok {
+			return nil, fmt.Errorf("unknown media type %#v", doc.TypeName)
+		}
+		return mt, nil
+	default:
+		return nil, fmt.Errorf("unknown type kind %#v", doc.Type)
+	}
+}
+
+// importValue converts a deserialized default, example or enum value to the Go type used by
+// the DSL for the given data type.
+func importValue(val interface{}, dt DataType) interface{} {
+	if val == nil {
+		return nil
+	}
+	switch dt.Kind() {
+	case IntegerKind:
+		if n, ok := val.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				return int(i)
+			}
+		}
+	case NumberKind:
+		if n, ok := val.(json.Number); ok {
+			if f, err := n.Float64(); err == nil {
+				return f
+			}
+		}
+	case ArrayKind:
+		if vals, ok := val.([]interface{}); ok {
+			elem := dt.ToArray().ElemType.Type
+			res := make([]interface{}, len(vals))
+			for i, v := range vals {
+				res[i] = importValue(v, elem)
+			}
+			return res
+		}
+	case HashKind:
+		if m, ok := val.(map[string]interface{}); ok {
+			h := dt.ToHash()
+			res := make(map[interface{}]interface{}, len(m))
+			for k, v := range m {
+				res[importKey(k, h.KeyType.Type)] = importValue(v, h.ElemType.Type)
+			}
+			return res
+		}
+	case ObjectKind, UserTypeKind, MediaTypeKind:
+		obj := dt.ToObject()
+		if m, ok := val.(map[string]interface{}); ok && obj != nil {
+			res := make(map[string]interface{}, len(m))
+			for k, v := range m {
+				if att, ok := obj[k]; ok {
+					res[k] = importValue(v, att.Type)
+				} else {
+					res[k] = importValue(v, Any)
+				}
+			}
+			return res
+		}
+		if dt.IsArray() || dt.IsHash() {
+			if ut, ok := dt.(*UserTypeDefinition); ok {
+				return importValue(val, ut.Type)
+			}
+			if mt, ok := dt.(*MediaTypeDefinition); ok {
+				return importValue(val, mt.Type)
+			}
+		}
+	}
+	return importAny(val)
+}
+
+// importAny converts the numbers contained in a deserialized value of unknown type to int or
+// float64.
+func importAny(val interface{}) interface{} {
+	switch actual := val.(type) {
+	case json.Number:
+		if i, err := actual.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := actual.Float64()
+		return f
+	case []interface{}:
+		res := make([]interface{}, len(actual))
+		for i, v := range actual {
+			res[i] = importAny(v)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			res[k] = importAny(v)
+		}
+		return res
+	default:
+		return val
+	}
+}
+
+// importKey converts a serialized hash key to the Go type used by the DSL for the given key type.
+func importKey(key string, dt DataType) interface{} {
+	switch dt.Kind() {
+	case IntegerKind:
+		if i, err := strconv.Atoi(key); err == nil {
+			return i
+		}
+	case NumberKind:
+		if f, err := strconv.ParseFloat(key, 64); err == nil {
+			return f
+		}
+	case BooleanKind:
+		if b, err := strconv.ParseBool(key); err == nil {
+			return b
+		}
+	}
+	return key
+}
+
+func importEncodings(docs []*encodingDoc) []*EncodingDefinition {
+	var res []*EncodingDefinition
+	for _, doc := range docs {
+		res = append(res, &EncodingDefinition{
+			MIMETypes:   doc.MIMETypes,
+			PackagePath: doc.PackagePath,
+			Function:    doc.Function,
+			Encoder:     doc.Encoder,
+		})
+	}
+	return res
+}
+
+func importOrigins(docs map[string]*corsDoc, parent dslengine.Definition) map[string]*CORSDefinition {
+	if len(docs) == 0 {
+		return nil
+	}
+	res := make(map[string]*CORSDefinition, len(docs))
+	for o, doc := range docs {
+		res[o] = &CORSDefinition{
+			Parent:      parent,
+			Origin:      o,
+			Headers:     doc.Headers,
+			Methods:     doc.Methods,
+			Exposed:     doc.Exposed,
+			MaxAge:      doc.MaxAge,
+			Credentials: doc.Credentials,
+			Regexp:      doc.Regexp,
+		}
+	}
+	return res
+}
+
+// yamlToJSON converts the given YAML document into JSON.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonValue(v))
+}
+
+// jsonValue converts the maps produced by the YAML decoder into maps indexed by strings.
+func jsonValue(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(actual))
+		for k, val := range actual {
+			res[fmt.Sprintf("%v", k)] = jsonValue(val)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(actual))
+		for i, val := range actual {
+			res[i] = jsonValue(val)
+		}
+		return res
+	default:
+		return v
+	}
+}
@@ -0,0 +1,209 @@
+package design_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var _ = Describe("ExportDesign", func() {
+	var exported []byte
+	var api *APIDefinition
+	var importErr error
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+		API("test", func() {
+			Title("title")
+			BasePath("/api")
+			HealthCheck("/healthz")
+			Origin("http://goa.design", func() {
+				Methods("GET")
+			})
+			JWTSecurity("jwt", func() {
+				Header("Authorization")
+				Scope("api:read")
+			})
+			Security("jwt")
+			Metadata("swagger:generate", "false")
+		})
+		BottleMedia := MediaType("application/vnd.goa.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("color", String, func() {
+					Enum("red", "white")
+					Default("red")
+				})
+				Attribute("ratings", HashOf(Integer, String))
+				Attribute("next", "application/vnd.goa.bottle")
+				Required("id")
+			})
+			Links(func() {
+				Link("next")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("color")
+				Attribute("links")
+			})
+			View("link", func() {
+				Attribute("id")
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			DefaultMedia(BottleMedia)
+			Authorize("owner")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", Integer, func() {
+						Minimum(1)
+					})
+				})
+				Response(OK)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(func() {
+					Member("color")
+					Attribute("years", ArrayOf(Integer), func() {
+						Default([]interface{}{2010, 2011})
+					})
+				})
+				Response(Created, CollectionOf(BottleMedia))
+				NoSecurity()
+				Authorize("owner", "admin")
+				Idempotent()
+				IdempotencyKey()
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		dslengine.Reset()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		exported, err = ExportDesign(Design)
+		Ω(err).ShouldNot(HaveOccurred())
+		api, importErr = ImportDesign(exported)
+	})
+
+	It("produces a versioned document", func() {
+		Ω(string(exported)).Should(ContainSubstring(`"format": "goa-design"`))
+		Ω(string(exported)).Should(ContainSubstring(`"version": "1"`))
+	})
+
+	It("round trips", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		again, err := ExportDesign(api)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(again)).Should(Equal(string(exported)))
+	})
+
+	It("restores the API properties", func() {
+		Ω(api.Name).Should(Equal("test"))
+		Ω(api.Title).Should(Equal("title"))
+		Ω(api.HealthCheckPath).Should(Equal("/healthz"))
+		Ω(api.Origins).Should(HaveKey("http://goa.design"))
+		Ω(api.Origins["http://goa.design"].Parent).Should(Equal(api))
+		Ω(api.Metadata).Should(Equal(dslengine.MetadataDefinition{"swagger:generate": {"false"}}))
+		Ω(api.SecuritySchemes).Should(HaveLen(1))
+		Ω(api.Security.Scheme).Should(Equal(api.SecuritySchemes[0]))
+		Ω(api.DefaultResponses).Should(HaveKey(OK))
+	})
+
+	It("restores the media types", func() {
+		mt := api.MediaTypes["application/vnd.goa.bottle"]
+		Ω(mt).ShouldNot(BeNil())
+		Ω(mt.Resource).Should(Equal(api.Resources["bottle"]))
+		obj := mt.Type.ToObject()
+		Ω(obj["next"].Type).Should(BeIdenticalTo(mt))
+		Ω(obj["color"].DefaultValue).Should(Equal("red"))
+		Ω(obj["color"].Validation.Values).Should(Equal([]interface{}{"red", "white"}))
+		Ω(obj["ratings"].Type.IsHash()).Should(BeTrue())
+		Ω(mt.Links).Should(HaveKey("next"))
+		Ω(mt.Links["next"].Parent).Should(BeIdenticalTo(mt))
+		Ω(mt.Views).Should(HaveKey("link"))
+		Ω(mt.Views["default"].Parent).Should(BeIdenticalTo(mt))
+		p, _, err := mt.Project("default")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(p.Type.ToObject()).Should(HaveKey("links"))
+	})
+
+	It("restores the resources and actions", func() {
+		r := api.Resources["bottle"]
+		Ω(r).ShouldNot(BeNil())
+		show := r.Actions["show"]
+		Ω(show.Parent).Should(BeIdenticalTo(r))
+		Ω(show.Routes).Should(HaveLen(1))
+		Ω(show.Routes[0].Parent).Should(BeIdenticalTo(show))
+		Ω(show.Routes[0].Verb).Should(Equal("GET"))
+		Ω(*show.Params.Type.ToObject()["id"].Validation.Minimum).Should(Equal(1.0))
+		Ω(show.Responses[OK].Parent).Should(BeIdenticalTo(show))
+		Ω(show.Responses[OK].MediaType).Should(Equal("application/vnd.goa.bottle"))
+
+		create := r.Actions["create"]
+		Ω(create.Payload).ShouldNot(BeNil())
+		Ω(create.Payload.TypeName).Should(Equal("CreateBottlePayload"))
+		years := create.Payload.Type.ToObject()["years"]
+		Ω(years.DefaultValue).Should(Equal([]interface{}{2010, 2011}))
+		Ω(create.Security).Should(BeNil())
+		Ω(create.Idempotent).Should(BeTrue())
+		Ω(create.IdempotencyKey).Should(BeTrue())
+		Ω(show.Idempotent).Should(BeFalse())
+		Ω(show.Security.Scheme).Should(BeIdenticalTo(api.SecuritySchemes[0]))
+		Ω(r.Authorization.Policies).Should(Equal([]string{"owner"}))
+		Ω(create.Authorization.Policies).Should(Equal([]string{"owner", "admin"}))
+	})
+
+	Context("with an invalid document", func() {
+		JustBeforeEach(func() {
+			_, importErr = ImportDesign([]byte(`{"format":"swagger","version":"1"}`))
+		})
+
+		It("returns an error", func() {
+			Ω(importErr).Should(HaveOccurred())
+		})
+	})
+
+	Describe("LoadDesign", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "export")
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("loads YAML documents", func() {
+			var v interface{}
+			Ω(yaml.Unmarshal(exported, &v)).Should(Succeed())
+			b, err := yaml.Marshal(v)
+			Ω(err).ShouldNot(HaveOccurred())
+			path := filepath.Join(dir, "design.yaml")
+			Ω(ioutil.WriteFile(path, b, 0644)).Should(Succeed())
+
+			loaded, err := LoadDesign(path)
+			Ω(err).ShouldNot(HaveOccurred())
+			again, err := ExportDesign(loaded)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(again)).Should(Equal(string(exported)))
+		})
+	})
+})
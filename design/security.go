@@ -22,6 +22,9 @@ const (
 	JWTSecurityKind
 	// NoSecurityKind means to have no security for this endpoint.
 	NoSecurityKind
+	// MutualTLSSecurityKind means a "mutualTLS" security type where clients authenticate with
+	// a certificate.
+	MutualTLSSecurityKind
 )
 
 // SecurityDefinition defines security requirements for an Action
@@ -36,6 +39,17 @@ type SecurityDefinition struct {
 // Context returns the generic definition name used in error messages.
 func (s *SecurityDefinition) Context() string { return "Security" }
 
+// AuthorizationDefinition lists the authorization policies that must allow a request before the
+// action runs. Policies are identified by name, e.g. "owner" or "admin", and are evaluated by the
+// goa.Policy of the service.
+type AuthorizationDefinition struct {
+	// Policies are the names of the authorization policies.
+	Policies []string `json:"policies"`
+}
+
+// Context returns the generic definition name used in error messages.
+func (a *AuthorizationDefinition) Context() string { return "Authorize" }
+
 // SecuritySchemeDefinition defines a security scheme used to
 // authenticate against the API being designed. See
 // http://swagger.io/specification/#securityDefinitionsObject for more
@@ -51,7 +65,7 @@ type SecuritySchemeDefinition struct {
 	SchemeName string `json:"scheme"`
 
 	// Type is one of "apiKey", "oauth2" or "basic", according to the
-	// Swagger specs. We also support "jwt".
+	// Swagger specs. We also support "jwt" and "mutualTLS".
 	Type string `json:"type"`
 	// Description describes the security scheme. Ex: "Google OAuth2"
 	Description string `json:"description"`
@@ -90,6 +104,8 @@ func (s *SecuritySchemeDefinition) Context() string {
 		dslFunc = "APIKeySecurity"
 	case JWTSecurityKind:
 		dslFunc = "JWTSecurity"
+	case MutualTLSSecurityKind:
+		dslFunc = "MutualTLSSecurity"
 	}
 	return dslFunc
 }
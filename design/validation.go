@@ -85,6 +85,7 @@ func (a *APIDefinition) Validate() error {
 	a.validateLicense(verr)
 	a.validateDocs(verr)
 	a.validateOrigins(verr)
+	a.validateHealthCheck(verr)
 
 	var allRoutes []*routeInfo
 	a.IterateResources(func(r *ResourceDefinition) error {
@@ -208,6 +209,34 @@ func (a *APIDefinition) validateOrigins(verr *dslengine.ValidationErrors) {
 	}
 }
 
+func (a *APIDefinition) validateHealthCheck(verr *dslengine.ValidationErrors) {
+	p := a.HealthCheckPath
+	if p == "" {
+		return
+	}
+	if !strings.HasPrefix(p, "/") {
+		verr.Add(a, "invalid health check path %#v: must start with /", p)
+		return
+	}
+	if len(ExtractWildcards(p)) > 0 {
+		verr.Add(a, "invalid health check path %#v: must not contain wildcards", p)
+		return
+	}
+	base := strings.TrimSuffix(p, "/")
+	paths := map[string]bool{p: true, base + "/ready": true, base + "/live": true}
+	a.IterateResources(func(r *ResourceDefinition) error {
+		r.IterateActions(func(ac *ActionDefinition) error {
+			for _, ro := range ac.Routes {
+				if ro.Verb == "GET" && paths[ro.FullPath()] {
+					verr.Add(ac, "route GET %s conflicts with the health check endpoints", ro.FullPath())
+				}
+			}
+			return nil
+		})
+		return nil
+	})
+}
+
 // Validate tests whether the resource definition is consistent: action names are valid and each action is
 // valid.
 func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
@@ -340,7 +369,39 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 			verr.Add(a, "Response %s contains an invalid type, action responses cannot contain a file", i)
 		}
 	}
+	streams := 0
+	a.IterateResponses(func(r *ResponseDefinition) error {
+		if !r.ServerSentEvents {
+			return nil
+		}
+		streams++
+		if streams > 1 {
+			verr.Add(r, "Multiple server-sent events responses, an action may stream only one")
+		}
+		if a.WebSocket() {
+			verr.Add(r, "Server-sent events response cannot be used by a websocket action")
+		}
+		return nil
+	})
 	verr.Merge(a.ValidateParams())
+	if a.InboundMessage != nil || a.OutboundMessage != nil {
+		if !a.WebSocket() {
+			verr.Add(a, "Messages can only be defined on websocket actions")
+		}
+		for _, dt := range []DataType{a.InboundMessage, a.OutboundMessage} {
+			if dt == nil {
+				continue
+			}
+			switch dt.(type) {
+			case *UserTypeDefinition, *MediaTypeDefinition:
+				if !dt.IsObject() {
+					verr.Add(a, "Invalid message type %s, messages must be objects", dt.Name())
+				}
+			default:
+				verr.Add(a, "Invalid message type %s, messages must be described by a user type or a media type", dt.Name())
+			}
+		}
+	}
 	if a.Payload != nil {
 		verr.Merge(a.Payload.Validate("action payload", a))
 		if HasFile(a.Payload.Type) && a.PayloadMultipart != true {
@@ -519,6 +580,9 @@ func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
 	if r.Status == 0 {
 		verr.Add(r, "response status not defined")
 	}
+	if r.ServerSentEvents && Design.MediaTypeWithIdentifier(r.MediaType) == nil {
+		verr.Add(r, "server-sent events response must use a media type defined in the design")
+	}
 	return verr.AsError()
 }
 
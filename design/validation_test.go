@@ -312,6 +312,44 @@ var _ = Describe("Validation", func() {
 		})
 	})
 
+	Context("with an action streaming multiple server-sent events responses", func() {
+		It("reports the response that comes last in alphabetical order", func() {
+			dslengine.Reset()
+
+			MediaType("application/vnd.event", func() {
+				Attributes(func() {
+					Attribute("id", String)
+				})
+				View("default", func() {
+					Attribute("id")
+				})
+			})
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(GET("/buz"))
+					Response("b", func() {
+						Status(206)
+						Media("application/vnd.event")
+						ServerSentEvents()
+					})
+					Response("a", func() {
+						Status(200)
+						Media("application/vnd.event")
+						ServerSentEvents()
+					})
+				})
+			})
+
+			dslengine.Run()
+
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(Equal(
+				`response "b" of resource "foo" action "bar": Multiple server-sent events responses, an action may stream only one`,
+			))
+			Ω(Design.Resources["foo"].Actions["bar"].EventStream().Name).Should(Equal("a"))
+		})
+	})
+
 	Context("with an action", func() {
 		var dsl func()
 
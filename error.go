@@ -47,6 +47,9 @@ var (
 	// ErrUnauthorized is a generic unauthorized error.
 	ErrUnauthorized = NewErrorClass("unauthorized", 401)
 
+	// ErrForbidden is the error produced when the authorization policy denies a request.
+	ErrForbidden = NewErrorClass("forbidden", 403)
+
 	// ErrInvalidRequest is the class of errors produced by the generated code when a request
 	// parameter or payload fails to validate.
 	ErrInvalidRequest = NewErrorClass("invalid_request", 400)
@@ -62,6 +65,10 @@ var (
 	// security scheme defined in the design.
 	ErrNoAuthMiddleware = NewErrorClass("no_auth_middleware", 500)
 
+	// ErrNoPolicy is the error produced when no authorization policy is set on the service
+	// for actions that define authorization policies in the design.
+	ErrNoPolicy = NewErrorClass("no_authorization_policy", 500)
+
 	// ErrInvalidFile is the error produced by ServeFiles when requested to serve non-existant
 	// or non-readable files.
 	ErrInvalidFile = NewErrorClass("invalid_file", 404)
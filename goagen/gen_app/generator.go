@@ -6,6 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
@@ -153,6 +157,8 @@ func (g *Generator) generateContexts() (err error) {
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.NewImport("uuid", "github.com/gofrs/uuid"),
 		codegen.SimpleImport("context"),
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("golang.org/x/net/websocket"),
 	}
 	g.API.IterateResources(func(r *design.ResourceDefinition) error {
 		return r.IterateActions(func(a *design.ActionDefinition) error {
@@ -195,18 +201,24 @@ func (g *Generator) generateContexts() (err error) {
 					non101[k] = v
 				}
 			}
+			in, out, err := a.WebSocketMessages()
+			if err != nil {
+				return err
+			}
 			ctxData := ContextTemplateData{
-				Name:         ctxName,
-				ResourceName: r.Name,
-				ActionName:   a.Name,
-				Payload:      a.Payload,
-				Params:       params,
-				Headers:      headers,
-				Routes:       a.Routes,
-				Responses:    non101,
-				API:          g.API,
-				DefaultPkg:   g.Target,
-				Security:     a.Security,
+				Name:            ctxName,
+				ResourceName:    r.Name,
+				ActionName:      a.Name,
+				Payload:         a.Payload,
+				Params:          params,
+				Headers:         headers,
+				Routes:          a.Routes,
+				Responses:       non101,
+				API:             g.API,
+				DefaultPkg:      g.Target,
+				Security:        a.Security,
+				InboundMessage:  in,
+				OutboundMessage: out,
 			}
 			return ctxWr.Execute(&ctxData)
 		})
@@ -241,6 +253,8 @@ func (g *Generator) generateControllers() (err error) {
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("github.com/goadesign/goa/cors"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware/idempotency"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware/ratelimit"),
 		codegen.SimpleImport("regexp"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("time"),
@@ -280,7 +294,7 @@ func (g *Generator) generateControllers() (err error) {
 
 	g.genfiles = append(g.genfiles, ctlFile)
 	var controllersData []*ControllerTemplateData
-	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
 		// Create file servers for all directory file servers that serve index.html.
 		fileServers := r.FileServers
 		for _, fs := range r.FileServers {
@@ -304,7 +318,11 @@ func (g *Generator) generateControllers() (err error) {
 			PreflightPaths: r.PreflightPaths(),
 			FileServers:    fileServers,
 		}
-		r.IterateActions(func(a *design.ActionDefinition) error {
+		err := r.IterateActions(func(a *design.ActionDefinition) error {
+			rateLimit, err := rateLimitData(a)
+			if err != nil {
+				return err
+			}
 			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
 			unmarshal := fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
 			action := map[string]interface{}{
@@ -317,10 +335,16 @@ func (g *Generator) generateControllers() (err error) {
 				"PayloadOptional":  a.PayloadOptional,
 				"PayloadMultipart": a.PayloadMultipart,
 				"Security":         a.Security,
+				"RateLimit":        rateLimit,
+				"IdempotencyKey":   idempotencyKeyName(a),
+				"Authorization":    authorizationData(a),
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
 		})
+		if err != nil {
+			return err
+		}
 		if len(data.Actions) > 0 || len(data.FileServers) > 0 {
 			data.Encoders = encoders
 			data.Decoders = decoders
@@ -329,10 +353,103 @@ func (g *Generator) generateControllers() (err error) {
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
 	err = ctlWr.Execute(controllersData)
 	return
 }
 
+// idempotencyKeyName returns the name that scopes the idempotency keys of the action in the
+// store, e.g. "bottle.create", or the empty string if the action does not use the idempotency
+// middleware. The name uses the design names like the rate limit quota names.
+func idempotencyKeyName(a *design.ActionDefinition) string {
+	if !a.IdempotencyKey {
+		return ""
+	}
+	return a.Parent.Name + "." + a.Name
+}
+
+// authorizationData returns the data used to generate the call to the authorization policy of
+// the service, nil if the action does not define authorization policies.
+func authorizationData(a *design.ActionDefinition) *AuthorizationTemplateData {
+	if a.Authorization == nil || len(a.Authorization.Policies) == 0 {
+		return nil
+	}
+	return &AuthorizationTemplateData{
+		Resource: a.Parent.Name,
+		Action:   a.Name,
+		Policies: a.Authorization.Policies,
+	}
+}
+
+// rateLimitData parses the "ratelimit" metadata of the action or of its resource. It returns nil
+// if the action is not rate limited.
+func rateLimitData(a *design.ActionDefinition) (*RateLimitTemplateData, error) {
+	vals, ok := a.Metadata["ratelimit"]
+	if !ok {
+		vals, ok = a.Parent.Metadata["ratelimit"]
+	}
+	if !ok || len(vals) == 0 {
+		return nil, nil
+	}
+	limit, period, err := parseRateLimit(vals[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ratelimit metadata of action %s of resource %s: %s", a.Name, a.Parent.Name, err)
+	}
+	return &RateLimitTemplateData{
+		Name:   a.Parent.Name + "." + a.Name,
+		Limit:  limit,
+		Period: durationLiteral(period),
+	}, nil
+}
+
+// parseRateLimit parses rate limits of the form "100/1m" or "10/s".
+func parseRateLimit(val string) (int, time.Duration, error) {
+	elems := strings.SplitN(val, "/", 2)
+	if len(elems) != 2 {
+		return 0, 0, fmt.Errorf("%#v must be of the form <limit>/<period>, e.g. \"100/1m\"", val)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(elems[0]))
+	if err != nil || limit <= 0 {
+		return 0, 0, fmt.Errorf("limit %#v must be a positive integer", elems[0])
+	}
+	p := strings.TrimSpace(elems[1])
+	if p != "" && !unicode.IsDigit(rune(p[0])) {
+		p = "1" + p
+	}
+	period, err := time.ParseDuration(p)
+	if err != nil || period <= 0 {
+		return 0, 0, fmt.Errorf("period %#v must be a positive duration", elems[1])
+	}
+	return limit, period, nil
+}
+
+// durationLiteral returns the Go expression for the given duration, e.g. "time.Minute" or
+// "90*time.Second".
+func durationLiteral(d time.Duration) string {
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+		{time.Nanosecond, "time.Nanosecond"},
+	}
+	for _, u := range units {
+		if d%u.d == 0 {
+			if d == u.d {
+				return u.name
+			}
+			return fmt.Sprintf("%d*%s", d/u.d, u.name)
+		}
+	}
+	return fmt.Sprintf("%d", d) // unreachable
+}
+
 // generateControllers iterates through the API resources and generates the low level
 // controllers.
 func (g *Generator) generateSecurity() (err error) {
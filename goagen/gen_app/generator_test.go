@@ -271,6 +271,132 @@ var _ = Describe("Generate", func() {
 				Ω(string(contextsContent)).Should(ContainSubstring(controllersMultipartPayloadCode))
 			})
 		})
+
+		Context("with redacted payload attributes", func() {
+			BeforeEach(func() {
+				redact := dslengine.MetadataDefinition{"log:redact": {}}
+				payload = &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"name":     &design.AttributeDefinition{Type: design.String},
+							"password": &design.AttributeDefinition{Type: design.String, Metadata: redact},
+							"cards": &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{
+								Type: design.Object{
+									"number": &design.AttributeDefinition{Type: design.String, Metadata: redact},
+								},
+							}}},
+						},
+					},
+					TypeName: "Account",
+				}
+				design.Design.Resources["Widget"].Actions["get"].Payload = payload
+				runCodeTemplates(map[string]string{"outDir": outDir, "design": "foo", "tmpDir": filepath.Base(outDir), "version": version.String()})
+			})
+
+			It("generates the RedactedFields method", func() {
+				Ω(genErr).Should(BeNil())
+
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`func (payload *Account) RedactedFields() []string {
+	return []string{"cards.number", "password"}
+}`))
+			})
+		})
+
+		Context("with a health check path", func() {
+			BeforeEach(func() {
+				design.Design.HealthCheckPath = "/healthz"
+				runCodeTemplates(map[string]string{"outDir": outDir, "design": "foo", "tmpDir": filepath.Base(outDir), "version": version.String()})
+			})
+
+			It("mounts the health endpoints", func() {
+				Ω(genErr).Should(BeNil())
+
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`	// Mount health endpoints
+	service.MountHealth("/healthz")
+}`))
+			})
+		})
+
+		Context("with authorization policies", func() {
+			BeforeEach(func() {
+				design.Design.Resources["Widget"].Actions["get"].Authorization = &design.AuthorizationDefinition{Policies: []string{"owner", "admin"}}
+				runCodeTemplates(map[string]string{"outDir": outDir, "design": "foo", "tmpDir": filepath.Base(outDir), "version": version.String()})
+			})
+
+			It("authorizes the requests", func() {
+				Ω(genErr).Should(BeNil())
+
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`service.Authorize(ctx, &goa.AuthorizationRequest{`))
+				Ω(string(content)).Should(ContainSubstring(`Policies: []string{"owner", "admin"},`))
+			})
+		})
+
+		Context("with an action requiring idempotency keys", func() {
+			BeforeEach(func() {
+				design.Design.Resources["Widget"].Name = "widget"
+				design.Design.Resources["Widget"].Actions["get"].IdempotencyKey = true
+				runCodeTemplates(map[string]string{"outDir": outDir, "design": "foo", "tmpDir": filepath.Base(outDir), "version": version.String()})
+			})
+
+			It("applies the idempotency middleware", func() {
+				Ω(genErr).Should(BeNil())
+
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`guardGet := idempotency.NewGuard(service, "widget.get")`))
+				Ω(string(content)).Should(ContainSubstring(`return guardGet.Run(ctx, rw, req, func(context.Context, http.ResponseWriter, *http.Request) error {
+			return ctrl.Get(rctx)
+		})`))
+			})
+		})
+
+		Context("with rate limits", func() {
+			BeforeEach(func() {
+				design.Design.Resources["Widget"].Actions["get"].Metadata = dslengine.MetadataDefinition{"ratelimit": {"100/1m"}}
+				runCodeTemplates(map[string]string{"outDir": outDir, "design": "foo", "tmpDir": filepath.Base(outDir), "version": version.String()})
+			})
+
+			It("applies the action rate limit", func() {
+				Ω(genErr).Should(BeNil())
+
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`h = ratelimit.Handle(service, "Widget.get", 100, time.Minute, h)`))
+				Ω(string(content)).Should(ContainSubstring(`"github.com/goadesign/goa/middleware/ratelimit"`))
+			})
+
+			Context("declared on the resource", func() {
+				BeforeEach(func() {
+					design.Design.Resources["Widget"].Actions["get"].Metadata = nil
+					design.Design.Resources["Widget"].Metadata = dslengine.MetadataDefinition{"ratelimit": {"10/90s"}}
+				})
+
+				It("applies the resource rate limit", func() {
+					Ω(genErr).Should(BeNil())
+
+					content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(content)).Should(ContainSubstring(`h = ratelimit.Handle(service, "Widget.get", 10, 90*time.Second, h)`))
+				})
+			})
+
+			Context("that are invalid", func() {
+				BeforeEach(func() {
+					design.Design.Resources["Widget"].Actions["get"].Metadata = dslengine.MetadataDefinition{"ratelimit": {"100 per minute"}}
+				})
+
+				It("returns an error", func() {
+					Ω(genErr).Should(HaveOccurred())
+					Ω(genErr.Error()).Should(ContainSubstring("invalid ratelimit metadata of action get of resource Widget"))
+				})
+			})
+		})
 	})
 })
 
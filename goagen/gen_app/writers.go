@@ -85,13 +85,17 @@ type (
 		API          *design.APIDefinition
 		DefaultPkg   string
 		Security     *design.SecurityDefinition
+		// InboundMessage and OutboundMessage are the types of the messages exchanged by
+		// websocket actions if any.
+		InboundMessage  design.DataType
+		OutboundMessage design.DataType
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
 	ControllerTemplateData struct {
 		API            *design.APIDefinition          // API definition
 		Resource       string                         // Lower case plural resource name, e.g. "bottles"
-		Actions        []map[string]interface{}       // Array of actions, each action has keys "Name", "DesignName", "Routes", "Context" and "Unmarshal"
+		Actions        []map[string]interface{}       // Array of actions, each action has keys "Name", "DesignName", "Routes", "Context", "Unmarshal", "RateLimit", "IdempotencyKey" and "Authorization"
 		FileServers    []*design.FileServerDefinition // File servers
 		Encoders       []*EncoderTemplateData         // Encoder data
 		Decoders       []*EncoderTemplateData         // Decoder data
@@ -99,6 +103,22 @@ type (
 		PreflightPaths []string
 	}
 
+	// RateLimitTemplateData contains the information required to apply the rate limit declared
+	// on an action.
+	RateLimitTemplateData struct {
+		Name   string // Name of the quota, e.g. "bottle.show"
+		Limit  int    // Number of requests allowed per period
+		Period string // Go expression of the period, e.g. "time.Minute"
+	}
+
+	// AuthorizationTemplateData contains the information required to call the authorization
+	// policy of the service before an action runs.
+	AuthorizationTemplateData struct {
+		Resource string   // Name of the resource as defined in the design
+		Action   string   // Name of the action as defined in the design
+		Policies []string // Names of the authorization policies
+	}
+
 	// ResourceData contains the information required to generate the resource GoGenerator
 	ResourceData struct {
 		Name              string                      // Name of resource
@@ -233,12 +253,19 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			fn := template.FuncMap{
 				"finalizeCode":   w.Finalizer.Code,
 				"validationCode": w.Validator.Code,
+				"redactedFields": redactedFields,
 			}
 			if err := w.ExecuteTemplate("payload", payloadT, fn, data); err != nil {
 				return err
 			}
 		}
 	}
+	if data.InboundMessage != nil || data.OutboundMessage != nil {
+		fn := template.FuncMap{"validationCode": w.Validator.Code}
+		if err := w.ExecuteTemplate("stream", ctxWSStreamT, fn, data); err != nil {
+			return err
+		}
+	}
 	return data.IterateResponses(func(resp *design.ResponseDefinition) error {
 		respData := map[string]interface{}{
 			"Context":  data,
@@ -277,13 +304,17 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 				respData["ViewName"] = view
 				respData["MediaType"] = mt
 				respData["ContentType"] = mt.ContentType
+				name, tmpl := resp.Name, ctxMTRespT
+				if resp.ServerSentEvents {
+					name, tmpl = "Send", ctxSSERespT
+				}
 				if view == "default" {
-					respData["RespName"] = codegen.Goify(resp.Name, true)
+					respData["RespName"] = codegen.Goify(name, true)
 				} else {
-					base := fmt.Sprintf("%s%s", resp.Name, strings.Title(view))
+					base := fmt.Sprintf("%s%s", name, strings.Title(view))
 					respData["RespName"] = codegen.Goify(base, true)
 				}
-				if err := w.ExecuteTemplate("response", ctxMTRespT, fn, respData); err != nil {
+				if err := w.ExecuteTemplate("response", tmpl, fn, respData); err != nil {
 					return err
 				}
 			}
@@ -435,6 +466,7 @@ func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
 	fn := template.FuncMap{
 		"finalizeCode":   w.Finalizer.Code,
 		"validationCode": w.Validator.Code,
+		"redactedFields": redactedFields,
 	}
 	return w.ExecuteTemplate("types", userTypeT, fn, t)
 }
@@ -451,6 +483,67 @@ func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, p
 	}
 }
 
+// redactedFields returns the paths of the attributes of att that have the "log:redact" metadata
+// sorted in lexical order. See the accesslog middleware package for the path syntax.
+func redactedFields(att *design.AttributeDefinition) []string {
+	var paths []string
+	var walk func(*design.AttributeDefinition, string, map[string]bool)
+	walk = func(a *design.AttributeDefinition, prefix string, seen map[string]bool) {
+		if a == nil || a.Type == nil {
+			return
+		}
+		if _, ok := a.Metadata["log:redact"]; ok {
+			paths = append(paths, prefix)
+			return
+		}
+		if ut, ok := a.Type.(*design.UserTypeDefinition); ok {
+			if seen[ut.TypeName] {
+				return
+			}
+			seen = copySeen(seen, ut.TypeName)
+			walk(ut.AttributeDefinition, prefix, seen)
+			return
+		}
+		if mt, ok := a.Type.(*design.MediaTypeDefinition); ok {
+			if seen[mt.TypeName] {
+				return
+			}
+			seen = copySeen(seen, mt.TypeName)
+			walk(mt.AttributeDefinition, prefix, seen)
+			return
+		}
+		join := func(name string) string {
+			if prefix == "" {
+				return name
+			}
+			return prefix + "." + name
+		}
+		switch actual := a.Type.(type) {
+		case design.Object:
+			for n, at := range actual {
+				walk(at, join(n), seen)
+			}
+		case *design.Array:
+			walk(actual.ElemType, prefix, seen)
+		case *design.Hash:
+			walk(actual.ElemType, join("*"), seen)
+		}
+	}
+	walk(att, "", nil)
+	sort.Strings(paths)
+	return paths
+}
+
+// copySeen returns a copy of seen that also contains name.
+func copySeen(seen map[string]bool, name string) map[string]bool {
+	c := make(map[string]bool, len(seen)+1)
+	for k := range seen {
+		c[k] = true
+	}
+	c[name] = true
+	return c
+}
+
 // arrayAttribute returns the array element attribute definition.
 func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
 	return a.Type.(*design.Array).ElemType
@@ -680,6 +773,59 @@ func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Proje
 }
 `
 
+	// ctxSSERespT generates the helpers that stream server-sent events.
+	// template input: map[string]interface{}
+	ctxSSERespT = `// {{ goify .RespName true }} sends the event to the client as a server-sent event. The first call
+// responds with status code {{ .Response.Status }}, subsequent calls write to the same stream.
+func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(event {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
+	stream, err := goa.OpenEventStream(ctx.Context, {{ .Response.Status }})
+	if err != nil {
+		return err
+	}
+	return stream.Send(event)
+}
+`
+
+	// ctxWSStreamT generates the typed stream of websocket actions that declare messages.
+	// template input: *ContextTemplateData
+	ctxWSStreamT = `{{ $streamName := printf "%s%sStream" (goify .ActionName true) (goify .ResourceName true) }}{{/*
+*/}}// {{ $streamName }} is the typed websocket stream of the {{ .ResourceName }} {{ .ActionName }} action.
+type {{ $streamName }} struct {
+	*websocket.Conn
+	service *goa.Service
+}
+
+// Stream wraps the websocket connection established for the action into a typed stream.
+func (ctx *{{ .Name }}) Stream(ws *websocket.Conn) *{{ $streamName }} {
+	return &{{ $streamName }}{Conn: ws, service: ctx.ResponseData.Service}
+}
+{{ if .InboundMessage }}{{ $in := .InboundMessage }}
+// Receive reads the next message sent by the client, decodes it and validates it.
+func (s *{{ $streamName }}) Receive() ({{ gotyperef $in $in.AllRequired 0 false }}, error) {
+	var data []byte
+	if err := websocket.Message.Receive(s.Conn, &data); err != nil {
+		return nil, err
+	}
+	var msg {{ gotypename $in $in.AllRequired 0 false }}
+	if err := s.service.Decoder.Decode(&msg, bytes.NewReader(data), "application/json"); err != nil {
+		return nil, goa.ErrBadRequest(err)
+	}
+{{ $validation := validationCode $in.AttributeDefinition false false false "msg" "message" 1 false }}{{ if $validation }}	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+{{ end }}	return &msg, nil
+}
+{{ end }}{{ if .OutboundMessage }}{{ $out := .OutboundMessage }}
+// Send encodes the message and sends it to the client.
+func (s *{{ $streamName }}) Send(msg {{ gotyperef $out $out.AllRequired 0 false }}) error {
+	var buf bytes.Buffer
+	if err := s.service.Encoder.Encode(msg, &buf, "application/json"); err != nil {
+		return err
+	}
+	return websocket.Message.Send(s.Conn, buf.String())
+}
+{{ end }}`
+
 	// ctxTRespT generates the response helpers for responses with overridden types.
 	// template input: map[string]interface{}
 	ctxTRespT = `// {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
@@ -737,8 +883,12 @@ type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
-}{{ end }}
-`
+}{{ end }}{{ $redacted := redactedFields .Payload.AttributeDefinition }}{{ if $redacted }}
+// RedactedFields returns the paths of the payload attributes that must not be logged.
+func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) RedactedFields() []string {
+	return {{ printf "%#v" $redacted }}
+}
+{{ end }}`
 	// ctrlT generates the controller interface for a given resource.
 	// template input: *ControllerTemplateData
 	ctrlT = `// {{ .Resource }}Controller is the controller interface for the {{ .Resource }} actions.
@@ -766,7 +916,10 @@ func initService(service *goa.Service) {
 */}}	service.Encoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
 {{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
 */}}	service.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
-{{ end }}{{ end }}}
+{{ end }}{{ end }}{{ if .API.HealthCheckPath }}
+	// Mount health endpoints
+	service.MountHealth({{ printf "%q" .API.HealthCheckPath }})
+{{ end }}}
 `
 
 	// mountT generates the code for a resource "Mount" function.
@@ -779,7 +932,8 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
 */}}	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
 {{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}
-	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+{{ with .IdempotencyKey }}	guard{{ $action.Name }} := idempotency.NewGuard(service, {{ printf "%q" . }})
+{{ end }}	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
@@ -795,9 +949,23 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ if not .PayloadOptional }}		} else {
 			return goa.MissingPayloadError()
 {{ end }}		}
-{{ end }}		return ctrl.{{ .Name }}(rctx)
-	}
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ with .Authorization }}		// Authorize the request
+		if err := service.Authorize(ctx, &goa.AuthorizationRequest{
+			Resource: {{ printf "%q" .Resource }},
+			Action:   {{ printf "%q" .Action }},
+			Policies: []string{ {{- range $i, $p := .Policies }}{{ if $i }}, {{ end }}{{ printf "%q" $p }}{{ end -}} },
+{{ if $action.Payload }}			Payload:  rctx.Payload,
+{{ end }}			Context:  rctx,
+		}); err != nil {
+			return err
+		}
+{{ end }}{{ if .IdempotencyKey }}		return guard{{ .Name }}.Run(ctx, rw, req, func(context.Context, http.ResponseWriter, *http.Request) error {
+			return ctrl.{{ .Name }}(rctx)
+		})
+{{ else }}		return ctrl.{{ .Name }}(rctx)
+{{ end }}	}
+{{ with .RateLimit }}	h = ratelimit.Handle(service, {{ printf "%q" .Name }}, {{ .Limit }}, {{ .Period }}, h)
+{{ end }}{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
 {{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
@@ -938,8 +1106,12 @@ type {{ $typeName }} {{ gotypedef . 0 true false }}
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
-}{{ end }}
-`
+}{{ end }}{{ $redacted := redactedFields .AttributeDefinition }}{{ if $redacted }}
+// RedactedFields returns the paths of the {{ $typeName }} attributes that must not be logged.
+func (ut {{ gotyperef . .AllRequired 0 false }}) RedactedFields() []string {
+	return {{ printf "%#v" $redacted }}
+}
+{{ end }}`
 
 	// securitySchemesT generates the code for the security module.
 	// template input: []*design.SecuritySchemeDefinition
@@ -136,6 +136,76 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a server-sent events response", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"foo": {Type: design.String}},
+							},
+							TypeName: "Event",
+						},
+						Identifier: "application/vnd.goa.event",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:             "OK",
+						Status:           200,
+						MediaType:        mediaType.Identifier,
+						ServerSentEvents: true,
+					}}
+				})
+
+				It("writes a Send method instead of the response helper", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(sseContextSend))
+					Ω(written).ShouldNot(ContainSubstring("func (ctx *ListBottleContext) OK("))
+				})
+			})
+			Context("with websocket messages", func() {
+				var message *design.UserTypeDefinition
+
+				BeforeEach(func() {
+					message = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type:       design.Object{"body": {Type: design.String}},
+							Validation: &dslengine.ValidationDefinition{Required: []string{"body"}},
+						},
+						TypeName: "Message",
+					}
+				})
+
+				JustBeforeEach(func() {
+					data.InboundMessage = message
+					data.OutboundMessage = message
+				})
+
+				It("writes the typed stream", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(wsContextStream))
+					Ω(written).Should(ContainSubstring(wsContextReceive))
+					Ω(written).Should(ContainSubstring(wsContextSend))
+				})
+			})
+
 			Context("with a collection media type", func() {
 				BeforeEach(func() {
 					elemType := &design.MediaTypeDefinition{
@@ -1632,6 +1702,56 @@ var _ = Describe("UserTypesWriter", func() {
 })
 
 const (
+	sseContextSend = `// Send sends the event to the client as a server-sent event. The first call
+// responds with status code 200, subsequent calls write to the same stream.
+func (ctx *ListBottleContext) Send(event *Event) error {
+	stream, err := goa.OpenEventStream(ctx.Context, 200)
+	if err != nil {
+		return err
+	}
+	return stream.Send(event)
+}
+`
+
+	wsContextStream = `// ListBottlesStream is the typed websocket stream of the bottles list action.
+type ListBottlesStream struct {
+	*websocket.Conn
+	service *goa.Service
+}
+
+// Stream wraps the websocket connection established for the action into a typed stream.
+func (ctx *ListBottleContext) Stream(ws *websocket.Conn) *ListBottlesStream {
+	return &ListBottlesStream{Conn: ws, service: ctx.ResponseData.Service}
+}
+`
+
+	wsContextReceive = `// Receive reads the next message sent by the client, decodes it and validates it.
+func (s *ListBottlesStream) Receive() (*Message, error) {
+	var data []byte
+	if err := websocket.Message.Receive(s.Conn, &data); err != nil {
+		return nil, err
+	}
+	var msg Message
+	if err := s.service.Decoder.Decode(&msg, bytes.NewReader(data), "application/json"); err != nil {
+		return nil, goa.ErrBadRequest(err)
+	}
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+`
+
+	wsContextSend = `// Send encodes the message and sends it to the client.
+func (s *ListBottlesStream) Send(msg *Message) error {
+	var buf bytes.Buffer
+	if err := s.service.Encoder.Encode(msg, &buf, "application/json"); err != nil {
+		return err
+	}
+	return websocket.Message.Send(s.Conn, buf.String())
+}
+`
+
 	emptyContext = `
 type ListBottleContext struct {
 	context.Context
@@ -243,6 +243,9 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 			}
 			var err error
 			if action.WebSocket() {
+				if data["InboundMessage"], data["OutboundMessage"], err = action.WebSocketMessages(); err != nil {
+					return err
+				}
 				err = commandsTmplWS.Execute(file, data)
 			} else {
 				err = commandsTmpl.Execute(file, data)
@@ -737,11 +740,20 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
 	}
-	go goaclient.WSWrite(ws)
-	goaclient.WSRead(ws)
+{{ if or .InboundMessage .OutboundMessage }}	stream := c.New{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}Stream(ws)
+{{ end }}{{ if .InboundMessage }}	go goaclient.WSSend(func(line []byte) error {
+		var msg {{ gotyperefext .InboundMessage 2 .Package }}
+		if err := json.Unmarshal(line, &msg); err != nil {
+			return err
+		}
+		return stream.Send(&msg)
+	})
+{{ else }}	go goaclient.WSWrite(ws)
+{{ end }}{{ if .OutboundMessage }}	return goaclient.WSReceive(func() (interface{}, error) { return stream.Receive() })
+{{ else }}	goaclient.WSRead(ws)
 
 	return nil
-}
+{{ end }}}
 `
 
 const downloadCommandTmpl = `
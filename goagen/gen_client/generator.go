@@ -347,6 +347,8 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
 	}
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
@@ -464,6 +466,9 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		clientsTmpl   = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
 		requestsTmpl  = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
 		clientsWSTmpl = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+		wsStreamTmpl  = template.Must(template.New("wsstream").Funcs(funcs).Parse(wsStreamTmpl))
+		streamTmpl    = template.Must(template.New("stream").Funcs(funcs).Parse(streamTmpl))
+		eventType     *design.MediaTypeDefinition
 	)
 	if action.Payload != nil {
 		params = append(params, "payload "+codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false))
@@ -490,9 +495,26 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 	queryParams = initParamsScoped(action.QueryParams)
 	headers = initParamsScoped(action.Headers)
 
-	if action.Security != nil {
+	if action.Security != nil && signerType(action.Security.Scheme) != "" {
 		signer = codegen.Goify(action.Security.Scheme.SchemeName, true)
 	}
+	if resp := action.EventStream(); resp != nil {
+		if mt := design.Design.MediaTypeWithIdentifier(resp.MediaType); mt != nil {
+			view := resp.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			p, _, err := mt.Project(view)
+			if err != nil {
+				return err
+			}
+			eventType = p
+		}
+	}
+	inMsg, outMsg, err := action.WebSocketMessages()
+	if err != nil {
+		return err
+	}
 	data := struct {
 		Name               string
 		ResourceName       string
@@ -500,6 +522,7 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Routes             []*design.RouteDefinition
 		Payload            *design.UserTypeDefinition
 		PayloadMultipart   bool
+		Idempotent         bool
 		HasPayload         bool
 		HasMultiContent    bool
 		DefaultContentType string
@@ -509,6 +532,9 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Signer             string
 		QueryParams        []*paramData
 		Headers            []*paramData
+		EventType          *design.MediaTypeDefinition
+		InboundMessage     design.DataType
+		OutboundMessage    design.DataType
 	}{
 		Name:               action.Name,
 		ResourceName:       action.Parent.Name,
@@ -516,6 +542,7 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Routes:             action.Routes,
 		Payload:            action.Payload,
 		PayloadMultipart:   action.PayloadMultipart,
+		Idempotent:         action.Idempotent,
 		HasPayload:         action.Payload != nil,
 		HasMultiContent:    len(design.Design.Consumes) > 1,
 		DefaultContentType: design.Design.Consumes[0].MIMETypes[0],
@@ -525,14 +552,29 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Signer:             signer,
 		QueryParams:        queryParams,
 		Headers:            headers,
+		EventType:          eventType,
+		InboundMessage:     inMsg,
+		OutboundMessage:    outMsg,
 	}
 	if action.WebSocket() {
-		return clientsWSTmpl.Execute(file, data)
+		if err := clientsWSTmpl.Execute(file, data); err != nil {
+			return err
+		}
+		if inMsg != nil || outMsg != nil {
+			return wsStreamTmpl.Execute(file, data)
+		}
+		return nil
 	}
 	if err := clientsTmpl.Execute(file, data); err != nil {
 		return err
 	}
-	return requestsTmpl.Execute(file, data)
+	if err := requestsTmpl.Execute(file, data); err != nil {
+		return err
+	}
+	if eventType != nil {
+		return streamTmpl.Execute(file, data)
+	}
+	return nil
 }
 
 // fileServerMethod returns the name of the client method for downloading assets served by the given
@@ -985,7 +1027,7 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	if err != nil {
 		return nil, err
 	}
-	return c.Client.Do(ctx, req)
+	return c.Client.Do({{ if .Idempotent }}goaclient.WithIdempotent(ctx){{ else }}ctx{{ end }}, req)
 }
 `
 
@@ -1026,6 +1068,67 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	cfg.Header["{{ $header.Name }}"] = []string{ {{ $tmp }} }
 {{ end }}	return websocket.DialConfig(cfg)
 }
+`
+
+	wsStreamTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }}Stream is the typed websocket stream of the {{ .Name }} action of the {{ .ResourceName }} resource.
+type {{ $funcName }}Stream struct {
+	*websocket.Conn
+	encoder *goa.HTTPEncoder
+	decoder *goa.HTTPDecoder
+}
+
+// New{{ $funcName }}Stream wraps the websocket connection returned by {{ $funcName }} into a typed stream.
+func (c *Client) New{{ $funcName }}Stream(ws *websocket.Conn) *{{ $funcName }}Stream {
+	return &{{ $funcName }}Stream{Conn: ws, encoder: c.Encoder, decoder: c.Decoder}
+}
+{{ if .InboundMessage }}{{ $in := .InboundMessage }}
+// Send encodes the message and sends it to the server.
+func (s *{{ $funcName }}Stream) Send(msg {{ gotyperef $in $in.AllRequired 1 false }}) error {
+	var buf bytes.Buffer
+	if err := s.encoder.Encode(msg, &buf, "application/json"); err != nil {
+		return err
+	}
+	return websocket.Message.Send(s.Conn, buf.String())
+}
+{{ end }}{{ if .OutboundMessage }}{{ $out := .OutboundMessage }}
+// Receive reads the next message sent by the server and decodes it.
+func (s *{{ $funcName }}Stream) Receive() ({{ gotyperef $out $out.AllRequired 1 false }}, error) {
+	var data []byte
+	if err := websocket.Message.Receive(s.Conn, &data); err != nil {
+		return nil, err
+	}
+	var msg {{ gotypename $out $out.AllRequired 1 false }}
+	if err := s.decoder.Decode(&msg, bytes.NewReader(data), "application/json"); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+{{ end }}`
+
+	streamTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{ $typeName := typeName .EventType }}{{/*
+*/}}// {{ $funcName }}Stream reads the server-sent events streamed by the {{ .Name }} action of the {{ .ResourceName }} resource.
+type {{ $funcName }}Stream struct {
+	*goaclient.EventReader
+	decoder *goa.HTTPDecoder
+}
+
+// New{{ $funcName }}Stream returns a reader for the events contained in the body of resp.
+func (c *Client) New{{ $funcName }}Stream(resp *http.Response) *{{ $funcName }}Stream {
+	return &{{ $funcName }}Stream{EventReader: goaclient.NewEventReader(resp.Body), decoder: c.Decoder}
+}
+
+// Next blocks until the next event is received and decodes it. It returns io.EOF once the server
+// closes the stream.
+func (s *{{ $funcName }}Stream) Next() ({{ if .EventType.IsObject }}*{{ end }}{{ $typeName }}, error) {
+	event, err := s.EventReader.Next()
+	if err != nil {
+		return nil, err
+	}
+	var decoded {{ $typeName }}
+	err = s.decoder.Decode(&decoded, bytes.NewReader(event.Data), "application/json")
+	return {{ if .EventType.IsObject }}&{{ end }}decoded, err
+}
 `
 
 	fsTmpl = `// {{ .Name }} downloads {{ if .DirName }}{{ .DirName }}files with the given filename{{ else }}{{ .FileName }}{{ end }} and writes it to the file dest.
@@ -1164,7 +1267,8 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	header.Set("{{ .Name }}", {{ $tmp }}){{ else }}
 	header.Set("{{ .Name }}", {{ .ValueName }})
 {{ end }}{{ if .CheckNil }}	}{{ end }}
-{{ end }}{{ end }}{{ if .Signer }}	if c.{{ .Signer }}Signer != nil {
+{{ end }}{{ end }}{{ if .EventType }}	req.Header.Set("Accept", "text/event-stream")
+{{ end }}{{ if .Signer }}	if c.{{ .Signer }}Signer != nil {
 		if err := c.{{ .Signer }}Signer.Sign(req); err != nil {
 			return nil, err
 		}
@@ -1181,10 +1285,11 @@ type Client struct {
 	Decoder *goa.HTTPDecoder
 }
 
-// New instantiates the client.
-func New(c goaclient.Doer) *Client {
+// New instantiates the client. The decorators wrap c to add behavior such as retries, see
+// goaclient.Retry, goaclient.CircuitBreaker and goaclient.LimitConcurrency.
+func New(c goaclient.Doer, decorators ...goaclient.Decorator) *Client {
 	client := &Client{
-		Client: goaclient.New(c),
+		Client: goaclient.New(c, decorators...),
 		Encoder: goa.NewHTTPEncoder(),
 		Decoder: goa.NewHTTPDecoder(),
 	}
@@ -377,6 +377,130 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with a websocket action declaring messages", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			message := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"body": &design.AttributeDefinition{Type: design.String}},
+				},
+				TypeName: "Message",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Types:    map[string]*design.UserTypeDefinition{"Message": message},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"chat": {
+								Name:    "chat",
+								Schemes: []string{"ws"},
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/chat",
+									},
+								},
+								InboundMessage:  message,
+								OutboundMessage: message,
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			chatAct := fooRes.Actions["chat"]
+			chatAct.Parent = fooRes
+			chatAct.Routes[0].Parent = chatAct
+		})
+
+		It("generates a typed websocket stream and command", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) NewChatFooStream(ws *websocket.Conn) *ChatFooStream"))
+			Ω(content).Should(ContainSubstring("func (s *ChatFooStream) Send(msg *Message) error"))
+			Ω(content).Should(ContainSubstring("func (s *ChatFooStream) Receive() (*Message, error)"))
+			c, err = ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content = string(c)
+			Ω(content).Should(ContainSubstring("stream := c.NewChatFooStream(ws)"))
+			Ω(content).Should(ContainSubstring("return stream.Send(&msg)"))
+			Ω(content).Should(ContainSubstring("return goaclient.WSReceive("))
+		})
+	})
+
+	Context("with an action streaming server-sent events", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			mt := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"id": &design.AttributeDefinition{Type: design.String}},
+					},
+					TypeName: "Event",
+				},
+				Identifier: "application/vnd.event",
+			}
+			mt.Views = map[string]*design.ViewDefinition{
+				"default": {
+					AttributeDefinition: mt.AttributeDefinition,
+					Name:                "default",
+					Parent:              mt,
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:       "testapi",
+				Consumes:   design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{mt.Identifier: mt},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"updates": {
+								Name: "updates",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/updates",
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {
+										Name:             "OK",
+										Status:           200,
+										MediaType:        mt.Identifier,
+										ServerSentEvents: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			updatesAct := fooRes.Actions["updates"]
+			updatesAct.Parent = fooRes
+			updatesAct.Routes[0].Parent = updatesAct
+			updatesAct.Responses["OK"].Parent = updatesAct
+		})
+
+		It("generates a typed event stream reader", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("type UpdatesFooStream struct"))
+			Ω(content).Should(ContainSubstring("func (c *Client) NewUpdatesFooStream(resp *http.Response) *UpdatesFooStream"))
+			Ω(content).Should(ContainSubstring("func (s *UpdatesFooStream) Next() (*Event, error)"))
+			Ω(content).Should(ContainSubstring(`req.Header.Set("Accept", "text/event-stream")`))
+		})
+	})
+
 	Context("with an action with multiple routes", func() {
 		BeforeEach(func() {
 			design.Design = &design.APIDefinition{
@@ -444,6 +568,46 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with an idempotent action", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:       "create",
+								Idempotent: true,
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "POST",
+										Path: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			createAct := fooRes.Actions["create"]
+			createAct.Parent = fooRes
+			createAct.Routes[0].Parent = createAct
+		})
+
+		It("marks the requests idempotent", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("return c.Client.Do(goaclient.WithIdempotent(ctx), req)"))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func New(c goaclient.Doer, decorators ...goaclient.Decorator) *Client {"))
+		})
+	})
+
 	Context("with an action with security configured", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
@@ -0,0 +1,28 @@
+/*
+Package gencontract provides a generator for a contract test suite that checks a running service
+against its design.
+
+The generated package uses the client package generated by "goagen client" to call each action of
+the API once. The requests use example parameters and payloads computed from the design: the
+examples defined with Example are used when present, random values that satisfy the attribute
+validations are generated otherwise. Optional parameters are not set.
+
+The tests check that the response status code is one of the status codes defined for the action
+and that the response has the required headers and the expected Content-Type. Media type response
+bodies must only contain the attributes of the response view, they are then decoded with the
+client package and validated with the generated validation code.
+
+The generated Run function runs the tests with a given client so that the suite may run against
+any server including a httptest.Server:
+
+	srv := httptest.NewServer(service.Mux)
+	defer srv.Close()
+	c, _ := contract.NewClient(srv.URL)
+	contract.Run(t, c)
+
+The generated TestContract test function runs the suite against the service at the URL given by
+the -base-url flag or the CONTRACT_BASE_URL environment variable:
+
+	go test ./contract -base-url http://localhost:8080
+*/
+package gencontract
@@ -0,0 +1,13 @@
+package gencontract_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenContract(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenContract Suite")
+}
@@ -0,0 +1,561 @@
+package gencontract
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+	"github.com/goadesign/goa/uuid"
+)
+
+//NewGenerator returns an initialized instance of a contract test Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{ClientPkg: "client", Target: "contract"}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the contract test suite generator.
+type Generator struct {
+	API       *design.APIDefinition // The API definition
+	OutDir    string                // Path to output directory
+	Target    string                // Name of generated package
+	ClientPkg string                // Name or import path of generated "client" package
+	genfiles  []string              // Generated files
+}
+
+type (
+	// actionTest is the data used to render the contract test of an action.
+	actionTest struct {
+		Name      string
+		Var       string
+		Action    *design.ActionDefinition
+		Call      string
+		Path      string
+		Args      []string
+		Payload   string
+		Example   string
+		Responses []*contractResponse
+	}
+
+	// contractResponse is the data used to render a goatest.ContractResponse literal.
+	contractResponse struct {
+		Name        string
+		Status      int
+		ContentType string
+		Stream      bool
+		Headers     []string
+		Collection  bool
+		Fields      []string
+		Required    []string
+		Decode      string
+	}
+)
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, target, clientPkg, ver string
+	)
+
+	set := flag.NewFlagSet("contract", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "contract", "")
+	set.StringVar(&clientPkg, "client-pkg", "client", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	target = codegen.Goify(target, false)
+	g := &Generator{OutDir: outDir, Target: target, ClientPkg: clientPkg, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the contract test suite.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	// Requests need examples even if the design disables them.
+	if design.Design != nil && design.Design.NoExamples {
+		design.Design.NoExamples = false
+		defer func() { design.Design.NoExamples = true }()
+	}
+
+	outDir := filepath.Join(g.OutDir, g.Target)
+	if err = os.MkdirAll(outDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, outDir)
+
+	clientImp := g.ClientPkg
+	if _, err := codegen.PackageSourcePath(g.ClientPkg); err != nil {
+		clientImp, err = codegen.PackagePath(g.OutDir)
+		if err != nil {
+			return nil, err
+		}
+		clientImp = path.Join(filepath.ToSlash(clientImp), g.ClientPkg)
+	}
+	elems := strings.Split(clientImp, "/")
+	clientPkg := elems[len(elems)-1]
+
+	var tests []*actionTest
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			test, err := g.actionTest(a, clientPkg)
+			if err != nil {
+				return err
+			}
+			tests = append(tests, test)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = g.generateSuite(filepath.Join(outDir, "contract.go"), clientImp, clientPkg, tests); err != nil {
+		return nil, err
+	}
+	if err = g.generateTest(filepath.Join(outDir, "contract_test.go")); err != nil {
+		return nil, err
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.RemoveAll(f)
+	}
+	g.genfiles = nil
+}
+
+// generateSuite writes the contract tests of all the actions.
+func (g *Generator) generateSuite(filename, clientImp, clientPkg string, tests []*actionTest) (err error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	g.genfiles = append(g.genfiles, filename)
+
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("testing"),
+		codegen.SimpleImport("time"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
+		codegen.SimpleImport("github.com/goadesign/goa/goatest"),
+		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
+		codegen.SimpleImport(clientImp),
+	}
+	title := fmt.Sprintf("API %q: contract tests", g.API.Name)
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	data := map[string]interface{}{
+		"API":       g.API,
+		"ClientPkg": clientPkg,
+		"Tests":     tests,
+	}
+	return file.ExecuteTemplate("suite", suiteT, nil, data)
+}
+
+// generateTest writes the test that runs the contract tests against the service at the base URL
+// given on the command line.
+func (g *Generator) generateTest(filename string) (err error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	g.genfiles = append(g.genfiles, filename)
+
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("flag"),
+		codegen.SimpleImport("os"),
+		codegen.SimpleImport("testing"),
+	}
+	title := fmt.Sprintf("API %q: contract test entry point", g.API.Name)
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	return file.ExecuteTemplate("test", testT, nil, nil)
+}
+
+// actionTest computes the data needed to render the contract test of the given action.
+func (g *Generator) actionTest(a *design.ActionDefinition, clientPkg string) (*actionTest, error) {
+	res := a.Parent.Name
+	name := codegen.Goify(a.Name+strings.Title(res), true)
+	test := &actionTest{
+		Name:   codegen.Goify(res, true) + codegen.Goify(a.Name, true),
+		Var:    codegen.Goify(res, false) + codegen.Goify(a.Name, true) + "Responses",
+		Action: a,
+		Call:   name,
+	}
+	if a.WebSocket() {
+		return test, nil
+	}
+	rand := g.API.RandomGenerator()
+
+	// Path
+	route := a.Routes[0]
+	var pathArgs []string
+	for _, p := range route.Params() {
+		pathArgs = append(pathArgs, literal(a.Params.Type.ToObject()[p], rand))
+	}
+	test.Path = fmt.Sprintf("%s.%sPath(%s)", clientPkg, name, strings.Join(pathArgs, ", "))
+
+	// Payload
+	if a.Payload != nil {
+		ex := a.Payload.GenerateExample(rand, nil)
+		b, err := json.Marshal(ex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize example payload of %s: %s", a.Context(), err)
+		}
+		ref := codegen.GoTypeRef(a.Payload, a.Payload.AllRequired(), 1, false)
+		arg := "payload"
+		if strings.HasPrefix(ref, "*") {
+			ref = ref[1:]
+			arg = "&payload"
+		}
+		test.Payload = qualify(ref, clientPkg)
+		test.Example = string(b)
+		test.Args = append(test.Args, arg)
+	}
+
+	// Query string and headers
+	test.Args = append(test.Args, paramArgs(a.QueryParams, rand)...)
+	test.Args = append(test.Args, paramArgs(a.Headers, rand)...)
+	if a.Payload != nil && len(g.API.Consumes) > 1 {
+		test.Args = append(test.Args, `""`)
+	}
+
+	// Responses
+	err := a.IterateResponses(func(r *design.ResponseDefinition) error {
+		cr, err := g.contractResponse(r)
+		if err != nil {
+			return err
+		}
+		test.Responses = append(test.Responses, cr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(test.Responses, func(i, j int) bool { return test.Responses[i].Status < test.Responses[j].Status })
+
+	return test, nil
+}
+
+// contractResponse computes the data needed to render the given response.
+func (g *Generator) contractResponse(r *design.ResponseDefinition) (*contractResponse, error) {
+	cr := &contractResponse{
+		Name:        r.Name,
+		Status:      r.Status,
+		ContentType: r.MediaType,
+		Stream:      r.ServerSentEvents,
+	}
+	if r.Headers != nil {
+		for n := range r.Headers.Type.ToObject() {
+			if r.Headers.IsRequired(n) {
+				cr.Headers = append(cr.Headers, n)
+			}
+		}
+		sort.Strings(cr.Headers)
+	}
+	mt := g.API.MediaTypeWithIdentifier(r.MediaType)
+	if mt == nil || r.ServerSentEvents {
+		return cr, nil
+	}
+	view := r.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	cr.Decode = "Decode" + typeName(p)
+	att := p.AttributeDefinition
+	if p.IsArray() {
+		cr.Collection = true
+		att = p.ToArray().ElemType
+		if elem, ok := att.Type.(*design.MediaTypeDefinition); ok {
+			att = elem.AttributeDefinition
+		}
+	}
+	if !att.Type.IsObject() {
+		return cr, nil
+	}
+	cr.Fields = []string{}
+	for n := range att.Type.ToObject() {
+		cr.Fields = append(cr.Fields, n)
+	}
+	sort.Strings(cr.Fields)
+	cr.Required = att.AllRequired()
+	sort.Strings(cr.Required)
+	return cr, nil
+}
+
+// paramArgs returns the arguments given to the client method for the given parameters. The
+// arguments of required parameters are examples, optional parameters are not set.
+func paramArgs(att *design.AttributeDefinition, rand *design.RandomGenerator) []string {
+	if att == nil {
+		return nil
+	}
+	var req, opt []string
+	for n := range att.Type.ToObject() {
+		if att.IsRequired(n) {
+			req = append(req, n)
+		} else {
+			opt = append(opt, n)
+		}
+	}
+	sort.Strings(req)
+	sort.Strings(opt)
+	params := att.Type.ToObject()
+	var args []string
+	for _, n := range req {
+		args = append(args, literal(params[n], rand))
+	}
+	for range opt {
+		args = append(args, "nil")
+	}
+	return args
+}
+
+// literal returns the Go code that initializes an example value for the given parameter.
+func literal(att *design.AttributeDefinition, rand *design.RandomGenerator) string {
+	ex := att.GenerateExample(rand, nil)
+	if ex == nil || ex == "-" {
+		return zero(att.Type)
+	}
+	return valueLiteral(att.Type, ex)
+}
+
+// valueLiteral returns the Go code that initializes the given value of the given type.
+func valueLiteral(t design.DataType, v interface{}) string {
+	switch t.Kind() {
+	case design.ArrayKind:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			return zero(t)
+		}
+		elem := t.ToArray().ElemType.Type
+		elems := make([]string, rv.Len())
+		for i := range elems {
+			elems[i] = valueLiteral(elem, rv.Index(i).Interface())
+		}
+		return fmt.Sprintf("%s{%s}", codegen.GoNativeType(t), strings.Join(elems, ", "))
+	case design.IntegerKind:
+		if i, ok := v.(int); ok {
+			return strconv.Itoa(i)
+		}
+	case design.NumberKind:
+		if f, ok := v.(float64); ok {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	case design.BooleanKind:
+		if b, ok := v.(bool); ok {
+			return strconv.FormatBool(b)
+		}
+	case design.StringKind:
+		if s, ok := v.(string); ok {
+			return strconv.Quote(s)
+		}
+	case design.DateTimeKind:
+		if d, ok := v.(time.Time); ok {
+			d = d.UTC()
+			return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, %d, time.UTC)",
+				d.Year(), d.Month(), d.Day(), d.Hour(), d.Minute(), d.Second(), d.Nanosecond())
+		}
+	case design.UUIDKind:
+		if s, ok := v.(string); ok {
+			if u, err := uuid.FromString(s); err == nil {
+				bytes := make([]string, len(u))
+				for i, b := range u {
+					bytes[i] = fmt.Sprintf("0x%02x", b)
+				}
+				return fmt.Sprintf("uuid.UUID{%s}", strings.Join(bytes, ", "))
+			}
+		}
+	case design.AnyKind:
+		switch actual := v.(type) {
+		case int:
+			return valueLiteral(design.Integer, actual)
+		case float64:
+			return valueLiteral(design.Number, actual)
+		case bool:
+			return valueLiteral(design.Boolean, actual)
+		case string:
+			return valueLiteral(design.String, actual)
+		case time.Time:
+			return valueLiteral(design.DateTime, actual)
+		}
+	}
+	return zero(t)
+}
+
+// zero returns the Go code for the zero value of the given type.
+func zero(t design.DataType) string {
+	switch t.Kind() {
+	case design.IntegerKind, design.NumberKind:
+		return "0"
+	case design.BooleanKind:
+		return "false"
+	case design.StringKind:
+		return `""`
+	case design.DateTimeKind:
+		return "time.Time{}"
+	case design.UUIDKind:
+		return "uuid.UUID{}"
+	default:
+		return "nil"
+	}
+}
+
+// typeName returns the name of the client type used to decode the given media type, it mirrors
+// the names produced by "goagen client".
+func typeName(mt *design.MediaTypeDefinition) string {
+	if mt.IsError() {
+		return "ErrorResponse"
+	}
+	return codegen.GoTypeName(mt, mt.AllRequired(), 1, false)
+}
+
+// exportedIdent matches the exported identifiers in a Go type reference.
+var exportedIdent = regexp.MustCompile(`(^|[\*\]])([A-Z]\w*)`)
+
+// qualify prefixes the exported identifiers of the given type reference with the package name.
+func qualify(ref, pkg string) string {
+	return exportedIdent.ReplaceAllString(ref, "${1}"+pkg+".${2}")
+}
+
+const suiteT = `// NewClient returns a client that sends requests to the service at baseURL, for example
+// "http://localhost:8080". Use the client Set*Signer methods to configure the request signers of
+// secured actions.
+func NewClient(baseURL string) (*{{ .ClientPkg }}.Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	c := {{ .ClientPkg }}.New(goaclient.HTTPClientDoer(http.DefaultClient))
+	c.Scheme = u.Scheme
+	c.Host = u.Host
+	return c, nil
+}
+
+// Run runs the contract tests of all the {{ .API.Name }} actions using c to send the requests.
+// Each action is called once with example parameters and payloads generated from the design. The
+// test checks that the response status code is one of the status codes defined in the design and
+// validates the headers, Content-Type and body of the response.
+func Run(t *testing.T, c *{{ .ClientPkg }}.Client) {
+{{ range .Tests }}	t.Run({{ printf "%q" (printf "%s/%s" .Action.Parent.Name .Action.Name) }}, func(t *testing.T) { test{{ .Name }}(t, c) })
+{{ end }}}
+{{ range .Tests }}
+// test{{ .Name }} checks the {{ .Action.Name }} action of the {{ .Action.Parent.Name }} resource.
+func test{{ .Name }}(t *testing.T, c *{{ $.ClientPkg }}.Client) {
+{{ if .Action.WebSocket }}	t.Skip("websocket actions are not tested")
+}
+{{ else }}{{ if .Payload }}	var payload {{ .Payload }}
+	if err := json.Unmarshal([]byte({{ printf "%q" .Example }}), &payload); err != nil {
+		t.Fatalf("invalid payload example: %s", err)
+	}
+{{ end }}	resp, err := c.{{ .Call }}(context.Background(), {{ .Path }}{{ range .Args }}, {{ . }}{{ end }})
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	defer resp.Body.Close()
+	r, err := goatest.CheckResponse(resp, {{ .Var }})
+	if err != nil {
+		t.Fatal(err)
+	}
+{{ $decode := false }}{{ range .Responses }}{{ if .Decode }}{{ $decode = true }}{{ end }}{{ end }}{{ if $decode }}	switch r.Status {
+{{ range .Responses }}{{ if .Decode }}	case {{ .Status }}:
+		res, err := c.{{ .Decode }}(resp)
+		if err != nil {
+			t.Fatalf("failed to decode %s response: %s", r.Name, err)
+		}
+		if err := goatest.Validate(res); err != nil {
+			t.Errorf("invalid %s response: %s", r.Name, err)
+		}
+{{ end }}{{ end }}	}
+{{ else }}	t.Logf("%s response", r.Name)
+{{ end }}}
+
+// {{ .Var }} lists the responses of the {{ .Action.Name }} action of the {{ .Action.Parent.Name }} resource.
+var {{ .Var }} = []*goatest.ContractResponse{
+{{ range .Responses }}	{
+		Name:   {{ printf "%q" .Name }},
+		Status: {{ .Status }},
+{{ if .ContentType }}		ContentType: {{ printf "%q" .ContentType }},
+{{ end }}{{ if .Stream }}		Stream: true,
+{{ end }}{{ if .Headers }}		Headers: {{ printf "%#v" .Headers }},
+{{ end }}{{ if .Collection }}		Collection: true,
+{{ end }}{{ if .Fields }}		Fields: {{ printf "%#v" .Fields }},
+{{ end }}{{ if .Required }}		Required: {{ printf "%#v" .Required }},
+{{ end }}	},
+{{ end }}}
+{{ end }}{{ end }}`
+
+const testT = `// baseURL is the base URL of the service under test.
+var baseURL = flag.String("base-url", os.Getenv("CONTRACT_BASE_URL"), "base ` + "`URL`" + ` of the service under test")
+
+// TestContract runs the contract tests against the service at the URL given by the -base-url flag
+// or the CONTRACT_BASE_URL environment variable.
+func TestContract(t *testing.T) {
+	if *baseURL == "" {
+		t.Skip("no base URL, use -base-url or set CONTRACT_BASE_URL")
+	}
+	c, err := NewClient(*baseURL)
+	if err != nil {
+		t.Fatalf("invalid base URL: %s", err)
+	}
+	Run(t, c)
+}
+`
@@ -0,0 +1,159 @@
+package gencontract_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	gencontract "github.com/goadesign/goa/goagen/gen_contract"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gencontract.Generator
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gencontract.NewGenerator(
+				gencontract.API(&APIDefinition{Name: "test api"}),
+				gencontract.OutDir("out_dir"),
+				gencontract.Target("checks"),
+				gencontract.ClientPkg("example.com/client"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal("test api"))
+			Ω(generator.OutDir).Should(Equal("out_dir"))
+			Ω(generator.Target).Should(Equal("checks"))
+			Ω(generator.ClientPkg).Should(Equal("example.com/client"))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = gencontract.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a bottle API", func() {
+		BeforeEach(func() {
+			var BottlePayload = Type("BottlePayload", func() {
+				Attribute("name", String, func() {
+					Example("muscadet")
+				})
+				Required("name")
+			})
+			var Bottle = MediaType("application/vnd.bottle+json", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+					Required("id")
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			API("test api", nil)
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer, func() {
+							Example(42)
+						})
+						Param("view", String)
+					})
+					Response(OK, Bottle)
+					Response(NotFound)
+				})
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(BottlePayload)
+					Response(Created, func() {
+						Media(Bottle, "tiny")
+						Headers(func() {
+							Header("Location")
+							Required("Location")
+						})
+					})
+				})
+				Action("list", func() {
+					Routing(GET(""))
+					Response(OK, CollectionOf(Bottle))
+				})
+			})
+		})
+
+		It("generates the contract test suite", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(HaveLen(3))
+
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "contract", "contract_test.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func TestContract(t *testing.T) {"))
+
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "contract", "contract.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`t.Run("bottle/show", func(t *testing.T) { testBottleShow(t, c) })`))
+			Ω(string(content)).Should(ContainSubstring(`resp, err := c.ShowBottle(context.Background(), client.ShowBottlePath(42), nil)`))
+			Ω(string(content)).Should(ContainSubstring(`	var payload client.BottlePayload
+	if err := json.Unmarshal([]byte("{\"name\":\"muscadet\"}"), &payload); err != nil {`))
+			Ω(string(content)).Should(ContainSubstring(`resp, err := c.CreateBottle(context.Background(), client.CreateBottlePath(), &payload, "")`))
+			Ω(string(content)).Should(ContainSubstring(`		res, err := c.DecodeBottleTiny(resp)`))
+			Ω(string(content)).Should(ContainSubstring(`var bottleCreateResponses = []*goatest.ContractResponse{
+	{
+		Name:        "Created",
+		Status:      201,
+		ContentType: "application/vnd.bottle+json",
+		Headers:     []string{"Location"},
+		Fields:      []string{"id"},
+		Required:    []string{"id"},
+	},
+}`))
+			Ω(string(content)).Should(ContainSubstring(`var bottleListResponses = []*goatest.ContractResponse{
+	{
+		Name:        "OK",
+		Status:      200,
+		ContentType: "application/vnd.bottle+json; type=collection",
+		Collection:  true,
+		Fields:      []string{"id", "name"},
+		Required:    []string{"id"},
+	},
+}`))
+		})
+	})
+})
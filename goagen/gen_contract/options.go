@@ -0,0 +1,34 @@
+package gencontract
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Target Name of generated package
+func Target(target string) Option {
+	return func(g *Generator) {
+		g.Target = target
+	}
+}
+
+//ClientPkg Name or import path of generated "client" package
+func ClientPkg(pkg string) Option {
+	return func(g *Generator) {
+		g.ClientPkg = pkg
+	}
+}
@@ -0,0 +1,282 @@
+package gendiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// ContractVersion is the version of the contract serialization format.
+const ContractVersion = "1"
+
+type (
+	// Contract is a flattened representation of the parts of an API design that clients
+	// depend on. Contracts are computed from a design with NewContract and can be serialized
+	// so that the design of a previous version of the API can be compared without having to
+	// compile its design package.
+	Contract struct {
+		// Version is the contract serialization format version.
+		Version string `json:"version"`
+		// API is the name of the API.
+		API string `json:"api"`
+		// Resources lists the API resources indexed by name.
+		Resources map[string]*Resource `json:"resources,omitempty"`
+		// MediaTypes lists the API media types indexed by canonical identifier.
+		MediaTypes map[string]*MediaType `json:"media_types,omitempty"`
+	}
+
+	// Resource describes the actions of a resource.
+	Resource struct {
+		// Actions lists the resource actions indexed by name.
+		Actions map[string]*Action `json:"actions,omitempty"`
+	}
+
+	// Action describes the requests and responses of a single action.
+	Action struct {
+		// Routes lists the action routes formatted as "VERB /full/path".
+		Routes []string `json:"routes,omitempty"`
+		// Params describes the action path and query string parameters.
+		Params *Attribute `json:"params,omitempty"`
+		// Headers describes the action request headers.
+		Headers *Attribute `json:"headers,omitempty"`
+		// Payload describes the action request body if any.
+		Payload *Attribute `json:"payload,omitempty"`
+		// PayloadOptional is true if the request body may be omitted.
+		PayloadOptional bool `json:"payload_optional,omitempty"`
+		// Idempotent is true if clients may retry the action.
+		Idempotent bool `json:"idempotent,omitempty"`
+		// Responses lists the action responses indexed by name.
+		Responses map[string]*Response `json:"responses,omitempty"`
+	}
+
+	// Response describes an action response.
+	Response struct {
+		// Status is the response HTTP status code.
+		Status int `json:"status"`
+		// MediaType is the canonical identifier of the response media type if any.
+		MediaType string `json:"media_type,omitempty"`
+		// View is the name of the view used to render the response body if any.
+		View string `json:"view,omitempty"`
+		// Headers describes the response headers.
+		Headers *Attribute `json:"headers,omitempty"`
+	}
+
+	// MediaType describes the views of a media type.
+	MediaType struct {
+		// Identifier is the media type identifier.
+		Identifier string `json:"identifier"`
+		// Views describes the rendered body of each view indexed by view name.
+		Views map[string]*Attribute `json:"views,omitempty"`
+	}
+
+	// Attribute describes the shape and validations of a data structure.
+	Attribute struct {
+		// Type is the name of the attribute type, one of the primitive type names,
+		// "array", "hash" or "object".
+		Type string `json:"type"`
+		// TypeName is the name of the user type or media type if any.
+		TypeName string `json:"type_name,omitempty"`
+		// Recursive is true if the attribute refers to a user type already described by
+		// one of its parents. The attribute fields are not described in this case.
+		Recursive bool `json:"recursive,omitempty"`
+		// Fields lists the object attributes indexed by name.
+		Fields map[string]*Attribute `json:"fields,omitempty"`
+		// Elem describes the array or hash element type.
+		Elem *Attribute `json:"elem,omitempty"`
+		// Key describes the hash key type.
+		Key *Attribute `json:"key,omitempty"`
+		// Validation lists the attribute validations.
+		Validation *Validation `json:"validation,omitempty"`
+	}
+
+	// Validation is the serializable version of dslengine.ValidationDefinition.
+	Validation struct {
+		Values    []interface{} `json:"enum,omitempty"`
+		Format    string        `json:"format,omitempty"`
+		Pattern   string        `json:"pattern,omitempty"`
+		Minimum   *float64      `json:"minimum,omitempty"`
+		Maximum   *float64      `json:"maximum,omitempty"`
+		MinLength *int          `json:"min_length,omitempty"`
+		MaxLength *int          `json:"max_length,omitempty"`
+		Required  []string      `json:"required,omitempty"`
+	}
+)
+
+// NewContract computes the contract of the given API.
+func NewContract(api *design.APIDefinition) *Contract {
+	c := &Contract{
+		Version:    ContractVersion,
+		API:        api.Name,
+		Resources:  make(map[string]*Resource),
+		MediaTypes: make(map[string]*MediaType),
+	}
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		res := &Resource{Actions: make(map[string]*Action)}
+		r.IterateActions(func(a *design.ActionDefinition) error {
+			res.Actions[a.Name] = newAction(a)
+			return nil
+		})
+		c.Resources[r.Name] = res
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		m := &MediaType{Identifier: mt.Identifier, Views: make(map[string]*Attribute)}
+		mt.IterateViews(func(v *design.ViewDefinition) error {
+			if p, _, err := mt.Project(v.Name); err == nil {
+				m.Views[v.Name] = NewAttribute(p.AttributeDefinition)
+			}
+			return nil
+		})
+		c.MediaTypes[design.CanonicalIdentifier(mt.Identifier)] = m
+		return nil
+	})
+	return c
+}
+
+// LoadContract reads a contract previously written with Write.
+func LoadContract(path string) (*Contract, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Contract
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid contract file %s: %s", path, err)
+	}
+	if c.Version != ContractVersion {
+		return nil, fmt.Errorf("unsupported contract version %#v in %s", c.Version, path)
+	}
+	return &c, nil
+}
+
+// Write serializes the contract to the file with the given path.
+func (c *Contract) Write(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// NewAttribute computes the contract description of the given attribute.
+func NewAttribute(at *design.AttributeDefinition) *Attribute {
+	return newAttribute(at, nil)
+}
+
+func newAction(a *design.ActionDefinition) *Action {
+	act := &Action{
+		Params:          NewAttribute(a.AllParams()),
+		PayloadOptional: a.PayloadOptional,
+		Idempotent:      a.Idempotent,
+		Responses:       make(map[string]*Response),
+	}
+	for _, r := range a.Routes {
+		act.Routes = append(act.Routes, r.Verb+" "+r.FullPath())
+	}
+	sort.Strings(act.Routes)
+	headers := &design.AttributeDefinition{Type: design.Object{}}
+	var required []string
+	a.IterateHeaders(func(name string, isRequired bool, h *design.AttributeDefinition) error {
+		headers.Type.ToObject()[name] = h
+		if isRequired {
+			required = append(required, name)
+		}
+		return nil
+	})
+	if len(headers.Type.ToObject()) > 0 {
+		if len(required) > 0 {
+			headers.Validation = &dslengine.ValidationDefinition{Required: required}
+		}
+		act.Headers = NewAttribute(headers)
+	}
+	if a.Payload != nil {
+		act.Payload = newUserTypeAttribute(a.Payload, nil, nil)
+	}
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		resp := &Response{Status: r.Status, View: r.ViewName}
+		if r.MediaType != "" {
+			resp.MediaType = design.CanonicalIdentifier(r.MediaType)
+			if resp.View == "" {
+				resp.View = design.DefaultView
+			}
+		}
+		if r.Headers != nil {
+			resp.Headers = NewAttribute(r.Headers)
+		}
+		act.Responses[r.Name] = resp
+		return nil
+	})
+	return act
+}
+
+func newAttribute(at *design.AttributeDefinition, seen []string) *Attribute {
+	if at == nil || at.Type == nil {
+		return nil
+	}
+	res := &Attribute{Validation: newValidation(at.Validation)}
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		res.Type = actual.Name()
+	case *design.Array:
+		res.Type = "array"
+		res.Elem = newAttribute(actual.ElemType, seen)
+	case *design.Hash:
+		res.Type = "hash"
+		res.Key = newAttribute(actual.KeyType, seen)
+		res.Elem = newAttribute(actual.ElemType, seen)
+	case design.Object:
+		res.Type = "object"
+		res.Fields = make(map[string]*Attribute, len(actual))
+		for n, att := range actual {
+			res.Fields[n] = newAttribute(att, seen)
+		}
+	case *design.UserTypeDefinition:
+		return newUserTypeAttribute(actual, res.Validation, seen)
+	case *design.MediaTypeDefinition:
+		return newUserTypeAttribute(actual.UserTypeDefinition, res.Validation, seen)
+	}
+	return res
+}
+
+func newUserTypeAttribute(ut *design.UserTypeDefinition, val *Validation, seen []string) *Attribute {
+	for _, s := range seen {
+		if s == ut.TypeName {
+			return &Attribute{Type: ut.Type.Name(), TypeName: ut.TypeName, Recursive: true}
+		}
+	}
+	res := newAttribute(ut.AttributeDefinition, append(seen, ut.TypeName))
+	res.TypeName = ut.TypeName
+	if val != nil {
+		if res.Validation == nil {
+			res.Validation = val
+		} else {
+			res.Validation.Required = append(res.Validation.Required, val.Required...)
+		}
+	}
+	return res
+}
+
+func newValidation(val *dslengine.ValidationDefinition) *Validation {
+	if val == nil {
+		return nil
+	}
+	v := &Validation{
+		Values:    val.Values,
+		Format:    val.Format,
+		Pattern:   val.Pattern,
+		Minimum:   val.Minimum,
+		Maximum:   val.Maximum,
+		MinLength: val.MinLength,
+		MaxLength: val.MaxLength,
+	}
+	if len(val.Required) > 0 {
+		v.Required = make([]string, len(val.Required))
+		copy(v.Required, val.Required)
+		sort.Strings(v.Required)
+	}
+	return v
+}
@@ -0,0 +1,426 @@
+package gendiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	// Change describes a single difference between two API contracts.
+	Change struct {
+		// Path identifies the design element that changed, e.g. "bottle#show param id".
+		Path string `json:"path"`
+		// Breaking is true if the change may break existing clients.
+		Breaking bool `json:"breaking"`
+		// Message describes the change.
+		Message string `json:"message"`
+	}
+
+	// direction indicates whether data flows from clients to the service (request) or from
+	// the service to clients (response). Narrowing the set of accepted values is breaking for
+	// requests while widening the set of returned values is breaking for responses.
+	direction int
+
+	// differ accumulates the changes found while comparing two contracts.
+	differ struct {
+		changes []*Change
+	}
+)
+
+const (
+	request direction = iota
+	response
+)
+
+// Compare computes the changes needed to go from the base contract to the head contract.
+// The changes are sorted by path.
+func Compare(base, head *Contract) []*Change {
+	d := &differ{}
+	d.compareResources(base.Resources, head.Resources)
+	d.compareMediaTypes(base.MediaTypes, head.MediaTypes)
+	sort.SliceStable(d.changes, func(i, j int) bool { return d.changes[i].Path < d.changes[j].Path })
+	return d.changes
+}
+
+// Breaking returns the breaking changes in the given list.
+func Breaking(changes []*Change) []*Change {
+	var res []*Change
+	for _, c := range changes {
+		if c.Breaking {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// String returns a human friendly representation of the change.
+func (c *Change) String() string {
+	kind := "compatible"
+	if c.Breaking {
+		kind = "BREAKING"
+	}
+	return fmt.Sprintf("%-10s %s: %s", kind, c.Path, c.Message)
+}
+
+func (d *differ) add(path string, breaking bool, format string, args ...interface{}) {
+	d.changes = append(d.changes, &Change{Path: path, Breaking: breaking, Message: fmt.Sprintf(format, args...)})
+}
+
+// narrowed records a change that reduces the set of valid values.
+func (d *differ) narrowed(path string, dir direction, format string, args ...interface{}) {
+	d.add(path, dir == request, format, args...)
+}
+
+// widened records a change that increases the set of valid values.
+func (d *differ) widened(path string, dir direction, format string, args ...interface{}) {
+	d.add(path, dir == response, format, args...)
+}
+
+func (d *differ) compareResources(base, head map[string]*Resource) {
+	for _, n := range sortedKeys(base) {
+		h, ok := head[n]
+		if !ok {
+			d.add(n, true, "resource removed")
+			continue
+		}
+		b := base[n]
+		for _, an := range sortedKeys(b.Actions) {
+			path := n + "#" + an
+			ha, ok := h.Actions[an]
+			if !ok {
+				d.add(path, true, "action removed")
+				continue
+			}
+			d.compareAction(path, b.Actions[an], ha)
+		}
+		for _, an := range sortedKeys(h.Actions) {
+			if _, ok := b.Actions[an]; !ok {
+				d.add(n+"#"+an, false, "action added")
+			}
+		}
+	}
+	for _, n := range sortedKeys(head) {
+		if _, ok := base[n]; !ok {
+			d.add(n, false, "resource added")
+		}
+	}
+}
+
+func (d *differ) compareAction(path string, base, head *Action) {
+	routes := make(map[string]bool, len(head.Routes))
+	for _, r := range head.Routes {
+		routes[r] = true
+	}
+	for _, r := range base.Routes {
+		if !routes[r] {
+			d.add(path, true, "route %s removed", r)
+		}
+		delete(routes, r)
+	}
+	for _, r := range head.Routes {
+		if routes[r] {
+			d.add(path, false, "route %s added", r)
+		}
+	}
+
+	if base.Idempotent && !head.Idempotent {
+		d.add(path, true, "action is no longer idempotent")
+	} else if !base.Idempotent && head.Idempotent {
+		d.add(path, false, "action is now idempotent")
+	}
+
+	d.compareFields(path+" param", base.Params, head.Params, request)
+	d.compareFields(path+" header", base.Headers, head.Headers, request)
+
+	ppath := path + " payload"
+	switch {
+	case base.Payload == nil && head.Payload != nil:
+		d.add(ppath, !head.PayloadOptional, "payload added")
+	case base.Payload != nil && head.Payload == nil:
+		d.add(ppath, true, "payload removed")
+	case base.Payload != nil:
+		if base.PayloadOptional && !head.PayloadOptional {
+			d.add(ppath, true, "payload is now required")
+		} else if !base.PayloadOptional && head.PayloadOptional {
+			d.add(ppath, false, "payload is now optional")
+		}
+		d.compareAttribute(ppath, base.Payload, head.Payload, request)
+	}
+
+	for _, n := range sortedKeys(base.Responses) {
+		rpath := path + " response " + n
+		br := base.Responses[n]
+		hr, ok := head.Responses[n]
+		if !ok {
+			d.add(rpath, br.Status >= 200 && br.Status < 300, "response removed")
+			continue
+		}
+		if br.Status != hr.Status {
+			d.add(rpath, true, "status changed from %d to %d", br.Status, hr.Status)
+		}
+		if br.MediaType != hr.MediaType {
+			d.add(rpath, true, "media type changed from %#v to %#v", br.MediaType, hr.MediaType)
+		} else if br.View != hr.View {
+			d.add(rpath, true, "view changed from %#v to %#v", br.View, hr.View)
+		}
+		d.compareFields(rpath+" header", br.Headers, hr.Headers, response)
+	}
+	for _, n := range sortedKeys(head.Responses) {
+		if _, ok := base.Responses[n]; !ok {
+			d.add(path+" response "+n, false, "response added")
+		}
+	}
+}
+
+func (d *differ) compareMediaTypes(base, head map[string]*MediaType) {
+	for _, id := range sortedKeys(base) {
+		h, ok := head[id]
+		if !ok {
+			d.add(id, true, "media type removed")
+			continue
+		}
+		b := base[id]
+		for _, v := range sortedKeys(b.Views) {
+			path := id + " view " + v
+			hv, ok := h.Views[v]
+			if !ok {
+				d.add(path, true, "view removed")
+				continue
+			}
+			d.compareAttribute(path, b.Views[v], hv, response)
+		}
+		for _, v := range sortedKeys(h.Views) {
+			if _, ok := b.Views[v]; !ok {
+				d.add(id+" view "+v, false, "view added")
+			}
+		}
+	}
+	for _, id := range sortedKeys(head) {
+		if _, ok := base[id]; !ok {
+			d.add(id, false, "media type added")
+		}
+	}
+}
+
+// compareFields compares two objects whose fields are reported individually using the given
+// path prefix, e.g. action parameters or headers.
+func (d *differ) compareFields(prefix string, base, head *Attribute, dir direction) {
+	if base == nil {
+		base = &Attribute{Type: "object"}
+	}
+	if head == nil {
+		head = &Attribute{Type: "object"}
+	}
+	d.compareObject(prefix, true, base, head, dir)
+}
+
+// compareAttribute records the differences between two attributes.
+func (d *differ) compareAttribute(path string, base, head *Attribute, dir direction) {
+	if base == nil || head == nil {
+		return
+	}
+	if base.Type != head.Type {
+		d.add(path, true, "type changed from %s to %s", base.Type, head.Type)
+		return
+	}
+	d.compareValidation(path, base.Validation, head.Validation, dir)
+	if base.Recursive || head.Recursive {
+		return
+	}
+	switch base.Type {
+	case "object":
+		d.compareObject(path, false, base, head, dir)
+	case "array":
+		d.compareAttribute(path+"[]", base.Elem, head.Elem, dir)
+	case "hash":
+		d.compareAttribute(path+"{key}", base.Key, head.Key, dir)
+		d.compareAttribute(path+"{}", base.Elem, head.Elem, dir)
+	}
+}
+
+// compareObject compares the fields of two objects. If fields is true then the object fields
+// paths are built by appending a space and the field name to prefix, otherwise a dot is used.
+func (d *differ) compareObject(prefix string, fields bool, base, head *Attribute, dir direction) {
+	fieldPath := func(n string) string {
+		if fields {
+			return prefix + " " + n
+		}
+		return prefix + "." + n
+	}
+	baseReq, headReq := required(base), required(head)
+	for _, n := range sortedKeys(base.Fields) {
+		path := fieldPath(n)
+		h, ok := head.Fields[n]
+		if !ok {
+			d.add(path, dir == response, "removed")
+			continue
+		}
+		if !baseReq[n] && headReq[n] {
+			d.narrowed(path, dir, "is now required")
+		} else if baseReq[n] && !headReq[n] {
+			d.widened(path, dir, "is no longer required")
+		}
+		d.compareAttribute(path, base.Fields[n], h, dir)
+	}
+	for _, n := range sortedKeys(head.Fields) {
+		if _, ok := base.Fields[n]; !ok {
+			if headReq[n] {
+				d.narrowed(fieldPath(n), dir, "added as required")
+			} else {
+				d.add(fieldPath(n), false, "added")
+			}
+		}
+	}
+}
+
+// compareValidation records the differences between two sets of validations. Required fields
+// are compared by compareObject.
+func (d *differ) compareValidation(path string, base, head *Validation, dir direction) {
+	if base == nil {
+		base = &Validation{}
+	}
+	if head == nil {
+		head = &Validation{}
+	}
+
+	// Enum
+	switch {
+	case len(base.Values) == 0 && len(head.Values) > 0:
+		d.narrowed(path, dir, "enum %v added", head.Values)
+	case len(base.Values) > 0 && len(head.Values) == 0:
+		d.widened(path, dir, "enum %v removed", base.Values)
+	case len(base.Values) > 0:
+		if removed := missingValues(base.Values, head.Values); len(removed) > 0 {
+			d.narrowed(path, dir, "enum values %v removed", removed)
+		}
+		if added := missingValues(head.Values, base.Values); len(added) > 0 {
+			d.widened(path, dir, "enum values %v added", added)
+		}
+	}
+
+	// Format and pattern
+	d.compareString(path, "format", base.Format, head.Format, dir)
+	d.compareString(path, "pattern", base.Pattern, head.Pattern, dir)
+
+	// Bounds
+	d.compareBound(path, "minimum", base.Minimum, head.Minimum, lower, dir)
+	d.compareBound(path, "maximum", base.Maximum, head.Maximum, upper, dir)
+	d.compareBound(path, "min length", lengthBound(base.MinLength), lengthBound(head.MinLength), lower, dir)
+	d.compareBound(path, "max length", lengthBound(base.MaxLength), lengthBound(head.MaxLength), upper, dir)
+}
+
+func (d *differ) compareString(path, name, base, head string, dir direction) {
+	switch {
+	case base == head:
+	case base == "":
+		d.narrowed(path, dir, "%s %#v added", name, head)
+	case head == "":
+		d.widened(path, dir, "%s %#v removed", name, base)
+	default:
+		// Cannot tell whether the new value is more or less restrictive, assume it's both.
+		d.add(path, true, "%s changed from %#v to %#v", name, base, head)
+	}
+}
+
+type bound int
+
+const (
+	lower bound = iota
+	upper
+)
+
+// compareBound compares two lower or upper bounds. nil values indicate the absence of bound.
+func (d *differ) compareBound(path, name string, base, head *float64, kind bound, dir direction) {
+	switch {
+	case base == nil && head == nil:
+	case base == nil:
+		d.narrowed(path, dir, "%s %v added", name, *head)
+	case head == nil:
+		d.widened(path, dir, "%s %v removed", name, *base)
+	case *base == *head:
+	case (*head > *base) == (kind == lower):
+		d.narrowed(path, dir, "%s changed from %v to %v", name, *base, *head)
+	default:
+		d.widened(path, dir, "%s changed from %v to %v", name, *base, *head)
+	}
+}
+
+// required returns the set of required field names of the given object attribute.
+func required(att *Attribute) map[string]bool {
+	res := make(map[string]bool)
+	if att.Validation != nil {
+		for _, n := range att.Validation.Required {
+			res[n] = true
+		}
+	}
+	return res
+}
+
+// missingValues returns the values in vals that are not in others.
+func missingValues(vals, others []interface{}) []interface{} {
+	var res []interface{}
+	for _, v := range vals {
+		found := false
+		for _, o := range others {
+			if fmt.Sprintf("%v", v) == fmt.Sprintf("%v", o) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// lengthBound converts a length validation into a bound usable with compareBound.
+func lengthBound(i *int) *float64 {
+	if i == nil {
+		return nil
+	}
+	f := float64(*i)
+	return &f
+}
+
+// sortedKeys returns the keys of the given map in alphabetical order, m must be a map indexed
+// by strings.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch actual := m.(type) {
+	case map[string]*Resource:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*Action:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*Response:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*MediaType:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*Attribute:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	default:
+		panic(fmt.Sprintf("unexpected map type %T", m)) // bug
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatChanges formats the given changes one per line.
+func formatChanges(changes []*Change) string {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,291 @@
+package gendiff_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	gendiff "github.com/goadesign/goa/goagen/gen_diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// contract runs the given DSL and returns the resulting contract.
+func contract(dsl func()) *gendiff.Contract {
+	dslengine.Reset()
+	ProjectedMediaTypes = make(MediaTypeRoot)
+	dsl()
+	Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	return gendiff.NewContract(Design)
+}
+
+// bottleDesign returns a design DSL which can be tweaked by the given functions.
+func bottleDesign(mediaAttrs, params, payload func()) func() {
+	return func() {
+		API("test", func() {})
+		BottleMedia := MediaType("application/vnd.goa.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("name", String)
+				mediaAttrs()
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(params)
+				Response(OK, BottleMedia)
+				Response(NotFound)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(payload)
+				Response(Created)
+			})
+		})
+	}
+}
+
+var _ = Describe("Compare", func() {
+	var base, head func()
+	var changes []*gendiff.Change
+
+	defaultParams := func() {
+		Param("id", Integer)
+		Param("color", String, func() {
+			Enum("red", "white")
+		})
+	}
+	defaultPayload := func() {
+		Attribute("name", String, func() {
+			MaxLength(100)
+		})
+		Attribute("vintage", Integer)
+		Required("name")
+	}
+
+	BeforeEach(func() {
+		base = bottleDesign(func() { Required("id") }, defaultParams, defaultPayload)
+		head = base
+	})
+
+	JustBeforeEach(func() {
+		b := contract(base)
+		h := contract(head)
+		changes = gendiff.Compare(b, h)
+	})
+
+	Context("with identical designs", func() {
+		It("does not report any change", func() {
+			Ω(changes).Should(BeEmpty())
+		})
+	})
+
+	Context("with a removed action", func() {
+		BeforeEach(func() {
+			head = func() {
+				API("test", func() {})
+				MediaType("application/vnd.goa.bottle", func() {
+					Attributes(func() {
+						Attribute("id", Integer)
+						Attribute("name", String)
+						Required("id")
+					})
+					View("default", func() {
+						Attribute("id")
+						Attribute("name")
+					})
+				})
+				Resource("bottle", func() {
+					BasePath("/bottles")
+					Action("create", func() {
+						Routing(POST(""))
+						Payload(defaultPayload)
+						Response(Created)
+					})
+					Action("list", func() {
+						Routing(GET(""))
+						Response(OK)
+					})
+				})
+			}
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(ConsistOf(
+				&gendiff.Change{Path: "bottle#list", Breaking: false, Message: "action added"},
+				&gendiff.Change{Path: "bottle#show", Breaking: true, Message: "action removed"},
+			))
+		})
+	})
+
+	Context("with a new required param", func() {
+		BeforeEach(func() {
+			head = bottleDesign(func() { Required("id") }, func() {
+				defaultParams()
+				Param("year", Integer)
+				Required("color")
+			}, defaultPayload)
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(ConsistOf(
+				&gendiff.Change{Path: "bottle#show param color", Breaking: true, Message: "is now required"},
+				&gendiff.Change{Path: "bottle#show param year", Breaking: false, Message: "added"},
+			))
+		})
+	})
+
+	Context("with a narrowed enum", func() {
+		BeforeEach(func() {
+			head = bottleDesign(func() { Required("id") }, func() {
+				Param("id", Integer)
+				Param("color", String, func() {
+					Enum("red", "rose")
+				})
+			}, defaultPayload)
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(ConsistOf(
+				&gendiff.Change{Path: "bottle#show param color", Breaking: true, Message: "enum values [white] removed"},
+				&gendiff.Change{Path: "bottle#show param color", Breaking: false, Message: "enum values [rose] added"},
+			))
+		})
+	})
+
+	Context("with payload validation changes", func() {
+		BeforeEach(func() {
+			head = bottleDesign(func() { Required("id") }, defaultParams, func() {
+				Attribute("name", String, func() {
+					MaxLength(50)
+				})
+				Attribute("vintage", Integer, func() {
+					Minimum(1900)
+				})
+				Attribute("review", String)
+			})
+		})
+
+		It("classifies narrowing validations as breaking", func() {
+			Ω(changes).Should(ConsistOf(
+				&gendiff.Change{Path: "bottle#create payload.name", Breaking: true, Message: "max length changed from 100 to 50"},
+				&gendiff.Change{Path: "bottle#create payload.name", Breaking: false, Message: "is no longer required"},
+				&gendiff.Change{Path: "bottle#create payload.review", Breaking: false, Message: "added"},
+				&gendiff.Change{Path: "bottle#create payload.vintage", Breaking: true, Message: "minimum 1900 added"},
+			))
+		})
+	})
+
+	Context("with a removed attribute in a view", func() {
+		BeforeEach(func() {
+			head = func() {
+				API("test", func() {})
+				BottleMedia := MediaType("application/vnd.goa.bottle", func() {
+					Attributes(func() {
+						Attribute("id", Integer)
+						Attribute("name", String)
+						Attribute("rating", Integer)
+					})
+					View("default", func() {
+						Attribute("id")
+						Attribute("rating")
+					})
+				})
+				Resource("bottle", func() {
+					BasePath("/bottles")
+					Action("show", func() {
+						Routing(GET("/:id"))
+						Params(defaultParams)
+						Response(OK, BottleMedia)
+					})
+					Action("create", func() {
+						Routing(POST(""))
+						Payload(defaultPayload)
+						Response(Created)
+					})
+				})
+			}
+		})
+
+		It("reports breaking changes", func() {
+			Ω(changes).Should(ConsistOf(
+				&gendiff.Change{Path: "application/vnd.goa.bottle view default.id", Breaking: true, Message: "is no longer required"},
+				&gendiff.Change{Path: "application/vnd.goa.bottle view default.name", Breaking: true, Message: "removed"},
+				&gendiff.Change{Path: "application/vnd.goa.bottle view default.rating", Breaking: false, Message: "added"},
+				&gendiff.Change{Path: "bottle#show response NotFound", Breaking: false, Message: "response removed"},
+			))
+		})
+	})
+
+	Context("with a changed attribute type", func() {
+		BeforeEach(func() {
+			head = bottleDesign(func() { Required("id") }, func() {
+				Param("id", String)
+				Param("color", String, func() {
+					Enum("red", "white")
+				})
+			}, defaultPayload)
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(ConsistOf(
+				&gendiff.Change{Path: "bottle#show param id", Breaking: true, Message: "type changed from integer to string"},
+			))
+		})
+	})
+
+	Context("with an idempotent action", func() {
+		idempotent := func() {
+			API("test", func() {})
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(defaultPayload)
+					Response(Created)
+					Idempotent()
+				})
+			})
+		}
+		notIdempotent := func() {
+			API("test", func() {})
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(defaultPayload)
+					Response(Created)
+				})
+			})
+		}
+
+		BeforeEach(func() {
+			base = notIdempotent
+			head = idempotent
+		})
+
+		It("reports a compatible change", func() {
+			Ω(changes).Should(ConsistOf(
+				&gendiff.Change{Path: "bottle#create", Breaking: false, Message: "action is now idempotent"},
+			))
+		})
+
+		Context("that is no longer idempotent", func() {
+			BeforeEach(func() {
+				base = idempotent
+				head = notIdempotent
+			})
+
+			It("reports a breaking change", func() {
+				Ω(changes).Should(ConsistOf(
+					&gendiff.Change{Path: "bottle#create", Breaking: true, Message: "action is no longer idempotent"},
+				))
+			})
+		})
+	})
+})
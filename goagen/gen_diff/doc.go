@@ -0,0 +1,12 @@
+/*
+Package gendiff provides a generator that detects breaking changes between two versions of an API
+design. The generator computes the contract of the API from the design, that is the resources,
+actions, routes, parameters, payloads, responses and media type views clients depend on, and
+compares it with the contract of a base design. Each change is classified as breaking or
+compatible, the generator returns an error listing the breaking changes if there is any so that
+goagen exits with a non-zero status code.
+
+The base contract is either computed from a base design package or loaded from a file written
+by a previous run of the generator.
+*/
+package gendiff
@@ -0,0 +1,173 @@
+package gendiff
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+// ContractFile is the name of the file containing the API contract written by the generator.
+const ContractFile = "contract.json"
+
+//NewGenerator returns an initialized instance of a diff Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the breaking change detector.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Base     *Contract             // Base contract the API is compared with
+	Format   string                // Report format, "text" or "json"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver, baseFile, format string
+	)
+
+	set := flag.NewFlagSet("diff", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&baseFile, "base", "", "")
+	set.StringVar(&format, "format", "text", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+	if baseFile == "" {
+		return nil, fmt.Errorf("missing base contract, use --base")
+	}
+	base, err := LoadContract(baseFile)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design, Base: base, Format: format}
+
+	return g.Generate()
+}
+
+// Snapshot is the meta generator entry point used to compute the contract of the base design.
+// It writes the contract of the API to the file ContractFile in the output directory.
+func Snapshot() (files []string, err error) {
+	var (
+		outDir, ver string
+	)
+
+	set := flag.NewFlagSet("diff", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+	if design.Design == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	path := filepath.Join(outDir, ContractFile)
+	if err := NewContract(design.Design).Write(path); err != nil {
+		return nil, err
+	}
+	return []string{path}, nil
+}
+
+// Generate compares the API contract with the base contract and writes the resulting report as
+// well as the API contract in the "diff" directory. Generate returns an error listing the
+// breaking changes if there is any.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	if g.Base == nil {
+		return nil, fmt.Errorf("missing base contract")
+	}
+	if g.Format == "" {
+		g.Format = "text"
+	}
+	if g.Format != "text" && g.Format != "json" {
+		return nil, fmt.Errorf(`invalid report format %#v, must be "text" or "json"`, g.Format)
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	head := NewContract(g.API)
+	changes := Compare(g.Base, head)
+
+	diffDir := filepath.Join(g.OutDir, "diff")
+	if err = os.MkdirAll(diffDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, diffDir)
+
+	contractFile := filepath.Join(diffDir, ContractFile)
+	if err = head.Write(contractFile); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, contractFile)
+
+	var (
+		report []byte
+		ext    = "txt"
+	)
+	if g.Format == "json" {
+		ext = "json"
+		if changes == nil {
+			changes = []*Change{}
+		}
+		if report, err = json.MarshalIndent(changes, "", "  "); err != nil {
+			return nil, err
+		}
+	} else {
+		report = []byte(formatChanges(changes) + "\n")
+	}
+	reportFile := filepath.Join(diffDir, "report."+ext)
+	if err = ioutil.WriteFile(reportFile, report, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, reportFile)
+
+	if breaking := Breaking(changes); len(breaking) > 0 {
+		// Keep the report around so it can be inspected.
+		files := g.genfiles
+		g.genfiles = nil
+		return files, fmt.Errorf("%d breaking change(s) detected:\n%s", len(breaking), formatChanges(breaking))
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,124 @@
+package gendiff_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	gendiff "github.com/goadesign/goa/goagen/gen_diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gendiff.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+		base   *gendiff.Contract
+		format string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		base:   &gendiff.Contract{API: "test api"},
+		format: "json",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gendiff.NewGenerator(
+				gendiff.API(args.api),
+				gendiff.OutDir(args.outDir),
+				gendiff.Base(args.base),
+				gendiff.Format(args.format),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Base).Should(Equal(args.base))
+			Ω(generator.Format).Should(Equal(args.format))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var outDir string
+	var base *gendiff.Contract
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "gendiff")
+		Ω(err).ShouldNot(HaveOccurred())
+		base = contract(func() {
+			API("test", func() {})
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Response(design.OK)
+				})
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		g := gendiff.NewGenerator(gendiff.API(design.Design), gendiff.OutDir(outDir), gendiff.Base(base))
+		files, genErr = g.Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	Context("with a compatible design", func() {
+		BeforeEach(func() {
+			contract(func() {
+				API("test", func() {})
+				Resource("bottle", func() {
+					Action("show", func() {
+						Routing(GET("/:id"), GET("/:id/details"))
+						Response(design.OK)
+					})
+				})
+			})
+		})
+
+		It("writes the report and the contract", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "diff", "report.txt")))
+			report, err := ioutil.ReadFile(filepath.Join(outDir, "diff", "report.txt"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(report)).Should(ContainSubstring("route GET /:id/details added"))
+
+			c, err := gendiff.LoadContract(filepath.Join(outDir, "diff", gendiff.ContractFile))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(gendiff.Compare(c, gendiff.NewContract(design.Design))).Should(BeEmpty())
+		})
+	})
+
+	Context("with a breaking design", func() {
+		BeforeEach(func() {
+			contract(func() {
+				API("test", func() {})
+				Resource("bottle", func() {})
+			})
+		})
+
+		It("returns an error listing the breaking changes", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring("1 breaking change(s) detected"))
+			Ω(genErr.Error()).Should(ContainSubstring("bottle#show: action removed"))
+			_, err := os.Stat(filepath.Join(outDir, "diff", "report.txt"))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,34 @@
+package gendiff
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Base Base contract the API definition is compared with
+func Base(base *Contract) Option {
+	return func(g *Generator) {
+		g.Base = base
+	}
+}
+
+//Format Format of the generated report, either "text" or "json"
+func Format(format string) Option {
+	return func(g *Generator) {
+		g.Format = format
+	}
+}
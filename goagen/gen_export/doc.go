@@ -0,0 +1,10 @@
+/*
+Package genexport provides a generator that serializes the finalized design of an API to a
+versioned JSON and YAML document. The document describes the resources, actions, routes, user
+types, media types, security schemes and metadata of the API after all the DSLs have run so that
+traits are resolved and inherited properties are set.
+
+Tools that need to consume the design without compiling Go code can load the document with
+design.LoadDesign.
+*/
+package genexport
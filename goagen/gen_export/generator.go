@@ -0,0 +1,117 @@
+package genexport
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a design export Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the design export generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver string
+	)
+
+	set := flag.NewFlagSet("export", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the design document files.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	exportDir := filepath.Join(g.OutDir, "export")
+	os.RemoveAll(exportDir)
+	if err = os.MkdirAll(exportDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, exportDir)
+
+	// JSON
+	rawJSON, err := design.ExportDesign(g.API)
+	if err != nil {
+		return nil, err
+	}
+	exportFile := filepath.Join(exportDir, "design.json")
+	if err := ioutil.WriteFile(exportFile, rawJSON, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, exportFile)
+
+	// YAML
+	rawYAML, err := jsonToYAML(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	exportFile = filepath.Join(exportDir, "design.yaml")
+	if err := ioutil.WriteFile(exportFile, rawYAML, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, exportFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+func jsonToYAML(rawJSON []byte) ([]byte, error) {
+	var yamlSource yaml.MapSlice
+	if err := yaml.Unmarshal(rawJSON, &yamlSource); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(yamlSource)
+}
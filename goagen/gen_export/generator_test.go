@@ -0,0 +1,100 @@
+package genexport_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	genexport "github.com/goadesign/goa/goagen/gen_export"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genexport.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genexport.NewGenerator(
+				genexport.API(args.api),
+				genexport.OutDir(args.outDir),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "genexport")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		API("test", func() {
+			Title("title")
+		})
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", design.Integer)
+				})
+				Response(design.NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		g := genexport.NewGenerator(genexport.API(design.Design), genexport.OutDir(outDir))
+		files, genErr = g.Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	It("writes the JSON and YAML documents", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		jsonFile := filepath.Join(outDir, "export", "design.json")
+		yamlFile := filepath.Join(outDir, "export", "design.yaml")
+		Ω(files).Should(ContainElement(jsonFile))
+		Ω(files).Should(ContainElement(yamlFile))
+
+		for _, f := range []string{jsonFile, yamlFile} {
+			api, err := design.LoadDesign(f)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(api.Name).Should(Equal("test"))
+			Ω(api.Resources).Should(HaveKey("bottle"))
+			Ω(api.Resources["bottle"].Actions).Should(HaveKey("show"))
+		}
+	})
+
+	It("writes a versioned document", func() {
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "export", "design.yaml"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("format: goa-design"))
+	})
+})
@@ -0,0 +1,25 @@
+/*
+Package gengrpc provides a generator for the gRPC services of a goa API.
+
+The generator produces a .proto file that describes one gRPC service per resource. Each action
+becomes a RPC whose request message contains the action parameters and payload and whose reply
+message is the media type of the first successful response rendered with the response view. User
+types and media types are mapped to protobuf messages, media types produce one message per view.
+Actions that stream data (websocket or server-sent events) are not exposed via gRPC.
+
+Protobuf field numbers are assigned in alphabetical order of the attribute names by default. Use
+the "struct:field:proto" metadata to assign stable numbers explicitly:
+
+	Attribute("name", String, func() {
+		Metadata("struct:field:proto", "2")
+	})
+
+The generator also produces, for each service, a Go type that implements the server interface
+generated by protoc by running the actions of the resource controller. The same controller
+implementation can thus serve both HTTP and gRPC requests. The Go code for the messages and the
+service interfaces must be generated from the .proto file with protoc, for example:
+
+	protoc --go_out=. --go_opt=paths=source_relative \
+		--go-grpc_out=. --go-grpc_opt=paths=source_relative grpc/pb/*.proto
+*/
+package gengrpc
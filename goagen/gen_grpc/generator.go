@@ -0,0 +1,286 @@
+package gengrpc
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+	"github.com/goadesign/goa/version"
+)
+
+//NewGenerator returns an initialized instance of a gRPC Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{AppPkg: "app", Target: "grpc"}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the gRPC code generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated package
+	AppPkg   string                // Name or import path of generated "app" package
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, target, appPkg, ver string
+	)
+
+	set := flag.NewFlagSet("grpc", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "grpc", "")
+	set.StringVar(&appPkg, "app-pkg", "app", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	target = codegen.Goify(target, false)
+	g := &Generator{OutDir: outDir, Target: target, AppPkg: appPkg, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the .proto file describing the API gRPC services and the Go code that
+// implements these services using the application controllers.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	outDir := filepath.Join(g.OutDir, g.Target)
+	pbDir := filepath.Join(outDir, "pb")
+	if err = os.MkdirAll(pbDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, outDir)
+
+	imp, err := codegen.PackagePath(outDir)
+	if err != nil {
+		return nil, err
+	}
+	imp = filepath.ToSlash(imp)
+	pbImp := path.Join(imp, "pb")
+
+	f, err := buildProto(g.API, protoPackage(g.API.Name), pbImp+";pb")
+	if err != nil {
+		return nil, err
+	}
+	if err = g.generateProto(f, filepath.Join(pbDir, codegen.SnakeCase(g.API.Name)+".proto")); err != nil {
+		return nil, err
+	}
+
+	appImp := g.AppPkg
+	if _, err := codegen.PackageSourcePath(g.AppPkg); err != nil {
+		appImp, err = codegen.PackagePath(g.OutDir)
+		if err != nil {
+			return nil, err
+		}
+		appImp = path.Join(filepath.ToSlash(appImp), g.AppPkg)
+	}
+	for _, svc := range f.Services {
+		filename := filepath.Join(outDir, codegen.SnakeCase(svc.Resource.Name)+".go")
+		if err = g.generateServer(svc, filename, appImp, pbImp); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// generateProto writes the .proto file.
+func (g *Generator) generateProto(f *protoFile, filename string) error {
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"ToolVersion": version.String(),
+		"Title":       fmt.Sprintf("API %q: gRPC service definitions", g.API.Name),
+		"File":        f,
+	}
+	if err := protoTmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to generate protobuf definitions: %s", err)
+	}
+	if err := ioutil.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, filename)
+	return nil
+}
+
+// generateServer writes the Go code implementing the gRPC service using the resource controller.
+func (g *Generator) generateServer(svc *protoService, filename, appImp, pbImp string) (err error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	g.genfiles = append(g.genfiles, filename)
+
+	elems := strings.Split(appImp, "/")
+	appPkg := elems[len(elems)-1]
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("goagrpc", "github.com/goadesign/goa/grpc"),
+		codegen.SimpleImport(appImp),
+		codegen.SimpleImport(pbImp),
+		codegen.SimpleImport("google.golang.org/protobuf/types/known/emptypb"),
+	}
+	title := fmt.Sprintf("API %q: %s gRPC server", g.API.Name, svc.Resource.Name)
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	data := map[string]interface{}{
+		"Service": svc,
+		"AppPkg":  appPkg,
+	}
+	funcs := template.FuncMap{
+		"goify":         codegen.Goify,
+		"gotypename":    codegen.GoTypeName,
+		"requestParams": requestParams,
+	}
+	return file.ExecuteTemplate("server", serverT, funcs, data)
+}
+
+// protoPackage computes the protobuf package name from the API name.
+func protoPackage(name string) string {
+	name = codegen.SnakeCase(codegen.Goify(name, false))
+	if name == "" {
+		return "api"
+	}
+	return name
+}
+
+// requestParams returns the fields of the request message that correspond to action parameters.
+func requestParams(rpc *protoRPC) []*protoField {
+	var params []*protoField
+	for _, f := range rpc.Request.Fields {
+		if rpc.Action.Payload != nil && f.Name == "payload" {
+			continue
+		}
+		params = append(params, f)
+	}
+	return params
+}
+
+var protoTmpl = template.Must(template.New("proto").Funcs(template.FuncMap{
+	"comment":      codegen.Comment,
+	"commandLine":  codegen.CommandLine,
+	"protoComment": protoComment,
+}).Parse(protoT))
+
+const protoT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+//
+// Command:
+{{ comment commandLine }}
+
+syntax = "proto3";
+
+package {{ .File.Package }};
+
+option go_package = "{{ .File.GoPackage }}";
+{{ range .File.Imports }}
+import "{{ . }}";
+{{ end }}{{ range .File.Services }}
+{{ protoComment .Description "" }}service {{ .Name }} {
+{{ range .RPCs }}{{ protoComment .Action.Description "\t" }}	rpc {{ .Name }}({{ .Request.Name }}) returns ({{ if .Reply }}{{ .Reply }}{{ else }}google.protobuf.Empty{{ end }});
+{{ end }}}
+{{ end }}{{ range .File.Messages }}
+{{ protoComment .Description "" }}message {{ .Name }} {
+{{ range .Unsupported }}	// {{ . }}
+{{ end }}{{ range .Fields }}{{ protoComment .Description "\t" }}	{{ if .Repeated }}repeated {{ else if .Optional }}optional {{ end }}{{ .Type }} {{ .Name }} = {{ .Number }};
+{{ end }}}
+{{ end }}`
+
+const serverT = `{{ $svc := .Service }}{{ $res := goify $svc.Resource.Name true }}// {{ $svc.Name }}Server implements the {{ $svc.Name }} gRPC service by running the actions of the
+// {{ $svc.Resource.Name }} controller. The service middleware is not run, use gRPC interceptors instead.
+type {{ $svc.Name }}Server struct {
+	pb.Unimplemented{{ $svc.Name }}Server
+	service *goa.Service
+	ctrl    {{ .AppPkg }}.{{ $res }}Controller
+}
+
+// New{{ $svc.Name }}Server returns a {{ $svc.Name }} gRPC service implementation that uses ctrl to
+// handle requests.
+func New{{ $svc.Name }}Server(service *goa.Service, ctrl {{ .AppPkg }}.{{ $res }}Controller) *{{ $svc.Name }}Server {
+	return &{{ $svc.Name }}Server{service: service, ctrl: ctrl}
+}
+{{ range $svc.RPCs }}{{ $action := goify .Action.Name true }}{{ $route := index .Action.Routes 0 }}
+// {{ .Name }} runs the {{ .Action.Name }} action of the {{ $svc.Resource.Name }} controller.
+func (s *{{ $svc.Name }}Server) {{ .Name }}(ctx context.Context, req *pb.{{ .Request.Name }}) ({{ if .Reply }}*pb.{{ .Reply }}{{ else }}*emptypb.Empty{{ end }}, error) {
+	params := url.Values{}
+{{ range requestParams . }}	goagrpc.SetParam(params, {{ printf "%q" .Name }}, req.{{ .GoName }})
+{{ end }}	ctx, rec := goagrpc.NewContext(ctx, s.service, {{ printf "%q" .Action.Name }}, {{ printf "%q" $route.Verb }}, {{ printf "%q" $route.FullPath }}, params)
+	rctx, err := {{ $.AppPkg }}.New{{ $action }}{{ $res }}Context(ctx, goa.ContextRequest(ctx).Request, s.service)
+	if err != nil {
+		return nil, goagrpc.EncodeError(err)
+	}
+{{ if .Action.Payload }}{{ $ptr := "" }}{{ if .Action.Payload.IsObject }}{{ $ptr = "&" }}{{ end }}	if req.Payload != nil {
+		var payload {{ $.AppPkg }}.{{ gotypename .Action.Payload nil 0 false }}
+		if err := goagrpc.Convert(req.Payload, &payload); err != nil {
+			return nil, goagrpc.EncodeError(goa.ErrBadRequest(err))
+		}
+		if err := goagrpc.Validate({{ $ptr }}payload); err != nil {
+			return nil, goagrpc.EncodeError(err)
+		}
+		rctx.Payload = {{ $ptr }}payload
+{{ if not .Action.PayloadOptional }}	} else {
+		return nil, goagrpc.EncodeError(goa.MissingPayloadError())
+{{ end }}	}
+{{ end }}	if err := s.ctrl.{{ $action }}(rctx); err != nil {
+		return nil, goagrpc.EncodeError(err)
+	}
+{{ if .Reply }}	reply := &pb.{{ .Reply }}{}
+	if err := rec.Decode({{ if .Collection }}&reply.Items{{ else }}reply{{ end }}); err != nil {
+		return nil, err
+	}
+	return reply, nil
+{{ else }}	if err := rec.Decode(nil); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+{{ end }}}
+{{ end }}`
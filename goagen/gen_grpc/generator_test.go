@@ -0,0 +1,226 @@
+package gengrpc_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	gengrpc "github.com/goadesign/goa/goagen/gen_grpc"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gengrpc.Generator
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gengrpc.NewGenerator(
+				gengrpc.API(&APIDefinition{Name: "test api"}),
+				gengrpc.OutDir("out_dir"),
+				gengrpc.Target("rpc"),
+				gengrpc.AppPkg("example.com/app"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal("test api"))
+			Ω(generator.OutDir).Should(Equal("out_dir"))
+			Ω(generator.Target).Should(Equal("rpc"))
+			Ω(generator.AppPkg).Should(Equal("example.com/app"))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = gengrpc.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a bottle API", func() {
+		BeforeEach(func() {
+			var BottlePayload = Type("BottlePayload", func() {
+				Attribute("name", String, func() {
+					Metadata("struct:field:proto", "3")
+				})
+				Attribute("vintage", Integer)
+				Attribute("tags", ArrayOf(String))
+				Required("name")
+			})
+			var Bottle = MediaType("application/vnd.bottle+json", func() {
+				Attributes(func() {
+					Attribute("id", Integer, "ID of bottle")
+					Attribute("name", String)
+					Attribute("ratings", HashOf(String, Integer))
+					Attribute("metadata", Any)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+					Attribute("ratings")
+					Attribute("metadata")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			API("cellar", nil)
+			Resource("bottle", func() {
+				Description("Bottle resource")
+				BasePath("/bottles")
+				Action("show", func() {
+					Description("Show a bottle")
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Response(OK, Bottle)
+				})
+				Action("list", func() {
+					Routing(GET(""))
+					Params(func() {
+						Param("tag", ArrayOf(String))
+					})
+					Response(OK, CollectionOf(Bottle), func() {
+						Media(CollectionOf(Bottle), "tiny")
+					})
+				})
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(BottlePayload)
+					Response(Created)
+				})
+				Action("watch", func() {
+					Routing(GET("/ws"))
+					Scheme("ws")
+					Response(SwitchingProtocols)
+				})
+			})
+		})
+
+		It("generates the protobuf definitions", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			protoFile := filepath.Join(outDir, "grpc", "pb", "cellar.proto")
+			Ω(files).Should(ContainElement(protoFile))
+			content, err := ioutil.ReadFile(protoFile)
+			Ω(err).ShouldNot(HaveOccurred())
+			proto := string(content)
+			Ω(proto).Should(ContainSubstring(`syntax = "proto3";`))
+			Ω(proto).Should(ContainSubstring("package cellar;"))
+			Ω(proto).Should(ContainSubstring(`option go_package = "`))
+			Ω(proto).Should(ContainSubstring(`import "google/protobuf/empty.proto";`))
+			Ω(proto).Should(ContainSubstring(`// Bottle resource
+service Bottle {
+	rpc Create(CreateBottleRequest) returns (google.protobuf.Empty);
+	rpc List(ListBottleRequest) returns (BottleTinyCollection);
+	// Show a bottle
+	rpc Show(ShowBottleRequest) returns (Bottle);
+}`))
+			Ω(proto).ShouldNot(ContainSubstring("rpc Watch"))
+			Ω(proto).Should(ContainSubstring(`message CreateBottleRequest {
+	BottlePayload payload = 1;
+}`))
+			Ω(proto).Should(ContainSubstring(`message BottlePayload {
+	repeated string tags = 1;
+	optional int64 vintage = 2;
+	string name = 3;
+}`))
+			Ω(proto).Should(ContainSubstring(`message ListBottleRequest {
+	repeated string tag = 1;
+}`))
+			Ω(proto).Should(ContainSubstring(`message BottleTinyCollection {
+	repeated BottleTiny items = 1;
+}`))
+			Ω(proto).Should(ContainSubstring(`message BottleTiny {
+	// ID of bottle
+	optional int64 id = 1;
+}`))
+			Ω(proto).Should(ContainSubstring(`message Bottle {
+	// attribute metadata of type any is not supported
+	// ID of bottle
+	optional int64 id = 1;
+	optional string name = 2;
+	map<string, int64> ratings = 3;
+}`))
+		})
+
+		It("generates the servers", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			serverFile := filepath.Join(outDir, "grpc", "bottle.go")
+			Ω(files).Should(ContainElement(serverFile))
+			content, err := ioutil.ReadFile(serverFile)
+			Ω(err).ShouldNot(HaveOccurred())
+			server := string(content)
+			Ω(server).Should(ContainSubstring("package grpc"))
+			Ω(server).Should(ContainSubstring(`goagrpc "github.com/goadesign/goa/grpc"`))
+			Ω(server).Should(ContainSubstring(`type BottleServer struct {
+	pb.UnimplementedBottleServer
+	service *goa.Service
+	ctrl    app.BottleController
+}`))
+			Ω(server).Should(ContainSubstring("func NewBottleServer(service *goa.Service, ctrl app.BottleController) *BottleServer {"))
+			Ω(server).Should(ContainSubstring("func (s *BottleServer) Show(ctx context.Context, req *pb.ShowBottleRequest) (*pb.Bottle, error) {"))
+			Ω(server).Should(ContainSubstring(`goagrpc.SetParam(params, "id", req.Id)`))
+			Ω(server).Should(ContainSubstring(`goagrpc.NewContext(ctx, s.service, "show", "GET", "/bottles/:id", params)`))
+			Ω(server).Should(ContainSubstring("app.NewShowBottleContext(ctx, goa.ContextRequest(ctx).Request, s.service)"))
+			Ω(server).Should(ContainSubstring("rec.Decode(&reply.Items)"))
+			Ω(server).Should(ContainSubstring("func (s *BottleServer) Create(ctx context.Context, req *pb.CreateBottleRequest) (*emptypb.Empty, error) {"))
+			Ω(server).Should(ContainSubstring("var payload app.BottlePayload"))
+			Ω(server).Should(ContainSubstring("rctx.Payload = &payload"))
+			Ω(server).Should(ContainSubstring("goa.MissingPayloadError()"))
+		})
+	})
+
+	Context("with conflicting field numbers", func() {
+		BeforeEach(func() {
+			var NumberedPayload = Type("NumberedPayload", func() {
+				Attribute("a", String, func() {
+					Metadata("struct:field:proto", "1")
+				})
+				Attribute("b", String, func() {
+					Metadata("struct:field:proto", "1")
+				})
+			})
+			API("test", nil)
+			Resource("res", func() {
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(NumberedPayload)
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring("use the same protobuf field number 1"))
+		})
+	})
+})
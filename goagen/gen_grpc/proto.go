@@ -0,0 +1,436 @@
+package gengrpc
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// FieldNumberMetadata is the name of the attribute metadata used to set the number of the
+// corresponding protobuf field. Setting explicit numbers guarantees that fields keep the same
+// number when attributes are added or removed from the design:
+//
+//	Attribute("name", String, func() {
+//		Metadata("struct:field:proto", "2")
+//	})
+//
+// Attributes with no explicit number are numbered in alphabetical order skipping explicit numbers.
+const FieldNumberMetadata = "struct:field:proto"
+
+// emptyProto is the protobuf well known type used by RPCs with no reply.
+const emptyProto = "google/protobuf/empty.proto"
+
+type (
+	// protoFile describes the content of the generated .proto file.
+	protoFile struct {
+		Package   string
+		GoPackage string
+		Imports   []string
+		Services  []*protoService
+		Messages  []*protoMessage
+	}
+
+	// protoService describes a gRPC service generated from a resource.
+	protoService struct {
+		Name        string
+		Description string
+		Resource    *design.ResourceDefinition
+		RPCs        []*protoRPC
+	}
+
+	// protoRPC describes a RPC generated from an action.
+	protoRPC struct {
+		Name    string
+		Action  *design.ActionDefinition
+		Request *protoMessage
+		// Reply is the name of the reply message, empty if the RPC returns
+		// google.protobuf.Empty.
+		Reply string
+		// Collection is true if the reply wraps a collection media type.
+		Collection bool
+	}
+
+	// protoMessage describes a protobuf message.
+	protoMessage struct {
+		Name        string
+		Description string
+		Fields      []*protoField
+		// Unsupported lists the attributes that cannot be represented in protobuf.
+		Unsupported []string
+		// key identifies the design type the message is generated from.
+		key interface{}
+	}
+
+	// protoField describes a protobuf message field.
+	protoField struct {
+		Name        string
+		GoName      string
+		Description string
+		Type        string
+		Number      int
+		Repeated    bool
+		Optional    bool
+	}
+
+	// protoBuilder builds the protobuf description of an API design.
+	protoBuilder struct {
+		messages []*protoMessage
+		byName   map[string]*protoMessage
+		imports  map[string]bool
+	}
+)
+
+// validFieldName matches the attribute names that are valid protobuf field names. The generated
+// servers rely on the attribute and field names being identical.
+var validFieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// buildProto computes the protobuf services and messages corresponding to the API design.
+func buildProto(api *design.APIDefinition, pkg, goPkg string) (*protoFile, error) {
+	b := &protoBuilder{byName: make(map[string]*protoMessage), imports: make(map[string]bool)}
+	f := &protoFile{Package: pkg, GoPackage: goPkg}
+	err := api.IterateResources(func(r *design.ResourceDefinition) error {
+		svc := &protoService{
+			Name:        codegen.Goify(r.Name, true),
+			Description: r.Description,
+			Resource:    r,
+		}
+		err := r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() || a.EventStream() != nil || len(a.Routes) == 0 {
+				return nil // Streaming actions are not supported
+			}
+			rpc, err := b.rpc(a)
+			if err != nil {
+				return fmt.Errorf("action %s of resource %s: %s", a.Name, r.Name, err)
+			}
+			svc.RPCs = append(svc.RPCs, rpc)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(svc.RPCs) > 0 {
+			f.Services = append(f.Services, svc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	f.Messages = b.messages
+	for imp := range b.imports {
+		f.Imports = append(f.Imports, imp)
+	}
+	sort.Strings(f.Imports)
+	return f, nil
+}
+
+// rpc builds the RPC corresponding to the given action.
+func (b *protoBuilder) rpc(a *design.ActionDefinition) (*protoRPC, error) {
+	name := codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true)
+	obj := make(design.Object)
+	var required []string
+	if params := a.AllParams(); params != nil {
+		for n, att := range params.Type.ToObject() {
+			obj[n] = att
+		}
+		if params.Validation != nil {
+			required = params.Validation.Required
+		}
+	}
+	if a.Payload != nil {
+		if _, ok := obj["payload"]; ok {
+			return nil, fmt.Errorf(`parameter name "payload" conflicts with the request payload field`)
+		}
+		obj["payload"] = &design.AttributeDefinition{Type: a.Payload, Description: a.Payload.Description}
+	}
+	reqAtt := &design.AttributeDefinition{
+		Type:       obj,
+		Validation: &dslengine.ValidationDefinition{Required: required},
+	}
+	req, err := b.message(name+"Request", "", reqAtt, reqAtt)
+	if err != nil {
+		return nil, err
+	}
+	rpc := &protoRPC{Name: codegen.Goify(a.Name, true), Action: a, Request: req}
+	if rpc.Reply, rpc.Collection, err = b.reply(a); err != nil {
+		return nil, err
+	}
+	if rpc.Reply == "" {
+		b.imports[emptyProto] = true
+	}
+	return rpc, nil
+}
+
+// reply returns the name of the message describing the body of the first successful action
+// response. It returns an empty string if the response has no body.
+func (b *protoBuilder) reply(a *design.ActionDefinition) (string, bool, error) {
+	var resp *design.ResponseDefinition
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		if resp == nil && r.Status >= 200 && r.Status < 300 {
+			resp = r
+		}
+		return nil
+	})
+	if resp == nil {
+		return "", false, nil
+	}
+	var dt design.DataType
+	if resp.Type != nil {
+		dt = resp.Type
+	} else if mt := design.Design.MediaTypeWithIdentifier(resp.MediaType); mt != nil {
+		dt = mt
+	}
+	if mt, ok := dt.(*design.MediaTypeDefinition); ok {
+		view := resp.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return "", false, err
+		}
+		dt = p
+		if p.IsArray() {
+			elem, _, err := b.fieldType(p.TypeName, "items", p.ToArray().ElemType)
+			if err != nil || elem == "" {
+				return "", false, err
+			}
+			if _, ok := b.byName[p.TypeName]; !ok {
+				m := &protoMessage{
+					Name:        p.TypeName,
+					Description: p.Description,
+					Fields:      []*protoField{{Name: "items", GoName: "Items", Type: elem, Number: 1, Repeated: true}},
+					key:         p,
+				}
+				b.byName[m.Name] = m
+				b.messages = append(b.messages, m)
+			}
+			return p.TypeName, true, nil
+		}
+	}
+	if dt == nil || !dt.IsObject() {
+		return "", false, nil
+	}
+	typ, _, err := b.fieldType(codegen.Goify(a.Name, true)+codegen.Goify(a.Parent.Name, true), "reply", &design.AttributeDefinition{Type: dt})
+	return typ, false, err
+}
+
+// message returns the message describing the given object attribute, creating it if needed.
+func (b *protoBuilder) message(name, desc string, key interface{}, att *design.AttributeDefinition) (*protoMessage, error) {
+	if m, ok := b.byName[name]; ok {
+		if m.key != key {
+			return nil, fmt.Errorf("protobuf message name %s is used by multiple types", name)
+		}
+		return m, nil
+	}
+	m := &protoMessage{Name: name, Description: desc, key: key}
+	b.byName[name] = m
+	b.messages = append(b.messages, m)
+
+	obj := att.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	used := make(map[int]string)
+	explicit := make(map[string]int)
+	for _, n := range names {
+		num, ok, err := fieldNumber(obj[n])
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s of %s: %s", n, name, err)
+		}
+		if !ok {
+			continue
+		}
+		if other, ok := used[num]; ok {
+			return nil, fmt.Errorf("attributes %s and %s of %s use the same protobuf field number %d", other, n, name, num)
+		}
+		used[num] = n
+		explicit[n] = num
+	}
+	next := 1
+	for _, n := range names {
+		if !validFieldName.MatchString(n) {
+			return nil, fmt.Errorf("attribute name %q of %s is not a valid protobuf field name", n, name)
+		}
+		at := obj[n]
+		typ, repeated, err := b.fieldType(name, n, at)
+		if err != nil {
+			return nil, err
+		}
+		if typ == "" {
+			m.Unsupported = append(m.Unsupported, fmt.Sprintf("attribute %s of type %s is not supported", n, at.Type.Name()))
+			continue
+		}
+		num, ok := explicit[n]
+		if !ok {
+			for used[next] != "" || (next >= 19000 && next <= 19999) {
+				next++
+			}
+			num = next
+			used[num] = n
+		}
+		m.Fields = append(m.Fields, &protoField{
+			Name:        n,
+			GoName:      goCamelCase(n),
+			Description: at.Description,
+			Type:        typ,
+			Number:      num,
+			Repeated:    repeated,
+			Optional:    !repeated && at.Type.IsPrimitive() && !att.IsRequired(n),
+		})
+	}
+	sort.Slice(m.Fields, func(i, j int) bool { return m.Fields[i].Number < m.Fields[j].Number })
+	return m, nil
+}
+
+// fieldType returns the protobuf type of the field generated for the attribute with the given
+// name. parent is the name of the message containing the field and is used to name the messages
+// generated for inline objects. fieldType returns an empty string if the attribute type cannot be
+// represented in protobuf.
+func (b *protoBuilder) fieldType(parent, name string, att *design.AttributeDefinition) (string, bool, error) {
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		return primitiveType(actual), false, nil
+	case *design.MediaTypeDefinition:
+		view := att.View
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := actual.Project(view)
+		if err != nil {
+			return "", false, err
+		}
+		if !p.IsObject() {
+			return b.fieldType(parent, name, &design.AttributeDefinition{Type: p.Type})
+		}
+		m, err := b.message(p.TypeName, p.Description, p, p.AttributeDefinition)
+		if err != nil {
+			return "", false, err
+		}
+		return m.Name, false, nil
+	case *design.UserTypeDefinition:
+		if !actual.IsObject() {
+			return b.fieldType(parent, name, actual.AttributeDefinition)
+		}
+		m, err := b.message(codegen.Goify(actual.TypeName, true), actual.Description, actual, actual.AttributeDefinition)
+		if err != nil {
+			return "", false, err
+		}
+		return m.Name, false, nil
+	case design.Object:
+		m, err := b.message(parent+codegen.Goify(name, true), att.Description, att, att)
+		if err != nil {
+			return "", false, err
+		}
+		return m.Name, false, nil
+	case *design.Array:
+		elem, repeated, err := b.fieldType(parent, name, actual.ElemType)
+		if err != nil || repeated || isMap(elem) {
+			return "", false, err
+		}
+		return elem, elem != "", nil
+	case *design.Hash:
+		key, ok := actual.KeyType.Type.(design.Primitive)
+		if !ok {
+			return "", false, nil
+		}
+		switch key.Kind() {
+		case design.StringKind, design.IntegerKind, design.BooleanKind:
+		default:
+			return "", false, nil
+		}
+		elem, repeated, err := b.fieldType(parent, name, actual.ElemType)
+		if err != nil || elem == "" || repeated || isMap(elem) {
+			return "", false, err
+		}
+		return fmt.Sprintf("map<%s, %s>", primitiveType(key), elem), false, nil
+	}
+	return "", false, nil
+}
+
+// primitiveType returns the protobuf scalar type corresponding to the given primitive, an empty
+// string if there isn't one.
+func primitiveType(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "bool"
+	case design.IntegerKind:
+		return "int64"
+	case design.NumberKind:
+		return "double"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind:
+		return "string"
+	}
+	return ""
+}
+
+// fieldNumber returns the field number set in the attribute metadata if any.
+func fieldNumber(att *design.AttributeDefinition) (int, bool, error) {
+	vals, ok := att.Metadata[FieldNumberMetadata]
+	if !ok || len(vals) == 0 {
+		return 0, false, nil
+	}
+	num, err := strconv.Atoi(vals[0])
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid %s metadata value %q", FieldNumberMetadata, vals[0])
+	}
+	if num < 1 || num > 536870911 || (num >= 19000 && num <= 19999) {
+		return 0, false, fmt.Errorf("invalid protobuf field number %d", num)
+	}
+	return num, true, nil
+}
+
+func isMap(typ string) bool {
+	return len(typ) > 4 && typ[:4] == "map<"
+}
+
+// goCamelCase returns the name of the Go struct field generated by protoc-gen-go for the protobuf
+// field with the given name.
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' && i == 0:
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// Skip over '_' in "_{{lowercase}}"
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool { return 'a' <= c && c <= 'z' }
+
+func isASCIIDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// protoComment returns the protobuf comment lines for the given text prefixed with indent, an
+// empty string if text is empty.
+func protoComment(text, indent string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(indent+"// "+strings.TrimSpace(l), " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -6,5 +6,13 @@ axios (https://github.com/axios/axios) javascript library to perform the actual
 The generator also produces an example controller and index HTML that shows how to use the module.
 The controller simply serves all the files under the "js" directory so that loading "/js" in a
 browser triggers the example code.
+
+When invoked with --ts the generator produces instead a TypeScript module in the "ts" directory.
+The module declares interfaces for all the user types and media type views, union types for the
+attributes that define an Enum validation and a Client class with one typed method per action.
+Methods accept the action path, query string and header parameters as a single object and reject
+with a HTTPError whose response describes the error responses declared in the design. Requests are
+sent with fetch by default, the ClientOptions fetch field makes it possible to use any other
+transport.
 */
 package genjs
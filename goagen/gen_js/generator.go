@@ -14,6 +14,7 @@ import (
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/utils"
+	"github.com/goadesign/goa/version"
 )
 
 //NewGenerator returns an initialized instance of a JavaScript Client Generator
@@ -29,13 +30,14 @@ func NewGenerator(options ...Option) *Generator {
 
 // Generator is the application code generator.
 type Generator struct {
-	API       *design.APIDefinition // The API definition
-	OutDir    string                // Destination directory
-	Timeout   time.Duration         // Timeout used by JavaScript client when making requests
-	Scheme    string                // Scheme used by JavaScript client
-	Host      string                // Host addressed by JavaScript client
-	NoExample bool                  // Do not generate an HTML example file
-	genfiles  []string              // Generated files
+	API        *design.APIDefinition // The API definition
+	OutDir     string                // Destination directory
+	Timeout    time.Duration         // Timeout used by JavaScript client when making requests
+	Scheme     string                // Scheme used by JavaScript client
+	Host       string                // Host addressed by JavaScript client
+	NoExample  bool                  // Do not generate an HTML example file
+	TypeScript bool                  // Generate a typed TypeScript client instead of the JavaScript module
+	genfiles   []string              // Generated files
 }
 
 // Generate is the generator entry point called by the meta generator.
@@ -45,6 +47,7 @@ func Generate() (files []string, err error) {
 		timeout      time.Duration
 		scheme, host string
 		noexample    bool
+		ts           bool
 	)
 
 	set := flag.NewFlagSet("client", flag.PanicOnError)
@@ -55,6 +58,7 @@ func Generate() (files []string, err error) {
 	set.StringVar(&host, "host", "", "")
 	set.StringVar(&ver, "version", "", "")
 	set.BoolVar(&noexample, "noexample", false, "")
+	set.BoolVar(&ts, "ts", false, "")
 	set.Parse(os.Args[1:])
 
 	// First check compatibility
@@ -63,7 +67,7 @@ func Generate() (files []string, err error) {
 	}
 
 	// Now proceed
-	g := &Generator{OutDir: outDir, Timeout: timeout, Scheme: scheme, Host: host, NoExample: noexample, API: design.Design}
+	g := &Generator{OutDir: outDir, Timeout: timeout, Scheme: scheme, Host: host, NoExample: noexample, TypeScript: ts, API: design.Design}
 
 	return g.Generate()
 }
@@ -94,6 +98,9 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if g.Host == "" {
 		g.Host = g.API.Host
 	}
+	if g.TypeScript {
+		return g.generateTypeScript()
+	}
 	if g.Host == "" {
 		return nil, fmt.Errorf("missing host value, set it with --host")
 	}
@@ -271,6 +278,57 @@ func (g *Generator) generateExample() error {
 	return file.ExecuteTemplate("examples", exampleCtrlT, nil, data)
 }
 
+// generateTypeScript produces the TypeScript client module in the "ts" directory.
+func (g *Generator) generateTypeScript() (_ []string, err error) {
+	decls, actions, err := buildTypeScript(g.API)
+	if err != nil {
+		return nil, err
+	}
+
+	outDir := filepath.Join(g.OutDir, "ts")
+	if err = os.RemoveAll(outDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(outDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, outDir)
+
+	tsFile := filepath.Join(outDir, "client.ts")
+	file, err := codegen.SourceFileFor(tsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	g.genfiles = append(g.genfiles, tsFile)
+
+	funcs := template.FuncMap{
+		"tsComment": tsComment,
+		"methodDoc": tsMethodDoc,
+	}
+	data := map[string]interface{}{
+		"API":         g.API,
+		"ToolVersion": version.String(),
+		"Host":        g.Host,
+		"Scheme":      g.Scheme,
+		"Timeout":     int64(g.Timeout / time.Millisecond),
+		"Actions":     actions,
+	}
+	if err = file.ExecuteTemplate("tsModule", tsModuleT, funcs, data); err != nil {
+		return nil, err
+	}
+	for _, d := range decls {
+		if err = file.ExecuteTemplate("tsDecl", tsDeclT, funcs, d); err != nil {
+			return nil, err
+		}
+	}
+	if err = file.ExecuteTemplate("tsClient", tsClientT, funcs, data); err != nil {
+		return nil, err
+	}
+
+	return g.genfiles, nil
+}
+
 // Cleanup removes all the files generated by this generator during the last invokation of Generate.
 func (g *Generator) Cleanup() {
 	for _, f := range g.genfiles {
@@ -111,6 +111,7 @@ var _ = Describe("NewGenerator", func() {
 		scheme    string
 		host      string
 		noExample bool
+		ts        bool
 	}{
 		api: &design.APIDefinition{
 			Name: "test api",
@@ -120,6 +121,7 @@ var _ = Describe("NewGenerator", func() {
 		scheme:    "http",
 		host:      "localhost",
 		noExample: true,
+		ts:        true,
 	}
 
 	Context("with options all options set", func() {
@@ -132,6 +134,7 @@ var _ = Describe("NewGenerator", func() {
 				genjs.Scheme(args.scheme),
 				genjs.Host(args.host),
 				genjs.NoExample(args.noExample),
+				genjs.TypeScript(args.ts),
 			)
 		})
 
@@ -143,6 +146,7 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator.Scheme).Should(Equal(args.scheme))
 			Ω(generator.Host).Should(Equal(args.host))
 			Ω(generator.NoExample).Should(Equal(args.noExample))
+			Ω(generator.TypeScript).Should(Equal(args.ts))
 		})
 
 	})
@@ -47,3 +47,10 @@ func NoExample(noExample bool) Option {
 		g.NoExample = noExample
 	}
 }
+
+//TypeScript Generate a typed TypeScript client instead of the JavaScript module
+func TypeScript(ts bool) Option {
+	return func(g *Generator) {
+		g.TypeScript = ts
+	}
+}
@@ -0,0 +1,653 @@
+package genjs
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+type (
+	// tsDecl is a TypeScript type declaration, either an interface or a type alias.
+	tsDecl struct {
+		Name        string
+		Description string
+		// Fields lists the interface fields, nil for type aliases.
+		Fields []*tsField
+		// Type is the aliased type, empty for interfaces.
+		Type string
+		// key identifies the design type the declaration is generated from.
+		key interface{}
+	}
+
+	// tsField is a TypeScript interface field.
+	tsField struct {
+		Name        string
+		Description string
+		Type        string
+		Optional    bool
+	}
+
+	// tsAction describes the client method generated for an action.
+	tsAction struct {
+		Name   string
+		Action *design.ActionDefinition
+		Verb   string
+		// Path is the TypeScript template literal that builds the request path.
+		Path string
+		// Params is the interface describing the action parameters and headers, nil if the action
+		// has none.
+		Params *tsDecl
+		// ParamsOptional is true if none of the parameters are required.
+		ParamsOptional bool
+		Query          []*tsParam
+		Headers        []*tsParam
+		// Payload is the type of the request body, empty if the action has no payload.
+		Payload string
+		// Result is the type of the response body.
+		Result string
+		// Errors is the type describing the error responses declared in the design, nil if there
+		// are none.
+		Errors *tsDecl
+	}
+
+	// tsParam is a query string or header parameter.
+	tsParam struct {
+		Name string
+		// Ref is the expression that reads the parameter value.
+		Ref string
+	}
+
+	// tsBuilder computes the TypeScript declarations for the API types and actions.
+	tsBuilder struct {
+		decls  []*tsDecl
+		byName map[string]*tsDecl
+	}
+)
+
+var (
+	// tsIdentifier matches valid TypeScript identifiers.
+	tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+	// tsReserved lists the names used by the generated client or by TypeScript global types that
+	// may not be used as declaration names.
+	tsReserved = map[string]bool{
+		"Array": true, "Boolean": true, "Client": true, "ClientOptions": true, "Date": true,
+		"Error": true, "ErrorResult": true, "Fetch": true, "HTTPError": true, "Map": true,
+		"Number": true, "Object": true, "Promise": true, "Record": true, "Request": true,
+		"RequestOptions": true, "Response": true, "Set": true, "String": true,
+	}
+)
+
+// buildTypeScript computes the TypeScript declarations and client methods for the given API.
+func buildTypeScript(api *design.APIDefinition) ([]*tsDecl, []*tsAction, error) {
+	b := &tsBuilder{byName: make(map[string]*tsDecl)}
+	var actions []*tsAction
+	err := api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() || a.EventStream() != nil || len(a.Routes) == 0 {
+				return nil // Streaming actions are not supported
+			}
+			ta, err := b.action(a)
+			if err != nil {
+				return fmt.Errorf("action %s of resource %s: %s", a.Name, r.Name, err)
+			}
+			actions = append(actions, ta)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	err = api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		_, err := b.typeRef("", "", &design.AttributeDefinition{Type: ut})
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			_, err := b.typeRef("", "", &design.AttributeDefinition{Type: mt, View: v.Name})
+			return err
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return b.decls, actions, nil
+}
+
+// action computes the client method generated for the given action.
+func (b *tsBuilder) action(a *design.ActionDefinition) (*tsAction, error) {
+	name := codegen.Goify(a.Name, false) + codegen.Goify(a.Parent.Name, true)
+	typeName := codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true)
+	route := a.Routes[0]
+	ta := &tsAction{Name: name, Action: a, Verb: route.Verb, ParamsOptional: true}
+
+	// Parameters
+	params := &design.AttributeDefinition{Type: design.Object{}}
+	if all := a.AllParams(); all != nil {
+		params = all
+	}
+	obj := make(design.Object)
+	required := make(map[string]bool)
+	for n, att := range params.Type.ToObject() {
+		obj[n] = att
+		required[n] = params.IsRequired(n)
+	}
+	pathParams := route.Params()
+	for _, n := range pathParams {
+		required[n] = true
+	}
+	if a.Headers != nil {
+		for n, att := range a.Headers.Type.ToObject() {
+			if _, ok := obj[n]; ok {
+				return nil, fmt.Errorf("header %s conflicts with parameter of the same name", n)
+			}
+			obj[n] = att
+			required[n] = a.Headers.IsRequired(n)
+			ta.Headers = append(ta.Headers, &tsParam{Name: n, Ref: "params" + tsAccessor(n)})
+		}
+		sort.Slice(ta.Headers, func(i, j int) bool { return ta.Headers[i].Name < ta.Headers[j].Name })
+	}
+	if len(obj) > 0 {
+		var req []string
+		for n, ok := range required {
+			if ok {
+				req = append(req, n)
+				ta.ParamsOptional = false
+			}
+		}
+		att := &design.AttributeDefinition{Type: obj}
+		att.Validation = &dslengine.ValidationDefinition{Required: req}
+		d, err := b.interfaceDecl(typeName+"Params", "", att, att)
+		if err != nil {
+			return nil, err
+		}
+		ta.Params = d
+	}
+	isPath := make(map[string]bool)
+	for _, n := range pathParams {
+		isPath[n] = true
+	}
+	var query []string
+	for n := range params.Type.ToObject() {
+		if !isPath[n] {
+			query = append(query, n)
+		}
+	}
+	sort.Strings(query)
+	for _, n := range query {
+		ta.Query = append(ta.Query, &tsParam{Name: n, Ref: "params" + tsAccessor(n)})
+	}
+	ta.Path = tsPath(route.FullPath())
+
+	// Payload
+	if a.Payload != nil {
+		p, err := b.typeRef("", "", &design.AttributeDefinition{Type: a.Payload})
+		if err != nil {
+			return nil, err
+		}
+		ta.Payload = p
+	}
+
+	// Responses
+	var results []string
+	seen := make(map[string]bool)
+	var errs []string
+	err := a.IterateResponses(func(r *design.ResponseDefinition) error {
+		body := "undefined"
+		var dt design.DataType
+		if r.Type != nil {
+			dt = r.Type
+		} else if mt := design.Design.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+			dt = mt
+		}
+		if dt != nil {
+			ref, err := b.typeRef("", "", &design.AttributeDefinition{Type: dt, View: r.ViewName})
+			if err != nil {
+				return err
+			}
+			body = ref
+		}
+		if r.Status >= 200 && r.Status < 300 {
+			if !seen[body] {
+				seen[body] = true
+				results = append(results, body)
+			}
+			return nil
+		}
+		if body == "undefined" {
+			errs = append(errs, fmt.Sprintf("{ status: %d; body?: undefined }", r.Status))
+		} else {
+			errs = append(errs, fmt.Sprintf("{ status: %d; body: %s }", r.Status, body))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ta.Result = strings.Join(results, " | ")
+	if ta.Result == "" || ta.Result == "undefined" {
+		ta.Result = "void"
+	}
+	if len(errs) > 0 {
+		d := &tsDecl{
+			Name:        typeName + "ErrorResult",
+			Description: fmt.Sprintf("%sErrorResult describes the error responses of the %s action of the %s resource.", typeName, a.Name, a.Parent.Name),
+			Type:        strings.Join(errs, " | "),
+			key:         a,
+		}
+		if err := b.declare(d); err != nil {
+			return nil, err
+		}
+		ta.Errors = d
+	}
+
+	return ta, nil
+}
+
+// typeRef returns the TypeScript type of the given attribute, declaring the interfaces and type
+// aliases it uses. parent and name are used to name the declarations generated for inline objects
+// and enums, they are empty when the attribute is not an object field.
+func (b *tsBuilder) typeRef(parent, name string, att *design.AttributeDefinition) (string, error) {
+	if enum := tsEnum(att); enum != "" {
+		if parent == "" {
+			return enum, nil
+		}
+		d := &tsDecl{Name: parent + codegen.Goify(name, true), Description: att.Description, Type: enum, key: att}
+		if err := b.declare(d); err != nil {
+			return "", err
+		}
+		return d.Name, nil
+	}
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		return tsPrimitive(actual), nil
+	case *design.Array:
+		elem, err := b.typeRef(parent, name, actual.ElemType)
+		if err != nil {
+			return "", err
+		}
+		if tsIdentifier.MatchString(elem) {
+			return elem + "[]", nil
+		}
+		return "Array<" + elem + ">", nil
+	case *design.Hash:
+		elem, err := b.typeRef(parent, name, actual.ElemType)
+		if err != nil {
+			return "", err
+		}
+		return "{ [key: string]: " + elem + " }", nil
+	case design.Object:
+		if parent == "" {
+			parent = "Anonymous"
+		}
+		d, err := b.interfaceDecl(parent+codegen.Goify(name, true), att.Description, att, att)
+		if err != nil {
+			return "", err
+		}
+		return d.Name, nil
+	case *design.MediaTypeDefinition:
+		view := att.View
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := actual.Project(view)
+		if err != nil {
+			return "", err
+		}
+		tname := tsTypeName(p.TypeName)
+		if actual.IsError() {
+			tname = "ErrorResponse"
+		}
+		if p.IsObject() {
+			d, err := b.interfaceDecl(tname, p.Description, p.Identifier, p.AttributeDefinition)
+			if err != nil {
+				return "", err
+			}
+			return d.Name, nil
+		}
+		return b.aliasDecl(tname, p.Description, p.Identifier, p.AttributeDefinition)
+	case *design.UserTypeDefinition:
+		tname := tsTypeName(actual.TypeName)
+		if actual.IsObject() {
+			d, err := b.interfaceDecl(tname, actual.Description, actual, actual.AttributeDefinition)
+			if err != nil {
+				return "", err
+			}
+			return d.Name, nil
+		}
+		return b.aliasDecl(tname, actual.Description, actual, actual.AttributeDefinition)
+	}
+	return "unknown", nil
+}
+
+// interfaceDecl returns the interface declaration with the given name describing the object
+// attribute att, creating it if needed. key identifies the design type the interface is generated
+// from.
+func (b *tsBuilder) interfaceDecl(name, desc string, key interface{}, att *design.AttributeDefinition) (*tsDecl, error) {
+	if d, ok := b.byName[name]; ok {
+		if d.key != key {
+			return nil, fmt.Errorf("TypeScript type name %s is used by multiple types", name)
+		}
+		return d, nil
+	}
+	d := &tsDecl{Name: name, Description: desc, Fields: []*tsField{}, key: key}
+	if err := b.declare(d); err != nil {
+		return nil, err
+	}
+	obj := att.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		typ, err := b.typeRef(name, n, obj[n])
+		if err != nil {
+			return nil, err
+		}
+		d.Fields = append(d.Fields, &tsField{
+			Name:        tsFieldName(n),
+			Description: obj[n].Description,
+			Type:        typ,
+			Optional:    !att.IsRequired(n),
+		})
+	}
+	return d, nil
+}
+
+// aliasDecl declares a type alias with the given name for the type of att. key identifies the
+// design type the alias is generated from.
+func (b *tsBuilder) aliasDecl(name, desc string, key interface{}, att *design.AttributeDefinition) (string, error) {
+	if d, ok := b.byName[name]; ok {
+		if d.key != key {
+			return "", fmt.Errorf("TypeScript type name %s is used by multiple types", name)
+		}
+		return name, nil
+	}
+	d := &tsDecl{Name: name, Description: desc, Type: tsEnum(att), key: key}
+	if err := b.declare(d); err != nil {
+		return "", err
+	}
+	if d.Type == "" {
+		typ, err := b.typeRef(name, "item", &design.AttributeDefinition{Type: att.Type})
+		if err != nil {
+			return "", err
+		}
+		d.Type = typ
+	}
+	return name, nil
+}
+
+// declare records the given declaration.
+func (b *tsBuilder) declare(d *tsDecl) error {
+	if existing, ok := b.byName[d.Name]; ok {
+		if existing.key == d.key {
+			return nil
+		}
+		return fmt.Errorf("TypeScript type name %s is used by multiple types", d.Name)
+	}
+	b.byName[d.Name] = d
+	b.decls = append(b.decls, d)
+	return nil
+}
+
+// tsPrimitive returns the TypeScript type corresponding to the given primitive.
+func tsPrimitive(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "boolean"
+	case design.IntegerKind, design.NumberKind:
+		return "number"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind:
+		return "string"
+	}
+	return "unknown"
+}
+
+// tsEnum returns the union of literal types corresponding to the Enum validation of att if any.
+func tsEnum(att *design.AttributeDefinition) string {
+	if att.Validation == nil || len(att.Validation.Values) == 0 || !att.Type.IsPrimitive() {
+		return ""
+	}
+	vals := make([]string, len(att.Validation.Values))
+	for i, v := range att.Validation.Values {
+		js, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		vals[i] = string(js)
+	}
+	return strings.Join(vals, " | ")
+}
+
+// tsTypeName returns the name of the TypeScript declaration for the given design type name.
+func tsTypeName(name string) string {
+	name = codegen.Goify(name, true)
+	if tsReserved[name] {
+		name += "Type"
+	}
+	return name
+}
+
+// tsFieldName returns the TypeScript property name for the given attribute name, quoting it if
+// necessary.
+func tsFieldName(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// tsAccessor returns the expression that accesses the property with the given name.
+func tsAccessor(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return "." + name
+	}
+	return fmt.Sprintf("[%q]", name)
+}
+
+// tsPath returns the template literal that builds the given request path.
+func tsPath(p string) string {
+	p = strings.NewReplacer("`", "\\`", "${", "\\${").Replace(p)
+	return "`" + design.WildcardRegex.ReplaceAllStringFunc(p, func(w string) string {
+		name := w[2:]
+		if w[1] == '*' {
+			return "/${encodeURI(String(params" + tsAccessor(name) + "))}"
+		}
+		return "/${encodeURIComponent(String(params" + tsAccessor(name) + "))}"
+	}) + "`"
+}
+
+// tsMethodDoc returns the JSDoc comment of the client method generated for the given action.
+func tsMethodDoc(ta *tsAction) string {
+	a := ta.Action
+	var lines []string
+	if a.Description != "" {
+		lines = append(lines, a.Description, "")
+	}
+	lines = append(lines, fmt.Sprintf("%s calls the %s action of the %s resource: %s %s", ta.Name, a.Name, a.Parent.Name, ta.Verb, a.Routes[0].FullPath()))
+	if ta.Errors != nil {
+		lines = append(lines, fmt.Sprintf("The promise is rejected with a HTTPError<%s> for the error responses declared in the design.", ta.Errors.Name))
+	}
+	return tsComment(strings.Join(lines, "\n"), "  ")
+}
+
+// tsComment returns the JSDoc comment lines for the given text prefixed with indent.
+func tsComment(text, indent string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(indent+" * "+strings.TrimSpace(l), " ")
+	}
+	return indent + "/**\n" + strings.Join(lines, "\n") + "\n" + indent + " */\n"
+}
+
+const tsModuleT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// API {{ printf "%q" .API.Name }}: TypeScript client
+//
+// Command:
+{{ comment commandLine }}
+
+/**
+ * Fetch sends HTTP requests, it has the same signature as the global fetch function which is used
+ * by default.
+ */
+export type Fetch = (url: string, init: RequestInit) => Promise<Response>;
+
+/**
+ * ClientOptions configures the client.
+ */
+export interface ClientOptions {
+  /** scheme used to make requests, defaults to "{{ .Scheme }}". */
+  scheme?: string;
+  /** host addressed by the client{{ if .Host }}, defaults to "{{ .Host }}"{{ end }}. Requests use relative URLs if empty. */
+  host?: string;
+  /** timeout is the request timeout in milliseconds, defaults to {{ .Timeout }}. Set to 0 to disable. */
+  timeout?: number;
+  /** headers are added to all requests. */
+  headers?: { [name: string]: string };
+  /** fetch sends the requests, defaults to the global fetch function. */
+  fetch?: Fetch;
+}
+
+/**
+ * RequestOptions configures a single request.
+ */
+export interface RequestOptions {
+  /** headers are added to the request. */
+  headers?: { [name: string]: string };
+  /** signal aborts the request, it overrides the client timeout. */
+  signal?: AbortSignal;
+}
+
+/**
+ * ErrorResult describes a response with a non-successful status code.
+ */
+export interface ErrorResult {
+  status: number;
+  body?: unknown;
+}
+
+/**
+ * HTTPError is the error raised when the API responds with a non-successful status code.
+ */
+export class HTTPError<R extends ErrorResult = ErrorResult> extends Error {
+  readonly response: R;
+
+  constructor(response: R) {
+    super(` + "`" + `request failed with status ${response.status}` + "`" + `);
+    Object.setPrototypeOf(this, HTTPError.prototype);
+    this.name = "HTTPError";
+    this.response = response;
+  }
+}
+`
+
+const tsDeclT = `{{ if .Description }}
+{{ tsComment .Description "" }}{{ else }}
+{{ end }}{{ if .Fields }}export interface {{ .Name }} {
+{{ range .Fields }}{{ if .Description }}{{ tsComment .Description "  " }}{{ end }}  {{ .Name }}{{ if .Optional }}?{{ end }}: {{ .Type }};
+{{ end }}}
+{{ else if eq .Type "" }}export interface {{ .Name }} {}
+{{ else }}export type {{ .Name }} = {{ .Type }};
+{{ end }}`
+
+const tsClientT = `
+/**
+ * Client gives access to the {{ .API.Name }} API.
+ */
+export class Client {
+  private readonly urlPrefix: string;
+  private readonly timeout: number;
+  private readonly headers: { [name: string]: string };
+  private readonly fetch: Fetch;
+
+  constructor(options: ClientOptions = {}) {
+    const scheme = options.scheme || {{ printf "%q" .Scheme }};
+    const host = options.host || {{ printf "%q" .Host }};
+    this.urlPrefix = host ? scheme + "://" + host : "";
+    this.timeout = options.timeout === undefined ? {{ .Timeout }} : options.timeout;
+    this.headers = options.headers || {};
+    this.fetch = options.fetch || ((url, init) => fetch(url, init));
+  }
+{{ range .Actions }}{{ $a := .Action }}
+{{ methodDoc . }}  async {{ .Name }}({{ if .Params }}params: {{ .Params.Name }}{{ if .ParamsOptional }} = {}{{ end }}, {{ end }}{{ if .Payload }}payload{{ if $a.PayloadOptional }}?{{ end }}: {{ .Payload }}, {{ end }}options: RequestOptions = {}): Promise<{{ .Result }}> {
+    {{ if ne .Result "void" }}const body = {{ end }}await this.request(
+      {{ printf "%q" .Verb }},
+      {{ .Path }},
+      [{{ range $i, $q := .Query }}{{ if $i }}, {{ end }}[{{ printf "%q" $q.Name }}, {{ $q.Ref }}]{{ end }}],
+      {{ if .Headers }}{ {{ range $i, $h := .Headers }}{{ if $i }}, {{ end }}{{ printf "%q" $h.Name }}: {{ $h.Ref }}{{ end }} }{{ else }}{}{{ end }},
+      {{ if .Payload }}payload{{ else }}undefined{{ end }},
+      options
+    );
+{{ if ne .Result "void" }}    return body as {{ .Result }};
+{{ end }}  }
+{{ end }}
+  private async request(
+    method: string,
+    path: string,
+    query: Array<[string, unknown]>,
+    headers: { [name: string]: unknown },
+    payload: unknown,
+    options: RequestOptions
+  ): Promise<unknown> {
+    const search = new URLSearchParams();
+    for (const [name, value] of query) {
+      if (value === undefined || value === null) {
+        continue;
+      }
+      for (const v of Array.isArray(value) ? value : [value]) {
+        search.append(name, String(v));
+      }
+    }
+    const qs = search.toString();
+    const hdrs: { [name: string]: string } = { Accept: "application/json", ...this.headers, ...options.headers };
+    for (const name of Object.keys(headers)) {
+      const value = headers[name];
+      if (value !== undefined && value !== null) {
+        hdrs[name] = String(value);
+      }
+    }
+    const init: RequestInit = { method, headers: hdrs, signal: options.signal };
+    if (payload !== undefined) {
+      hdrs["Content-Type"] = "application/json";
+      init.body = JSON.stringify(payload);
+    }
+    let timer: ReturnType<typeof setTimeout> | undefined;
+    if (!options.signal && this.timeout > 0 && typeof AbortController !== "undefined") {
+      const controller = new AbortController();
+      init.signal = controller.signal;
+      timer = setTimeout(() => controller.abort(), this.timeout);
+    }
+    try {
+      const resp = await this.fetch(this.urlPrefix + path + (qs ? "?" + qs : ""), init);
+      const text = await resp.text();
+      let body: unknown = undefined;
+      if (text) {
+        try {
+          body = JSON.parse(text);
+        } catch (e) {
+          body = text;
+        }
+      }
+      if (resp.status < 200 || resp.status >= 300) {
+        throw new HTTPError({ status: resp.status, body });
+      }
+      return body;
+    } finally {
+      if (timer !== undefined) {
+        clearTimeout(timer);
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,166 @@
+package genjs_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	genjs "github.com/goadesign/goa/goagen/gen_js"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// dslDesign is the API definition registered with the DSL engine, other tests replace Design.
+var dslDesign = Design
+
+var _ = Describe("Generate TypeScript", func() {
+	const testgenPackagePath = "github.com/goadesign/goa/goagen/gen_js/test_ts"
+
+	var outDir string
+	var files []string
+	var genErr error
+	var content string
+
+	BeforeEach(func() {
+		gopath := filepath.SplitList(os.Getenv("GOPATH"))[0]
+		outDir = filepath.Join(gopath, "src", testgenPackagePath)
+		err := os.MkdirAll(outDir, 0777)
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--ts", "--version=" + version.String()}
+		Design = dslDesign
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+		content = ""
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genjs.Generate()
+		if genErr == nil {
+			b, err := ioutil.ReadFile(filepath.Join(outDir, "ts", "client.ts"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content = string(b)
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	Context("with a bottle API", func() {
+		BeforeEach(func() {
+			var BottlePayload = Type("BottlePayload", func() {
+				Attribute("name", String)
+				Attribute("color", String, func() {
+					Enum("red", "white")
+				})
+				Attribute("vintage", Integer)
+				Required("name")
+			})
+			var Bottle = MediaType("application/vnd.bottle+json", func() {
+				Description("A bottle of wine")
+				Attributes(func() {
+					Attribute("id", Integer, "ID of bottle")
+					Attribute("name", String)
+					Attribute("ratings", HashOf(String, Integer))
+					Required("id")
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+					Attribute("ratings")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			API("cellar", func() {
+				Host("localhost:8080")
+			})
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Action("show", func() {
+					Description("Show a bottle")
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Headers(func() {
+						Header("X-Request-Id")
+					})
+					Response(OK, Bottle)
+					Response(NotFound)
+					Response(BadRequest, ErrorMedia)
+				})
+				Action("list", func() {
+					Routing(GET(""))
+					Params(func() {
+						Param("sort", String, func() {
+							Enum("asc", "desc")
+						})
+						Param("tag", ArrayOf(String))
+					})
+					Response(OK, CollectionOf(Bottle), func() {
+						Media(CollectionOf(Bottle), "tiny")
+					})
+				})
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(BottlePayload)
+					Response(Created)
+				})
+			})
+		})
+
+		It("generates the client module", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "ts", "client.ts")))
+			Ω(files).ShouldNot(ContainElement(filepath.Join(outDir, "js", "client.js")))
+			Ω(content).Should(ContainSubstring("export type Fetch = (url: string, init: RequestInit) => Promise<Response>;"))
+			Ω(content).Should(ContainSubstring("export class HTTPError<R extends ErrorResult = ErrorResult> extends Error {"))
+			Ω(content).Should(ContainSubstring(`const host = options.host || "localhost:8080";`))
+		})
+
+		It("generates interfaces for the user types and media type views", func() {
+			Ω(content).Should(ContainSubstring(`export interface BottlePayload {
+  color?: BottlePayloadColor;
+  name: string;
+  vintage?: number;
+}`))
+			Ω(content).Should(ContainSubstring(`export type BottlePayloadColor = "red" | "white";`))
+			Ω(content).Should(ContainSubstring(`/**
+ * A bottle of wine (default view)
+ */
+export interface Bottle {
+  /**
+   * ID of bottle
+   */
+  id: number;
+  name?: string;
+  ratings?: { [key: string]: number };
+}`))
+			Ω(content).Should(ContainSubstring("export interface BottleTiny {"))
+			Ω(content).Should(ContainSubstring("export type BottleTinyCollection = BottleTiny[];"))
+			Ω(content).Should(ContainSubstring("export interface ErrorResponse {"))
+		})
+
+		It("generates typed request functions", func() {
+			Ω(content).Should(ContainSubstring(`export interface ShowBottleParams {
+  "X-Request-Id"?: string;
+  id: number;
+}`))
+			Ω(content).Should(ContainSubstring("async showBottle(params: ShowBottleParams, options: RequestOptions = {}): Promise<Bottle> {"))
+			Ω(content).Should(ContainSubstring("`/bottles/${encodeURIComponent(String(params.id))}`"))
+			Ω(content).Should(ContainSubstring(`{ "X-Request-Id": params["X-Request-Id"] }`))
+			Ω(content).Should(ContainSubstring(`export type ShowBottleErrorResult = { status: 400; body: ErrorResponse } | { status: 404; body?: undefined };`))
+			Ω(content).Should(ContainSubstring(`export type ListBottleParamsSort = "asc" | "desc";`))
+			Ω(content).Should(ContainSubstring("async listBottle(params: ListBottleParams = {}, options: RequestOptions = {}): Promise<BottleTinyCollection> {"))
+			Ω(content).Should(ContainSubstring(`[["sort", params.sort], ["tag", params.tag]]`))
+			Ω(content).Should(ContainSubstring("async createBottle(payload: BottlePayload, options: RequestOptions = {}): Promise<void> {"))
+		})
+	})
+})
@@ -0,0 +1,21 @@
+/*
+Package genlint provides a generator that checks an API design against a set of style rules.
+Rules implement the Rule interface and inspect the finalized design. The built-in rules check
+that:
+
+	kebab-case-paths    request paths only contain lowercase kebab-case segments
+	action-description  every action has a description
+	success-media-type  every successful response with a body has a media type
+	no-any              attributes do not use the Any type
+	uuid-ids            identifier attributes ("id" or ending with "_id") are UUIDs
+	list-pagination     actions that list resources accept pagination parameters
+
+Additional rules are registered with Register, typically from the init function of a package
+imported by the design package. Rules may be disabled or have their severity changed with a JSON
+configuration file, see LoadConfig.
+
+The generator writes the report in the "lint" directory in text, JSON or SARIF format and returns
+an error listing the issues with severity error if there is any so that goagen exits with a
+non-zero status code.
+*/
+package genlint
@@ -0,0 +1,131 @@
+package genlint
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a lint Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{Format: FormatText}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the design linter.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Config   *Config               // Rules configuration
+	Format   string                // Report format, "text", "json" or "sarif"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver, format, configFile, enable, disable string
+	)
+
+	set := flag.NewFlagSet("lint", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&format, "format", FormatText, "")
+	set.StringVar(&configFile, "config", "", "")
+	set.StringVar(&enable, "enable", "", "")
+	set.StringVar(&disable, "disable", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if configFile != "" {
+		if cfg, err = LoadConfig(configFile); err != nil {
+			return nil, err
+		}
+	}
+	if enable != "" {
+		cfg.Enable(strings.Split(enable, ",")...)
+	}
+	if disable != "" {
+		cfg.Disable(strings.Split(disable, ",")...)
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design, Config: cfg, Format: format}
+
+	return g.Generate()
+}
+
+// Generate runs the registered rules against the API design and writes the report in the "lint"
+// directory. Generate returns an error listing the issues with severity error if there is any.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	rules := Rules()
+	if err := g.Config.Validate(rules); err != nil {
+		return nil, err
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	issues := Lint(g.API, rules, g.Config)
+	report, err := Format(issues, rules, g.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	lintDir := filepath.Join(g.OutDir, "lint")
+	if err = os.MkdirAll(lintDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, lintDir)
+
+	ext := g.Format
+	if ext == "" || ext == FormatText {
+		ext = "txt"
+	}
+	reportFile := filepath.Join(lintDir, "report."+ext)
+	if err = ioutil.WriteFile(reportFile, report, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, reportFile)
+
+	if errs := Errors(issues); len(errs) > 0 {
+		// Keep the report around so it can be inspected.
+		files := g.genfiles
+		g.genfiles = nil
+		return files, fmt.Errorf("%d lint error(s) found:\n%s", len(errs), strings.TrimSuffix(formatText(errs), "\n"))
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,245 @@
+package genlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/goadesign/goa/design"
+)
+
+type (
+	// Rule is implemented by the linter rules. Rules inspect the finalized design and report the
+	// issues they find.
+	Rule interface {
+		// Name returns the rule unique name, e.g. "kebab-case-paths".
+		Name() string
+		// Description returns a short description of the rule.
+		Description() string
+		// Severity returns the default severity of the issues reported by the rule.
+		Severity() Severity
+		// Check runs the rule against the API design and returns the issues found.
+		Check(api *design.APIDefinition) []*Issue
+	}
+
+	// Issue describes a design element that does not comply with a rule.
+	Issue struct {
+		// Rule is the name of the rule that reported the issue.
+		Rule string `json:"rule"`
+		// Severity is the issue severity.
+		Severity Severity `json:"severity"`
+		// Location identifies the design element, e.g. `action "show" of resource "bottle"`.
+		Location string `json:"location"`
+		// Message describes the issue.
+		Message string `json:"message"`
+	}
+
+	// Severity is the severity of an issue.
+	Severity string
+
+	// Config configures the rules run by the linter.
+	Config struct {
+		// Rules indexes the rule configurations by rule name.
+		Rules map[string]*RuleConfig `json:"rules"`
+	}
+
+	// RuleConfig configures a single rule.
+	RuleConfig struct {
+		// Disabled prevents the rule from running.
+		Disabled bool `json:"disabled,omitempty"`
+		// Severity overrides the rule default severity.
+		Severity Severity `json:"severity,omitempty"`
+	}
+
+	// rule is the Rule implementation returned by NewRule.
+	rule struct {
+		name, description string
+		severity          Severity
+		check             func(*design.APIDefinition) []*Issue
+	}
+)
+
+const (
+	// SeverityError is the severity of issues that make the linter fail.
+	SeverityError Severity = "error"
+	// SeverityWarning is the severity of issues that should be fixed but do not make the linter
+	// fail.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo is the severity of informational issues.
+	SeverityInfo Severity = "info"
+)
+
+var (
+	registry   = make(map[string]Rule)
+	registryMu sync.Mutex
+)
+
+// Register adds a rule to the set of rules run by the linter. Custom rules are typically
+// registered in the init function of a package imported by the design package. Register panics
+// if a rule with the same name is already registered.
+func Register(r Rule) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[r.Name()]; ok {
+		panic(fmt.Sprintf("goagen lint: rule %s registered twice", r.Name())) // bug
+	}
+	registry[r.Name()] = r
+}
+
+// Rules returns the registered rules sorted by name.
+func Rules() []Rule {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	rules := make([]Rule, 0, len(registry))
+	for _, r := range registry {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name() < rules[j].Name() })
+	return rules
+}
+
+// NewRule creates a rule that uses the given function to check the design. The function does not
+// need to set the Rule and Severity fields of the issues it returns.
+func NewRule(name, description string, severity Severity, check func(*design.APIDefinition) []*Issue) Rule {
+	return &rule{name: name, description: description, severity: severity, check: check}
+}
+
+// Lint runs the given rules against the API design and returns the issues found sorted by
+// location. cfg may be nil in which case all the rules run with their default severity.
+func Lint(api *design.APIDefinition, rules []Rule, cfg *Config) []*Issue {
+	var issues []*Issue
+	for _, r := range rules {
+		rc := cfg.get(r.Name())
+		if rc.Disabled {
+			continue
+		}
+		sev := r.Severity()
+		if rc.Severity != "" {
+			sev = rc.Severity
+		}
+		for _, issue := range r.Check(api) {
+			issue.Rule = r.Name()
+			issue.Severity = sev
+			issues = append(issues, issue)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Message < b.Message
+	})
+	return issues
+}
+
+// Errors returns the issues with severity error in the given list.
+func Errors(issues []*Issue) []*Issue {
+	var res []*Issue
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// LoadConfig reads the linter configuration from the JSON file at the given path, for example:
+//
+//	{
+//		"rules": {
+//			"no-any": {"disabled": true},
+//			"action-description": {"severity": "warning"}
+//		}
+//	}
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid lint configuration %s: %s", path, err)
+	}
+	return &cfg, nil
+}
+
+// Enable enables the rules with the given names.
+func (c *Config) Enable(names ...string) {
+	for _, n := range names {
+		c.ruleConfig(n).Disabled = false
+	}
+}
+
+// Disable disables the rules with the given names.
+func (c *Config) Disable(names ...string) {
+	for _, n := range names {
+		c.ruleConfig(n).Disabled = true
+	}
+}
+
+// Validate checks that the configuration only refers to the given rules and uses valid
+// severities.
+func (c *Config) Validate(rules []Rule) error {
+	if c == nil {
+		return nil
+	}
+	known := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		known[r.Name()] = true
+	}
+	for n, rc := range c.Rules {
+		if !known[n] {
+			return fmt.Errorf("unknown lint rule %#v", n)
+		}
+		switch rc.Severity {
+		case "", SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			return fmt.Errorf("invalid severity %#v for lint rule %s", rc.Severity, n)
+		}
+	}
+	return nil
+}
+
+// get returns the configuration of the rule with the given name.
+func (c *Config) get(name string) *RuleConfig {
+	if c == nil || c.Rules[name] == nil {
+		return &RuleConfig{}
+	}
+	return c.Rules[name]
+}
+
+// ruleConfig returns the configuration of the rule with the given name, creating it if needed.
+func (c *Config) ruleConfig(name string) *RuleConfig {
+	if c.Rules == nil {
+		c.Rules = make(map[string]*RuleConfig)
+	}
+	rc, ok := c.Rules[name]
+	if !ok {
+		rc = &RuleConfig{}
+		c.Rules[name] = rc
+	}
+	return rc
+}
+
+// String returns a human friendly representation of the issue.
+func (i *Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", i.Severity, i.Location, i.Message, i.Rule)
+}
+
+// Name returns the rule name.
+func (r *rule) Name() string { return r.name }
+
+// Description returns the rule description.
+func (r *rule) Description() string { return r.description }
+
+// Severity returns the rule default severity.
+func (r *rule) Severity() Severity { return r.severity }
+
+// Check runs the rule check function.
+func (r *rule) Check(api *design.APIDefinition) []*Issue { return r.check(api) }
@@ -0,0 +1,267 @@
+package genlint_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	genlint "github.com/goadesign/goa/goagen/gen_lint"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// rulesNamed returns the registered rules with the given names.
+func rulesNamed(names ...string) []genlint.Rule {
+	var rules []genlint.Rule
+	for _, r := range genlint.Rules() {
+		for _, n := range names {
+			if r.Name() == n {
+				rules = append(rules, r)
+			}
+		}
+	}
+	return rules
+}
+
+var _ = Describe("Lint", func() {
+	var rules []genlint.Rule
+	var cfg *genlint.Config
+	var issues []*genlint.Issue
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		rules = genlint.Rules()
+		cfg = nil
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		issues = genlint.Lint(Design, rules, cfg)
+	})
+
+	Context("with a compliant design", func() {
+		BeforeEach(func() {
+			var Bottle = MediaType("application/vnd.bottle+json", func() {
+				Attributes(func() {
+					Attribute("id", UUID)
+					Attribute("winery_id", UUID)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("winery_id")
+				})
+			})
+			API("test", nil)
+			Resource("bottle", func() {
+				BasePath("/wine-bottles")
+				Action("list", func() {
+					Description("List bottles")
+					Routing(GET(""))
+					Params(func() {
+						Param("page", Integer)
+					})
+					Response(OK, CollectionOf(Bottle))
+				})
+				Action("delete", func() {
+					Description("Delete bottle")
+					Routing(DELETE("/:id"))
+					Params(func() {
+						Param("id", UUID)
+					})
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("reports no issue", func() {
+			Ω(issues).Should(BeEmpty())
+		})
+	})
+
+	Context("with a design violating the built-in rules", func() {
+		BeforeEach(func() {
+			var Bottle = MediaType("application/vnd.bottle+json", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("extra", Any)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("extra")
+				})
+			})
+			API("test", nil)
+			Resource("bottle", func() {
+				BasePath("/wineBottles")
+				Action("list", func() {
+					Routing(GET(""))
+					Response(OK, CollectionOf(Bottle))
+				})
+				Action("create", func() {
+					Description("Create bottle")
+					Routing(POST(""))
+					Response(Created)
+				})
+			})
+		})
+
+		It("reports the issues", func() {
+			var found []string
+			for _, i := range issues {
+				Ω(i.Severity).Should(Equal(genlint.SeverityError))
+				found = append(found, i.Rule+" "+i.Location)
+			}
+			Ω(found).Should(ConsistOf(
+				`action-description resource "bottle" action "list"`,
+				`kebab-case-paths route GET "" of resource "bottle" action "list"`,
+				`kebab-case-paths route POST "" of resource "bottle" action "create"`,
+				`list-pagination resource "bottle" action "list"`,
+				`no-any attribute "extra" of media type "application/vnd.bottle+json"`,
+				`success-media-type response "Created" of resource "bottle" action "create"`,
+				`uuid-ids attribute "id" of media type "application/vnd.bottle+json"`,
+			))
+		})
+
+		Context("with a configuration", func() {
+			BeforeEach(func() {
+				cfg = &genlint.Config{Rules: map[string]*genlint.RuleConfig{
+					"no-any": {Severity: genlint.SeverityWarning},
+				}}
+				cfg.Disable("kebab-case-paths", "uuid-ids", "list-pagination")
+			})
+
+			It("applies it", func() {
+				Ω(issues).Should(HaveLen(3))
+				for _, i := range issues {
+					Ω(i.Rule).ShouldNot(Equal("kebab-case-paths"))
+					if i.Rule == "no-any" {
+						Ω(i.Severity).Should(Equal(genlint.SeverityWarning))
+					}
+				}
+				Ω(genlint.Errors(issues)).Should(HaveLen(2))
+			})
+		})
+	})
+
+	Context("with a custom rule", func() {
+		BeforeEach(func() {
+			rules = []genlint.Rule{genlint.NewRule("api-title", "API has a title", genlint.SeverityInfo,
+				func(api *APIDefinition) []*genlint.Issue {
+					if api.Title == "" {
+						return []*genlint.Issue{{Location: api.Context(), Message: "missing title"}}
+					}
+					return nil
+				})}
+			API("test", nil)
+		})
+
+		It("runs it", func() {
+			Ω(issues).Should(Equal([]*genlint.Issue{{
+				Rule:     "api-title",
+				Severity: genlint.SeverityInfo,
+				Location: `API "test"`,
+				Message:  "missing title",
+			}}))
+		})
+	})
+})
+
+var _ = Describe("Config", func() {
+	It("loads JSON files", func() {
+		f, err := ioutil.TempFile("", "lint")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer os.Remove(f.Name())
+		_, err = f.WriteString(`{"rules": {"no-any": {"disabled": true}, "uuid-ids": {"severity": "warning"}}}`)
+		Ω(err).ShouldNot(HaveOccurred())
+		f.Close()
+
+		cfg, err := genlint.LoadConfig(f.Name())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(cfg.Rules["no-any"].Disabled).Should(BeTrue())
+		Ω(cfg.Rules["uuid-ids"].Severity).Should(Equal(genlint.SeverityWarning))
+		Ω(cfg.Validate(genlint.Rules())).Should(Succeed())
+	})
+
+	It("rejects unknown rules and severities", func() {
+		cfg := &genlint.Config{}
+		cfg.Disable("unknown")
+		Ω(cfg.Validate(genlint.Rules())).Should(MatchError(`unknown lint rule "unknown"`))
+		cfg = &genlint.Config{Rules: map[string]*genlint.RuleConfig{"no-any": {Severity: "fatal"}}}
+		Ω(cfg.Validate(rulesNamed("no-any"))).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var outDir string
+	var files []string
+	var genErr error
+	var format string
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "lint")
+		Ω(err).ShouldNot(HaveOccurred())
+		format = genlint.FormatText
+		dslengine.Reset()
+		API("test", nil)
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/current"))
+				Response(NoContent)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		g := genlint.NewGenerator(
+			genlint.API(Design),
+			genlint.OutDir(outDir),
+			genlint.RulesConfig(&genlint.Config{}),
+			genlint.ReportFormat(format),
+		)
+		files, genErr = g.Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	It("writes the report and fails", func() {
+		Ω(genErr).Should(MatchError(ContainSubstring(`1 lint error(s) found:
+error: resource "bottle" action "show": action has no description [action-description]`)))
+		report := filepath.Join(outDir, "lint", "report.txt")
+		Ω(files).Should(ContainElement(report))
+		content, err := ioutil.ReadFile(report)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("[action-description]"))
+	})
+
+	Context("with the SARIF format", func() {
+		BeforeEach(func() {
+			format = genlint.FormatSARIF
+		})
+
+		It("writes a SARIF log", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "lint", "report.sarif"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`"version": "2.1.0"`))
+			Ω(string(content)).Should(ContainSubstring(`"ruleId": "action-description"`))
+			Ω(string(content)).Should(ContainSubstring(`"fullyQualifiedName": "resource \"bottle\" action \"show\""`))
+		})
+	})
+
+	Context("with an invalid format", func() {
+		BeforeEach(func() {
+			format = "xml"
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(MatchError(ContainSubstring("invalid report format")))
+			Ω(files).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,34 @@
+package genlint
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//RulesConfig Rules configuration
+func RulesConfig(cfg *Config) Option {
+	return func(g *Generator) {
+		g.Config = cfg
+	}
+}
+
+//ReportFormat Report format, "text", "json" or "sarif"
+func ReportFormat(format string) Option {
+	return func(g *Generator) {
+		g.Format = format
+	}
+}
@@ -0,0 +1,148 @@
+package genlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goadesign/goa/version"
+)
+
+// Report formats supported by the linter.
+const (
+	// FormatText produces a human friendly report.
+	FormatText = "text"
+	// FormatJSON produces a JSON array of issues.
+	FormatJSON = "json"
+	// FormatSARIF produces a SARIF 2.1.0 log that code scanning tools can ingest.
+	FormatSARIF = "sarif"
+)
+
+type (
+	// sarifLog is the root of a SARIF 2.1.0 log.
+	sarifLog struct {
+		Schema  string      `json:"$schema"`
+		Version string      `json:"version"`
+		Runs    []*sarifRun `json:"runs"`
+	}
+
+	// sarifRun describes a single run of the linter.
+	sarifRun struct {
+		Tool    *sarifTool     `json:"tool"`
+		Results []*sarifResult `json:"results"`
+	}
+
+	// sarifTool describes the linter and its rules.
+	sarifTool struct {
+		Driver *sarifDriver `json:"driver"`
+	}
+
+	// sarifDriver describes the linter.
+	sarifDriver struct {
+		Name           string       `json:"name"`
+		Version        string       `json:"version"`
+		InformationURI string       `json:"informationUri"`
+		Rules          []*sarifRule `json:"rules"`
+	}
+
+	// sarifRule describes a rule.
+	sarifRule struct {
+		ID               string        `json:"id"`
+		ShortDescription *sarifMessage `json:"shortDescription"`
+	}
+
+	// sarifResult describes an issue.
+	sarifResult struct {
+		RuleID    string           `json:"ruleId"`
+		RuleIndex int              `json:"ruleIndex"`
+		Level     string           `json:"level"`
+		Message   *sarifMessage    `json:"message"`
+		Locations []*sarifLocation `json:"locations"`
+	}
+
+	// sarifMessage is a SARIF text message.
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	// sarifLocation identifies the design element an issue applies to.
+	sarifLocation struct {
+		LogicalLocations []*sarifLogicalLocation `json:"logicalLocations"`
+	}
+
+	// sarifLogicalLocation is a location that is not a position in a file.
+	sarifLogicalLocation struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+	}
+)
+
+// Format renders the issues reported by the given rules in the given format.
+func Format(issues []*Issue, rules []Rule, format string) ([]byte, error) {
+	switch format {
+	case "", FormatText:
+		return []byte(formatText(issues)), nil
+	case FormatJSON:
+		if issues == nil {
+			issues = []*Issue{}
+		}
+		return json.MarshalIndent(issues, "", "  ")
+	case FormatSARIF:
+		return json.MarshalIndent(newSARIFLog(issues, rules), "", "  ")
+	}
+	return nil, fmt.Errorf(`invalid report format %#v, must be "text", "json" or "sarif"`, format)
+}
+
+// formatText returns a human friendly representation of the issues.
+func formatText(issues []*Issue) string {
+	if len(issues) == 0 {
+		return "no issue found\n"
+	}
+	lines := make([]string, len(issues))
+	for i, issue := range issues {
+		lines[i] = issue.String()
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// newSARIFLog builds the SARIF log describing the given issues.
+func newSARIFLog(issues []*Issue, rules []Rule) *sarifLog {
+	driver := &sarifDriver{
+		Name:           "goagen lint",
+		Version:        version.String(),
+		InformationURI: "https://goa.design",
+		Rules:          make([]*sarifRule, len(rules)),
+	}
+	index := make(map[string]int, len(rules))
+	for i, r := range rules {
+		driver.Rules[i] = &sarifRule{ID: r.Name(), ShortDescription: &sarifMessage{Text: r.Description()}}
+		index[r.Name()] = i
+	}
+	results := make([]*sarifResult, len(issues))
+	for i, issue := range issues {
+		results[i] = &sarifResult{
+			RuleID:    issue.Rule,
+			RuleIndex: index[issue.Rule],
+			Level:     sarifLevel(issue.Severity),
+			Message:   &sarifMessage{Text: issue.Message},
+			Locations: []*sarifLocation{{
+				LogicalLocations: []*sarifLogicalLocation{{FullyQualifiedName: issue.Location}},
+			}},
+		}
+	}
+	return &sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []*sarifRun{{Tool: &sarifTool{Driver: driver}, Results: results}},
+	}
+}
+
+// sarifLevel returns the SARIF level corresponding to the given severity.
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
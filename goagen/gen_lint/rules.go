@@ -0,0 +1,241 @@
+package genlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+)
+
+// PaginationParams lists the names of the query string parameters recognized as pagination
+// parameters by the "list-pagination" rule.
+var PaginationParams = []string{"page", "per_page", "page_size", "limit", "offset", "cursor", "after", "before"}
+
+// kebabSegment matches kebab-case path segments, dots are allowed for file extensions.
+var kebabSegment = regexp.MustCompile(`^[a-z0-9]+([-.][a-z0-9]+)*$`)
+
+func init() {
+	Register(NewRule("kebab-case-paths",
+		"Request paths only contain lowercase kebab-case segments.",
+		SeverityError, checkKebabCasePaths))
+	Register(NewRule("action-description",
+		"Every action has a description.",
+		SeverityError, checkActionDescription))
+	Register(NewRule("success-media-type",
+		"Every successful response with a body has a media type.",
+		SeverityError, checkSuccessMediaType))
+	Register(NewRule("no-any",
+		"Attributes do not use the Any type.",
+		SeverityError, checkNoAny))
+	Register(NewRule("uuid-ids",
+		`Identifier attributes ("id" or ending with "_id") are UUIDs.`,
+		SeverityError, checkUUIDIDs))
+	Register(NewRule("list-pagination",
+		"Actions that list resources accept pagination parameters.",
+		SeverityError, checkListPagination))
+}
+
+// checkKebabCasePaths implements the "kebab-case-paths" rule.
+func checkKebabCasePaths(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	check := func(loc, p string) {
+		for _, seg := range strings.Split(p, "/") {
+			if seg == "" || seg[0] == ':' || seg[0] == '*' {
+				continue
+			}
+			if !kebabSegment.MatchString(seg) {
+				issues = append(issues, &Issue{
+					Location: loc,
+					Message:  fmt.Sprintf("path segment %#v of %#v is not kebab-case", seg, p),
+				})
+			}
+		}
+	}
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		r.IterateActions(func(a *design.ActionDefinition) error {
+			for _, route := range a.Routes {
+				check(route.Context(), route.FullPath())
+			}
+			return nil
+		})
+		return r.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			check(fs.Context(), fs.RequestPath)
+			return nil
+		})
+	})
+	return issues
+}
+
+// checkActionDescription implements the "action-description" rule.
+func checkActionDescription(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	iterateActions(api, func(a *design.ActionDefinition) {
+		if strings.TrimSpace(a.Description) == "" {
+			issues = append(issues, &Issue{Location: a.Context(), Message: "action has no description"})
+		}
+	})
+	return issues
+}
+
+// checkSuccessMediaType implements the "success-media-type" rule.
+func checkSuccessMediaType(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	iterateActions(api, func(a *design.ActionDefinition) {
+		if a.WebSocket() {
+			return
+		}
+		a.IterateResponses(func(r *design.ResponseDefinition) error {
+			if r.Status < 200 || r.Status >= 300 || r.Status == 204 || r.Status == 205 {
+				return nil
+			}
+			if r.MediaType == "" && r.Type == nil {
+				issues = append(issues, &Issue{
+					Location: r.Context(),
+					Message:  fmt.Sprintf("successful response with status %d has no media type", r.Status),
+				})
+			}
+			return nil
+		})
+	})
+	return issues
+}
+
+// checkNoAny implements the "no-any" rule.
+func checkNoAny(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	walkAttributes(api, func(loc, _ string, att *design.AttributeDefinition) {
+		if att.Type.Kind() == design.AnyKind {
+			issues = append(issues, &Issue{Location: loc, Message: "attribute uses the Any type"})
+		}
+	})
+	return issues
+}
+
+// checkUUIDIDs implements the "uuid-ids" rule.
+func checkUUIDIDs(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	walkAttributes(api, func(loc, name string, att *design.AttributeDefinition) {
+		if name != "id" && !strings.HasSuffix(name, "_id") || !att.Type.IsPrimitive() {
+			return
+		}
+		if att.Type.Kind() != design.UUIDKind {
+			issues = append(issues, &Issue{Location: loc, Message: "identifier must use the UUID type"})
+		}
+	})
+	return issues
+}
+
+// checkListPagination implements the "list-pagination" rule.
+func checkListPagination(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	iterateActions(api, func(a *design.ActionDefinition) {
+		if !isListAction(api, a) {
+			return
+		}
+		params := a.AllParams().Type.ToObject()
+		for _, p := range PaginationParams {
+			if _, ok := params[p]; ok {
+				return
+			}
+		}
+		issues = append(issues, &Issue{
+			Location: a.Context(),
+			Message:  fmt.Sprintf("list action has no pagination parameter (one of %s)", strings.Join(PaginationParams, ", ")),
+		})
+	})
+	return issues
+}
+
+// isListAction returns true if the action is named "list" or returns a collection.
+func isListAction(api *design.APIDefinition, a *design.ActionDefinition) bool {
+	if a.Name == "list" || strings.HasPrefix(a.Name, "list_") {
+		return true
+	}
+	list := false
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		if r.Status < 200 || r.Status >= 300 {
+			return nil
+		}
+		if r.Type != nil && r.Type.IsArray() {
+			list = true
+		} else if mt := api.MediaTypeWithIdentifier(r.MediaType); mt != nil && mt.IsArray() {
+			list = true
+		}
+		return nil
+	})
+	return list
+}
+
+// iterateActions calls fn for each action of the API.
+func iterateActions(api *design.APIDefinition, fn func(*design.ActionDefinition)) {
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			fn(a)
+			return nil
+		})
+	})
+}
+
+// walkAttributes calls fn for each attribute defined in the API user types, media types, action
+// parameters, headers and payloads. fn is given the location that identifies the attribute and
+// the attribute name. Attributes of user types and media types are visited once where the type is
+// defined.
+func walkAttributes(api *design.APIDefinition, fn func(loc, name string, att *design.AttributeDefinition)) {
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		walkFields(fmt.Sprintf("type %#v", ut.TypeName), "", ut.AttributeDefinition, fn)
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		walkFields(fmt.Sprintf("media type %#v", mt.Identifier), "", mt.AttributeDefinition, fn)
+		return nil
+	})
+	iterateActions(api, func(a *design.ActionDefinition) {
+		if a.Params != nil {
+			for n, att := range a.Params.Type.ToObject() {
+				walkField(fmt.Sprintf("parameters of %s", a.Context()), n, att, fn)
+			}
+		}
+		if a.Headers != nil {
+			for n, att := range a.Headers.Type.ToObject() {
+				walkField(fmt.Sprintf("headers of %s", a.Context()), n, att, fn)
+			}
+		}
+		if a.Payload != nil {
+			if _, ok := api.Types[a.Payload.TypeName]; !ok {
+				walkFields(fmt.Sprintf("payload of %s", a.Context()), "", a.Payload.AttributeDefinition, fn)
+			}
+		}
+	})
+}
+
+// walkFields visits the fields of the given object attribute recursively.
+func walkFields(parent, prefix string, att *design.AttributeDefinition, fn func(string, string, *design.AttributeDefinition)) {
+	for n, f := range att.Type.ToObject() {
+		if prefix != "" {
+			n = prefix + "." + n
+		}
+		walkField(parent, n, f, fn)
+	}
+}
+
+// walkField visits the given attribute and its inline children.
+func walkField(parent, path string, att *design.AttributeDefinition, fn func(string, string, *design.AttributeDefinition)) {
+	name := path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		name = path[i+1:]
+	}
+	fn(fmt.Sprintf("attribute %#v of %s", path, parent), name, att)
+	switch actual := att.Type.(type) {
+	case design.Object:
+		walkFields(parent, path, att, fn)
+	case *design.Array:
+		walkField(parent, path, actual.ElemType, fn)
+	case *design.Hash:
+		walkField(parent, path, actual.KeyType, fn)
+		walkField(parent, path, actual.ElemType, fn)
+	}
+}
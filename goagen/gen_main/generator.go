@@ -369,8 +369,12 @@ func okResp(a *design.ActionDefinition, appPkg string) map[string]interface{} {
 	if view != "default" {
 		nameSuffix = codegen.Goify(view, true)
 	}
+	name := ok.Name
+	if ok.ServerSentEvents {
+		name = "Send"
+	}
 	return map[string]interface{}{
-		"Name":    ok.Name + nameSuffix,
+		"Name":    name + nameSuffix,
 		"GoType":  codegen.GoNativeType(pmt),
 		"TypeRef": typeref,
 	}
@@ -465,6 +469,8 @@ func main() {
 	{{ $tmp := tempvar }}{{ $tmp }} := New{{ $name }}Controller(service)
 	{{ targetPkg }}.Mount{{ $name }}Controller(service, {{ $tmp }})
 {{ end }}
+	// Shut down gracefully on SIGTERM and SIGINT
+	service.ShutdownOnSignal(30 * time.Second)
 
 {{ if .TLS }}
 	// Start service
@@ -54,6 +54,7 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(len(strings.Split(string(content), "\n"))).Should(BeNumerically(">=", 16))
 			Ω(string(content)).Should(ContainSubstring(listenAndServeCode))
+			Ω(string(content)).Should(ContainSubstring("service.ShutdownOnSignal(30 * time.Second)"))
 			_, err = gexec.Build(testgenPackagePath)
 			Ω(err).ShouldNot(HaveOccurred())
 		})
@@ -0,0 +1,22 @@
+/*
+Package genmock provides a generator for a mock server of a goa API.
+
+The generated mock server is a main package that mounts one controller per resource using the
+"app" package generated by "goagen app". Requests thus go through the generated handlers which
+decode and validate the request parameters and payloads with the generated validators. Each action
+responds with an example of the response body computed from the design: the examples defined with
+Example are used when present, random values that satisfy the attribute validations are generated
+otherwise. The random generator is seeded with the API name so that the examples only change when
+the design does. Examples are generated even if the design uses NoExample.
+
+The response is the first successful response defined by the action by default. Clients may
+select another response by setting the X-Mock-Response header to the response name or status
+code, for example:
+
+	curl -H "X-Mock-Response: NotFound" localhost:8080/bottles/1
+
+Media type examples are rendered with the view given by the "view" parameter if the action defines
+one, the default view otherwise. Security schemes let all requests through and websocket actions
+echo back the messages they receive.
+*/
+package genmock
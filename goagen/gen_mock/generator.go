@@ -0,0 +1,344 @@
+package genmock
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a mock server Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{AppPkg: "app", Target: "mock"}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the mock server code generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated directory
+	AppPkg   string                // Name or import path of generated "app" package
+	genfiles []string              // Generated files
+}
+
+// mockResponse is the data used to render a mock.Response literal.
+type mockResponse struct {
+	Name        string
+	Status      int
+	ContentType string
+	Stream      bool
+	Examples    map[string]string
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, target, appPkg, ver string
+	)
+
+	set := flag.NewFlagSet("mock", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "mock", "")
+	set.StringVar(&appPkg, "app-pkg", "app", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Target: target, AppPkg: appPkg, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the mock server main package.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	// Mock responses need examples even if the design disables them.
+	if design.Design != nil && design.Design.NoExamples {
+		design.Design.NoExamples = false
+		defer func() { design.Design.NoExamples = true }()
+	}
+
+	outDir := filepath.Join(g.OutDir, g.Target)
+	if err = os.MkdirAll(outDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, outDir)
+
+	appImp := g.AppPkg
+	if _, err := codegen.PackageSourcePath(g.AppPkg); err != nil {
+		appImp, err = codegen.PackagePath(g.OutDir)
+		if err != nil {
+			return nil, err
+		}
+		appImp = path.Join(filepath.ToSlash(appImp), g.AppPkg)
+	}
+	elems := strings.Split(appImp, "/")
+	appPkg := elems[len(elems)-1]
+
+	if err = g.generateMain(filepath.Join(outDir, "main.go"), appImp, appPkg); err != nil {
+		return nil, err
+	}
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		filename := filepath.Join(outDir, codegen.SnakeCase(r.Name)+".go")
+		return g.generateController(r, filename, appImp, appPkg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.RemoveAll(f)
+	}
+	g.genfiles = nil
+}
+
+// generateMain writes the mock server main function.
+func (g *Generator) generateMain(filename, appImp, appPkg string) (err error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	g.genfiles = append(g.genfiles, filename)
+
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("flag"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
+		codegen.SimpleImport("github.com/goadesign/goa/mock"),
+		codegen.SimpleImport(appImp),
+	}
+	title := fmt.Sprintf("API %q: mock server", g.API.Name)
+	if err = file.WriteHeader(title, "main", imports); err != nil {
+		return err
+	}
+	port := "8080"
+	if _, p, err := net.SplitHostPort(g.API.Host); err == nil {
+		port = p
+	}
+	data := map[string]interface{}{
+		"API":    g.API,
+		"AppPkg": appPkg,
+		"Port":   port,
+	}
+	funcs := template.FuncMap{"goify": codegen.Goify}
+	return file.ExecuteTemplate("main", mainT, funcs, data)
+}
+
+// generateController writes the mock controller of the given resource.
+func (g *Generator) generateController(r *design.ResourceDefinition, filename, appImp, appPkg string) (err error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	g.genfiles = append(g.genfiles, filename)
+
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("github.com/goadesign/goa/mock"),
+		codegen.SimpleImport(appImp),
+		codegen.SimpleImport("golang.org/x/net/websocket"),
+	}
+	title := fmt.Sprintf("API %q: %s mock controller", g.API.Name, r.Name)
+	if err = file.WriteHeader(title, "main", imports); err != nil {
+		return err
+	}
+	var actions []map[string]interface{}
+	err = r.IterateActions(func(a *design.ActionDefinition) error {
+		responses, err := g.mockResponses(a)
+		if err != nil {
+			return err
+		}
+		actions = append(actions, map[string]interface{}{
+			"Action":    a,
+			"Responses": responses,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	data := map[string]interface{}{
+		"Resource": r,
+		"Actions":  actions,
+		"AppPkg":   appPkg,
+	}
+	funcs := template.FuncMap{"goify": codegen.Goify}
+	return file.ExecuteTemplate("controller", ctrlT, funcs, data)
+}
+
+// mockResponses computes the mock responses of the given action sorted by status code.
+func (g *Generator) mockResponses(a *design.ActionDefinition) ([]*mockResponse, error) {
+	var responses []*mockResponse
+	err := a.IterateResponses(func(r *design.ResponseDefinition) error {
+		mr := &mockResponse{
+			Name:        r.Name,
+			Status:      r.Status,
+			ContentType: r.MediaType,
+			Stream:      r.ServerSentEvents,
+			Examples:    make(map[string]string),
+		}
+		if mt := g.API.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+			views := []string{r.ViewName}
+			if r.ViewName == "" {
+				views = nil
+				for v := range mt.Views {
+					views = append(views, v)
+				}
+				sort.Strings(views)
+			}
+			for _, v := range views {
+				p, _, err := mt.Project(v)
+				if err != nil {
+					return err
+				}
+				if err := g.addExample(mr, v, p.AttributeDefinition); err != nil {
+					return err
+				}
+			}
+		} else if r.Type != nil {
+			if err := g.addExample(mr, "", &design.AttributeDefinition{Type: r.Type}); err != nil {
+				return err
+			}
+		}
+		responses = append(responses, mr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].Status != responses[j].Status {
+			return responses[i].Status < responses[j].Status
+		}
+		return responses[i].Name < responses[j].Name
+	})
+	return responses, nil
+}
+
+// addExample adds the JSON representation of the attribute example to the mock response.
+func (g *Generator) addExample(mr *mockResponse, view string, att *design.AttributeDefinition) error {
+	ex := att.GenerateExample(g.API.RandomGenerator(), nil)
+	if ex == nil || ex == "-" {
+		return nil
+	}
+	b, err := json.Marshal(ex)
+	if err != nil {
+		return fmt.Errorf("failed to serialize example of response %s: %s", mr.Name, err)
+	}
+	mr.Examples[view] = string(b)
+	return nil
+}
+
+const mainT = `func main() {
+	addr := flag.String("addr", ":{{ .Port }}", "listen ` + "`" + `address` + "`" + `")
+	flag.Parse()
+
+	// Create service
+	service := goa.New({{ printf "%q" .API.Name }})
+
+	// Mount middleware
+	service.Use(middleware.RequestID())
+	service.Use(middleware.LogRequest(true))
+	service.Use(middleware.ErrorHandler(service, true))
+	service.Use(middleware.Recover())
+{{ if .API.SecuritySchemes }}
+	// Let all requests through
+{{ range .API.SecuritySchemes }}	{{ $.AppPkg }}.Use{{ goify .SchemeName true }}Middleware(service, mock.Middleware)
+{{ end }}{{ end }}
+	// Mount controllers
+{{ range .API.Resources }}	{{ $.AppPkg }}.Mount{{ goify .Name true }}Controller(service, New{{ goify .Name true }}Controller(service))
+{{ end }}
+	// Start service
+	if err := service.ListenAndServe(*addr); err != nil {
+		service.LogError("startup", "err", err)
+	}
+}
+`
+
+const ctrlT = `{{ $res := goify .Resource.Name true }}{{ $ctrl := printf "%sController" $res }}// {{ $ctrl }} implements the {{ .Resource.Name }} resource with mock responses.
+type {{ $ctrl }} struct {
+	*goa.Controller
+}
+
+// New{{ $ctrl }} creates a {{ .Resource.Name }} mock controller.
+func New{{ $ctrl }}(service *goa.Service) *{{ $ctrl }} {
+	return &{{ $ctrl }}{Controller: service.NewController({{ printf "%q" $ctrl }})}
+}
+{{ range .Actions }}{{ $action := goify .Action.Name true }}{{ $var := printf "%s%sResponses" (goify $.Resource.Name false) $action }}
+// {{ $action }} runs the {{ .Action.Name }} action.
+func (c *{{ $ctrl }}) {{ $action }}(ctx *{{ $.AppPkg }}.{{ $action }}{{ $res }}Context) error {
+{{ if .Action.WebSocket }}	c.{{ $action }}WSHandler(ctx).ServeHTTP(ctx.ResponseWriter, ctx.Request)
+	return nil
+}
+
+// {{ $action }}WSHandler establishes a websocket connection that echoes back the messages it receives.
+func (c *{{ $ctrl }}) {{ $action }}WSHandler(ctx *{{ $.AppPkg }}.{{ $action }}{{ $res }}Context) websocket.Handler {
+	return func(ws *websocket.Conn) {
+		io.Copy(ws, ws)
+	}
+}
+{{ else }}	return mock.Respond(ctx, {{ $var }})
+}
+
+// {{ $var }} lists the responses of the {{ .Action.Name }} action.
+var {{ $var }} = []*mock.Response{
+{{ range .Responses }}	{
+		Name:   {{ printf "%q" .Name }},
+		Status: {{ .Status }},
+{{ if .ContentType }}		ContentType: {{ printf "%q" .ContentType }},
+{{ end }}{{ if .Stream }}		Stream: true,
+{{ end }}{{ if .Examples }}		Examples: map[string]string{
+{{ range $view, $ex := .Examples }}			{{ printf "%q" $view }}: {{ printf "%q" $ex }},
+{{ end }}		},
+{{ end }}	},
+{{ end }}}
+{{ end }}{{ end }}`
@@ -0,0 +1,140 @@
+package genmock_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	genmock "github.com/goadesign/goa/goagen/gen_mock"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genmock.Generator
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genmock.NewGenerator(
+				genmock.API(&APIDefinition{Name: "test api"}),
+				genmock.OutDir("out_dir"),
+				genmock.Target("fake"),
+				genmock.AppPkg("example.com/app"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal("test api"))
+			Ω(generator.OutDir).Should(Equal("out_dir"))
+			Ω(generator.Target).Should(Equal("fake"))
+			Ω(generator.AppPkg).Should(Equal("example.com/app"))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genmock.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a bottle API", func() {
+		BeforeEach(func() {
+			var Bottle = MediaType("application/vnd.bottle+json", func() {
+				Attributes(func() {
+					Attribute("id", Integer, func() {
+						Example(42)
+					})
+					Attribute("name", String, func() {
+						Example("muscadet")
+					})
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			API("test api", func() {
+				Host("localhost:8081")
+				JWTSecurity("jwt", func() {
+					Header("Authorization")
+				})
+			})
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/bottles/:id"))
+					Security("jwt")
+					Response(OK, Bottle)
+					Response(NotFound)
+				})
+				Action("watch", func() {
+					Routing(GET("/bottles/:id/watch"))
+					Scheme("ws")
+					Response(SwitchingProtocols)
+				})
+			})
+		})
+
+		It("generates the mock server", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(HaveLen(3))
+
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "mock", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`addr := flag.String("addr", ":8081", "listen ` + "`address`" + `")`))
+			Ω(string(content)).Should(ContainSubstring(`app.UseJWTMiddleware(service, mock.Middleware)`))
+			Ω(string(content)).Should(ContainSubstring(`app.MountBottleController(service, NewBottleController(service))`))
+
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "mock", "bottle.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`func (c *BottleController) Show(ctx *app.ShowBottleContext) error {
+	return mock.Respond(ctx, bottleShowResponses)
+}`))
+			Ω(string(content)).Should(ContainSubstring(`var bottleShowResponses = []*mock.Response{
+	{
+		Name:        "OK",
+		Status:      200,
+		ContentType: "application/vnd.bottle+json",
+		Examples: map[string]string{
+			"default": "{\"id\":42,\"name\":\"muscadet\"}",
+			"tiny":    "{\"id\":42}",
+		},
+	},
+	{
+		Name:   "NotFound",
+		Status: 404,
+	},
+}`))
+			Ω(string(content)).Should(ContainSubstring(`c.WatchWSHandler(ctx).ServeHTTP(ctx.ResponseWriter, ctx.Request)`))
+		})
+	})
+})
@@ -0,0 +1,8 @@
+/*
+Package genopenapi provides a generator for the OpenAPI 3.x specification of an API.
+The generator walks the same design as the Swagger generator and produces both a JSON and a YAML
+representation of the specification. Compared to Swagger 2.0 the OpenAPI 3 specification makes it
+possible to describe multiple servers, request bodies per media type and reusable components.
+See https://spec.openapis.org/oas/v3.0.3 for more information.
+*/
+package genopenapi
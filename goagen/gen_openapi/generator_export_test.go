@@ -0,0 +1,4 @@
+package genopenapi
+
+// Export internal functions for testing.
+var JSONToYAML = jsonToYAML
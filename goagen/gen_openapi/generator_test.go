@@ -0,0 +1,49 @@
+package genopenapi_test
+
+import (
+	"github.com/goadesign/goa/design"
+	genopenapi "github.com/goadesign/goa/goagen/gen_openapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genopenapi.Generator
+
+	var args = struct {
+		api     *design.APIDefinition
+		outDir  string
+		version string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:  "out_dir",
+		version: genopenapi.Version31,
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genopenapi.NewGenerator(
+				genopenapi.API(args.api),
+				genopenapi.OutDir(args.outDir),
+				genopenapi.Version(args.version),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Version).Should(Equal(args.version))
+		})
+	})
+})
+
+var _ = Describe("jsonToYAML", func() {
+	It("converts JSON to YAML and keeps right number type", func() {
+		rawYAML, err := genopenapi.JSONToYAML([]byte(`{"id":1234567}`))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(rawYAML)).Should(Equal("id: 1234567\n"))
+	})
+})
@@ -0,0 +1,1095 @@
+package genopenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	genschema "github.com/goadesign/goa/goagen/gen_schema"
+)
+
+type (
+	// OpenAPI represents an instance of an OpenAPI 3.x document.
+	// See https://spec.openapis.org/oas/v3.0.3
+	OpenAPI struct {
+		OpenAPI      string               `json:"openapi"`
+		Info         *Info                `json:"info"`
+		Servers      []*Server            `json:"servers,omitempty"`
+		Paths        map[string]*PathItem `json:"paths"`
+		Components   *Components          `json:"components,omitempty"`
+		Tags         []*Tag               `json:"tags,omitempty"`
+		ExternalDocs *ExternalDocs        `json:"externalDocs,omitempty"`
+	}
+
+	// Info provides metadata about the API. The metadata can be used by the clients if needed,
+	// and can be presented in tools for convenience.
+	Info struct {
+		Title          string                    `json:"title"`
+		Description    string                    `json:"description,omitempty"`
+		TermsOfService string                    `json:"termsOfService,omitempty"`
+		Contact        *design.ContactDefinition `json:"contact,omitempty"`
+		License        *design.LicenseDefinition `json:"license,omitempty"`
+		Version        string                    `json:"version"`
+		Extensions     map[string]interface{}    `json:"-"`
+	}
+
+	// Server represents a server hosting the API.
+	Server struct {
+		// URL to the target host, may be relative to the location of the document.
+		URL string `json:"url"`
+		// Description is an optional string describing the host.
+		Description string `json:"description,omitempty"`
+	}
+
+	// PathItem describes the operations available on a single path.
+	PathItem struct {
+		// Get defines a GET operation on this path.
+		Get *Operation `json:"get,omitempty"`
+		// Put defines a PUT operation on this path.
+		Put *Operation `json:"put,omitempty"`
+		// Post defines a POST operation on this path.
+		Post *Operation `json:"post,omitempty"`
+		// Delete defines a DELETE operation on this path.
+		Delete *Operation `json:"delete,omitempty"`
+		// Options defines a OPTIONS operation on this path.
+		Options *Operation `json:"options,omitempty"`
+		// Head defines a HEAD operation on this path.
+		Head *Operation `json:"head,omitempty"`
+		// Patch defines a PATCH operation on this path.
+		Patch *Operation `json:"patch,omitempty"`
+		// Trace defines a TRACE operation on this path.
+		Trace *Operation `json:"trace,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Operation describes a single API operation on a path.
+	Operation struct {
+		// Tags is a list of tags for API documentation control.
+		Tags []string `json:"tags,omitempty"`
+		// Summary is a short summary of what the operation does.
+		Summary string `json:"summary,omitempty"`
+		// Description is a verbose explanation of the operation behavior.
+		// CommonMark syntax can be used for rich text representation.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs points to additional external documentation for this operation.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// OperationID is a unique string used to identify the operation.
+		OperationID string `json:"operationId,omitempty"`
+		// Parameters is a list of parameters that are applicable for this operation.
+		Parameters []*Parameter `json:"parameters,omitempty"`
+		// RequestBody is the request body applicable for this operation.
+		RequestBody *RequestBody `json:"requestBody,omitempty"`
+		// Responses is the list of possible responses as they are returned from executing
+		// this operation.
+		Responses map[string]*Response `json:"responses"`
+		// Deprecated declares this operation to be deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Security is a declaration of which security schemes are applied for this operation.
+		Security []map[string][]string `json:"security,omitempty"`
+		// Servers overrides the API servers for this operation.
+		Servers []*Server `json:"servers,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Parameter describes a single operation parameter.
+	Parameter struct {
+		// Name of the parameter. Parameter names are case sensitive.
+		Name string `json:"name"`
+		// In is the location of the parameter.
+		// Possible values are "query", "header", "path" or "cookie".
+		In string `json:"in"`
+		// Description is a brief description of the parameter.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this parameter is mandatory.
+		Required bool `json:"required"`
+		// Schema defines the type used for the parameter.
+		Schema *Schema `json:"schema,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// RequestBody describes a single request body.
+	RequestBody struct {
+		// Description is a brief description of the request body.
+		Description string `json:"description,omitempty"`
+		// Content maps the supported media types to their schemas.
+		Content map[string]*MediaType `json:"content"`
+		// Required determines if the request body is required in the request.
+		Required bool `json:"required,omitempty"`
+	}
+
+	// MediaType provides the schema and examples for the media type identified by its key.
+	MediaType struct {
+		// Schema defines the content of the request or response.
+		Schema *Schema `json:"schema,omitempty"`
+	}
+
+	// Response describes an operation response.
+	Response struct {
+		// Description of the response. CommonMark syntax can be used for rich text
+		// representation.
+		Description string `json:"description"`
+		// Headers is a list of headers that are sent with the response.
+		Headers map[string]*Header `json:"headers,omitempty"`
+		// Content maps the response media types to their schemas.
+		Content map[string]*MediaType `json:"content,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Header represents a response header.
+	Header struct {
+		// Description is a brief description of the header.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this header is mandatory.
+		Required bool `json:"required,omitempty"`
+		// Schema defines the type used for the header.
+		Schema *Schema `json:"schema,omitempty"`
+	}
+
+	// Components holds a set of reusable objects for different aspects of the API.
+	Components struct {
+		// Schemas contains the schemas of the API user types and media type views.
+		Schemas map[string]*Schema `json:"schemas,omitempty"`
+		// Responses contains the responses defined at the API level.
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// Parameters contains the base parameters common to all API endpoints.
+		Parameters map[string]*Parameter `json:"parameters,omitempty"`
+		// SecuritySchemes contains the API security schemes.
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme defines a security scheme that can be used by the operations.
+	SecurityScheme struct {
+		// Type of the security scheme. Valid values are "apiKey", "http", "oauth2",
+		// "openIdConnect" or, with OpenAPI 3.1, "mutualTLS".
+		Type string `json:"type"`
+		// Description for security scheme.
+		Description string `json:"description,omitempty"`
+		// Name of the header, query or cookie parameter to be used when type is "apiKey".
+		Name string `json:"name,omitempty"`
+		// In is the location of the API key when type is "apiKey".
+		In string `json:"in,omitempty"`
+		// Scheme is the name of the HTTP Authorization scheme when type is "http".
+		Scheme string `json:"scheme,omitempty"`
+		// BearerFormat is a hint to the client to identify how the bearer token is formatted.
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		// Flows contains configuration information for the flow types supported.
+		Flows *OAuthFlows `json:"flows,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OAuthFlows allows configuration of the supported OAuth2 flows.
+	OAuthFlows struct {
+		Implicit          *OAuthFlow `json:"implicit,omitempty"`
+		Password          *OAuthFlow `json:"password,omitempty"`
+		ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+		AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	}
+
+	// OAuthFlow contains the configuration details for a supported OAuth2 flow.
+	OAuthFlow struct {
+		// AuthorizationURL is the authorization URL to be used for this flow.
+		AuthorizationURL string `json:"authorizationUrl,omitempty"`
+		// TokenURL is the token URL to be used for this flow.
+		TokenURL string `json:"tokenUrl,omitempty"`
+		// Scopes lists the available scopes for the OAuth2 security scheme.
+		Scopes map[string]string `json:"scopes"`
+	}
+
+	// Tag adds metadata to a single tag that is used by the Operation Object.
+	Tag struct {
+		// Name of the tag.
+		Name string `json:"name"`
+		// Description is a short description of the tag.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs is additional external documentation for this tag.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// ExternalDocs allows referencing an external resource for extended documentation.
+	ExternalDocs struct {
+		// Description is a short description of the target documentation.
+		Description string `json:"description,omitempty"`
+		// URL for the target documentation.
+		URL string `json:"url"`
+	}
+
+	// Schema is the OpenAPI flavor of a JSON schema. It is produced from the JSON schemas
+	// generated by the genschema package.
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Title                string             `json:"title,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		AdditionalProperties bool               `json:"additionalProperties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		DefaultValue         interface{}        `json:"default,omitempty"`
+		Example              interface{}        `json:"example,omitempty"`
+		Examples             []interface{}      `json:"examples,omitempty"`
+		ReadOnly             bool               `json:"readOnly,omitempty"`
+		Enum                 []interface{}      `json:"enum,omitempty"`
+		Pattern              string             `json:"pattern,omitempty"`
+		Minimum              *float64           `json:"minimum,omitempty"`
+		Maximum              *float64           `json:"maximum,omitempty"`
+		MinLength            *int               `json:"minLength,omitempty"`
+		MaxLength            *int               `json:"maxLength,omitempty"`
+		MinItems             *int               `json:"minItems,omitempty"`
+		MaxItems             *int               `json:"maxItems,omitempty"`
+		AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	}
+
+	// These types are used in marshalJSON() to avoid recursive call of json.Marshal().
+	_Info           Info
+	_PathItem       PathItem
+	_Operation      Operation
+	_Parameter      Parameter
+	_Response       Response
+	_SecurityScheme SecurityScheme
+	_Tag            Tag
+)
+
+const (
+	// Version30 is the default OpenAPI specification version generated.
+	Version30 = "3.0.3"
+	// Version31 is the OpenAPI 3.1 specification version.
+	Version31 = "3.1.0"
+)
+
+// builder holds the state needed while walking the API definition.
+type builder struct {
+	api      *design.APIDefinition
+	spec     *OpenAPI
+	version  string
+	basePath string
+	consumes []string
+}
+
+func marshalJSON(v interface{}, extensions map[string]interface{}) ([]byte, error) {
+	marshaled, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(extensions) == 0 {
+		return marshaled, nil
+	}
+	var unmarshaled map[string]interface{}
+	if err := json.Unmarshal(marshaled, &unmarshaled); err != nil {
+		return nil, err
+	}
+	for k, v := range extensions {
+		unmarshaled[k] = v
+	}
+	return json.Marshal(unmarshaled)
+}
+
+// MarshalJSON returns the JSON encoding of i.
+func (i Info) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Info(i), i.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p PathItem) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_PathItem(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of o.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Operation(o), o.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Parameter(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r Response) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Response(r), r.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of s.
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_SecurityScheme(s), s.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of t.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Tag(t), t.Extensions)
+}
+
+// New creates an OpenAPI document from an API definition. version is the OpenAPI specification
+// version, it must be either a 3.0.x or a 3.1.x version and defaults to Version30 if empty.
+func New(api *design.APIDefinition, version string) (*OpenAPI, error) {
+	if api == nil {
+		return nil, nil
+	}
+	if version == "" {
+		version = Version30
+	}
+	if !strings.HasPrefix(version, "3.0") && !strings.HasPrefix(version, "3.1") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %#v, must be 3.0.x or 3.1.x", version)
+	}
+	b := &builder{api: api, version: version}
+	return b.build()
+}
+
+func (b *builder) build() (*OpenAPI, error) {
+	api := b.api
+	b.basePath = api.BasePath
+	if hasAbsoluteRoutes(api) || len(design.ExtractWildcards(api.BasePath)) > 0 {
+		b.basePath = ""
+	}
+	for _, c := range api.Consumes {
+		b.consumes = append(b.consumes, c.MIMETypes...)
+	}
+	if len(b.consumes) == 0 {
+		b.consumes = []string{"application/json"}
+	}
+	b.spec = &OpenAPI{
+		OpenAPI: b.version,
+		Info: &Info{
+			Title:          api.Title,
+			Description:    api.Description,
+			TermsOfService: api.TermsOfService,
+			Contact:        api.Contact,
+			License:        api.License,
+			Version:        api.Version,
+			Extensions:     extensionsFromDefinition(api.Metadata),
+		},
+		Servers:      serversFromSchemes(api.Schemes, api.Host, b.basePath),
+		Paths:        make(map[string]*PathItem),
+		Tags:         tagsFromDefinition(api.Metadata),
+		ExternalDocs: docsFromDefinition(api.Docs),
+		Components:   &Components{SecuritySchemes: securitySchemesFromDefinition(api.SecuritySchemes, b.is31())},
+	}
+	if api.Title == "" {
+		b.spec.Info.Title = api.Name
+	}
+
+	if api.Params != nil {
+		params, err := b.paramsFromDefinition(api.Params, api.BasePath)
+		if err != nil {
+			return nil, err
+		}
+		if len(params) > 0 {
+			b.spec.Components.Parameters = make(map[string]*Parameter, len(params))
+			for _, p := range params {
+				b.spec.Components.Parameters[p.Name] = p
+			}
+		}
+	}
+
+	err := api.IterateResponses(func(r *design.ResponseDefinition) error {
+		res, err := b.responseFromDefinition(r)
+		if err != nil {
+			return err
+		}
+		if b.spec.Components.Responses == nil {
+			b.spec.Components.Responses = make(map[string]*Response)
+		}
+		b.spec.Components.Responses[r.Name] = res
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			if !mustGenerate(fs.Metadata) {
+				return nil
+			}
+			return b.buildPathFromFileServer(fs)
+		})
+		if err != nil {
+			return err
+		}
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if !mustGenerate(a.Metadata) {
+				return nil
+			}
+			for _, route := range a.Routes {
+				if err := b.buildPathFromDefinition(route); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure every user type and media type view is described even if not used directly
+	// by an action.
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		if mustGenerate(ut.Metadata) {
+			genschema.TypeRef(api, ut)
+		}
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if !mustGenerate(mt.Metadata) {
+			return nil
+		}
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			if _, _, err := mt.Project(v.Name); err == nil {
+				genschema.MediaTypeRef(api, mt, v.Name)
+			}
+			return nil
+		})
+	})
+	if len(genschema.Definitions) > 0 {
+		b.spec.Components.Schemas = make(map[string]*Schema, len(genschema.Definitions))
+		for n, d := range genschema.Definitions {
+			b.spec.Components.Schemas[n] = b.schema(d)
+		}
+	}
+
+	if len(b.spec.Components.Schemas) == 0 && len(b.spec.Components.Responses) == 0 &&
+		len(b.spec.Components.Parameters) == 0 && len(b.spec.Components.SecuritySchemes) == 0 {
+		b.spec.Components = nil
+	}
+	return b.spec, nil
+}
+
+// is31 returns true if the document being built follows the OpenAPI 3.1 specification.
+func (b *builder) is31() bool {
+	return strings.HasPrefix(b.version, "3.1")
+}
+
+// schema converts the given JSON schema into an OpenAPI schema, rewriting references to point
+// to the document components.
+func (b *builder) schema(js *genschema.JSONSchema) *Schema {
+	if js == nil {
+		return nil
+	}
+	s := &Schema{
+		Ref:                  componentRef(js.Ref),
+		Title:                js.Title,
+		Type:                 string(js.Type),
+		Format:               js.Format,
+		Description:          js.Description,
+		Items:                b.schema(js.Items),
+		AdditionalProperties: js.AdditionalProperties,
+		Required:             js.Required,
+		DefaultValue:         js.DefaultValue,
+		ReadOnly:             js.ReadOnly,
+		Enum:                 js.Enum,
+		Pattern:              js.Pattern,
+		Minimum:              js.Minimum,
+		Maximum:              js.Maximum,
+		MinLength:            js.MinLength,
+		MaxLength:            js.MaxLength,
+		MinItems:             js.MinItems,
+		MaxItems:             js.MaxItems,
+	}
+	if js.Type == genschema.JSONFile {
+		// OpenAPI 3 describes file content as binary strings.
+		s.Type = genschema.JSONString
+		s.Format = "binary"
+	}
+	if js.Example != nil {
+		if b.is31() {
+			s.Examples = []interface{}{js.Example}
+		} else {
+			s.Example = js.Example
+		}
+	}
+	if len(js.Properties) > 0 {
+		s.Properties = make(map[string]*Schema, len(js.Properties))
+		for n, p := range js.Properties {
+			s.Properties[n] = b.schema(p)
+		}
+	}
+	for _, a := range js.AnyOf {
+		s.AnyOf = append(s.AnyOf, b.schema(a))
+	}
+	return s
+}
+
+// componentRef converts a JSON schema definition reference into a reference to the document
+// components.
+func componentRef(ref string) string {
+	return strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+}
+
+// mustGenerate returns true if the metadata indicates that an OpenAPI specification should be
+// generated, false otherwise. It uses the same "swagger:generate" key as the Swagger generator.
+func mustGenerate(meta dslengine.MetadataDefinition) bool {
+	if m, ok := meta["swagger:generate"]; ok {
+		if len(m) > 0 && m[0] == "false" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAbsoluteRoutes returns true if any action exposed by the API uses an absolute route or if the
+// API has file servers. In this case the server URLs cannot include the base path and all paths
+// must be absolute.
+func hasAbsoluteRoutes(api *design.APIDefinition) bool {
+	for _, res := range api.Resources {
+		for _, fs := range res.FileServers {
+			if mustGenerate(fs.Metadata) {
+				return true
+			}
+		}
+		for _, a := range res.Actions {
+			if !mustGenerate(a.Metadata) {
+				continue
+			}
+			for _, ro := range a.Routes {
+				if ro.IsAbsolute() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// serversFromSchemes returns one server per HTTP scheme. Websocket schemes are not described by
+// OpenAPI and are skipped.
+func serversFromSchemes(schemes []string, host, basePath string) []*Server {
+	if host == "" {
+		if basePath == "" || basePath == "/" {
+			return nil
+		}
+		return []*Server{{URL: basePath}}
+	}
+	var servers []*Server
+	for _, s := range schemes {
+		if s != "http" && s != "https" {
+			continue
+		}
+		servers = append(servers, &Server{URL: fmt.Sprintf("%s://%s%s", s, host, basePath)})
+	}
+	if len(servers) == 0 {
+		servers = []*Server{{URL: fmt.Sprintf("http://%s%s", host, basePath)}}
+	}
+	return servers
+}
+
+// securitySchemesFromDefinition builds the security scheme components, mutual TLS schemes are only
+// described by OpenAPI 3.1.
+func securitySchemesFromDefinition(schemes []*design.SecuritySchemeDefinition, is31 bool) map[string]*SecurityScheme {
+	if len(schemes) == 0 {
+		return nil
+	}
+	defs := make(map[string]*SecurityScheme)
+	for _, scheme := range schemes {
+		def := &SecurityScheme{
+			Description: scheme.Description,
+			Extensions:  extensionsFromDefinition(scheme.Metadata),
+		}
+		switch scheme.Kind {
+		case design.BasicAuthSecurityKind:
+			def.Type = "http"
+			def.Scheme = "basic"
+		case design.APIKeySecurityKind:
+			def.Type = "apiKey"
+			def.Name = scheme.Name
+			def.In = scheme.In
+		case design.JWTSecurityKind:
+			def.Type = "http"
+			def.Scheme = "bearer"
+			def.BearerFormat = "JWT"
+			if scheme.TokenURL != "" {
+				def.Description += fmt.Sprintf("\n\n**Token URL**: %s", scheme.TokenURL)
+			}
+			if len(scheme.Scopes) != 0 {
+				def.Description += fmt.Sprintf("\n\n**Security Scopes**:\n%s", scopesMapList(scheme.Scopes))
+			}
+		case design.MutualTLSSecurityKind:
+			if !is31 {
+				continue
+			}
+			def.Type = "mutualTLS"
+		case design.OAuth2SecurityKind:
+			def.Type = "oauth2"
+			scopes := scheme.Scopes
+			if scopes == nil {
+				scopes = make(map[string]string)
+			}
+			flow := &OAuthFlow{
+				AuthorizationURL: scheme.AuthorizationURL,
+				TokenURL:         scheme.TokenURL,
+				Scopes:           scopes,
+			}
+			def.Flows = &OAuthFlows{}
+			switch scheme.Flow {
+			case "implicit":
+				def.Flows.Implicit = flow
+			case "password":
+				def.Flows.Password = flow
+			case "application":
+				def.Flows.ClientCredentials = flow
+			case "accessCode":
+				def.Flows.AuthorizationCode = flow
+			}
+		default:
+			continue
+		}
+		defs[scheme.SchemeName] = def
+	}
+	return defs
+}
+
+func scopesMapList(scopes map[string]string) string {
+	names := []string{}
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  * `%s`: %s", name, scopes[name]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func tagsFromDefinition(mdata dslengine.MetadataDefinition) (tags []*Tag) {
+	var keys []string
+	for k := range mdata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 {
+			continue
+		}
+		if chunks[0] != "swagger" || chunks[1] != "tag" {
+			continue
+		}
+
+		tag := &Tag{Name: chunks[2]}
+		if desc := mdata[key+":desc"]; len(desc) != 0 {
+			tag.Description = desc[0]
+		}
+		docs := &ExternalDocs{}
+		if u := mdata[key+":url"]; len(u) != 0 {
+			docs.URL = u[0]
+		}
+		if desc := mdata[key+":url:desc"]; len(desc) != 0 {
+			docs.Description = desc[0]
+		}
+		if docs.URL != "" || docs.Description != "" {
+			tag.ExternalDocs = docs
+		}
+		tag.Extensions = extensionsFromDefinition(mdata)
+
+		tags = append(tags, tag)
+	}
+	return
+}
+
+func tagNamesFromDefinitions(mdatas ...dslengine.MetadataDefinition) (tagNames []string) {
+	for _, mdata := range mdatas {
+		for _, tag := range tagsFromDefinition(mdata) {
+			tagNames = append(tagNames, tag.Name)
+		}
+	}
+	return
+}
+
+func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) string {
+	if mdata, ok := metadata["swagger:summary"]; ok && len(mdata) > 0 {
+		return mdata[0]
+	}
+	return name
+}
+
+func extensionsFromDefinition(mdata dslengine.MetadataDefinition) map[string]interface{} {
+	extensions := make(map[string]interface{})
+	for key, value := range mdata {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 {
+			continue
+		}
+		if chunks[0] != "swagger" || chunks[1] != "extension" {
+			continue
+		}
+		if !strings.HasPrefix(chunks[2], "x-") {
+			continue
+		}
+		val := value[0]
+		ival := interface{}(val)
+		if err := json.Unmarshal([]byte(val), &ival); err != nil {
+			extensions[chunks[2]] = val
+			continue
+		}
+		extensions[chunks[2]] = ival
+	}
+	if len(extensions) == 0 {
+		return nil
+	}
+	return extensions
+}
+
+func docsFromDefinition(docs *design.DocsDefinition) *ExternalDocs {
+	if docs == nil {
+		return nil
+	}
+	return &ExternalDocs{
+		Description: docs.Description,
+		URL:         docs.URL,
+	}
+}
+
+func (b *builder) paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*Parameter, error) {
+	if params == nil {
+		return nil, nil
+	}
+	obj := params.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("invalid parameters definition, not an object")
+	}
+	var res []*Parameter
+	wildcards := design.ExtractWildcards(path)
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		in := "query"
+		required := params.IsRequired(n)
+		for _, w := range wildcards {
+			if n == w {
+				in = "path"
+				required = true
+				break
+			}
+		}
+		res = append(res, b.paramFor(at, n, in, required))
+		return nil
+	})
+	return res, nil
+}
+
+func (b *builder) paramsFromHeaders(action *design.ActionDefinition) []*Parameter {
+	var params []*Parameter
+	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
+		params = append(params, b.paramFor(header, name, "header", required))
+		return nil
+	})
+	return params
+}
+
+func (b *builder) paramFor(at *design.AttributeDefinition, name, in string, required bool) *Parameter {
+	s := b.schema(genschema.AttributeSchema(b.api, at))
+	desc := s.Description
+	s.Description = ""
+	return &Parameter{
+		In:          in,
+		Name:        name,
+		Description: desc,
+		Required:    required,
+		Schema:      s,
+		Extensions:  extensionsFromDefinition(at.Metadata),
+	}
+}
+
+func (b *builder) headersFromDefinition(headers *design.AttributeDefinition) (map[string]*Header, error) {
+	if headers == nil {
+		return nil, nil
+	}
+	obj := headers.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("invalid headers definition, not an object")
+	}
+	res := make(map[string]*Header)
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		s := b.schema(genschema.AttributeSchema(b.api, at))
+		desc := s.Description
+		s.Description = ""
+		res[n] = &Header{
+			Description: desc,
+			Required:    headers.IsRequired(n),
+			Schema:      s,
+		}
+		return nil
+	})
+	return res, nil
+}
+
+func (b *builder) responseFromDefinition(r *design.ResponseDefinition) (*Response, error) {
+	var schema *Schema
+	if r.MediaType != "" {
+		if mt, ok := b.api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+			view := r.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			schema = &Schema{Ref: componentRef(genschema.MediaTypeRef(b.api, mt, view))}
+		}
+	}
+	if schema == nil && r.Type != nil {
+		schema = b.schema(genschema.TypeSchema(b.api, r.Type))
+	}
+	headers, err := b.headersFromDefinition(r.Headers)
+	if err != nil {
+		return nil, err
+	}
+	desc := r.Description
+	if desc == "" {
+		desc = http.StatusText(r.Status)
+	}
+	res := &Response{
+		Description: desc,
+		Headers:     headers,
+		Extensions:  extensionsFromDefinition(r.Metadata),
+	}
+	if r.MediaType != "" {
+		res.Content = map[string]*MediaType{r.MediaType: {Schema: schema}}
+	}
+	return res, nil
+}
+
+func (b *builder) requestBodyFromDefinition(action *design.ActionDefinition) *RequestBody {
+	if action.Payload == nil {
+		return nil
+	}
+	schema := b.schema(genschema.TypeSchema(b.api, action.Payload))
+	body := &RequestBody{
+		Description: action.Payload.Description,
+		Content:     make(map[string]*MediaType),
+		Required:    !action.PayloadOptional,
+	}
+	if action.PayloadMultipart {
+		body.Content["multipart/form-data"] = &MediaType{Schema: schema}
+		return body
+	}
+	for _, c := range b.consumes {
+		body.Content[c] = &MediaType{Schema: schema}
+	}
+	return body
+}
+
+func (b *builder) buildPathFromFileServer(fs *design.FileServerDefinition) error {
+	wcs := design.ExtractWildcards(fs.RequestPath)
+	var params []*Parameter
+	if len(wcs) > 0 {
+		params = []*Parameter{{
+			In:          "path",
+			Name:        wcs[0],
+			Description: "Relative file path",
+			Required:    true,
+			Schema:      &Schema{Type: genschema.JSONString},
+		}}
+	}
+
+	responses := map[string]*Response{
+		"200": {
+			Description: "File downloaded",
+			Content: map[string]*MediaType{
+				"application/octet-stream": {Schema: &Schema{Type: genschema.JSONString, Format: "binary"}},
+			},
+		},
+	}
+	if len(wcs) > 0 {
+		schema := b.schema(genschema.TypeSchema(b.api, design.ErrorMedia))
+		responses["404"] = &Response{
+			Description: "File not found",
+			Content:     map[string]*MediaType{design.ErrorMediaIdentifier: {Schema: schema}},
+		}
+	}
+
+	operation := &Operation{
+		Description:  fs.Description,
+		Summary:      summaryFromDefinition(fmt.Sprintf("Download %s", fs.FilePath), fs.Metadata),
+		ExternalDocs: docsFromDefinition(fs.Docs),
+		OperationID:  fmt.Sprintf("%s#%s", fs.Parent.Name, fs.RequestPath),
+		Parameters:   params,
+		Responses:    responses,
+	}
+	b.applySecurity(operation, fs.Security)
+
+	p := b.pathItem(fs.RequestPath)
+	p.Get = operation
+	p.Extensions = extensionsFromDefinition(fs.Metadata)
+	return nil
+}
+
+func (b *builder) buildPathFromDefinition(route *design.RouteDefinition) error {
+	action := route.Parent
+
+	tagNames := tagNamesFromDefinitions(action.Parent.Metadata, action.Metadata)
+	if len(tagNames) == 0 {
+		// By default tag with resource name
+		tagNames = []string{action.Parent.Name}
+	}
+	params, err := b.paramsFromDefinition(action.AllParams(), route.FullPath())
+	if err != nil {
+		return err
+	}
+	params = append(params, b.paramsFromHeaders(action)...)
+
+	responses := make(map[string]*Response, len(action.Responses))
+	err = action.IterateResponses(func(r *design.ResponseDefinition) error {
+		resp, err := b.responseFromDefinition(r)
+		if err != nil {
+			return err
+		}
+		responses[strconv.Itoa(r.Status)] = resp
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(responses) == 0 {
+		responses["default"] = &Response{Description: "Default response"}
+	}
+
+	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	for i, rt := range action.Routes {
+		if rt == route && i > 0 {
+			operationID = fmt.Sprintf("%s#%d", operationID, i)
+			break
+		}
+	}
+
+	operation := &Operation{
+		Tags:         tagNames,
+		Description:  action.Description,
+		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
+		ExternalDocs: docsFromDefinition(action.Docs),
+		OperationID:  operationID,
+		Parameters:   params,
+		RequestBody:  b.requestBodyFromDefinition(action),
+		Responses:    responses,
+		Extensions:   extensionsFromDefinition(route.Metadata),
+	}
+	if len(action.Schemes) > 0 {
+		servers := serversFromSchemes(action.Schemes, b.api.Host, b.basePath)
+		if len(servers) > 0 && !sameServers(servers, b.spec.Servers) {
+			operation.Servers = servers
+		}
+	}
+	b.applySecurity(operation, action.Security)
+	applyAuthorization(operation, action.Authorization)
+
+	p := b.pathItem(route.FullPath())
+	switch route.Verb {
+	case "GET":
+		p.Get = operation
+	case "PUT":
+		p.Put = operation
+	case "POST":
+		p.Post = operation
+	case "DELETE":
+		p.Delete = operation
+	case "OPTIONS":
+		p.Options = operation
+	case "HEAD":
+		p.Head = operation
+	case "PATCH":
+		p.Patch = operation
+	case "TRACE":
+		p.Trace = operation
+	}
+	p.Extensions = extensionsFromDefinition(action.Metadata)
+	return nil
+}
+
+// pathItem returns the path item for the given goa path, creating it if needed. The path
+// wildcards are converted to OpenAPI templates and the base path is trimmed if the servers
+// include it.
+func (b *builder) pathItem(path string) *PathItem {
+	toTemplate := func(p string) string {
+		return design.WildcardRegex.ReplaceAllStringFunc(p, func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		})
+	}
+	key := toTemplate(path)
+	if bp := toTemplate(b.basePath); bp != "" && bp != "/" {
+		key = strings.TrimPrefix(key, bp)
+	}
+	if key == "" {
+		key = "/"
+	}
+	p, ok := b.spec.Paths[key]
+	if !ok {
+		p = new(PathItem)
+		b.spec.Paths[key] = p
+	}
+	return p
+}
+
+// applyAuthorization documents the authorization policies of the action with the
+// "x-authorize" operation extension.
+func applyAuthorization(operation *Operation, authz *design.AuthorizationDefinition) {
+	if authz == nil || len(authz.Policies) == 0 {
+		return
+	}
+	if operation.Extensions == nil {
+		operation.Extensions = make(map[string]interface{})
+	}
+	operation.Extensions["x-authorize"] = authz.Policies
+}
+
+func (b *builder) applySecurity(operation *Operation, security *design.SecurityDefinition) {
+	if security == nil || security.Scheme.Kind == design.NoSecurityKind {
+		return
+	}
+	if security.Scheme.Kind == design.MutualTLSSecurityKind && !b.is31() {
+		if operation.Description != "" {
+			operation.Description += "\n\n"
+		}
+		operation.Description += "Requires a client certificate (mutual TLS)."
+		return
+	}
+	scopes := security.Scopes
+	if scopes == nil {
+		scopes = make([]string, 0)
+	}
+	if security.Scheme.Kind != design.OAuth2SecurityKind {
+		if security.Scheme.Kind == design.JWTSecurityKind && len(scopes) > 0 {
+			if operation.Description != "" {
+				operation.Description += "\n\n"
+			}
+			operation.Description += fmt.Sprintf("Required security scopes:\n%s", scopesList(scopes))
+		}
+		// OpenAPI 3.0 only allows scopes for OAuth2 schemes.
+		if !b.is31() {
+			scopes = make([]string, 0)
+		}
+	}
+	operation.Security = []map[string][]string{{security.Scheme.SchemeName: scopes}}
+}
+
+func scopesList(scopes []string) string {
+	sorted := make([]string, len(scopes))
+	copy(sorted, scopes)
+	sort.Strings(sorted)
+
+	var lines []string
+	for _, scope := range sorted {
+		lines = append(lines, fmt.Sprintf("  * `%s`", scope))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sameServers(a, b []*Server) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].URL != b[i].URL {
+			return false
+		}
+	}
+	return true
+}
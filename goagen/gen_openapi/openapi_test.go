@@ -0,0 +1,310 @@
+package genopenapi_test
+
+import (
+	"encoding/json"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	genopenapi "github.com/goadesign/goa/goagen/gen_openapi"
+	genschema "github.com/goadesign/goa/goagen/gen_schema"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var version string
+	var spec *genopenapi.OpenAPI
+	var newErr error
+
+	BeforeEach(func() {
+		version = ""
+		spec = nil
+		newErr = nil
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		spec, newErr = genopenapi.New(Design, version)
+	})
+
+	Context("with a valid API definition", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Title("title")
+				Description("description")
+				Host("goa.design")
+				Scheme("http", "https")
+				BasePath("/base")
+				Metadata("swagger:tag:tag")
+				Metadata("swagger:tag:tag:desc", "Tag desc.")
+				Metadata("swagger:extension:x-api", `{"foo":"bar"}`)
+			})
+		})
+
+		It("sets the basic fields", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(spec.OpenAPI).Should(Equal(genopenapi.Version30))
+			Ω(spec.Info.Title).Should(Equal("title"))
+			Ω(spec.Info.Description).Should(Equal("description"))
+			Ω(spec.Info.Extensions).Should(HaveKey("x-api"))
+			Ω(spec.Tags).Should(Equal([]*genopenapi.Tag{{Name: "tag", Description: "Tag desc.", Extensions: spec.Info.Extensions}}))
+		})
+
+		It("creates one server per scheme", func() {
+			Ω(spec.Servers).Should(Equal([]*genopenapi.Server{
+				{URL: "http://goa.design/base"},
+				{URL: "https://goa.design/base"},
+			}))
+		})
+
+		Context("with an unsupported version", func() {
+			BeforeEach(func() {
+				version = "2.0"
+			})
+
+			It("returns an error", func() {
+				Ω(newErr).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with actions", func() {
+		BeforeEach(func() {
+			BottleMedia := MediaType("application/vnd.goa.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+					Required("id")
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			BottlePayload := Type("BottlePayload", func() {
+				Attribute("name", String, func() {
+					MinLength(1)
+				})
+				Required("name")
+			})
+			API("test", func() {
+				Consumes("application/json")
+				Consumes("application/xml")
+				OAuth2Security("oauth2", func() {
+					AccessCodeFlow("http://goa.design/authorization", "http://goa.design/token")
+					Scope("api:read", "Read access")
+				})
+			})
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer, "Bottle ID")
+						Param("view", String, func() {
+							Enum("default", "tiny")
+						})
+					})
+					Headers(func() {
+						Header("X-Request-Id")
+					})
+					Security("oauth2", func() {
+						Scope("api:read")
+					})
+					Response(OK, BottleMedia)
+					Response(NotFound)
+				})
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(BottlePayload)
+					Response(Created, func() {
+						Headers(func() {
+							Header("Location", String, "Bottle href")
+							Required("Location")
+						})
+					})
+				})
+			})
+		})
+
+		It("describes path and query parameters", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(spec.Paths).Should(HaveKey("/bottles/{id}"))
+			op := spec.Paths["/bottles/{id}"].Get
+			Ω(op).ShouldNot(BeNil())
+			Ω(op.OperationID).Should(Equal("bottle#show"))
+			Ω(op.Parameters).Should(HaveLen(3))
+			Ω(op.Parameters[0]).Should(Equal(&genopenapi.Parameter{
+				Name: "id", In: "path", Description: "Bottle ID", Required: true,
+				Schema: &genopenapi.Schema{Type: "integer", Format: "int64", Example: op.Parameters[0].Schema.Example},
+			}))
+			Ω(op.Parameters[1].In).Should(Equal("query"))
+			Ω(op.Parameters[1].Schema.Enum).Should(Equal([]interface{}{"default", "tiny"}))
+			Ω(op.Parameters[2].In).Should(Equal("header"))
+			Ω(op.Parameters[2].Name).Should(Equal("X-Request-Id"))
+		})
+
+		It("references the media type views in responses", func() {
+			op := spec.Paths["/bottles/{id}"].Get
+			Ω(op.Responses).Should(HaveKey("200"))
+			Ω(op.Responses["200"].Content).Should(HaveKey("application/vnd.goa.bottle"))
+			ref := op.Responses["200"].Content["application/vnd.goa.bottle"].Schema.Ref
+			Ω(ref).Should(Equal("#/components/schemas/GoaBottle"))
+			Ω(op.Responses["404"].Description).Should(Equal("Not Found"))
+			Ω(op.Responses["404"].Content).Should(BeNil())
+		})
+
+		It("generates components for every view", func() {
+			Ω(spec.Components.Schemas).Should(HaveKey("GoaBottle"))
+			Ω(spec.Components.Schemas).Should(HaveKey("GoaBottleTiny"))
+			Ω(spec.Components.Schemas).Should(HaveKey("BottlePayload"))
+			Ω(spec.Components.Schemas["GoaBottleTiny"].Properties).Should(HaveLen(1))
+		})
+
+		It("describes the request body for each consumed media type", func() {
+			op := spec.Paths["/bottles"].Post
+			Ω(op).ShouldNot(BeNil())
+			Ω(op.RequestBody).ShouldNot(BeNil())
+			Ω(op.RequestBody.Required).Should(BeTrue())
+			Ω(op.RequestBody.Content).Should(HaveLen(2))
+			Ω(op.RequestBody.Content["application/json"].Schema.Ref).Should(Equal("#/components/schemas/BottlePayload"))
+			Ω(op.RequestBody.Content["application/xml"].Schema.Ref).Should(Equal("#/components/schemas/BottlePayload"))
+		})
+
+		It("describes the response headers", func() {
+			op := spec.Paths["/bottles"].Post
+			Ω(op.Responses["201"].Headers).Should(HaveKey("Location"))
+			h := op.Responses["201"].Headers["Location"]
+			Ω(h.Description).Should(Equal("Bottle href"))
+			Ω(h.Required).Should(BeTrue())
+			Ω(h.Schema.Type).Should(Equal("string"))
+		})
+
+		It("describes the security schemes", func() {
+			Ω(spec.Components.SecuritySchemes).Should(HaveKey("oauth2"))
+			s := spec.Components.SecuritySchemes["oauth2"]
+			Ω(s.Type).Should(Equal("oauth2"))
+			Ω(s.Flows.AuthorizationCode).Should(Equal(&genopenapi.OAuthFlow{
+				AuthorizationURL: "http://goa.design/authorization",
+				TokenURL:         "http://goa.design/token",
+				Scopes:           map[string]string{"api:read": "Read access"},
+			}))
+			op := spec.Paths["/bottles/{id}"].Get
+			Ω(op.Security).Should(Equal([]map[string][]string{{"oauth2": {"api:read"}}}))
+		})
+
+		It("serializes into JSON", func() {
+			b, err := json.Marshal(spec)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"openapi":"3.0.3"`))
+			Ω(string(b)).Should(ContainSubstring(`"requestBody"`))
+			Ω(string(b)).ShouldNot(ContainSubstring(`#/definitions/`))
+		})
+	})
+
+	Context("with a multipart payload", func() {
+		BeforeEach(func() {
+			version = genopenapi.Version31
+			API("test", func() {
+				BasicAuthSecurity("basic")
+			})
+			Resource("res", func() {
+				Action("upload", func() {
+					Routing(PUT("/"))
+					MultipartForm()
+					Payload(func() {
+						Attribute("image", File, "Binary image data")
+					})
+					Security("basic")
+				})
+			})
+		})
+
+		It("uses the multipart/form-data media type", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(spec.OpenAPI).Should(Equal(genopenapi.Version31))
+			op := spec.Paths["/"].Put
+			Ω(op.RequestBody.Content).Should(HaveLen(1))
+			Ω(op.RequestBody.Content).Should(HaveKey("multipart/form-data"))
+		})
+
+		It("describes files as binary strings", func() {
+			s := spec.Components.Schemas["UploadResPayload"]
+			Ω(s).ShouldNot(BeNil())
+			Ω(s.Properties["image"].Type).Should(Equal("string"))
+			Ω(s.Properties["image"].Format).Should(Equal("binary"))
+		})
+
+		It("uses the HTTP basic security scheme", func() {
+			s := spec.Components.SecuritySchemes["basic"]
+			Ω(s.Type).Should(Equal("http"))
+			Ω(s.Scheme).Should(Equal("basic"))
+			op := spec.Paths["/"].Put
+			Ω(op.Security).Should(Equal([]map[string][]string{{"basic": {}}}))
+		})
+	})
+
+	Context("with a mutual TLS security scheme", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				MutualTLSSecurity("cert")
+			})
+			Resource("res", func() {
+				Action("show", func() {
+					Routing(GET("/"))
+					Security("cert")
+				})
+			})
+		})
+
+		Context("with OpenAPI 3.1", func() {
+			BeforeEach(func() {
+				version = genopenapi.Version31
+			})
+
+			It("uses the mutualTLS security scheme", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(spec.Components.SecuritySchemes["cert"].Type).Should(Equal("mutualTLS"))
+				Ω(spec.Paths["/"].Get.Security).Should(Equal([]map[string][]string{{"cert": {}}}))
+			})
+		})
+
+		Context("with OpenAPI 3.0", func() {
+			BeforeEach(func() {
+				version = genopenapi.Version30
+			})
+
+			It("describes the requirement in the operation description", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(spec.Components).Should(BeNil())
+				op := spec.Paths["/"].Get
+				Ω(op.Security).Should(BeEmpty())
+				Ω(op.Description).Should(ContainSubstring("mutual TLS"))
+			})
+		})
+	})
+
+	Context("with authorization policies", func() {
+		BeforeEach(func() {
+			API("test", func() {})
+			Resource("res", func() {
+				Authorize("owner", "admin")
+				Action("show", func() {
+					Routing(GET("/"))
+				})
+			})
+		})
+
+		It("lists the policies in the operation extensions", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(spec.Paths["/"].Get.Extensions).Should(HaveKeyWithValue("x-authorize", []string{"owner", "admin"}))
+		})
+	})
+})
@@ -0,0 +1,27 @@
+package genopenapi
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Version OpenAPI specification version, either 3.0.x or 3.1.x
+func Version(version string) Option {
+	return func(g *Generator) {
+		g.Version = version
+	}
+}
@@ -71,6 +71,10 @@ type (
 		TargetSchema *JSONSchema `json:"targetSchema,omitempty"`
 		MediaType    string      `json:"mediaType,omitempty"`
 		EncType      string      `json:"encType,omitempty"`
+		// InboundMessage and OutboundMessage describe the messages exchanged over the
+		// websocket connection established by the link if any.
+		InboundMessage  *JSONSchema `json:"x-websocket-inbound-message,omitempty"`
+		OutboundMessage *JSONSchema `json:"x-websocket-outbound-message,omitempty"`
 	}
 )
 
@@ -212,15 +216,24 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 				}
 			}
 		}
+		var inboundSchema, outboundSchema *JSONSchema
+		if a.InboundMessage != nil {
+			inboundSchema = TypeSchema(api, a.InboundMessage)
+		}
+		if a.OutboundMessage != nil {
+			outboundSchema = TypeSchema(api, a.OutboundMessage)
+		}
 		for i, r := range a.Routes {
 			link := JSONLink{
-				Title:        a.Name,
-				Rel:          a.Name,
-				Href:         toSchemaHref(api, r),
-				Method:       r.Verb,
-				Schema:       requestSchema,
-				TargetSchema: targetSchema,
-				MediaType:    identifier,
+				Title:           a.Name,
+				Rel:             a.Name,
+				Href:            toSchemaHref(api, r),
+				Method:          r.Verb,
+				Schema:          requestSchema,
+				TargetSchema:    targetSchema,
+				MediaType:       identifier,
+				InboundMessage:  inboundSchema,
+				OutboundMessage: outboundSchema,
 			}
 			if i == 0 {
 				if ca := a.Parent.CanonicalAction(); ca != nil {
@@ -320,6 +333,12 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	return s
 }
 
+// AttributeSchema produces the JSON schema corresponding to the given attribute including its
+// description, default value, example and validations.
+func AttributeSchema(api *design.APIDefinition, at *design.AttributeDefinition) *JSONSchema {
+	return buildAttributeSchema(api, NewJSONSchema(), at)
+}
+
 type mergeItems []struct {
 	a, b   interface{}
 	needed bool
@@ -79,3 +79,39 @@ var _ = Describe("TypeSchema", func() {
 
 	})
 })
+
+var _ = Describe("GenerateResourceDefinition", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	})
+
+	Context("with a websocket action declaring messages", func() {
+		BeforeEach(func() {
+			msg := Type("Message", func() {
+				Attribute("body", design.String)
+			})
+			Resource("chat", func() {
+				Action("join", func() {
+					Scheme("ws")
+					Routing(GET("/join"))
+					InboundMessage(msg)
+					OutboundMessage(msg)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			genschema.GenerateResourceDefinition(design.Design, design.Design.Resources["chat"])
+		})
+
+		It("documents the messages in the action link", func() {
+			Ω(genschema.Definitions).Should(HaveKey("chat"))
+			links := genschema.Definitions["chat"].Links
+			Ω(links).Should(HaveLen(1))
+			Ω(links[0].InboundMessage).ShouldNot(BeNil())
+			Ω(links[0].InboundMessage.Ref).Should(Equal("#/definitions/Message"))
+			Ω(links[0].OutboundMessage).ShouldNot(BeNil())
+			Ω(links[0].OutboundMessage.Ref).Should(Equal("#/definitions/Message"))
+		})
+	})
+})
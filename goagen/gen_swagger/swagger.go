@@ -461,6 +461,9 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 			s.Definitions[n] = d
 		}
 	}
+	if api.HealthCheckPath != "" {
+		buildHealthPaths(s, api)
+	}
 	return s, nil
 }
 
@@ -475,10 +478,13 @@ func mustGenerate(meta dslengine.MetadataDefinition) bool {
 	return true
 }
 
-// hasAbsoluteRoutes returns true if any action exposed by the API uses an absolute route of if the
-// API has file servers. This is needed as Swagger does not support exceptions to the base path so
+// hasAbsoluteRoutes returns true if any action exposed by the API uses an absolute route or if the
+// API has file servers or health endpoints. This is needed as Swagger does not support exceptions to the base path so
 // if the API has any absolute route the base path must be "/" and all routes must be absolutes.
 func hasAbsoluteRoutes(api *design.APIDefinition) bool {
+	if api.HealthCheckPath != "" {
+		return true
+	}
 	hasAbsoluteRoutes := false
 	for _, res := range api.Resources {
 		for _, fs := range res.FileServers {
@@ -509,6 +515,59 @@ func hasAbsoluteRoutes(api *design.APIDefinition) bool {
 	return hasAbsoluteRoutes
 }
 
+// buildHealthPaths adds the health endpoints mounted by Service.MountHealth and the definition of
+// the HealthReport they return.
+func buildHealthPaths(s *Swagger, api *design.APIDefinition) {
+	if s.Definitions == nil {
+		s.Definitions = make(map[string]*genschema.JSONSchema)
+	}
+	s.Definitions["HealthReport"] = &genschema.JSONSchema{
+		Title:       "HealthReport",
+		Description: "Aggregated result of the service health checks",
+		Type:        genschema.JSONObject,
+		Properties: map[string]*genschema.JSONSchema{
+			"status": {
+				Type:        genschema.JSONString,
+				Description: "ok if all the checks passed, fail otherwise",
+				Enum:        []interface{}{"ok", "fail"},
+			},
+			"checks": {
+				Type:                 genschema.JSONObject,
+				Description:          "Results of the individual checks indexed by name",
+				AdditionalProperties: true,
+			},
+		},
+		Required: []string{"status"},
+	}
+	ref := &genschema.JSONSchema{Ref: "#/definitions/HealthReport"}
+	responses := map[string]*Response{
+		"200": {Description: "Healthy", Schema: ref},
+		"503": {Description: "Unhealthy", Schema: ref},
+	}
+	base := strings.TrimSuffix(api.HealthCheckPath, "/")
+	endpoints := []struct{ path, id, summary string }{
+		{api.HealthCheckPath, "health#health", "Readiness report"},
+		{base + "/ready", "health#ready", "Readiness report"},
+		{base + "/live", "health#live", "Liveness report"},
+	}
+	for _, e := range endpoints {
+		var path interface{}
+		var ok bool
+		if path, ok = s.Paths[e.path]; !ok {
+			path = new(Path)
+			s.Paths[e.path] = path
+		}
+		path.(*Path).Get = &Operation{
+			Tags:        []string{"health"},
+			Summary:     e.summary,
+			OperationID: e.id,
+			Produces:    []string{"application/json"},
+			Responses:   responses,
+			Schemes:     api.Schemes,
+		}
+	}
+}
+
 func securityDefsFromDefinition(schemes []*design.SecuritySchemeDefinition) map[string]*SecurityDefinition {
 	if len(schemes) == 0 {
 		return nil
@@ -516,6 +575,10 @@ func securityDefsFromDefinition(schemes []*design.SecuritySchemeDefinition) map[
 
 	defs := make(map[string]*SecurityDefinition)
 	for _, scheme := range schemes {
+		if scheme.Kind == design.MutualTLSSecurityKind {
+			// Swagger 2.0 cannot describe mutual TLS, see applySecurity.
+			continue
+		}
 		def := &SecurityDefinition{
 			Type:             scheme.Type,
 			Description:      scheme.Description,
@@ -980,6 +1043,15 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		Deprecated:   false,
 		Extensions:   extensionsFromDefinition(route.Metadata),
 	}
+	if (action.InboundMessage != nil || action.OutboundMessage != nil) && operation.Extensions == nil {
+		operation.Extensions = make(map[string]interface{})
+	}
+	if action.InboundMessage != nil {
+		operation.Extensions["x-websocket-inbound-message"] = genschema.TypeSchema(api, action.InboundMessage)
+	}
+	if action.OutboundMessage != nil {
+		operation.Extensions["x-websocket-outbound-message"] = genschema.TypeSchema(api, action.OutboundMessage)
+	}
 
 	if consumesMultipart {
 		operation.Consumes = append(operation.Consumes, "multipart/form-data")
@@ -987,11 +1059,24 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 
 	computeProduces(operation, s, action)
 	applySecurity(operation, action.Security)
+	applyAuthorization(operation, action.Authorization)
 
 	computePaths(operation, s, route, basePath)
 	return nil
 }
 
+// applyAuthorization documents the authorization policies of the action with the
+// "x-authorize" operation extension.
+func applyAuthorization(operation *Operation, authz *design.AuthorizationDefinition) {
+	if authz == nil || len(authz.Policies) == 0 {
+		return
+	}
+	if operation.Extensions == nil {
+		operation.Extensions = make(map[string]interface{})
+	}
+	operation.Extensions["x-authorize"] = authz.Policies
+}
+
 func computeProduces(operation *Operation, s *Swagger, action *design.ActionDefinition) {
 	produces := make(map[string]struct{})
 	action.IterateResponses(func(resp *design.ResponseDefinition) error {
@@ -1071,6 +1156,13 @@ func computePaths(operation *Operation, s *Swagger, route *design.RouteDefinitio
 }
 
 func applySecurity(operation *Operation, security *design.SecurityDefinition) {
+	if security != nil && security.Scheme.Kind == design.MutualTLSSecurityKind {
+		if operation.Description != "" {
+			operation.Description += "\n\n"
+		}
+		operation.Description += "Requires a client certificate (mutual TLS)."
+		return
+	}
 	if security != nil && security.Scheme.Kind != design.NoSecurityKind {
 		if security.Scheme.Kind == design.JWTSecurityKind && len(security.Scopes) > 0 {
 			if operation.Description != "" {
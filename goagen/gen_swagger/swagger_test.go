@@ -54,6 +54,61 @@ var _ = Describe("New", func() {
 		swagger, newErr = genswagger.New(Design)
 	})
 
+	Context("with a health check path", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				BasePath("/base")
+				HealthCheck("/healthz")
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/"))
+				})
+			})
+		})
+
+		It("documents the health endpoints", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(swagger.BasePath).Should(BeEmpty())
+			for _, p := range []string{"/healthz", "/healthz/ready", "/healthz/live"} {
+				Ω(swagger.Paths).Should(HaveKey(p))
+				op := swagger.Paths[p].(*genswagger.Path).Get
+				Ω(op).ShouldNot(BeNil())
+				Ω(op.Tags).Should(Equal([]string{"health"}))
+				Ω(op.Responses).Should(HaveKey("200"))
+				Ω(op.Responses).Should(HaveKey("503"))
+			}
+			Ω(swagger.Paths).Should(HaveKey("/base/"))
+			Ω(swagger.Definitions).Should(HaveKey("HealthReport"))
+		})
+
+		It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+	})
+
+	Context("with a mutual TLS security scheme", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				MutualTLSSecurity("cert")
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/"))
+					Security("cert")
+				})
+			})
+		})
+
+		It("describes the requirement in the operation description", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(swagger.SecurityDefinitions).ShouldNot(HaveKey("cert"))
+			op := swagger.Paths["/"].(*genswagger.Path).Get
+			Ω(op.Security).Should(BeEmpty())
+			Ω(op.Description).Should(ContainSubstring("mutual TLS"))
+		})
+
+		It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+	})
+
 	Context("with a valid API definition", func() {
 		const (
 			title        = "title"
@@ -214,6 +269,73 @@ var _ = Describe("New", func() {
 			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
 		})
 
+		Context("with websocket messages", func() {
+			BeforeEach(func() {
+				m := Type("Message", func() {
+					Member("body", String)
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Scheme("ws")
+						Routing(
+							GET("/"),
+						)
+						InboundMessage(m)
+						OutboundMessage(m)
+					})
+				})
+			})
+
+			It("documents the messages with extensions", func() {
+				validateSwaggerWithFragments(swagger, [][]byte{
+					[]byte(`"x-websocket-inbound-message":{"$ref":"#/definitions/Message"}`),
+					[]byte(`"x-websocket-outbound-message":{"$ref":"#/definitions/Message"}`),
+				})
+			})
+		})
+
+		Context("with a websocket inbound message and no other extension", func() {
+			BeforeEach(func() {
+				m := Type("Message", func() {
+					Member("body", String)
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Scheme("ws")
+						Routing(
+							GET("/"),
+						)
+						InboundMessage(m)
+					})
+				})
+			})
+
+			It("documents the message with an extension", func() {
+				validateSwaggerWithFragments(swagger, [][]byte{
+					[]byte(`"x-websocket-inbound-message":{"$ref":"#/definitions/Message"}`),
+				})
+			})
+		})
+
+		Context("with authorization policies", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Authorize("owner", "admin")
+					Action("act", func() {
+						Routing(
+							GET("/"),
+						)
+					})
+				})
+			})
+
+			It("lists the policies with an extension", func() {
+				validateSwaggerWithFragments(swagger, [][]byte{
+					[]byte(`"x-authorize":["owner","admin"]`),
+				})
+			})
+		})
+
 		Context("with required payload", func() {
 			BeforeEach(func() {
 				p := Type("RequiredPayload", func() {
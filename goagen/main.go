@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -110,11 +111,74 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(swaggerCmd)
 
+	// openapiCmd implements the "openapi" command.
+	var (
+		openapiVersion string
+	)
+	openapiCmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Generate OpenAPI 3 specification",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genopenapi", c) },
+	}
+	openapiCmd.Flags().StringVar(&openapiVersion, "openapi-version", "3.0.3", "OpenAPI specification version, either 3.0.x or 3.1.x")
+	rootCmd.AddCommand(openapiCmd)
+
+	// diffCmd implements the "diff" command.
+	var (
+		base, format string
+	)
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Detect breaking changes between two versions of a design",
+		Long: `The diff command compares the design with a base design and lists the changes classified as
+breaking or compatible. The command exits with a non-zero status if any change is breaking.
+
+The base is either the import path of a design package or the path to a contract file written by
+a previous run of the command (diff/contract.json).`,
+		Run: func(c *cobra.Command, _ []string) { files, err = runDiff(c) },
+	}
+	diffCmd.Flags().StringVar(&base, "base", "", "import path of the base design package or path to a contract JSON file")
+	diffCmd.Flags().StringVar(&format, "format", "text", `report format, either "text" or "json"`)
+	rootCmd.AddCommand(diffCmd)
+
+	// lintCmd implements the "lint" command.
+	var (
+		lintConfig, enable, disable string
+	)
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check the design against style rules",
+		Long: `The lint command runs a set of rules against the design and writes the issues found to
+lint/report.txt, lint/report.json or lint/report.sarif depending on the report format. The command
+exits with a non-zero status if any issue has severity error.
+
+Rules are enabled by default and may be disabled with --disable or with a JSON configuration file
+which can also change the rule severities.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = runLint(c) },
+	}
+	lintCmd.Flags().StringVar(&format, "format", "text", `report format, one of "text", "json" or "sarif"`)
+	lintCmd.Flags().StringVar(&lintConfig, "config", "", "path to a JSON file configuring the rules")
+	lintCmd.Flags().StringVar(&enable, "enable", "", "comma separated list of rules to enable")
+	lintCmd.Flags().StringVar(&disable, "disable", "", "comma separated list of rules to disable")
+	rootCmd.AddCommand(lintCmd)
+
+	// exportCmd implements the "design-export" command.
+	exportCmd := &cobra.Command{
+		Use:   "design-export",
+		Short: "Export the design to a JSON and YAML document",
+		Long: `The design-export command serializes the finalized design (resources, actions, user types,
+media types, security schemes and metadata) to export/design.json and export/design.yaml. The
+documents can be loaded with design.LoadDesign by tools that do not compile the design.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genexport", c) },
+	}
+	rootCmd.AddCommand(exportCmd)
+
 	// jsCmd implements the "js" command.
 	var (
 		timeout      = time.Duration(20) * time.Second
 		scheme, host string
 		noexample    bool
+		ts           bool
 	)
 	jsCmd := &cobra.Command{
 		Use:   "js",
@@ -125,6 +189,7 @@ package and tool and the Swagger specification for the API.
 	jsCmd.Flags().StringVar(&scheme, "scheme", "", `the URL scheme used to make requests to the API, defaults to the scheme defined in the API design if any.`)
 	jsCmd.Flags().StringVar(&host, "host", "", `the API hostname, defaults to the hostname defined in the API design if any`)
 	jsCmd.Flags().BoolVar(&noexample, "noexample", false, `Skip generation of example HTML and controller`)
+	jsCmd.Flags().BoolVar(&ts, "ts", false, `Generate a typed TypeScript client in the "ts" directory instead of the JavaScript module`)
 	rootCmd.AddCommand(jsCmd)
 
 	// schemaCmd implements the "schema" command.
@@ -199,6 +264,46 @@ package and tool and the Swagger specification for the API.
 	controllerCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
 	rootCmd.AddCommand(controllerCmd)
 
+	// grpcCmd implements the "grpc" command.
+	grpcCmd := &cobra.Command{
+		Use:   "grpc",
+		Short: "Generate gRPC service definitions and servers",
+		Long: `The grpc command generates a protobuf file describing one gRPC service per resource in
+grpc/pb and the Go code implementing these services with the resource controllers in grpc. The Go
+code for the protobuf messages and service interfaces must be generated with protoc.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("gengrpc", c) },
+	}
+	grpcCmd.Flags().StringVar(&pkg, "pkg", "grpc", "name of the generated gRPC server `package`")
+	grpcCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
+	rootCmd.AddCommand(grpcCmd)
+
+	// mockCmd implements the "mock" command.
+	mockCmd := &cobra.Command{
+		Use:   "mock",
+		Short: "Generate a mock server",
+		Long: `The mock command generates a server that mounts all the API actions and responds with
+examples computed from the design. Requests are validated by the handlers generated with
+'goagen app'. Set the X-Mock-Response header to the name or status code of a response to select it.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genmock", c) },
+	}
+	mockCmd.Flags().StringVar(&pkg, "pkg", "mock", "name of the generated mock server `directory`")
+	mockCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
+	rootCmd.AddCommand(mockCmd)
+
+	// contractCmd implements the "contract" command.
+	var clientPkg string
+	contractCmd := &cobra.Command{
+		Use:   "contract",
+		Short: "Generate a contract test suite",
+		Long: `The contract command generates a test suite that calls each action of a running service with
+the client package generated by 'goagen client' and checks the responses against the design. Run it
+with 'go test ./contract -base-url http://localhost:8080'.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("gencontract", c) },
+	}
+	contractCmd.Flags().StringVar(&pkg, "pkg", "contract", "name of the generated contract test `package`")
+	contractCmd.Flags().StringVar(&clientPkg, "client-pkg", "client", "`import path` of Go package generated with 'goagen client', may be relative to output")
+	rootCmd.AddCommand(contractCmd)
+
 	// cmdsCmd implements the commands command
 	// It lists all the commands and flags in JSON to enable shell integrations.
 	cmdsCmd := &cobra.Command{
@@ -271,6 +376,56 @@ func runGen(c *cobra.Command, args []string) ([]string, error) {
 	return generate(pkgName, pkgPath, c, args)
 }
 
+func runDiff(c *cobra.Command) ([]string, error) {
+	base := c.Flag("base").Value.String()
+	if base == "" {
+		return nil, fmt.Errorf("missing base design, use --base")
+	}
+	if strings.HasSuffix(base, ".json") {
+		abs, err := filepath.Abs(base)
+		if err != nil {
+			return nil, err
+		}
+		c.Flags().Set("base", abs)
+	} else {
+		// Compute the contract of the base design using the snapshot entry point.
+		tmpDir, err := ioutil.TempDir("", "goagen-diff")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmpDir)
+		m := map[string]string{"design": base, "out": tmpDir}
+		if c.Flag("debug").Changed {
+			m["debug"] = c.Flag("debug").Value.String()
+		}
+		gen, err := meta.NewGenerator(
+			"gendiff.Snapshot",
+			[]*codegen.ImportSpec{codegen.SimpleImport("github.com/goadesign/goa/goagen/gen_diff")},
+			m,
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := gen.Generate(); err != nil {
+			return nil, fmt.Errorf("failed to load base design: %s", err)
+		}
+		c.Flags().Set("base", filepath.Join(tmpDir, "contract.json"))
+	}
+	return run("gendiff", c)
+}
+
+func runLint(c *cobra.Command) ([]string, error) {
+	if cfg := c.Flag("config").Value.String(); cfg != "" {
+		abs, err := filepath.Abs(cfg)
+		if err != nil {
+			return nil, err
+		}
+		c.Flags().Set("config", abs)
+	}
+	return run("genlint", c)
+}
+
 func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]string, error) {
 	m := make(map[string]string)
 	c.Flags().Visit(func(f *pflag.Flag) {
@@ -0,0 +1,152 @@
+package goatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ContractResponse describes a response defined in the design. The contract tests generated by
+// "goagen contract" check that the responses of the service match one of the responses defined
+// for the action.
+type ContractResponse struct {
+	// Name is the name of the response in the design, e.g. "OK" or "NotFound".
+	Name string
+	// Status is the response HTTP status code.
+	Status int
+	// ContentType is the response media type identifier if any.
+	ContentType string
+	// Stream is true if the response body is a stream of server-sent events.
+	Stream bool
+	// Headers lists the names of the required response headers.
+	Headers []string
+	// Collection is true if the response body is a collection.
+	Collection bool
+	// Fields lists the attributes of the response media type view. The body must not contain
+	// other attributes. Fields is nil if the response body is not a media type.
+	Fields []string
+	// Required lists the attributes of the response media type view that the body must contain.
+	Required []string
+}
+
+// Validator is implemented by the generated types that define validations.
+type Validator interface {
+	Validate() error
+}
+
+// CheckResponse checks the status code, headers and body of resp against the given responses and
+// returns the matching response. CheckResponse reads the body of non-streaming responses and
+// replaces resp.Body so that it can be decoded afterwards.
+func CheckResponse(resp *http.Response, responses []*ContractResponse) (*ContractResponse, error) {
+	var r *ContractResponse
+	statuses := make([]string, len(responses))
+	for i, cr := range responses {
+		if cr.Status == resp.StatusCode {
+			r = cr
+			break
+		}
+		statuses[i] = fmt.Sprintf("%d (%s)", cr.Status, cr.Name)
+	}
+	if r == nil {
+		return nil, fmt.Errorf("unexpected response status %d, must be one of %s", resp.StatusCode, strings.Join(statuses, ", "))
+	}
+	for _, h := range r.Headers {
+		if resp.Header.Get(h) == "" {
+			return r, fmt.Errorf("%s response is missing required header %s", r.Name, h)
+		}
+	}
+	if r.Stream {
+		return r, checkContentType(r, resp, "text/event-stream")
+	}
+	if r.ContentType != "" {
+		if err := checkContentType(r, resp, r.ContentType); err != nil {
+			return r, err
+		}
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return r, fmt.Errorf("failed to read %s response body: %s", r.Name, err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if r.Fields == nil {
+		return r, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return r, fmt.Errorf("invalid %s response body: %s", r.Name, err)
+	}
+	if !r.Collection {
+		return r, r.checkFields(v)
+	}
+	elems, ok := v.([]interface{})
+	if !ok {
+		return r, fmt.Errorf("%s response body must be a collection", r.Name)
+	}
+	for _, e := range elems {
+		if err := r.checkFields(e); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// Validate runs the validations defined on v if any.
+func Validate(v interface{}) error {
+	if val, ok := v.(Validator); ok {
+		return val.Validate()
+	}
+	return nil
+}
+
+// checkContentType checks that the response Content-Type header matches the given media type,
+// media type parameters are ignored.
+func checkContentType(r *ContractResponse, resp *http.Response, expected string) error {
+	ct := resp.Header.Get("Content-Type")
+	actual, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return fmt.Errorf("invalid %s response Content-Type %#v: %s", r.Name, ct, err)
+	}
+	if exp, _, err := mime.ParseMediaType(expected); err == nil {
+		expected = exp
+	}
+	if actual != expected {
+		return fmt.Errorf("%s response Content-Type is %#v, expected %#v", r.Name, ct, expected)
+	}
+	return nil
+}
+
+// checkFields checks that v is an object that only contains the response attributes and that the
+// required attributes are present.
+func (r *ContractResponse) checkFields(v interface{}) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s response body must be an object", r.Name)
+	}
+	allowed := make(map[string]bool, len(r.Fields))
+	for _, f := range r.Fields {
+		allowed[f] = true
+	}
+	var unknown []string
+	for k := range obj {
+		if !allowed[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%s response body contains attributes that are not part of the media type view: %s",
+			r.Name, strings.Join(unknown, ", "))
+	}
+	for _, f := range r.Required {
+		if _, ok := obj[f]; !ok {
+			return fmt.Errorf("%s response body is missing required attribute %#v", r.Name, f)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package goatest_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa/goatest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// validated implements goatest.Validator.
+type validated struct{ err error }
+
+func (v validated) Validate() error { return v.err }
+
+var _ = Describe("CheckResponse", func() {
+	var responses []*goatest.ContractResponse
+	var status int
+	var header http.Header
+	var body string
+
+	var r *goatest.ContractResponse
+	var resp *http.Response
+	var err error
+
+	BeforeEach(func() {
+		responses = []*goatest.ContractResponse{
+			{
+				Name:        "OK",
+				Status:      200,
+				ContentType: "application/vnd.bottle+json",
+				Headers:     []string{"Location"},
+				Fields:      []string{"id", "name"},
+				Required:    []string{"id"},
+			},
+			{Name: "NotFound", Status: 404},
+		}
+		status = 200
+		header = http.Header{
+			"Content-Type": {"application/vnd.bottle+json; charset=utf-8"},
+			"Location":     {"/bottles/1"},
+		}
+		body = `{"id":1,"name":"muscadet"}`
+	})
+
+	JustBeforeEach(func() {
+		rw := httptest.NewRecorder()
+		for k, v := range header {
+			rw.Header()[k] = v
+		}
+		rw.WriteHeader(status)
+		rw.WriteString(body)
+		resp = rw.Result()
+		r, err = goatest.CheckResponse(resp, responses)
+	})
+
+	It("accepts valid responses", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(r).Should(Equal(responses[0]))
+		b, err := ioutil.ReadAll(resp.Body)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(body))
+	})
+
+	Context("with an unknown status", func() {
+		BeforeEach(func() {
+			status = 500
+		})
+
+		It("fails", func() {
+			Ω(err).Should(MatchError("unexpected response status 500, must be one of 200 (OK), 404 (NotFound)"))
+		})
+	})
+
+	Context("with a missing header", func() {
+		BeforeEach(func() {
+			header.Del("Location")
+		})
+
+		It("fails", func() {
+			Ω(err).Should(MatchError("OK response is missing required header Location"))
+		})
+	})
+
+	Context("with the wrong Content-Type", func() {
+		BeforeEach(func() {
+			header.Set("Content-Type", "application/json")
+		})
+
+		It("fails", func() {
+			Ω(err).Should(MatchError(`OK response Content-Type is "application/json", expected "application/vnd.bottle+json"`))
+		})
+	})
+
+	Context("with attributes not in the view", func() {
+		BeforeEach(func() {
+			body = `{"id":1,"vintage":2012}`
+		})
+
+		It("fails", func() {
+			Ω(err).Should(MatchError("OK response body contains attributes that are not part of the media type view: vintage"))
+		})
+	})
+
+	Context("with a missing required attribute", func() {
+		BeforeEach(func() {
+			body = `{"name":"muscadet"}`
+		})
+
+		It("fails", func() {
+			Ω(err).Should(MatchError(`OK response body is missing required attribute "id"`))
+		})
+	})
+
+	Context("with a collection", func() {
+		BeforeEach(func() {
+			responses[0].Collection = true
+			body = `[{"id":1},{"name":"muscadet"}]`
+		})
+
+		It("checks each element", func() {
+			Ω(err).Should(MatchError(`OK response body is missing required attribute "id"`))
+		})
+	})
+})
+
+var _ = Describe("Validate", func() {
+	It("runs the validations if any", func() {
+		Ω(goatest.Validate(validated{})).Should(Succeed())
+		Ω(goatest.Validate(validated{errors.New("invalid")})).Should(MatchError("invalid"))
+		Ω(goatest.Validate(42)).Should(Succeed())
+	})
+})
@@ -0,0 +1,12 @@
+/*
+Package grpc contains the runtime support for the gRPC servers generated by "goagen grpc".
+
+The generated servers implement the gRPC service interfaces produced by protoc from the generated
+.proto file. Each RPC builds a goa request context from the gRPC request message, runs the
+corresponding action of the controller implementing the generated controller interface and converts
+the response written by the action into the RPC reply message. This makes it possible for a single
+controller implementation to serve both HTTP and gRPC requests.
+
+The functions exposed by this package are meant to be called by the generated code.
+*/
+package grpc
@@ -0,0 +1,207 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ResponseRecorder is the http.ResponseWriter given to the controller actions run on behalf of
+// gRPC calls. It records the response status and body so they can be converted into the RPC reply.
+type ResponseRecorder struct {
+	// Status is the HTTP status code written by the action.
+	Status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+// NewContext creates the goa request context used to run the given controller action on behalf
+// of a gRPC call. params contains the action path and query string parameters, the metadata of the
+// incoming gRPC call is copied into the request headers. The returned recorder captures the
+// response written by the action.
+func NewContext(ctx context.Context, service *goa.Service, action, method, path string, params url.Values) (context.Context, *ResponseRecorder) {
+	u := &url.URL{Path: path, RawQuery: params.Encode()}
+	req := &http.Request{
+		Method:     method,
+		URL:        u,
+		Proto:      "HTTP/2.0",
+		ProtoMajor: 2,
+		Header:     make(http.Header),
+		RequestURI: u.RequestURI(),
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, vals := range md {
+			if strings.HasPrefix(k, ":") {
+				continue // pseudo headers
+			}
+			for _, v := range vals {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	req = req.WithContext(ctx)
+	if logger := goa.ContextLogger(service.Context); logger != nil && goa.ContextLogger(ctx) == nil {
+		ctx = goa.WithLogger(ctx, logger)
+	}
+	rec := &ResponseRecorder{header: make(http.Header)}
+	return goa.NewContext(goa.WithAction(ctx, action), rec, req, params), rec
+}
+
+// Header returns the response headers.
+func (r *ResponseRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader records the response status code.
+func (r *ResponseRecorder) WriteHeader(status int) {
+	if r.Status == 0 {
+		r.Status = status
+	}
+}
+
+// Write records the response body.
+func (r *ResponseRecorder) Write(b []byte) (int, error) {
+	if r.Status == 0 {
+		r.Status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+// Body returns the recorded response body.
+func (r *ResponseRecorder) Body() []byte {
+	return r.body.Bytes()
+}
+
+// Decode decodes the recorded JSON response body into v. Error responses are converted into gRPC
+// status errors. v may be nil if the RPC reply is empty.
+func (r *ResponseRecorder) Decode(v interface{}) error {
+	if r.Status >= 400 {
+		return responseError(r.Status, r.body.Bytes())
+	}
+	if v == nil || r.body.Len() == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.body.Bytes(), v); err != nil {
+		return status.Errorf(codes.Internal, "failed to decode response: %s", err)
+	}
+	return nil
+}
+
+// SetParam adds the string representation of v to params. v is typically the field of a gRPC
+// request message: nil pointers are ignored and slices add one value per element.
+func SetParam(params url.Values, name string, v interface{}) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return
+		}
+		SetParam(params, name, rv.Elem().Interface())
+	case reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			params.Add(name, fmt.Sprint(rv.Index(i).Interface()))
+		}
+	default:
+		params.Set(name, fmt.Sprint(v))
+	}
+}
+
+// Convert copies the content of src into dst using their JSON representation. It is used to
+// convert between protobuf messages and the types generated by goagen.
+func Convert(src, dst interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// Validate runs the validations defined in the design on v if any.
+func Validate(v interface{}) error {
+	if val, ok := v.(interface {
+		Validate() error
+	}); ok {
+		return val.Validate()
+	}
+	return nil
+}
+
+// EncodeError converts the error returned by a controller action into a gRPC status error. The
+// status code of goa errors is derived from their HTTP response status.
+func EncodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(interface {
+		GRPCStatus() *status.Status
+	}); ok {
+		return err
+	}
+	if serr, ok := err.(goa.ServiceError); ok {
+		return status.Error(Code(serr.ResponseStatus()), serr.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// Code returns the gRPC status code corresponding to the given HTTP status code.
+func Code(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return codes.OutOfRange
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	}
+	switch {
+	case httpStatus < 400:
+		return codes.OK
+	case httpStatus < 500:
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
+
+// responseError builds the gRPC status error corresponding to an error response written by an
+// action.
+func responseError(httpStatus int, body []byte) error {
+	msg := http.StatusText(httpStatus)
+	var resp goa.ErrorResponse
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Detail != "" {
+		msg = resp.Detail
+	} else if len(body) > 0 && err != nil {
+		msg = string(body)
+	}
+	return status.Error(Code(httpStatus), msg)
+}
@@ -0,0 +1,126 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	goagrpc "github.com/goadesign/goa/grpc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var _ = Describe("NewContext", func() {
+	var service *goa.Service
+	var params url.Values
+	var ctx context.Context
+
+	var rctx context.Context
+	var rec *goagrpc.ResponseRecorder
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		params = url.Values{"id": {"42"}}
+		ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "foo"))
+	})
+
+	JustBeforeEach(func() {
+		rctx, rec = goagrpc.NewContext(ctx, service, "show", "GET", "/bottles/:id", params)
+	})
+
+	It("initializes the goa request context", func() {
+		Ω(rec).ShouldNot(BeNil())
+		req := goa.ContextRequest(rctx)
+		Ω(req).ShouldNot(BeNil())
+		Ω(req.Params).Should(Equal(params))
+		Ω(req.Method).Should(Equal("GET"))
+		Ω(req.Header.Get("X-Request-Id")).Should(Equal("foo"))
+		Ω(req.Header.Get("Accept")).Should(Equal("application/json"))
+		Ω(goa.ContextAction(rctx)).Should(Equal("show"))
+		Ω(goa.ContextResponse(rctx).ResponseWriter).Should(Equal(rec))
+	})
+
+	Context("with an action writing a response", func() {
+		JustBeforeEach(func() {
+			Ω(service.Send(rctx, 200, map[string]interface{}{"name": "foo"})).ShouldNot(HaveOccurred())
+		})
+
+		It("records the response", func() {
+			Ω(rec.Status).Should(Equal(200))
+			var v struct {
+				Name string `json:"name"`
+			}
+			Ω(rec.Decode(&v)).ShouldNot(HaveOccurred())
+			Ω(v.Name).Should(Equal("foo"))
+		})
+	})
+
+	Context("with an action writing an error response", func() {
+		JustBeforeEach(func() {
+			Ω(service.Send(rctx, 404, goa.ErrNotFound("bottle not found"))).ShouldNot(HaveOccurred())
+		})
+
+		It("returns a gRPC status error", func() {
+			err := rec.Decode(nil)
+			Ω(err).Should(HaveOccurred())
+			st, ok := status.FromError(err)
+			Ω(ok).Should(BeTrue())
+			Ω(st.Code()).Should(Equal(codes.NotFound))
+			Ω(st.Message()).Should(Equal("bottle not found"))
+		})
+	})
+})
+
+var _ = Describe("SetParam", func() {
+	var params url.Values
+
+	BeforeEach(func() {
+		params = url.Values{}
+	})
+
+	It("sets primitive values", func() {
+		goagrpc.SetParam(params, "id", int64(42))
+		Ω(params.Get("id")).Should(Equal("42"))
+	})
+
+	It("ignores nil pointers", func() {
+		var s *string
+		goagrpc.SetParam(params, "name", s)
+		Ω(params).ShouldNot(HaveKey("name"))
+	})
+
+	It("dereferences pointers", func() {
+		s := "foo"
+		goagrpc.SetParam(params, "name", &s)
+		Ω(params.Get("name")).Should(Equal("foo"))
+	})
+
+	It("adds one value per slice element", func() {
+		goagrpc.SetParam(params, "tags", []string{"a", "b"})
+		Ω(params["tags"]).Should(Equal([]string{"a", "b"}))
+	})
+})
+
+var _ = Describe("EncodeError", func() {
+	It("maps goa errors using their response status", func() {
+		st, ok := status.FromError(goagrpc.EncodeError(goa.ErrBadRequest("invalid")))
+		Ω(ok).Should(BeTrue())
+		Ω(st.Code()).Should(Equal(codes.InvalidArgument))
+	})
+
+	It("returns gRPC status errors as is", func() {
+		err := status.Error(codes.Aborted, "aborted")
+		Ω(goagrpc.EncodeError(err)).Should(Equal(err))
+	})
+
+	It("maps other errors to internal errors", func() {
+		st, ok := status.FromError(goagrpc.EncodeError(errors.New("boom")))
+		Ω(ok).Should(BeTrue())
+		Ω(st.Code()).Should(Equal(codes.Internal))
+	})
+})
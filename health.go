@@ -0,0 +1,294 @@
+package goa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCheckTimeout is the default maximum duration of a single health check.
+	DefaultCheckTimeout = 5 * time.Second
+	// DefaultCheckCacheTTL is the default duration during which the result of a health check
+	// is reused.
+	DefaultCheckCacheTTL = time.Second
+)
+
+const (
+	// HealthOK is the status of a passing check or of a healthy service.
+	HealthOK HealthStatus = "ok"
+	// HealthFail is the status of a failing check or of an unhealthy service.
+	HealthFail HealthStatus = "fail"
+)
+
+// ErrShuttingDown is the error reported by the readiness endpoint in the report Error field once
+// Shutdown has been called.
+var ErrShuttingDown = errors.New("service is shutting down")
+
+type (
+	// Checker is the interface implemented by health checks. Check returns a non-nil error if
+	// the checked dependency is unhealthy. Implementations should honor the context deadline.
+	Checker interface {
+		Check(context.Context) error
+	}
+
+	// CheckerFunc is an adapter that makes it possible to use a function as a Checker.
+	CheckerFunc func(context.Context) error
+
+	// CheckOption configures a health check registered with AddCheck.
+	CheckOption func(*check)
+
+	// HealthStatus is the status of a health check or of a health report.
+	HealthStatus string
+
+	// HealthReport is the body of the responses sent by the health endpoints.
+	HealthReport struct {
+		// Status is HealthOK if all the checks passed and the service is not shutting
+		// down, HealthFail otherwise.
+		Status HealthStatus `json:"status"`
+		// Error is the reason the service is unhealthy independently of the checks, for
+		// example ErrShuttingDown.
+		Error string `json:"error,omitempty"`
+		// Checks lists the results of the individual checks indexed by name.
+		Checks map[string]*CheckResult `json:"checks,omitempty"`
+	}
+
+	// CheckResult is the result of a single health check.
+	CheckResult struct {
+		// Status is the check status.
+		Status HealthStatus `json:"status"`
+		// Error is the error message returned by the check if any.
+		Error string `json:"error,omitempty"`
+		// Duration is the time it took to run the check.
+		Duration string `json:"duration"`
+		// CheckedAt is the time the check ran, it may be in the past if the result was
+		// cached.
+		CheckedAt time.Time `json:"checked_at"`
+	}
+
+	// health holds the checks registered with a service. The zero value is ready to use.
+	health struct {
+		mu      sync.Mutex
+		checks  []*check
+		mounted map[string]bool
+	}
+
+	// check is a registered health check together with its cached result.
+	check struct {
+		name     string
+		checker  Checker
+		timeout  time.Duration
+		ttl      time.Duration
+		liveness bool
+
+		mu      sync.Mutex // serializes runs so that concurrent probes share a result
+		result  *CheckResult
+		expires time.Time
+	}
+)
+
+// Check calls f(ctx).
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckTimeout sets the maximum duration of the check, DefaultCheckTimeout by default. A check
+// that does not complete in time is reported as failed.
+func CheckTimeout(d time.Duration) CheckOption {
+	return func(c *check) {
+		c.timeout = d
+	}
+}
+
+// CheckCacheTTL sets the duration during which the result of the check is reused instead of
+// running the check again, DefaultCheckCacheTTL by default. Zero disables caching.
+func CheckCacheTTL(d time.Duration) CheckOption {
+	return func(c *check) {
+		c.ttl = d
+	}
+}
+
+// Liveness makes the liveness endpoint run the check in addition to the readiness endpoint. Use
+// it only for checks whose failure requires a restart of the process, liveness reports the
+// service healthy when no check uses this option.
+func Liveness() CheckOption {
+	return func(c *check) {
+		c.liveness = true
+	}
+}
+
+// AddCheck registers a health check under the given name. Registering a check with the name of
+// an existing check replaces it.
+func (service *Service) AddCheck(name string, c Checker, opts ...CheckOption) {
+	ch := &check{
+		name:    name,
+		checker: c,
+		timeout: DefaultCheckTimeout,
+		ttl:     DefaultCheckCacheTTL,
+	}
+	for _, o := range opts {
+		o(ch)
+	}
+	h := &service.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, existing := range h.checks {
+		if existing.name == name {
+			h.checks[i] = ch
+			return
+		}
+	}
+	h.checks = append(h.checks, ch)
+}
+
+// Readiness runs all the registered checks concurrently and returns the aggregated report. The
+// report status is HealthFail if any check fails or if the service is shutting down.
+func (service *Service) Readiness(ctx context.Context) *HealthReport {
+	report := service.runChecks(ctx, false)
+	if service.ShuttingDown() {
+		report.Status = HealthFail
+		report.Error = ErrShuttingDown.Error()
+	}
+	return report
+}
+
+// Liveness runs the checks registered with the Liveness option concurrently and returns the
+// aggregated report.
+func (service *Service) Liveness(ctx context.Context) *HealthReport {
+	return service.runChecks(ctx, true)
+}
+
+// MountHealth mounts the health endpoints on the service mux:
+//
+//	GET path        readiness report
+//	GET path/ready  readiness report
+//	GET path/live   liveness report
+//
+// The endpoints respond with status 200 when the report status is HealthOK and 503 otherwise.
+// They are mounted directly on the mux and thus bypass the service middleware. Mounting the
+// same path more than once has no effect.
+func (service *Service) MountHealth(path string) {
+	h := &service.health
+	h.mu.Lock()
+	if h.mounted[path] {
+		h.mu.Unlock()
+		return
+	}
+	if h.mounted == nil {
+		h.mounted = make(map[string]bool)
+	}
+	h.mounted[path] = true
+	h.mu.Unlock()
+
+	base := strings.TrimSuffix(path, "/")
+	ready := service.healthHandler(service.Readiness)
+	service.Mux.Handle("GET", path, ready)
+	service.Mux.Handle("GET", base+"/ready", ready)
+	service.Mux.Handle("GET", base+"/live", service.healthHandler(service.Liveness))
+	service.LogInfo("mount", "health", path)
+}
+
+// healthHandler returns a mux handler that writes the report produced by the given function.
+func (service *Service) healthHandler(report func(context.Context) *HealthReport) MuxHandler {
+	return func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+		r := report(req.Context())
+		status := http.StatusOK
+		if r.Status != HealthOK {
+			status = http.StatusServiceUnavailable
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Cache-Control", "no-store")
+		rw.WriteHeader(status)
+		if err := json.NewEncoder(rw).Encode(r); err != nil {
+			service.LogError("health", "err", err)
+		}
+	}
+}
+
+// runChecks runs the registered checks concurrently, liveness restricts the run to the checks
+// registered with the Liveness option.
+func (service *Service) runChecks(ctx context.Context, liveness bool) *HealthReport {
+	h := &service.health
+	h.mu.Lock()
+	var checks []*check
+	for _, c := range h.checks {
+		if !liveness || c.liveness {
+			checks = append(checks, c)
+		}
+	}
+	h.mu.Unlock()
+
+	results := make([]*CheckResult, len(checks))
+	var wg sync.WaitGroup
+	wg.Add(len(checks))
+	for i, c := range checks {
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := &HealthReport{Status: HealthOK, Checks: make(map[string]*CheckResult, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != HealthOK {
+			report.Status = HealthFail
+		}
+	}
+	return report
+}
+
+// run returns the cached result of the check if still valid, otherwise it runs the check with
+// the check timeout and caches the result.
+func (c *check) run(ctx context.Context) *CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if c.result != nil && now.Before(c.expires) {
+		return c.result
+	}
+
+	parent := ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	errc := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errc <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		errc <- c.checker.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+		if err == context.DeadlineExceeded {
+			err = fmt.Errorf("timeout after %s", c.timeout)
+		}
+	}
+
+	res := &CheckResult{Status: HealthOK, Duration: time.Since(now).String(), CheckedAt: now}
+	if err != nil {
+		res.Status = HealthFail
+		res.Error = err.Error()
+	}
+	if parent.Err() == nil {
+		// Do not cache results of checks interrupted by the caller.
+		c.result = res
+		c.expires = now.Add(c.ttl)
+	}
+	return res
+}
@@ -0,0 +1,149 @@
+package goa_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Health", func() {
+	var s *goa.Service
+
+	BeforeEach(func() {
+		s = goa.New("test")
+		s.WithLogger(goa.NewLogger(log.New(ioutil.Discard, "", 0)))
+	})
+
+	Context("Readiness", func() {
+		It("reports ok when no check is registered", func() {
+			report := s.Readiness(context.Background())
+			Ω(report.Status).Should(Equal(goa.HealthOK))
+			Ω(report.Checks).Should(BeEmpty())
+		})
+
+		It("aggregates the check results", func() {
+			s.AddCheck("db", goa.CheckerFunc(func(context.Context) error { return nil }))
+			s.AddCheck("cache", goa.CheckerFunc(func(context.Context) error { return errors.New("unreachable") }))
+			report := s.Readiness(context.Background())
+			Ω(report.Status).Should(Equal(goa.HealthFail))
+			Ω(report.Checks).Should(HaveLen(2))
+			Ω(report.Checks["db"].Status).Should(Equal(goa.HealthOK))
+			Ω(report.Checks["cache"].Status).Should(Equal(goa.HealthFail))
+			Ω(report.Checks["cache"].Error).Should(Equal("unreachable"))
+		})
+
+		It("fails checks that time out", func() {
+			s.AddCheck("slow", goa.CheckerFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}), goa.CheckTimeout(10*time.Millisecond))
+			report := s.Readiness(context.Background())
+			Ω(report.Status).Should(Equal(goa.HealthFail))
+			Ω(report.Checks["slow"].Error).Should(ContainSubstring("timeout"))
+		})
+
+		It("recovers from panicking checks", func() {
+			s.AddCheck("panic", goa.CheckerFunc(func(context.Context) error { panic("boom") }))
+			report := s.Readiness(context.Background())
+			Ω(report.Checks["panic"].Status).Should(Equal(goa.HealthFail))
+			Ω(report.Checks["panic"].Error).Should(Equal("panic: boom"))
+		})
+
+		It("caches the check results", func() {
+			var runs int32
+			s.AddCheck("db", goa.CheckerFunc(func(context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			}), goa.CheckCacheTTL(time.Minute))
+			s.Readiness(context.Background())
+			s.Readiness(context.Background())
+			Ω(atomic.LoadInt32(&runs)).Should(Equal(int32(1)))
+		})
+
+		It("runs the checks again when caching is disabled", func() {
+			var runs int32
+			s.AddCheck("db", goa.CheckerFunc(func(context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			}), goa.CheckCacheTTL(0))
+			s.Readiness(context.Background())
+			s.Readiness(context.Background())
+			Ω(atomic.LoadInt32(&runs)).Should(Equal(int32(2)))
+		})
+
+		It("fails once the service is shutting down", func() {
+			Ω(s.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+			report := s.Readiness(context.Background())
+			Ω(report.Status).Should(Equal(goa.HealthFail))
+			Ω(report.Error).Should(Equal(goa.ErrShuttingDown.Error()))
+		})
+
+		It("does not replace a check named shutdown", func() {
+			s.AddCheck("shutdown", goa.CheckerFunc(func(context.Context) error { return nil }))
+			Ω(s.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+			report := s.Readiness(context.Background())
+			Ω(report.Status).Should(Equal(goa.HealthFail))
+			Ω(report.Checks["shutdown"].Status).Should(Equal(goa.HealthOK))
+		})
+	})
+
+	Context("Liveness", func() {
+		It("only runs the liveness checks", func() {
+			s.AddCheck("db", goa.CheckerFunc(func(context.Context) error { return errors.New("down") }))
+			s.AddCheck("deadlock", goa.CheckerFunc(func(context.Context) error { return nil }), goa.Liveness())
+			report := s.Liveness(context.Background())
+			Ω(report.Status).Should(Equal(goa.HealthOK))
+			Ω(report.Checks).Should(HaveLen(1))
+			Ω(report.Checks).Should(HaveKey("deadlock"))
+		})
+	})
+
+	Context("MountHealth", func() {
+		var rw *httptest.ResponseRecorder
+
+		serve := func(path string) *goa.HealthReport {
+			rw = httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", path, nil)
+			s.Mux.ServeHTTP(rw, req)
+			var report goa.HealthReport
+			Ω(json.Unmarshal(rw.Body.Bytes(), &report)).ShouldNot(HaveOccurred())
+			return &report
+		}
+
+		BeforeEach(func() {
+			s.AddCheck("db", goa.CheckerFunc(func(context.Context) error { return errors.New("down") }))
+			s.MountHealth("/healthz")
+		})
+
+		It("serves the readiness report", func() {
+			for _, p := range []string{"/healthz", "/healthz/ready"} {
+				report := serve(p)
+				Ω(rw.Code).Should(Equal(http.StatusServiceUnavailable))
+				Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+				Ω(rw.Header().Get("Cache-Control")).Should(Equal("no-store"))
+				Ω(report.Status).Should(Equal(goa.HealthFail))
+				Ω(report.Checks["db"].Error).Should(Equal("down"))
+			}
+		})
+
+		It("serves the liveness report", func() {
+			report := serve("/healthz/live")
+			Ω(rw.Code).Should(Equal(http.StatusOK))
+			Ω(report.Status).Should(Equal(goa.HealthOK))
+		})
+
+		It("can be called more than once", func() {
+			Ω(func() { s.MountHealth("/healthz") }).ShouldNot(Panic())
+		})
+	})
+})
@@ -25,6 +25,17 @@ type (
 		New(keyvals ...interface{}) LogAdapter
 	}
 
+	// LeveledLogAdapter is implemented by the log adapters that support the debug and warning
+	// levels in addition to the informational and error levels. Use LogDebug and LogWarn to log
+	// messages at these levels with any adapter.
+	LeveledLogAdapter interface {
+		LogAdapter
+		// Debug logs a debug message.
+		Debug(msg string, keyvals ...interface{})
+		// Warn logs a warning.
+		Warn(msg string, keyvals ...interface{})
+	}
+
 	// adapter is the stdlib logger adapter.
 	adapter struct {
 		*log.Logger
@@ -121,3 +132,30 @@ func LogError(ctx context.Context, msg string, keyvals ...interface{}) {
 		}
 	}
 }
+
+// LogDebug extracts the logger from the given context and calls Debug on it if it implements
+// LeveledLogAdapter. The message is discarded otherwise so that debug messages do not show up
+// as informational messages.
+// This is intended for code that needs portable logging such as the internal code of goa and
+// middleware. User code should use the log adapters instead.
+func LogDebug(ctx context.Context, msg string, keyvals ...interface{}) {
+	if l := ctx.Value(logKey); l != nil {
+		if logger, ok := l.(LeveledLogAdapter); ok {
+			logger.Debug(msg, keyvals...)
+		}
+	}
+}
+
+// LogWarn extracts the logger from the given context and calls Warn on it if it implements
+// LeveledLogAdapter, Info otherwise.
+// This is intended for code that needs portable logging such as the internal code of goa and
+// middleware. User code should use the log adapters instead.
+func LogWarn(ctx context.Context, msg string, keyvals ...interface{}) {
+	if l := ctx.Value(logKey); l != nil {
+		if logger, ok := l.(LeveledLogAdapter); ok {
+			logger.Warn(msg, keyvals...)
+		} else if logger, ok := l.(LogAdapter); ok {
+			logger.Info(msg, keyvals...)
+		}
+	}
+}
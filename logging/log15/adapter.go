@@ -25,7 +25,8 @@ type adapter struct {
 	log15.Logger
 }
 
-// New wraps a log15 logger into a goa logger adapter.
+// New wraps a log15 logger into a goa logger adapter. The adapter implements
+// goa.LeveledLogAdapter.
 func New(logger log15.Logger) goa.LogAdapter {
 	return &adapter{Logger: logger}
 }
@@ -49,6 +50,16 @@ func (a *adapter) Error(msg string, data ...interface{}) {
 	a.Logger.Error(msg, data...)
 }
 
+// Debug logs debug messages using log15.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.Logger.Debug(msg, data...)
+}
+
+// Warn logs warnings using log15.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.Logger.Warn(msg, data...)
+}
+
 // New creates a new logger given a context.
 func (a *adapter) New(data ...interface{}) goa.LogAdapter {
 	return &adapter{Logger: a.Logger.New(data...)}
@@ -38,6 +38,15 @@ var _ = Describe("New", func() {
 		Ω(handler.records[0].Msg).Should(ContainSubstring(msg))
 	})
 
+	It("supports the debug and warning levels", func() {
+		ctx := goa.WithLogger(context.Background(), adapter)
+		goa.LogDebug(ctx, "debug")
+		goa.LogWarn(ctx, "warn")
+		Ω(handler.records).Should(HaveLen(2))
+		Ω(handler.records[0].Lvl).Should(Equal(log15.LvlDebug))
+		Ω(handler.records[1].Lvl).Should(Equal(log15.LvlWarn))
+	})
+
 	Context("Logger", func() {
 		var ctx context.Context
 
@@ -27,7 +27,7 @@ type adapter struct {
 	*logrus.Entry
 }
 
-// New wraps a logrus logger into a goa logger.
+// New wraps a logrus logger into a goa logger. The adapter implements goa.LeveledLogAdapter.
 func New(logger *logrus.Logger) goa.LogAdapter {
 	return FromEntry(logrus.NewEntry(logger))
 }
@@ -56,6 +56,16 @@ func (a *adapter) Error(msg string, data ...interface{}) {
 	a.Entry.WithFields(data2rus(data)).Error(msg)
 }
 
+// Debug logs debug messages using logrus.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.Entry.WithFields(data2rus(data)).Debug(msg)
+}
+
+// Warn logs warnings using logrus.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.Entry.WithFields(data2rus(data)).Warn(msg)
+}
+
 // New creates a new logger given a context.
 func (a *adapter) New(data ...interface{}) goa.LogAdapter {
 	return &adapter{Entry: a.Entry.WithFields(data2rus(data))}
@@ -28,6 +28,18 @@ var _ = Describe("goalogrus", func() {
 		adapter.Info(msg)
 		Ω(buf.String()).Should(ContainSubstring(msg))
 	})
+
+	It("supports the debug and warning levels", func() {
+		buf.Reset()
+		ctx := goa.WithLogger(context.Background(), adapter)
+		goa.LogDebug(ctx, "debug")
+		Ω(buf.String()).Should(BeEmpty())
+		logger.Level = logrus.DebugLevel
+		goa.LogDebug(ctx, "debug")
+		goa.LogWarn(ctx, "warn")
+		Ω(buf.String()).Should(ContainSubstring("level=debug msg=debug"))
+		Ω(buf.String()).Should(ContainSubstring("level=warning msg=warn"))
+	})
 })
 
 var _ = Describe("FromEntry", func() {
@@ -0,0 +1,85 @@
+//go:build go1.21
+// +build go1.21
+
+/*
+Package goaslog contains an adapter that makes it possible to configure goa so it uses the
+standard library log/slog package as logger backend. The package requires Go 1.21 or later.
+Usage:
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	// Initialize goa service logger using adapter
+	service.WithLogger(goaslog.New(logger))
+	// ... Proceed with configuring and starting the goa service
+
+	// In handlers:
+	goaslog.Logger(ctx).Info("foo", "bar", "baz")
+
+The adapter implements goa.LeveledLogAdapter, the slog handler level decides whether debug
+messages are logged.
+*/
+package goaslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/goadesign/goa"
+)
+
+// adapter is the slog goa logger adapter.
+type adapter struct {
+	*slog.Logger
+}
+
+// New wraps a slog logger into a goa logger adapter.
+func New(logger *slog.Logger) goa.LogAdapter {
+	return &adapter{Logger: logger}
+}
+
+// Logger returns the slog logger stored in the given context if any, nil otherwise.
+func Logger(ctx context.Context) *slog.Logger {
+	logger := goa.ContextLogger(ctx)
+	if a, ok := logger.(*adapter); ok {
+		return a.Logger
+	}
+	return nil
+}
+
+// Info logs informational messages using slog.
+func (a *adapter) Info(msg string, data ...interface{}) {
+	a.Logger.Info(msg, args(data)...)
+}
+
+// Error logs error messages using slog.
+func (a *adapter) Error(msg string, data ...interface{}) {
+	a.Logger.Error(msg, args(data)...)
+}
+
+// Debug logs debug messages using slog.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.Logger.Debug(msg, args(data)...)
+}
+
+// Warn logs warnings using slog.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.Logger.Warn(msg, args(data)...)
+}
+
+// New creates a new logger given a context.
+func (a *adapter) New(data ...interface{}) goa.LogAdapter {
+	return &adapter{Logger: a.Logger.With(args(data)...)}
+}
+
+// args converts the goa key/value pairs into slog attributes.
+func args(keyvals []interface{}) []interface{} {
+	res := make([]interface{}, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = goa.ErrMissingLogValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		res = append(res, slog.Any(fmt.Sprintf("%v", keyvals[i]), v))
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+//go:build go1.21
+// +build go1.21
+
+package goaslog_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+
+	"github.com/goadesign/goa"
+	goaslog "github.com/goadesign/goa/logging/slog"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var logger *slog.Logger
+	var adapter goa.LogAdapter
+	var buf bytes.Buffer
+
+	BeforeEach(func() {
+		buf.Reset()
+		logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+		adapter = goaslog.New(logger)
+	})
+
+	It("creates an adapter that logs", func() {
+		adapter.Info("msg", "foo", "bar")
+		Ω(buf.String()).Should(ContainSubstring("level=INFO msg=msg foo=bar"))
+	})
+
+	It("appends to the logger context", func() {
+		adapter.New("id", 42).Error("msg", "odd")
+		Ω(buf.String()).Should(ContainSubstring("level=ERROR msg=msg id=42 odd=MISSING"))
+	})
+
+	It("supports the debug and warning levels", func() {
+		ctx := goa.WithLogger(context.Background(), adapter)
+		goa.LogDebug(ctx, "debug")
+		goa.LogWarn(ctx, "warn")
+		Ω(buf.String()).ShouldNot(ContainSubstring("debug"))
+		Ω(buf.String()).Should(ContainSubstring("level=WARN msg=warn"))
+	})
+
+	Context("Logger", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = goa.WithLogger(context.Background(), adapter)
+		})
+
+		It("extracts the logger", func() {
+			Ω(goaslog.Logger(ctx)).Should(Equal(logger))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+/*
+Package goazap contains an adapter that makes it possible to configure goa so it uses zap as
+logger backend.
+Usage:
+
+    logger, _ := zap.NewProduction()
+    // Initialize goa service logger using adapter
+    service.WithLogger(goazap.New(logger))
+    // ... Proceed with configuring and starting the goa service
+
+    // In handlers:
+    goazap.Logger(ctx).Info("foo", zap.String("bar", "baz"))
+
+The adapter implements goa.LeveledLogAdapter, the zap logger level decides whether debug
+messages are logged.
+*/
+package goazap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/goadesign/goa"
+	"go.uber.org/zap"
+)
+
+// adapter is the zap goa logger adapter.
+type adapter struct {
+	*zap.Logger
+}
+
+// New wraps a zap logger into a goa logger adapter.
+func New(logger *zap.Logger) goa.LogAdapter {
+	return &adapter{Logger: logger}
+}
+
+// Logger returns the zap logger stored in the given context if any, nil otherwise.
+func Logger(ctx context.Context) *zap.Logger {
+	logger := goa.ContextLogger(ctx)
+	if a, ok := logger.(*adapter); ok {
+		return a.Logger
+	}
+	return nil
+}
+
+// Info logs informational messages using zap.
+func (a *adapter) Info(msg string, data ...interface{}) {
+	a.Logger.Info(msg, fields(data)...)
+}
+
+// Error logs error messages using zap.
+func (a *adapter) Error(msg string, data ...interface{}) {
+	a.Logger.Error(msg, fields(data)...)
+}
+
+// Debug logs debug messages using zap.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.Logger.Debug(msg, fields(data)...)
+}
+
+// Warn logs warnings using zap.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.Logger.Warn(msg, fields(data)...)
+}
+
+// New creates a new logger given a context.
+func (a *adapter) New(data ...interface{}) goa.LogAdapter {
+	return &adapter{Logger: a.Logger.With(fields(data)...)}
+}
+
+// fields converts the goa key/value pairs into zap fields.
+func fields(keyvals []interface{}) []zap.Field {
+	res := make([]zap.Field, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = goa.ErrMissingLogValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		res = append(res, zap.Any(fmt.Sprintf("%v", keyvals[i]), v))
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package goazap_test
+
+import (
+	"context"
+
+	"github.com/goadesign/goa"
+	goazap "github.com/goadesign/goa/logging/zap"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var _ = Describe("New", func() {
+	var logger *zap.Logger
+	var logs *observer.ObservedLogs
+	var adapter goa.LogAdapter
+
+	BeforeEach(func() {
+		var core zapcore.Core
+		core, logs = observer.New(zapcore.InfoLevel)
+		logger = zap.New(core)
+		adapter = goazap.New(logger)
+	})
+
+	It("creates an adapter that logs", func() {
+		adapter.Info("msg", "foo", "bar")
+		Ω(logs.Len()).Should(Equal(1))
+		e := logs.All()[0]
+		Ω(e.Level).Should(Equal(zapcore.InfoLevel))
+		Ω(e.Message).Should(Equal("msg"))
+		Ω(e.ContextMap()).Should(Equal(map[string]interface{}{"foo": "bar"}))
+	})
+
+	It("appends to the logger context", func() {
+		adapter.New("id", 42).Error("msg", "odd")
+		e := logs.All()[0]
+		Ω(e.Level).Should(Equal(zapcore.ErrorLevel))
+		Ω(e.ContextMap()).Should(Equal(map[string]interface{}{"id": int64(42), "odd": goa.ErrMissingLogValue}))
+	})
+
+	It("supports the debug and warning levels", func() {
+		ctx := goa.WithLogger(context.Background(), adapter)
+		goa.LogDebug(ctx, "debug")
+		goa.LogWarn(ctx, "warn")
+		Ω(logs.Len()).Should(Equal(1))
+		Ω(logs.All()[0].Level).Should(Equal(zapcore.WarnLevel))
+	})
+
+	Context("Logger", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = goa.WithLogger(context.Background(), adapter)
+		})
+
+		It("extracts the logger", func() {
+			Ω(goazap.Logger(ctx)).Should(Equal(logger))
+		})
+	})
+})
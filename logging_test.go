@@ -27,6 +27,71 @@ var _ = Describe("Error", func() {
 	})
 })
 
+var _ = Describe("Debug and Warn", func() {
+	var out bytes.Buffer
+	var ctx context.Context
+
+	BeforeEach(func() {
+		out.Reset()
+	})
+
+	Context("with a nil Log", func() {
+		It("doesn't log and doesn't crash", func() {
+			Ω(func() { goa.LogDebug(context.Background(), "foo", "bar") }).ShouldNot(Panic())
+			Ω(func() { goa.LogWarn(context.Background(), "foo", "bar") }).ShouldNot(Panic())
+		})
+	})
+
+	Context("with a logger that does not support levels", func() {
+		BeforeEach(func() {
+			ctx = goa.WithLogger(context.Background(), goa.NewLogger(log.New(&out, "", 0)))
+		})
+
+		It("discards debug messages", func() {
+			goa.LogDebug(ctx, "foo", "bar", "baz")
+			Ω(out.String()).Should(BeEmpty())
+		})
+
+		It("logs warnings as informational messages", func() {
+			goa.LogWarn(ctx, "foo", "bar", "baz")
+			Ω(out.String()).Should(Equal("[INFO] foo bar=baz\n"))
+		})
+	})
+
+	Context("with a leveled logger", func() {
+		var logger *leveledLogger
+
+		BeforeEach(func() {
+			logger = &leveledLogger{}
+			ctx = goa.WithLogger(context.Background(), logger)
+		})
+
+		It("logs debug messages and warnings", func() {
+			goa.LogDebug(ctx, "foo")
+			goa.LogWarn(ctx, "bar")
+			Ω(logger.logs).Should(Equal([]string{"debug foo", "warn bar"}))
+		})
+	})
+})
+
+type leveledLogger struct {
+	logs []string
+}
+
+func (l *leveledLogger) Info(msg string, keyvals ...interface{}) {
+	l.logs = append(l.logs, "info "+msg)
+}
+func (l *leveledLogger) Error(msg string, keyvals ...interface{}) {
+	l.logs = append(l.logs, "error "+msg)
+}
+func (l *leveledLogger) Debug(msg string, keyvals ...interface{}) {
+	l.logs = append(l.logs, "debug "+msg)
+}
+func (l *leveledLogger) Warn(msg string, keyvals ...interface{}) {
+	l.logs = append(l.logs, "warn "+msg)
+}
+func (l *leveledLogger) New(keyvals ...interface{}) goa.LogAdapter { return l }
+
 var _ = Describe("LogAdapter", func() {
 	Context("with a valid Log", func() {
 		var logger goa.LogAdapter
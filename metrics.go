@@ -40,6 +40,23 @@ type Collector interface {
 	SetGauge(key []string, val float32)
 }
 
+// LabeledCollector is the interface implemented by collectors that support labels. goa emits the
+// metrics it collects with labels to these collectors, other collectors get the label values
+// appended to the metric keys instead.
+type LabeledCollector interface {
+	Collector
+	AddSampleWithLabels(key []string, val float32, labels []Label)
+	IncrCounterWithLabels(key []string, val float32, labels []Label)
+	MeasureSinceWithLabels(key []string, start time.Time, labels []Label)
+	SetGaugeWithLabels(key []string, val float32, labels []Label)
+}
+
+// Label is a metric label.
+type Label struct {
+	Name  string
+	Value string
+}
+
 func init() {
 	SetMetrics(NewNoOpCollector())
 }
@@ -139,6 +156,39 @@ func MeasureSince(key []string, start time.Time) {
 	GetMetrics().MeasureSince(key, start)
 }
 
+// IncrCounterWithLabels increments the counter named by `key` with the given labels. The label
+// values are appended to the key if the collector does not implement LabeledCollector.
+// Usage:
+//     IncrCounterWithLabels([]string{"my","namespace","counter"}, 1.0, []Label{{"status", "200"}})
+func IncrCounterWithLabels(key []string, val float32, labels []Label) {
+	m := GetMetrics()
+	if lc, ok := m.(LabeledCollector); ok {
+		normalizeKeys(key)
+		lc.IncrCounterWithLabels(key, val, labels)
+		return
+	}
+	key = appendLabels(key, labels)
+	normalizeKeys(key)
+	m.IncrCounter(key, val)
+}
+
+// MeasureSinceWithLabels creates a timing metric with the given labels that records the duration
+// of elapsed time since `start`. The label values are appended to the key if the collector does
+// not implement LabeledCollector.
+// Usage:
+//     defer MeasureSinceWithLabels([]string{"my","namespace","action"}, time.Now(), []Label{{"content_type", ct}})
+func MeasureSinceWithLabels(key []string, start time.Time, labels []Label) {
+	m := GetMetrics()
+	if lc, ok := m.(LabeledCollector); ok {
+		normalizeKeys(key)
+		lc.MeasureSinceWithLabels(key, start, labels)
+		return
+	}
+	key = appendLabels(key, labels)
+	normalizeKeys(key)
+	m.MeasureSince(key, start)
+}
+
 // SetGauge sets the named gauge to the specified value
 // Usage:
 //     SetGauge([]string{"my","namespace"}, 2.0)
@@ -148,6 +198,16 @@ func SetGauge(key []string, val float32) {
 	GetMetrics().SetGauge(key, val)
 }
 
+// appendLabels returns a new key made of key followed by the label values.
+func appendLabels(key []string, labels []Label) []string {
+	res := make([]string, len(key), len(key)+len(labels))
+	copy(res, key)
+	for _, l := range labels {
+		res = append(res, l.Value)
+	}
+	return res
+}
+
 // This function is used to make metric names safe for all metric services. Specifically, prometheus does
 // not support * or / in metric names.
 func normalizeKeys(key []string) {
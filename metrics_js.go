@@ -15,3 +15,19 @@ func IncrCounter(key []string, val float32) {
 func MeasureSince(key []string, start time.Time) {
 	// Do nothing
 }
+
+// Label is a metric label.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Not supported in gopherjs
+func IncrCounterWithLabels(key []string, val float32, labels []Label) {
+	// Do nothing
+}
+
+// Not supported in gopherjs
+func MeasureSinceWithLabels(key []string, start time.Time, labels []Label) {
+	// Do nothing
+}
@@ -121,3 +121,59 @@ var _ = Describe("Metrics", func() {
 		})
 	})
 })
+
+var _ = Describe("Labeled metrics", func() {
+	var collector *labeledCollector
+
+	BeforeEach(func() {
+		collector = &labeledCollector{}
+	})
+
+	AfterEach(func() {
+		goa.SetMetrics(goa.NewNoOpCollector())
+	})
+
+	It("passes the labels to labeled collectors", func() {
+		goa.SetMetrics(collector)
+		goa.IncrCounterWithLabels([]string{"goa", "response"}, 1, []goa.Label{{Name: "status", Value: "200"}})
+		Ω(collector.key).Should(Equal([]string{"goa", "response"}))
+		Ω(collector.labels).Should(Equal([]goa.Label{{Name: "status", Value: "200"}}))
+	})
+
+	It("appends the label values to the key of other collectors", func() {
+		goa.SetMetrics(&collector.flat)
+		goa.MeasureSinceWithLabels([]string{"goa", "decode"}, time.Now(), []goa.Label{{Name: "content_type", Value: "*/*"}})
+		Ω(collector.flat.key).Should(Equal([]string{"goa", "decode", "all"}))
+	})
+})
+
+// flatCollector records the key of the last metric.
+type flatCollector struct {
+	key []string
+}
+
+func (c *flatCollector) AddSample(key []string, val float32)        { c.key = key }
+func (c *flatCollector) EmitKey(key []string, val float32)          { c.key = key }
+func (c *flatCollector) IncrCounter(key []string, val float32)      { c.key = key }
+func (c *flatCollector) MeasureSince(key []string, start time.Time) { c.key = key }
+func (c *flatCollector) SetGauge(key []string, val float32)         { c.key = key }
+
+// labeledCollector records the key and labels of the last metric.
+type labeledCollector struct {
+	flatCollector
+	flat   flatCollector
+	labels []goa.Label
+}
+
+func (c *labeledCollector) AddSampleWithLabels(key []string, val float32, labels []goa.Label) {
+	c.key, c.labels = key, labels
+}
+func (c *labeledCollector) IncrCounterWithLabels(key []string, val float32, labels []goa.Label) {
+	c.key, c.labels = key, labels
+}
+func (c *labeledCollector) MeasureSinceWithLabels(key []string, start time.Time, labels []goa.Label) {
+	c.key, c.labels = key, labels
+}
+func (c *labeledCollector) SetGaugeWithLabels(key []string, val float32, labels []goa.Label) {
+	c.key, c.labels = key, labels
+}
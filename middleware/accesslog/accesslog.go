@@ -0,0 +1,393 @@
+package accesslog
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+)
+
+type (
+	// Option configures the middleware.
+	Option func(*options)
+
+	// Format is the format of the access log entries.
+	Format int
+
+	// Level is the level used to log an entry.
+	Level int
+
+	options struct {
+		format   Format
+		output   io.Writer
+		verbose  bool
+		maxBody  int
+		headers  map[string]bool
+		params   map[string]bool
+		fields   []string
+		levels   [6]Level
+		sampler  middleware.Sampler
+		proxies  []*net.IPNet
+		outputMu sync.Mutex
+	}
+
+	// field is a key/value pair of a log entry.
+	field struct {
+		key   string
+		value interface{}
+	}
+
+	// entry is the access log entry of a request.
+	entry struct {
+		fields []field
+		// remaining fields are used by the combined log format
+		time      time.Time
+		from      string
+		request   string
+		status    int
+		bytes     int
+		referer   string
+		userAgent string
+	}
+)
+
+const (
+	// FormatKeyValues hands the entry to the goa logger as key/value pairs. This is the default.
+	FormatKeyValues Format = iota
+	// FormatLogfmt renders the entry using the logfmt format.
+	FormatLogfmt
+	// FormatJSON renders the entry as a JSON object.
+	FormatJSON
+	// FormatCombined renders the entry using the Apache combined log format.
+	FormatCombined
+)
+
+const (
+	// LevelNone disables logging.
+	LevelNone Level = iota
+	// LevelInfo logs the entry with the goa logger Info method.
+	LevelInfo
+	// LevelError logs the entry with the goa logger Error method.
+	LevelError
+)
+
+// Redacted is the value logged in place of the redacted values.
+const Redacted = "<redacted>"
+
+// DefaultMaxBodySize is the default maximum number of bytes of the payload that are logged.
+const DefaultMaxBodySize = 1024
+
+// defaultRedactedHeaders lists the headers redacted by default.
+var defaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// WithFormat sets the format of the log entries.
+func WithFormat(f Format) Option {
+	return func(o *options) {
+		o.format = f
+	}
+}
+
+// Output writes the log entries to w instead of the goa logger. The entries are written one per
+// line and include the request ID if any. The level has no effect on the output other than
+// LevelNone which disables logging.
+func Output(w io.Writer) Option {
+	return func(o *options) {
+		o.output = w
+	}
+}
+
+// Verbose causes the entries to include the request headers, parameters and payload.
+func Verbose() Option {
+	return func(o *options) {
+		o.verbose = true
+	}
+}
+
+// MaxBodySize sets the maximum number of bytes of the serialized payload that are logged, longer
+// payloads are truncated. The default is DefaultMaxBodySize, a negative value disables the limit.
+func MaxBodySize(n int) Option {
+	return func(o *options) {
+		o.maxBody = n
+	}
+}
+
+// RedactHeaders redacts the values of the given request headers in addition to the
+// Authorization, Proxy-Authorization, Cookie and Set-Cookie headers.
+func RedactHeaders(names ...string) Option {
+	return func(o *options) {
+		for _, n := range names {
+			o.headers[http.CanonicalHeaderKey(n)] = true
+		}
+	}
+}
+
+// RedactParams redacts the values of the given path and query string parameters.
+func RedactParams(names ...string) Option {
+	return func(o *options) {
+		for _, n := range names {
+			o.params[n] = true
+		}
+	}
+}
+
+// RedactFields redacts the payload attributes at the given paths in addition to the paths
+// returned by the payload RedactedFields method. See Redact for the path syntax.
+func RedactFields(paths ...string) Option {
+	return func(o *options) {
+		o.fields = append(o.fields, paths...)
+	}
+}
+
+// StatusLevel sets the level used to log the requests whose response status belongs to the
+// given class, e.g. 4 for 4xx responses. Requests are logged at LevelInfo by default except for
+// 5xx responses which are logged at LevelError.
+func StatusLevel(class int, l Level) Option {
+	return func(o *options) {
+		if class >= 0 && class < len(o.levels) {
+			o.levels[class] = l
+		}
+	}
+}
+
+// Sample causes only a sample of the successful requests to be logged. Requests whose response
+// status is 400 or more are always logged.
+func Sample(s middleware.Sampler) Option {
+	return func(o *options) {
+		o.sampler = s
+	}
+}
+
+// TrustedProxies causes the client address to be read from the X-Forwarded-For request header
+// when the request comes from one of the given proxies. Proxies are given as IP addresses or CIDR
+// ranges, e.g. "10.0.0.0/8". The logged address is the last address of the header that is not a
+// trusted proxy. By default the header is ignored since any client can set it and the address
+// is the request remote address. TrustedProxies panics if a value is not a valid IP address or
+// CIDR range.
+func TrustedProxies(proxies ...string) Option {
+	return func(o *options) {
+		for _, p := range proxies {
+			if !strings.Contains(p, "/") {
+				if ip := net.ParseIP(p); ip != nil && ip.To4() != nil {
+					p += "/32"
+				} else {
+					p += "/128"
+				}
+			}
+			_, n, err := net.ParseCIDR(p)
+			if err != nil {
+				panic("invalid trusted proxy: " + err.Error())
+			}
+			o.proxies = append(o.proxies, n)
+		}
+	}
+}
+
+// New returns a middleware that logs a single entry when a request completes. The middleware
+// adds the request ID set by the RequestID middleware to the context logger.
+func New(opts ...Option) goa.Middleware {
+	o := &options{
+		maxBody: DefaultMaxBodySize,
+		headers: make(map[string]bool),
+		params:  make(map[string]bool),
+		levels:  [6]Level{LevelInfo, LevelInfo, LevelInfo, LevelInfo, LevelInfo, LevelError},
+	}
+	for _, h := range defaultRedactedHeaders {
+		o.headers[h] = true
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			reqID := middleware.ContextRequestID(ctx)
+			if reqID != "" {
+				ctx = goa.WithLogContext(ctx, "req_id", reqID)
+			}
+			startedAt := time.Now()
+			err := h(ctx, rw, req)
+
+			resp := goa.ContextResponse(ctx)
+			status := resp.Status
+			if status == 0 {
+				if err != nil {
+					status = http.StatusInternalServerError
+				} else {
+					status = http.StatusOK
+				}
+			}
+			level := LevelError
+			if class := status / 100; class < len(o.levels) {
+				level = o.levels[class]
+			}
+			if level == LevelNone {
+				return err
+			}
+			if status < 400 && o.sampler != nil && !o.sampler.Sample() {
+				return err
+			}
+			e := o.entry(ctx, req, reqID, status, time.Since(startedAt))
+			if err != nil && resp.ErrorCode == "" {
+				e.add("err", err.Error())
+			}
+			o.log(ctx, level, e)
+			return err
+		}
+	}
+}
+
+// entry builds the log entry of the request.
+func (o *options) entry(ctx context.Context, req *http.Request, reqID string, status int, d time.Duration) *entry {
+	resp := goa.ContextResponse(ctx)
+	r := goa.ContextRequest(ctx)
+	e := &entry{
+		time:      time.Now(),
+		from:      o.from(req),
+		request:   req.Method + " " + req.URL.RequestURI() + " " + req.Proto,
+		status:    status,
+		bytes:     resp.Length,
+		referer:   req.Referer(),
+		userAgent: req.UserAgent(),
+	}
+	if o.output != nil && reqID != "" {
+		e.add("req_id", reqID)
+	}
+	e.add("method", req.Method)
+	e.add("path", req.URL.Path)
+	e.add("from", e.from)
+	e.add("ctrl", goa.ContextController(ctx))
+	e.add("action", goa.ContextAction(ctx))
+	e.add("status", status)
+	if resp.ErrorCode != "" {
+		e.add("error", resp.ErrorCode)
+	}
+	e.add("bytes", resp.Length)
+	e.add("time", d.String())
+	if !o.verbose {
+		return e
+	}
+	for _, k := range sortedKeys(req.Header) {
+		v := strings.Join(req.Header[k], ", ")
+		if o.headers[k] {
+			v = Redacted
+		}
+		e.add("header."+k, v)
+	}
+	if r != nil {
+		for _, k := range sortedKeys(r.Params) {
+			v := strings.Join(r.Params[k], ", ")
+			if o.params[k] {
+				v = Redacted
+			}
+			e.add("param."+k, v)
+		}
+		if r.Payload != nil {
+			e.add("payload", o.payload(r.Payload))
+		}
+	}
+	return e
+}
+
+// payload returns the redacted and truncated JSON representation of the payload.
+func (o *options) payload(p interface{}) string {
+	paths := o.fields
+	if rd, ok := p.(Redactor); ok {
+		paths = append(rd.RedactedFields(), paths...)
+	}
+	js, err := Redact(p, paths...)
+	if err != nil {
+		return "<invalid JSON>"
+	}
+	if o.maxBody >= 0 && len(js) > o.maxBody {
+		return string(js[:o.maxBody]) + "...(truncated)"
+	}
+	return string(js)
+}
+
+// log writes the entry to the output or to the context logger.
+func (o *options) log(ctx context.Context, level Level, e *entry) {
+	if o.output != nil {
+		line := e.format(o.format)
+		o.outputMu.Lock()
+		defer o.outputMu.Unlock()
+		io.WriteString(o.output, line+"\n")
+		return
+	}
+	logf := goa.LogInfo
+	if level == LevelError {
+		logf = goa.LogError
+	}
+	if o.format == FormatKeyValues {
+		logf(ctx, "completed", e.keyvals()...)
+		return
+	}
+	logf(ctx, e.format(o.format))
+}
+
+// add appends a field to the entry.
+func (e *entry) add(key string, value interface{}) {
+	e.fields = append(e.fields, field{key, value})
+}
+
+// keyvals returns the entry fields as a list of alternating keys and values.
+func (e *entry) keyvals() []interface{} {
+	kv := make([]interface{}, 0, 2*len(e.fields))
+	for _, f := range e.fields {
+		kv = append(kv, f.key, f.value)
+	}
+	return kv
+}
+
+// sortedKeys returns the keys of m in lexical order.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// from computes the request client IP. It uses the X-Forwarded-For header only if the request
+// was made by a trusted proxy.
+func (o *options) from(req *http.Request) string {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	if !o.trusted(ip) {
+		return ip
+	}
+	hops := strings.Split(req.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !o.trusted(hop) {
+			return hop
+		}
+		ip = hop
+	}
+	return ip
+}
+
+// trusted returns true if addr is the IP address of a trusted proxy.
+func (o *options) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range o.proxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+package accesslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	"github.com/goadesign/goa/middleware/accesslog"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	card struct {
+		Number string `json:"number"`
+		Holder string `json:"holder"`
+	}
+
+	payload struct {
+		Name     string  `json:"name"`
+		Password string  `json:"password"`
+		Cards    []*card `json:"cards"`
+	}
+
+	logEntry struct {
+		Msg     string
+		KeyVals []interface{}
+		Error   bool
+	}
+
+	testLogger struct {
+		entries []logEntry
+	}
+
+	countSampler struct {
+		calls int
+	}
+)
+
+func (p *payload) RedactedFields() []string {
+	return []string{"password", "cards.number"}
+}
+
+func (l *testLogger) Info(msg string, keyvals ...interface{}) {
+	l.entries = append(l.entries, logEntry{msg, keyvals, false})
+}
+
+func (l *testLogger) Error(msg string, keyvals ...interface{}) {
+	l.entries = append(l.entries, logEntry{msg, keyvals, true})
+}
+
+func (l *testLogger) New(keyvals ...interface{}) goa.LogAdapter {
+	return l
+}
+
+func (s *countSampler) Sample() bool {
+	s.calls++
+	return false
+}
+
+var _ = Describe("New", func() {
+	var (
+		logger *testLogger
+		ctx    context.Context
+		req    *http.Request
+		rw     *httptest.ResponseRecorder
+		status int
+		herr   error
+	)
+
+	BeforeEach(func() {
+		logger = new(testLogger)
+		status = http.StatusOK
+		herr = nil
+		var err error
+		req, err = http.NewRequest("POST", "/users?token=secret&page=2", strings.NewReader("{}"))
+		Ω(err).ShouldNot(HaveOccurred())
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("User-Agent", "test")
+		rw = httptest.NewRecorder()
+		service := &goa.Service{Name: "test", Context: goa.WithLogger(context.Background(), logger)}
+		ctrl := service.NewController("UserController")
+		params := url.Values{"token": {"secret"}, "page": {"2"}}
+		ctx = goa.NewContext(goa.WithAction(ctrl.Context, "create"), rw, req, params)
+		goa.ContextRequest(ctx).Payload = &payload{
+			Name:     "joe",
+			Password: "hunter2",
+			Cards:    []*card{{Number: "4111", Holder: "joe"}},
+		}
+	})
+
+	serve := func(opts ...accesslog.Option) error {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			goa.ContextResponse(ctx).WriteHeader(status)
+			return herr
+		}
+		return middleware.RequestID()(accesslog.New(opts...)(h))(ctx, rw, req)
+	}
+
+	keyvals := func(e logEntry) map[string]interface{} {
+		m := make(map[string]interface{})
+		for i := 0; i < len(e.KeyVals); i += 2 {
+			m[e.KeyVals[i].(string)] = e.KeyVals[i+1]
+		}
+		return m
+	}
+
+	It("logs a single entry with the request summary", func() {
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(logger.entries).Should(HaveLen(1))
+		e := logger.entries[0]
+		Ω(e.Msg).Should(Equal("completed"))
+		Ω(e.Error).Should(BeFalse())
+		Ω(e.KeyVals[:14]).Should(Equal([]interface{}{
+			"method", "POST", "path", "/users", "from", "10.0.0.1",
+			"ctrl", "UserController", "action", "create", "status", 200, "bytes", 0,
+		}))
+		Ω(keyvals(e)).ShouldNot(HaveKey("payload"))
+	})
+
+	It("ignores the X-Forwarded-For header by default", func() {
+		req.Header.Set("X-Forwarded-For", "1.2.3.4")
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(keyvals(logger.entries[0])).Should(HaveKeyWithValue("from", "10.0.0.1"))
+	})
+
+	It("reads the X-Forwarded-For header set by trusted proxies", func() {
+		req.Header.Set("X-Forwarded-For", "6.6.6.6, 1.2.3.4, 10.0.0.2")
+		Ω(serve(accesslog.TrustedProxies("10.0.0.0/24"))).ShouldNot(HaveOccurred())
+		Ω(keyvals(logger.entries[0])).Should(HaveKeyWithValue("from", "1.2.3.4"))
+	})
+
+	It("ignores the X-Forwarded-For header of untrusted clients", func() {
+		req.Header.Set("X-Forwarded-For", "1.2.3.4")
+		Ω(serve(accesslog.TrustedProxies("10.0.0.2"))).ShouldNot(HaveOccurred())
+		Ω(keyvals(logger.entries[0])).Should(HaveKeyWithValue("from", "10.0.0.1"))
+	})
+
+	It("redacts the headers, params and payload fields", func() {
+		Ω(serve(accesslog.Verbose(), accesslog.RedactParams("token"), accesslog.RedactFields("name"))).ShouldNot(HaveOccurred())
+		kv := keyvals(logger.entries[0])
+		Ω(kv).Should(HaveKeyWithValue("header.Authorization", accesslog.Redacted))
+		Ω(kv).Should(HaveKeyWithValue("header.User-Agent", "test"))
+		Ω(kv).Should(HaveKeyWithValue("param.token", accesslog.Redacted))
+		Ω(kv).Should(HaveKeyWithValue("param.page", "2"))
+		Ω(kv).Should(HaveKeyWithValue("payload",
+			`{"cards":[{"holder":"joe","number":"<redacted>"}],"name":"<redacted>","password":"<redacted>"}`))
+	})
+
+	It("truncates the payload", func() {
+		Ω(serve(accesslog.Verbose(), accesslog.MaxBodySize(10))).ShouldNot(HaveOccurred())
+		Ω(keyvals(logger.entries[0])).Should(HaveKeyWithValue("payload", `{"cards":[...(truncated)`))
+	})
+
+	It("uses the level configured for the status class", func() {
+		status = http.StatusNotFound
+		Ω(serve(accesslog.StatusLevel(4, accesslog.LevelError))).ShouldNot(HaveOccurred())
+		Ω(logger.entries[0].Error).Should(BeTrue())
+
+		logger.entries = nil
+		status = http.StatusServiceUnavailable
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(logger.entries[0].Error).Should(BeTrue())
+
+		logger.entries = nil
+		Ω(serve(accesslog.StatusLevel(5, accesslog.LevelNone))).ShouldNot(HaveOccurred())
+		Ω(logger.entries).Should(BeEmpty())
+	})
+
+	It("samples the successful requests only", func() {
+		sampler := &countSampler{}
+		Ω(serve(accesslog.Sample(sampler))).ShouldNot(HaveOccurred())
+		Ω(logger.entries).Should(BeEmpty())
+		Ω(sampler.calls).Should(Equal(1))
+
+		status = http.StatusBadRequest
+		Ω(serve(accesslog.Sample(sampler))).ShouldNot(HaveOccurred())
+		Ω(logger.entries).Should(HaveLen(1))
+		Ω(sampler.calls).Should(Equal(1))
+	})
+
+	It("logs the handler error", func() {
+		herr = errors.New("boom")
+		status = http.StatusInternalServerError
+		Ω(serve()).Should(Equal(herr))
+		Ω(keyvals(logger.entries[0])).Should(HaveKeyWithValue("err", "boom"))
+	})
+
+	Context("with an output", func() {
+		var buf bytes.Buffer
+
+		BeforeEach(func() {
+			buf.Reset()
+			req.Header.Set(middleware.RequestIDHeader, "abc")
+		})
+
+		It("writes logfmt lines", func() {
+			Ω(serve(accesslog.Output(&buf), accesslog.WithFormat(accesslog.FormatLogfmt))).ShouldNot(HaveOccurred())
+			Ω(logger.entries).Should(BeEmpty())
+			Ω(buf.String()).Should(MatchRegexp(`^req_id=abc method=POST path=/users from=10.0.0.1 ctrl=UserController action=create status=200 bytes=0 time=\S+\n$`))
+		})
+
+		It("writes JSON lines", func() {
+			Ω(serve(accesslog.Output(&buf), accesslog.WithFormat(accesslog.FormatJSON), accesslog.Verbose())).ShouldNot(HaveOccurred())
+			var m map[string]interface{}
+			Ω(json.Unmarshal(buf.Bytes(), &m)).ShouldNot(HaveOccurred())
+			Ω(m).Should(HaveKeyWithValue("req_id", "abc"))
+			Ω(m).Should(HaveKeyWithValue("status", 200.0))
+			Ω(m["payload"]).Should(ContainSubstring(`"password":"<redacted>"`))
+		})
+
+		It("writes Apache combined lines", func() {
+			req.Header.Set("Referer", "http://example.com")
+			Ω(serve(accesslog.Output(&buf), accesslog.WithFormat(accesslog.FormatCombined))).ShouldNot(HaveOccurred())
+			Ω(buf.String()).Should(MatchRegexp(`^10\.0\.0\.1 - - \[[^\]]+\] "POST /users\?token=secret&page=2 HTTP/1.1" 200 - "http://example.com" "test"\n$`))
+		})
+	})
+})
+
+var _ = Describe("Redact", func() {
+	It("redacts the values at the given paths", func() {
+		v := map[string]interface{}{
+			"a": map[string]interface{}{"b": 1, "c": 2},
+			"m": map[string]interface{}{"x": map[string]interface{}{"s": 1}, "y": map[string]interface{}{"s": 2}},
+		}
+		js, err := accesslog.Redact(v, "a.b", "m.*.s", "missing.path")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(js)).Should(Equal(`{"a":{"b":"<redacted>","c":2},"m":{"x":{"s":"<redacted>"},"y":{"s":"<redacted>"}}}`))
+	})
+
+	It("redacts the whole value with the empty path", func() {
+		js, err := accesslog.Redact("secret", "")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(js)).Should(Equal(`"<redacted>"`))
+	})
+})
@@ -0,0 +1,35 @@
+/*
+Package accesslog provides a request logging middleware that writes a single access log entry per
+request. It is the successor of middleware.LogRequest and adds:
+
+  - field level redaction of the request headers, parameters and payload,
+  - a cap on the size of the logged payload,
+  - configurable log levels per status class,
+  - sampling of the successful requests using a middleware.Sampler,
+  - a choice of formats: key/value pairs handed to the goa logger, logfmt, JSON or the Apache
+    combined log format.
+
+Payload attributes may be marked for redaction in the design using the "log:redact" metadata:
+
+	Attribute("password", String, func() {
+		Metadata("log:redact")
+	})
+
+The code generated by goagen app then implements the Redactor interface on the payload and user
+types so that the middleware replaces the values of these attributes with "<redacted>". Redacted
+paths may also be given explicitly with the RedactFields option.
+
+The logged client address is the request remote address. Services running behind a reverse proxy
+or load balancer can use the TrustedProxies option so that the address is read from the
+X-Forwarded-For header of the requests made by these proxies.
+
+Typical usage:
+
+	service.Use(middleware.RequestID())
+	service.Use(accesslog.New(
+		accesslog.WithFormat(accesslog.FormatJSON),
+		accesslog.Output(os.Stdout),
+		accesslog.Sample(middleware.NewAdaptiveSampler(10, 100)),
+	))
+*/
+package accesslog
@@ -0,0 +1,86 @@
+package accesslog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// combinedTimeFormat is the time layout used by the Apache combined log format.
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// format renders the entry using the given format.
+func (e *entry) format(f Format) string {
+	switch f {
+	case FormatJSON:
+		return e.json()
+	case FormatCombined:
+		return e.combined()
+	default:
+		return e.logfmt()
+	}
+}
+
+// logfmt renders the entry using the logfmt format.
+func (e *entry) logfmt() string {
+	var b bytes.Buffer
+	for i, f := range e.fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.key)
+		b.WriteByte('=')
+		v := fmt.Sprint(f.value)
+		if v == "" || strings.ContainsAny(v, " =\"\\") || strings.IndexFunc(v, isControl) >= 0 {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(v)
+	}
+	return b.String()
+}
+
+// json renders the entry as a JSON object, the fields are written in order.
+func (e *entry) json() string {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range e.fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := marshal(f.key)
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := marshal(f.value)
+		if err != nil {
+			v, _ = marshal(fmt.Sprint(f.value))
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// combined renders the entry using the Apache combined log format.
+func (e *entry) combined() string {
+	size := "-"
+	if e.bytes > 0 {
+		size = strconv.Itoa(e.bytes)
+	}
+	return fmt.Sprintf("%s - - [%s] %s %d %s %s %s",
+		e.from, e.time.Format(combinedTimeFormat), strconv.Quote(e.request), e.status, size,
+		quoteOrDash(e.referer), quoteOrDash(e.userAgent))
+}
+
+// quoteOrDash returns the quoted string or "-" if s is empty.
+func quoteOrDash(s string) string {
+	if s == "" {
+		return `"-"`
+	}
+	return strconv.Quote(s)
+}
+
+// isControl returns true if r is a control character.
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
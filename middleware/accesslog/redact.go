@@ -0,0 +1,75 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Redactor is implemented by the types that contain attributes that must not be logged. The code
+// generated by goagen app implements Redactor for the payload and user types that contain
+// attributes with the "log:redact" metadata.
+type Redactor interface {
+	// RedactedFields returns the paths of the attributes that must be redacted.
+	RedactedFields() []string
+}
+
+// Redact returns the JSON representation of v where the values at the given paths are replaced
+// with Redacted. A path lists the names of the attributes separated with dots, e.g.
+// "card.number". Arrays are traversed transparently so that "items.secret" redacts the secret
+// attribute of all the elements of the items array. The "*" element matches all the keys of a
+// map and the empty path redacts the whole value.
+func Redact(v interface{}, paths ...string) ([]byte, error) {
+	if len(paths) == 0 {
+		return marshal(v)
+	}
+	js, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(js, &generic); err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		if p == "" {
+			return marshal(Redacted)
+		}
+		generic = redact(generic, strings.Split(p, "."))
+	}
+	return marshal(generic)
+}
+
+// redact replaces the value at the given path in v.
+func redact(v interface{}, path []string) interface{} {
+	switch actual := v.(type) {
+	case []interface{}:
+		for i, e := range actual {
+			actual[i] = redact(e, path)
+		}
+	case map[string]interface{}:
+		for k, e := range actual {
+			if k != path[0] && path[0] != "*" {
+				continue
+			}
+			if len(path) == 1 {
+				actual[k] = Redacted
+			} else {
+				actual[k] = redact(e, path[1:])
+			}
+		}
+	}
+	return v
+}
+
+// marshal returns the JSON representation of v without escaping HTML characters so that
+// Redacted shows as is.
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
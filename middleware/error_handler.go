@@ -15,6 +15,8 @@ import (
 // them, it turns other Go error types into a 500 internal error response.
 // If verbose is false the details of internal errors is not included in HTTP responses.
 // If you use github.com/pkg/errors then wrapping the error will allow a trace to be printed to the logs
+// Errors other than internal errors are logged at the debug level if the logger implements
+// goa.LeveledLogAdapter.
 func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -34,7 +36,9 @@ func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 				respBody = e.Error()
 				rw.Header().Set("Content-Type", "text/plain")
 			}
-			if status == http.StatusInternalServerError {
+			if status != http.StatusInternalServerError {
+				goa.LogDebug(ctx, "request error", "status", status, "err", fmt.Sprintf("%+v", e))
+			} else {
 				reqID := ctx.Value(reqIDKey)
 				if reqID == nil {
 					reqID = shortID()
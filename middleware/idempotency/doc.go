@@ -0,0 +1,29 @@
+/*
+Package idempotency provides a middleware that gives at-most-once semantics to requests carrying
+an Idempotency-Key header.
+
+The first request made with a given key runs the action and the middleware records the response
+status, headers and body. Requests retried with the same key get the recorded response back
+without running the action again, the replayed responses include the Idempotent-Replayed header.
+The middleware returns ErrConflict (409) when a request arrives while another request with the
+same key is still running and ErrKeyReused (422) when the key was used for a request with a
+different method, path or payload.
+
+Keys are scoped to the client that made the request so that a response is never replayed to a
+different client. By default clients are identified by the principal set by the security
+middleware (goa.ContextPrincipal) or by their Authorization header when there is no principal,
+for example when the middleware is mounted on the service and thus runs before the security
+middleware. Use ScopeBy to identify clients differently.
+
+Responses with a 5xx status and actions returning an error are not recorded so that clients may
+retry them. Recorded responses are kept in a Store, the default store keeps them in memory,
+external stores can be used to share them across service instances:
+
+	service.Use(idempotency.New(idempotency.TTL(24 * time.Hour)))
+
+Actions may also use the middleware by calling the IdempotencyKey DSL in the design. The code
+generated by "goagen app" applies the middleware to these actions after the security middleware
+and the authorization policy, use Configure to change the options they use. Configure may be
+called before or after the controllers are mounted.
+*/
+package idempotency
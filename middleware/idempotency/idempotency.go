@@ -0,0 +1,318 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/internal/config"
+)
+
+type (
+	// Option configures the idempotency middleware.
+	Option func(*options)
+
+	// ScopeFunc computes the identity of the client that made a request. Recorded responses are
+	// only replayed to requests made by the same client.
+	ScopeFunc func(ctx context.Context, req *http.Request) string
+
+	// Guard runs an action at most once per idempotency key using the options given to
+	// Configure.
+	Guard struct {
+		service  *goa.Service
+		name     string
+		fallback Store
+	}
+
+	options struct {
+		header      string
+		store       Store
+		ttl         time.Duration
+		lockTimeout time.Duration
+		required    bool
+		prefix      string
+		scope       ScopeFunc
+	}
+
+	// recorder is the response writer that records the response written by the action.
+	recorder struct {
+		http.ResponseWriter
+		status   int
+		body     bytes.Buffer
+		streamed bool
+	}
+)
+
+const (
+	// DefaultHeader is the name of the request header that contains the idempotency key.
+	DefaultHeader = "Idempotency-Key"
+
+	// ReplayedHeader is the name of the response header set on replayed responses.
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+var (
+	// ErrConflict is the error returned when a request with the same idempotency key is in
+	// progress.
+	ErrConflict = goa.NewErrorClass("idempotency_conflict", http.StatusConflict)
+
+	// ErrKeyReused is the error returned when the idempotency key was used for a different
+	// request.
+	ErrKeyReused = goa.NewErrorClass("idempotency_key_reused", http.StatusUnprocessableEntity)
+
+	// ErrMissingKey is the error returned when the idempotency key is required and missing.
+	ErrMissingKey = goa.NewErrorClass("idempotency_key_missing", http.StatusBadRequest)
+)
+
+// Header sets the name of the request header that contains the idempotency key. The default is
+// DefaultHeader.
+func Header(name string) Option {
+	return func(o *options) {
+		o.header = name
+	}
+}
+
+// Backend sets the store used to keep the recorded responses. The default is a memory store.
+func Backend(store Store) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// TTL sets the time the recorded responses are kept. The default is 24 hours.
+func TTL(d time.Duration) Option {
+	return func(o *options) {
+		o.ttl = d
+	}
+}
+
+// LockTimeout sets the maximum time a key stays reserved by a request that does not complete, for
+// example because the service crashed. The default is one minute.
+func LockTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.lockTimeout = d
+	}
+}
+
+// Required causes requests that do not have an idempotency key to fail with ErrMissingKey.
+func Required() Option {
+	return func(o *options) {
+		o.required = true
+	}
+}
+
+// Prefix sets the prefix added to the keys in the store. Middlewares that share a store should
+// use different prefixes.
+func Prefix(p string) Option {
+	return func(o *options) {
+		o.prefix = p
+	}
+}
+
+// ScopeBy sets the function used to identify the client that made the request. The default is
+// Principal.
+func ScopeBy(fn ScopeFunc) Option {
+	return func(o *options) {
+		o.scope = fn
+	}
+}
+
+// Principal returns a scope function that identifies clients by the principal authenticated by
+// the security middleware, see goa.ContextPrincipal. Requests without principal are identified by
+// their Authorization header.
+func Principal() ScopeFunc {
+	return func(ctx context.Context, req *http.Request) string {
+		if p := goa.ContextPrincipal(ctx); p != nil {
+			return fmt.Sprintf("principal:%T:%v", p, p)
+		}
+		if auth := req.Header.Get("Authorization"); auth != "" {
+			return "authorization:" + auth
+		}
+		return ""
+	}
+}
+
+// New returns a middleware that records the responses of requests that have an idempotency key
+// and replays them when the requests are retried.
+func New(opts ...Option) goa.Middleware {
+	o := newOptions(opts)
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return o.handle(ctx, rw, req, h, o.prefix)
+		}
+	}
+}
+
+// configs records the options given to Configure for each service.
+var configs config.Registry
+
+// Configure sets the options used by the actions of the service that use the IdempotencyKey DSL,
+// whether they are mounted before or after Configure is called.
+func Configure(service *goa.Service, opts ...Option) {
+	configs.Set(service, opts)
+}
+
+// NewGuard returns a guard for the action of service identified by name, name scopes the keys in
+// the store. This function is intended for the controller generated code. User code should not
+// need to call it directly.
+func NewGuard(service *goa.Service, name string) *Guard {
+	return &Guard{service: service, name: name, fallback: NewMemoryStore()}
+}
+
+// Run calls h at most once per idempotency key and replays the recorded response to the requests
+// retried with the same key. The generated code calls Run after the request has been
+// authenticated and authorized so that recorded responses are only replayed to authorized
+// requests.
+func (g *Guard) Run(ctx context.Context, rw http.ResponseWriter, req *http.Request, h goa.Handler) error {
+	opts, _ := configs.Get(g.service).([]Option)
+	o := newOptions(append([]Option{Backend(g.fallback)}, opts...))
+	return o.handle(ctx, rw, req, h, o.prefix+g.name+":")
+}
+
+// Handle wraps h with the idempotency middleware configured with Configure for service. name
+// identifies the action and scopes the keys in the store. The generated code uses NewGuard
+// instead so that the middleware runs after the authorization policy.
+func Handle(service *goa.Service, name string, h goa.Handler) goa.Handler {
+	g := NewGuard(service, name)
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return g.Run(ctx, rw, req, h)
+	}
+}
+
+// newOptions applies the given options to the default options.
+func newOptions(opts []Option) *options {
+	o := &options{
+		header:      DefaultHeader,
+		ttl:         24 * time.Hour,
+		lockTimeout: time.Minute,
+		scope:       Principal(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.store == nil {
+		o.store = NewMemoryStore()
+	}
+	return o
+}
+
+// handle runs h at most once per idempotency key and client.
+func (o *options) handle(ctx context.Context, rw http.ResponseWriter, req *http.Request, h goa.Handler, prefix string) error {
+	key := req.Header.Get(o.header)
+	if key == "" {
+		if o.required {
+			return ErrMissingKey("missing " + o.header + " header")
+		}
+		return h(ctx, rw, req)
+	}
+	scope := sha256.Sum256([]byte(o.scope(ctx, req)))
+	key = prefix + hex.EncodeToString(scope[:]) + ":" + key
+	fp, err := fingerprint(ctx, req)
+	if err != nil {
+		return err
+	}
+	rec, err := o.store.Begin(ctx, key, fp, o.lockTimeout)
+	if err != nil {
+		return err
+	}
+	if rec != nil {
+		if rec.Fingerprint != fp {
+			return ErrKeyReused("idempotency key was already used for a different request")
+		}
+		if !rec.Done {
+			return ErrConflict("a request with the same idempotency key is in progress")
+		}
+		return replay(rw, rec)
+	}
+
+	completed := false
+	defer func() {
+		if !completed {
+			if err := o.store.Release(ctx, key); err != nil {
+				goa.LogError(ctx, "failed to release idempotency key", "err", err)
+			}
+		}
+	}()
+	r := &recorder{}
+	if resp := goa.ContextResponse(ctx); resp != nil {
+		r.ResponseWriter = resp.SwitchWriter(r)
+		defer resp.SwitchWriter(r.ResponseWriter)
+	} else {
+		r.ResponseWriter = rw
+		rw = r
+	}
+	if err := h(ctx, rw, req); err != nil {
+		return err
+	}
+	if r.status == 0 || r.status >= 500 || r.streamed {
+		return nil
+	}
+	header := make(http.Header, len(r.Header()))
+	for k, v := range r.Header() {
+		header[k] = append([]string(nil), v...)
+	}
+	res := &Record{Fingerprint: fp, Done: true, Status: r.status, Header: header, Body: r.body.Bytes()}
+	if err := o.store.Complete(ctx, key, res, o.ttl); err != nil {
+		goa.LogError(ctx, "failed to record idempotent response", "err", err)
+		return nil
+	}
+	completed = true
+	return nil
+}
+
+// fingerprint computes a hash of the request method, path and payload.
+func fingerprint(ctx context.Context, req *http.Request) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	if r := goa.ContextRequest(ctx); r != nil && r.Payload != nil {
+		b, err := json.Marshal(r.Payload)
+		if err != nil {
+			return "", err
+		}
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// replay writes the recorded response.
+func replay(rw http.ResponseWriter, rec *Record) error {
+	header := rw.Header()
+	for k, v := range rec.Header {
+		header[k] = v
+	}
+	header.Set(ReplayedHeader, "true")
+	rw.WriteHeader(rec.Status)
+	_, err := rw.Write(rec.Body)
+	return err
+}
+
+// WriteHeader records the status code.
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body.
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, flushed responses are streamed and thus not recorded.
+func (r *recorder) Flush() {
+	r.streamed = true
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,13 @@
+package idempotency_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestIdempotency(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Idempotency Suite")
+}
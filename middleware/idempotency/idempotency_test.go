@@ -0,0 +1,204 @@
+package idempotency_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/idempotency"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var handler goa.Handler
+	var calls int
+	var status int
+	var block, started chan struct{}
+	var opts []idempotency.Option
+	var principal interface{}
+	var authorization string
+
+	BeforeEach(func() {
+		calls = 0
+		principal = nil
+		authorization = ""
+		status = http.StatusCreated
+		block = nil
+		opts = nil
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			calls++
+			if block != nil {
+				started <- struct{}{}
+				<-block
+			}
+			rw.Header().Set("Location", "/orders/1")
+			rw.WriteHeader(status)
+			rw.Write([]byte(`{"id":1}`))
+			return nil
+		}
+	})
+
+	serve := func(h goa.Handler, key string, payload interface{}) (*httptest.ResponseRecorder, error) {
+		req, err := http.NewRequest("POST", "/orders", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		if principal != nil {
+			ctx = goa.WithPrincipal(ctx, principal)
+		}
+		goa.ContextRequest(ctx).Payload = payload
+		return rw, h(ctx, goa.ContextResponse(ctx), req)
+	}
+
+	It("replays the recorded response", func() {
+		h := idempotency.New(opts...)(handler)
+		rw, err := serve(h, "abc", map[string]int{"amount": 10})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Code).Should(Equal(http.StatusCreated))
+		Ω(rw.Header().Get(idempotency.ReplayedHeader)).Should(BeEmpty())
+
+		rw, err = serve(h, "abc", map[string]int{"amount": 10})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(1))
+		Ω(rw.Code).Should(Equal(http.StatusCreated))
+		Ω(rw.Body.String()).Should(Equal(`{"id":1}`))
+		Ω(rw.Header().Get("Location")).Should(Equal("/orders/1"))
+		Ω(rw.Header().Get(idempotency.ReplayedHeader)).Should(Equal("true"))
+	})
+
+	It("rejects keys reused with a different payload", func() {
+		h := idempotency.New(opts...)(handler)
+		_, err := serve(h, "abc", map[string]int{"amount": 10})
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = serve(h, "abc", map[string]int{"amount": 20})
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusUnprocessableEntity))
+	})
+
+	It("rejects concurrent duplicates", func() {
+		h := idempotency.New(opts...)(handler)
+		block = make(chan struct{})
+		started = make(chan struct{})
+		done := make(chan error)
+		go func() {
+			_, err := serve(h, "abc", nil)
+			done <- err
+		}()
+		<-started
+		_, err := serve(h, "abc", nil)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusConflict))
+		close(block)
+		Ω(<-done).ShouldNot(HaveOccurred())
+	})
+
+	It("does not record server errors", func() {
+		h := idempotency.New(opts...)(handler)
+		status = http.StatusServiceUnavailable
+		serve(h, "abc", nil)
+		status = http.StatusCreated
+		rw, err := serve(h, "abc", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(2))
+		Ω(rw.Code).Should(Equal(http.StatusCreated))
+	})
+
+	It("ignores requests without key", func() {
+		h := idempotency.New(opts...)(handler)
+		serve(h, "", nil)
+		serve(h, "", nil)
+		Ω(calls).Should(Equal(2))
+	})
+
+	It("requires the key if configured to", func() {
+		h := idempotency.New(idempotency.Required())(handler)
+		_, err := serve(h, "", nil)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusBadRequest))
+		Ω(calls).Should(Equal(0))
+	})
+
+	It("scopes keys per action with Handle", func() {
+		service := goa.New("test")
+		create := idempotency.Handle(service, "order.create", handler)
+		update := idempotency.Handle(service, "order.update", handler)
+		serve(create, "abc", nil)
+		serve(update, "abc", nil)
+		serve(create, "abc", nil)
+		Ω(calls).Should(Equal(2))
+	})
+
+	It("uses the options configured after the actions are mounted", func() {
+		service := goa.New("test")
+		create := idempotency.Handle(service, "order.create", handler)
+		idempotency.Configure(service, idempotency.Required())
+		_, err := serve(create, "", nil)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusBadRequest))
+		Ω(calls).Should(Equal(0))
+	})
+
+	It("does not replay responses to other principals", func() {
+		h := idempotency.New(opts...)(handler)
+		principal = "alice"
+		rw, err := serve(h, "abc", map[string]int{"amount": 10})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Header().Get(idempotency.ReplayedHeader)).Should(BeEmpty())
+
+		principal = "bob"
+		rw, err = serve(h, "abc", map[string]int{"amount": 10})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(2))
+		Ω(rw.Header().Get(idempotency.ReplayedHeader)).Should(BeEmpty())
+
+		principal = "alice"
+		rw, err = serve(h, "abc", map[string]int{"amount": 10})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(2))
+		Ω(rw.Header().Get(idempotency.ReplayedHeader)).Should(Equal("true"))
+	})
+
+	It("scopes keys by Authorization header without principal", func() {
+		h := idempotency.New(opts...)(handler)
+		authorization = "Bearer alice"
+		serve(h, "abc", nil)
+		authorization = "Bearer bob"
+		rw, err := serve(h, "abc", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(2))
+		Ω(rw.Header().Get(idempotency.ReplayedHeader)).Should(BeEmpty())
+	})
+
+	It("replays responses to requests authorized by the wrapping handler", func() {
+		guard := idempotency.NewGuard(goa.New("test"), "order.create")
+		var authorized []interface{}
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			p := goa.ContextPrincipal(ctx)
+			if p != "alice" {
+				return goa.ErrForbidden("not allowed")
+			}
+			authorized = append(authorized, p)
+			return guard.Run(ctx, rw, req, handler)
+		}
+		principal = "alice"
+		serve(h, "abc", nil)
+		principal = "mallory"
+		_, err := serve(h, "abc", nil)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusForbidden))
+		principal = "alice"
+		rw, err := serve(h, "abc", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(1))
+		Ω(authorized).Should(HaveLen(2))
+		Ω(rw.Header().Get(idempotency.ReplayedHeader)).Should(Equal("true"))
+	})
+})
@@ -0,0 +1,68 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa/middleware/internal/expiring"
+)
+
+type (
+	// Store keeps the responses recorded by the middleware.
+	Store interface {
+		// Begin reserves key for a request with the given fingerprint. It returns nil if
+		// the key was not in use and the existing record otherwise. The reservation must be
+		// atomic and expire after ttl unless Complete is called.
+		Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error)
+		// Complete stores the response recorded for key. The record expires after ttl.
+		Complete(ctx context.Context, key string, rec *Record, ttl time.Duration) error
+		// Release deletes the reservation of key so that the request may be retried.
+		Release(ctx context.Context, key string) error
+	}
+
+	// Record is the state of an idempotency key.
+	Record struct {
+		// Fingerprint identifies the request that reserved the key.
+		Fingerprint string
+		// Done is false while the request that reserved the key is running.
+		Done bool
+		// Status is the recorded response status code.
+		Status int
+		// Header contains the recorded response headers.
+		Header http.Header
+		// Body is the recorded response body.
+		Body []byte
+	}
+
+	// MemoryStore is a Store that keeps the records in memory.
+	MemoryStore struct {
+		entries *expiring.Map
+	}
+)
+
+// NewMemoryStore returns a store that keeps the records in memory. It is the default store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: expiring.New()}
+}
+
+// Begin implements Store.
+func (s *MemoryStore) Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	if v, ok := s.entries.GetOrSet(key, &Record{Fingerprint: fingerprint}, ttl); ok {
+		rec := *v.(*Record)
+		return &rec, nil
+	}
+	return nil, nil
+}
+
+// Complete implements Store.
+func (s *MemoryStore) Complete(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	s.entries.Set(key, rec, ttl)
+	return nil
+}
+
+// Release implements Store.
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.entries.Delete(key)
+	return nil
+}
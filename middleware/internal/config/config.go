@@ -0,0 +1,34 @@
+// Package config stores the options that the ratelimit and idempotency middlewares apply to the
+// actions of a service.
+package config
+
+import (
+	"sync"
+
+	"github.com/goadesign/goa"
+)
+
+// Registry maps services to their options. Options are looked up when requests are handled so
+// that they may be set before or after the controllers are mounted. The zero value is ready to
+// use.
+type Registry struct {
+	mu   sync.RWMutex
+	opts map[*goa.Service]interface{}
+}
+
+// Set records the options of the service, replacing any previous value.
+func (r *Registry) Set(service *goa.Service, opts interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.opts == nil {
+		r.opts = make(map[*goa.Service]interface{})
+	}
+	r.opts[service] = opts
+}
+
+// Get returns the options of the service, nil if none were set.
+func (r *Registry) Get(service *goa.Service) interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.opts[service]
+}
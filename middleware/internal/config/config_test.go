@@ -0,0 +1,29 @@
+package config_test
+
+import (
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/internal/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var r *config.Registry
+
+	BeforeEach(func() {
+		r = &config.Registry{}
+	})
+
+	It("returns nil for services without options", func() {
+		Ω(r.Get(goa.New("test"))).Should(BeNil())
+	})
+
+	It("keeps the options of each service", func() {
+		a, b := goa.New("a"), goa.New("b")
+		r.Set(a, "a")
+		r.Set(b, "b")
+		r.Set(a, "updated")
+		Ω(r.Get(a)).Should(Equal("updated"))
+		Ω(r.Get(b)).Should(Equal("b"))
+	})
+})
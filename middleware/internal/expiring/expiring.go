@@ -0,0 +1,97 @@
+// Package expiring implements the in-memory map with expiring entries used by the default stores
+// of the ratelimit and idempotency middlewares.
+package expiring
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Map is a map safe for concurrent use whose entries expire after a given time. Expired
+	// entries are removed periodically.
+	Map struct {
+		mu      sync.Mutex
+		entries map[string]*entry
+		sweep   time.Time
+	}
+
+	// entry is a value stored in a Map.
+	entry struct {
+		value   interface{}
+		expires time.Time
+	}
+)
+
+// sweepInterval is the interval at which the map removes expired entries.
+const sweepInterval = time.Minute
+
+// New returns an empty map.
+func New() *Map {
+	return &Map{entries: make(map[string]*entry), sweep: time.Now()}
+}
+
+// Update calls fn with the value stored under key, nil if there is none or if it has expired, and
+// stores the value returned by fn. The value expires after ttl. Concurrent updates do not
+// interleave.
+func (m *Map) Update(key string, ttl time.Duration, fn func(interface{}) interface{}) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expire(now)
+	var v interface{}
+	if e, ok := m.entries[key]; ok && !now.After(e.expires) {
+		v = e.value
+	}
+	m.entries[key] = &entry{value: fn(v), expires: now.Add(ttl)}
+}
+
+// GetOrSet returns the value stored under key and true if it has not expired. Otherwise it
+// stores v under key with the given ttl and returns nil and false.
+func (m *Map) GetOrSet(key string, v interface{}, ttl time.Duration) (interface{}, bool) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expire(now)
+	if e, ok := m.entries[key]; ok && !now.After(e.expires) {
+		return e.value, true
+	}
+	m.entries[key] = &entry{value: v, expires: now.Add(ttl)}
+	return nil, false
+}
+
+// Set stores v under key, the value expires after ttl.
+func (m *Map) Set(key string, v interface{}, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = &entry{value: v, expires: time.Now().Add(ttl)}
+}
+
+// Delete removes the value stored under key.
+func (m *Map) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+// Len returns the number of entries in the map including the expired entries that have not been
+// removed yet.
+func (m *Map) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// expire removes the expired entries if the last sweep is older than sweepInterval. The caller
+// must hold the lock.
+func (m *Map) expire(now time.Time) {
+	if now.Sub(m.sweep) <= sweepInterval {
+		return
+	}
+	for k, e := range m.entries {
+		if now.After(e.expires) {
+			delete(m.entries, k)
+		}
+	}
+	m.sweep = now
+}
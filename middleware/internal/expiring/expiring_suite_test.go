@@ -0,0 +1,13 @@
+package expiring_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestExpiring(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Expiring Suite")
+}
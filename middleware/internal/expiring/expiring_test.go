@@ -0,0 +1,56 @@
+package expiring_test
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/middleware/internal/expiring"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Map", func() {
+	var m *expiring.Map
+
+	BeforeEach(func() {
+		m = expiring.New()
+	})
+
+	It("updates values", func() {
+		inc := func(v interface{}) interface{} {
+			n, _ := v.(int)
+			return n + 1
+		}
+		m.Update("a", time.Minute, inc)
+		m.Update("a", time.Minute, inc)
+		v, ok := m.GetOrSet("a", 0, time.Minute)
+		Ω(ok).Should(BeTrue())
+		Ω(v).Should(Equal(2))
+	})
+
+	It("sets values only if absent", func() {
+		_, ok := m.GetOrSet("a", 1, time.Minute)
+		Ω(ok).Should(BeFalse())
+		v, ok := m.GetOrSet("a", 2, time.Minute)
+		Ω(ok).Should(BeTrue())
+		Ω(v).Should(Equal(1))
+	})
+
+	It("expires values", func() {
+		m.Set("a", 1, time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		m.Update("a", time.Minute, func(v interface{}) interface{} {
+			Ω(v).Should(BeNil())
+			return 2
+		})
+		v, _ := m.GetOrSet("a", 0, time.Minute)
+		Ω(v).Should(Equal(2))
+	})
+
+	It("deletes values", func() {
+		m.Set("a", 1, time.Minute)
+		m.Delete("a")
+		Ω(m.Len()).Should(Equal(0))
+		_, ok := m.GetOrSet("a", 2, time.Minute)
+		Ω(ok).Should(BeFalse())
+	})
+})
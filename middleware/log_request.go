@@ -19,7 +19,11 @@ import (
 // LogRequest creates a request logger middleware.
 // This middleware is aware of the RequestID middleware and if registered after it leverages the
 // request ID for logging.
-// If verbose is true then the middlware logs the request and response bodies.
+// If verbose is true then the middlware logs the request headers, parameters and payload. These
+// details are logged at the debug level if the logger implements goa.LeveledLogAdapter, at the
+// info level otherwise.
+// See the accesslog package for a middleware that logs a single entry per request and supports
+// redaction, sampling and multiple formats.
 func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 	var suppressed map[string]struct{}
 	if len(sensitiveHeaders) > 0 {
@@ -61,7 +65,7 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 						}
 						i = i + 2
 					}
-					goa.LogInfo(ctx, "headers", logCtx...)
+					logDetail(ctx, "headers", logCtx...)
 				}
 				if len(r.Params) > 0 {
 					logCtx := make([]interface{}, 2*len(r.Params))
@@ -71,7 +75,7 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 						logCtx[i+1] = interface{}(strings.Join(v, ", "))
 						i = i + 2
 					}
-					goa.LogInfo(ctx, "params", logCtx...)
+					logDetail(ctx, "params", logCtx...)
 				}
 				if r.ContentLength > 0 {
 					if mp, ok := r.Payload.(map[string]interface{}); ok {
@@ -82,14 +86,14 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 							logCtx[i+1] = interface{}(v)
 							i = i + 2
 						}
-						goa.LogInfo(ctx, "payload", logCtx...)
+						logDetail(ctx, "payload", logCtx...)
 					} else {
 						// Not the most efficient but this is used for debugging
 						js, err := json.Marshal(r.Payload)
 						if err != nil {
 							js = []byte("<invalid JSON>")
 						}
-						goa.LogInfo(ctx, "payload", "raw", string(js))
+						logDetail(ctx, "payload", "raw", string(js))
 					}
 				}
 			}
@@ -109,6 +113,16 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 	}
 }
 
+// logDetail logs verbose details at the debug level if the context logger supports it, at the
+// info level otherwise.
+func logDetail(ctx context.Context, msg string, keyvals ...interface{}) {
+	if _, ok := goa.ContextLogger(ctx).(goa.LeveledLogAdapter); ok {
+		goa.LogDebug(ctx, msg, keyvals...)
+		return
+	}
+	goa.LogInfo(ctx, msg, keyvals...)
+}
+
 // shortID produces a "unique" 6 bytes long string.
 // Do not use as a reliable way to get unique IDs, instead use for things like logging.
 func shortID() string {
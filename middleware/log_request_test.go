@@ -76,6 +76,22 @@ var _ = Describe("LogRequest", func() {
 		Ω(logger.InfoEntries[3].Data[11]).Should(Equal("goo"))
 	})
 
+	It("logs the request details at the debug level if supported", func() {
+		leveled := &leveledTestLogger{testLogger: logger}
+		ctx = goa.WithLogger(ctx, leveled)
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 200, "ok")
+		}
+		lg := middleware.LogRequest(true)(h)
+		Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(logger.InfoEntries).Should(HaveLen(2))
+		Ω(logger.InfoEntries[0].Msg).Should(Equal("started"))
+		Ω(logger.InfoEntries[1].Msg).Should(Equal("completed"))
+		Ω(leveled.DebugEntries).Should(HaveLen(2))
+		Ω(leveled.DebugEntries[0].Msg).Should(Equal("params"))
+		Ω(leveled.DebugEntries[1].Msg).Should(Equal("payload"))
+	})
+
 	It("logs error codes", func() {
 		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			return goa.MissingParamError("foo")
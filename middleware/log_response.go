@@ -18,12 +18,14 @@ type loggingResponseWriter struct {
 
 // Write will write raw data to logger and response writer.
 func (lrw *loggingResponseWriter) Write(buf []byte) (int, error) {
-	goa.LogInfo(lrw.ctx, "response", "body", string(buf))
+	logDetail(lrw.ctx, "response", "body", string(buf))
 	return lrw.ResponseWriter.Write(buf)
 }
 
 // LogResponse creates a response logger middleware.
 // Only Logs the raw response data without accumulating any statistics.
+// The response data is logged at the debug level if the logger implements goa.LeveledLogAdapter,
+// at the info level otherwise.
 func LogResponse() goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -50,6 +50,27 @@ func (t *testLogger) New(data ...interface{}) goa.LogAdapter {
 	return t
 }
 
+type leveledTestLogger struct {
+	*testLogger
+	DebugEntries []logEntry
+	WarnEntries  []logEntry
+}
+
+func (t *leveledTestLogger) Debug(msg string, data ...interface{}) {
+	e := logEntry{msg, append(t.Context, data...)}
+	t.DebugEntries = append(t.DebugEntries, e)
+}
+
+func (t *leveledTestLogger) Warn(msg string, data ...interface{}) {
+	e := logEntry{msg, append(t.Context, data...)}
+	t.WarnEntries = append(t.WarnEntries, e)
+}
+
+func (t *leveledTestLogger) New(data ...interface{}) goa.LogAdapter {
+	t.testLogger.New(data...)
+	return t
+}
+
 type testResponseWriter struct {
 	ParentHeader http.Header
 	Body         []byte
@@ -0,0 +1,294 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type (
+	// Collector is a goa.LabeledCollector that stores the metrics in a Prometheus registry.
+	Collector struct {
+		registerer prom.Registerer
+		gatherer   prom.Gatherer
+		namespace  string
+		buckets    []float64
+
+		mu         sync.Mutex
+		counters   map[string]*vec
+		gauges     map[string]*vec
+		histograms map[string]*vec
+		summaries  map[string]*vec
+
+		requests *prom.CounterVec
+		duration *prom.HistogramVec
+		inFlight *prom.GaugeVec
+	}
+
+	// Option configures a Collector.
+	Option func(*Collector)
+
+	// vec is a metric vector created on demand together with its label names.
+	vec struct {
+		labels    []string
+		collector prom.Collector
+	}
+)
+
+var (
+	// invalidCharsRE matches the characters that are not valid in metric and label names.
+	invalidCharsRE = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+	// requestLabels are the labels of the request metrics.
+	requestLabels = []string{"controller", "action", "method", "status"}
+)
+
+// WithRegistry sets the registry used to store the metrics. The default is a new registry that
+// also includes the Go runtime and process metrics.
+func WithRegistry(r *prom.Registry) Option {
+	return func(c *Collector) {
+		c.registerer = r
+		c.gatherer = r
+	}
+}
+
+// Namespace sets the prefix of the metric names.
+func Namespace(ns string) Option {
+	return func(c *Collector) {
+		c.namespace = ns
+	}
+}
+
+// Buckets sets the buckets of the latency histograms in seconds. The default is
+// prometheus.DefBuckets.
+func Buckets(b []float64) Option {
+	return func(c *Collector) {
+		c.buckets = b
+	}
+}
+
+// NewCollector creates a collector and registers the request metrics.
+func NewCollector(opts ...Option) *Collector {
+	c := &Collector{
+		buckets:    prom.DefBuckets,
+		counters:   make(map[string]*vec),
+		gauges:     make(map[string]*vec),
+		histograms: make(map[string]*vec),
+		summaries:  make(map[string]*vec),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	if c.registerer == nil {
+		r := prom.NewRegistry()
+		r.MustRegister(prom.NewGoCollector(), prom.NewProcessCollector(prom.ProcessCollectorOpts{}))
+		c.registerer, c.gatherer = r, r
+	}
+	c.requests = prom.NewCounterVec(prom.CounterOpts{
+		Namespace: c.namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests handled.",
+	}, requestLabels)
+	c.duration = prom.NewHistogramVec(prom.HistogramOpts{
+		Namespace: c.namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of the HTTP requests in seconds.",
+		Buckets:   c.buckets,
+	}, requestLabels)
+	c.inFlight = prom.NewGaugeVec(prom.GaugeOpts{
+		Namespace: c.namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Number of HTTP requests being handled.",
+	}, requestLabels[:3])
+	c.registerer.MustRegister(c.requests, c.duration, c.inFlight)
+	return c
+}
+
+// Handler returns the HTTP handler that serves the metrics. The handler uses the OpenMetrics
+// format if the client accepts it and the Prometheus text format otherwise.
+func (c *Collector) Handler() http.Handler {
+	return promhttp.HandlerFor(c.gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+// Mount mounts the metrics handler on the service at the given path.
+func (c *Collector) Mount(service *goa.Service, path string) {
+	h := c.Handler()
+	service.Mux.Handle("GET", path, func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+		h.ServeHTTP(rw, req)
+	})
+	service.LogInfo("mount", "metrics", path)
+}
+
+// Middleware returns a middleware that records the number and duration of the requests and the
+// number of requests in flight.
+func (c *Collector) Middleware() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			ctrl, action := goa.ContextController(ctx), goa.ContextAction(ctx)
+			inFlight := c.inFlight.WithLabelValues(ctrl, action, req.Method)
+			inFlight.Inc()
+			defer inFlight.Dec()
+			start := time.Now()
+			err := h(ctx, rw, req)
+			status := 0
+			if resp := goa.ContextResponse(ctx); resp != nil {
+				status = resp.Status
+			}
+			if err != nil {
+				status = http.StatusInternalServerError
+				if serr, ok := err.(goa.ServiceError); ok {
+					status = serr.ResponseStatus()
+				}
+			}
+			labels := []string{ctrl, action, req.Method, strconv.Itoa(status)}
+			c.requests.WithLabelValues(labels...).Inc()
+			c.duration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// AddSample implements goa.Collector.
+func (c *Collector) AddSample(key []string, val float32) {
+	c.AddSampleWithLabels(key, val, nil)
+}
+
+// EmitKey implements goa.Collector, the value is recorded as a gauge.
+func (c *Collector) EmitKey(key []string, val float32) {
+	c.SetGaugeWithLabels(key, val, nil)
+}
+
+// IncrCounter implements goa.Collector.
+func (c *Collector) IncrCounter(key []string, val float32) {
+	c.IncrCounterWithLabels(key, val, nil)
+}
+
+// MeasureSince implements goa.Collector.
+func (c *Collector) MeasureSince(key []string, start time.Time) {
+	c.MeasureSinceWithLabels(key, start, nil)
+}
+
+// SetGauge implements goa.Collector.
+func (c *Collector) SetGauge(key []string, val float32) {
+	c.SetGaugeWithLabels(key, val, nil)
+}
+
+// AddSampleWithLabels implements goa.LabeledCollector, the samples are recorded in a summary.
+func (c *Collector) AddSampleWithLabels(key []string, val float32, labels []goa.Label) {
+	names, values := split(labels)
+	v := c.get(c.summaries, c.name(key, ""), names, func(name string) prom.Collector {
+		return prom.NewSummaryVec(prom.SummaryOpts{Name: name, Help: help(key)}, names)
+	})
+	if v != nil {
+		v.(*prom.SummaryVec).WithLabelValues(values...).Observe(float64(val))
+	}
+}
+
+// IncrCounterWithLabels implements goa.LabeledCollector.
+func (c *Collector) IncrCounterWithLabels(key []string, val float32, labels []goa.Label) {
+	names, values := split(labels)
+	v := c.get(c.counters, c.name(key, "_total"), names, func(name string) prom.Collector {
+		return prom.NewCounterVec(prom.CounterOpts{Name: name, Help: help(key)}, names)
+	})
+	if v != nil && val >= 0 {
+		v.(*prom.CounterVec).WithLabelValues(values...).Add(float64(val))
+	}
+}
+
+// MeasureSinceWithLabels implements goa.LabeledCollector, the durations are recorded in a
+// histogram.
+func (c *Collector) MeasureSinceWithLabels(key []string, start time.Time, labels []goa.Label) {
+	names, values := split(labels)
+	v := c.get(c.histograms, c.name(key, "_seconds"), names, func(name string) prom.Collector {
+		return prom.NewHistogramVec(prom.HistogramOpts{Name: name, Help: help(key), Buckets: c.buckets}, names)
+	})
+	if v != nil {
+		v.(*prom.HistogramVec).WithLabelValues(values...).Observe(time.Since(start).Seconds())
+	}
+}
+
+// SetGaugeWithLabels implements goa.LabeledCollector.
+func (c *Collector) SetGaugeWithLabels(key []string, val float32, labels []goa.Label) {
+	names, values := split(labels)
+	v := c.get(c.gauges, c.name(key, ""), names, func(name string) prom.Collector {
+		return prom.NewGaugeVec(prom.GaugeOpts{Name: name, Help: help(key)}, names)
+	})
+	if v != nil {
+		v.(*prom.GaugeVec).WithLabelValues(values...).Set(float64(val))
+	}
+}
+
+// get returns the vector with the given name creating and registering it if needed. It returns
+// nil if the vector cannot be registered or if it was created with different labels.
+func (c *Collector) get(vecs map[string]*vec, name string, labels []string, create func(string) prom.Collector) prom.Collector {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := vecs[name]
+	if !ok {
+		v = &vec{labels: labels, collector: create(name)}
+		if err := c.registerer.Register(v.collector); err != nil {
+			v.collector = nil
+		}
+		vecs[name] = v
+	}
+	if v.collector == nil || !equal(v.labels, labels) {
+		return nil
+	}
+	return v.collector
+}
+
+// name computes the metric name from the key.
+func (c *Collector) name(key []string, suffix string) string {
+	elems := make([]string, 0, len(key)+1)
+	if c.namespace != "" {
+		elems = append(elems, c.namespace)
+	}
+	elems = append(elems, key...)
+	name := invalidCharsRE.ReplaceAllString(strings.Join(elems, "_"), "_")
+	if !strings.HasSuffix(name, suffix) {
+		name += suffix
+	}
+	return name
+}
+
+// help returns the help text of the metric with the given key.
+func help(key []string) string {
+	return "goa metric " + strings.Join(key, ".")
+}
+
+// split returns the sorted label names and the corresponding values.
+func split(labels []goa.Label) ([]string, []string) {
+	sorted := make([]goa.Label, len(labels))
+	copy(sorted, labels)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	names := make([]string, len(sorted))
+	values := make([]string, len(sorted))
+	for i, l := range sorted {
+		names[i] = invalidCharsRE.ReplaceAllString(l.Name, "_")
+		values[i] = l.Value
+	}
+	return names, values
+}
+
+// equal returns true if a and b contain the same strings in the same order.
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
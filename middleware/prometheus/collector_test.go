@@ -0,0 +1,100 @@
+package prometheus_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/prometheus"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+var _ = Describe("Collector", func() {
+	var collector *prometheus.Collector
+
+	BeforeEach(func() {
+		collector = prometheus.NewCollector(prometheus.WithRegistry(prom.NewRegistry()))
+	})
+
+	scrape := func(accept string) (string, string) {
+		req, _ := http.NewRequest("GET", "/metrics", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rw := httptest.NewRecorder()
+		collector.Handler().ServeHTTP(rw, req)
+		body, _ := ioutil.ReadAll(rw.Body)
+		return string(body), rw.Header().Get("Content-Type")
+	}
+
+	Context("Middleware", func() {
+		serve := func(h goa.Handler) error {
+			req, _ := http.NewRequest("POST", "/bottles", nil)
+			rw := httptest.NewRecorder()
+			ctx := goa.WithAction(goa.NewContext(context.Background(), rw, req, nil), "create")
+			return collector.Middleware()(h)(ctx, goa.ContextResponse(ctx), req)
+		}
+
+		It("records the requests", func() {
+			err := serve(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				rw.WriteHeader(http.StatusCreated)
+				return nil
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+			body, _ := scrape("")
+			Ω(body).Should(ContainSubstring(`http_requests_total{action="create",controller="<unknown>",method="POST",status="201"} 1`))
+			Ω(body).Should(ContainSubstring(`http_request_duration_seconds_count{action="create",controller="<unknown>",method="POST",status="201"} 1`))
+			Ω(body).Should(ContainSubstring(`http_requests_in_flight{action="create",controller="<unknown>",method="POST"} 0`))
+		})
+
+		It("uses the status of the errors", func() {
+			serve(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return goa.ErrNotFound("not found")
+			})
+			body, _ := scrape("")
+			Ω(body).Should(ContainSubstring(`status="404"} 1`))
+		})
+	})
+
+	Context("used as goa collector", func() {
+		It("records labelled metrics", func() {
+			collector.IncrCounterWithLabels([]string{"goa", "response"}, 1, []goa.Label{{Name: "status", Value: "200"}})
+			collector.MeasureSinceWithLabels([]string{"goa", "decode"}, time.Now(), []goa.Label{{Name: "content_type", Value: "application/json"}})
+			collector.SetGauge([]string{"queue", "size"}, 3)
+			collector.AddSample([]string{"batch", "size"}, 10)
+			body, _ := scrape("")
+			Ω(body).Should(ContainSubstring(`goa_response_total{status="200"} 1`))
+			Ω(body).Should(ContainSubstring(`goa_decode_seconds_count{content_type="application/json"} 1`))
+			Ω(body).Should(ContainSubstring(`queue_size 3`))
+			Ω(body).Should(ContainSubstring(`batch_size_sum 10`))
+		})
+
+		It("ignores metrics reusing a name with different labels", func() {
+			collector.IncrCounterWithLabels([]string{"hits"}, 1, []goa.Label{{Name: "a", Value: "1"}})
+			collector.IncrCounterWithLabels([]string{"hits"}, 1, []goa.Label{{Name: "b", Value: "1"}})
+			body, _ := scrape("")
+			Ω(body).Should(ContainSubstring(`hits_total{a="1"} 1`))
+			Ω(body).ShouldNot(ContainSubstring(`b="1"`))
+		})
+
+		It("receives the metrics emitted by goa with labels", func() {
+			goa.SetMetrics(collector)
+			defer goa.SetMetrics(goa.NewNoOpCollector())
+			goa.IncrCounterWithLabels([]string{"goa", "validation", "error"}, 1, []goa.Label{{Name: "format", Value: "email"}})
+			body, _ := scrape("")
+			Ω(body).Should(ContainSubstring(`goa_validation_error_total{format="email"} 1`))
+		})
+	})
+
+	It("serves the OpenMetrics format", func() {
+		collector.IncrCounter([]string{"hits"}, 1)
+		body, ct := scrape("application/openmetrics-text; version=1.0.0")
+		Ω(ct).Should(HavePrefix("application/openmetrics-text"))
+		Ω(body).Should(ContainSubstring("# EOF"))
+	})
+})
@@ -0,0 +1,20 @@
+/*
+Package prometheus exposes the metrics of goa services in the Prometheus and OpenMetrics formats.
+
+The package provides a Collector that stores the metrics emitted by goa and by user code via
+goa.IncrCounter, goa.MeasureSince etc. in a Prometheus registry, a middleware that records the
+number, latency and concurrency of requests labelled by controller, action, method and status
+and a handler that serves the metrics:
+
+	collector := prometheus.NewCollector()
+	goa.SetMetrics(collector)
+	service.Use(collector.Middleware())
+	collector.Mount(service, "/metrics")
+
+The collector implements goa.LabeledCollector so that the metrics emitted by goa carry labels
+instead of having the label values baked into their names, for example the goa.decode metric
+becomes the goa_decode_seconds histogram labelled by content type. Metric names are built by
+joining the key elements with underscores, counters get the "_total" suffix and timings the
+"_seconds" suffix.
+*/
+package prometheus
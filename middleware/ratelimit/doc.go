@@ -0,0 +1,29 @@
+/*
+Package ratelimit provides a middleware that limits the rate of requests made by each client.
+
+Requests are identified by a key computed by a KeyFunc, for example the client IP address, the
+API key or the subject of the JWT token validated by the jwt security middleware. Each key gets a
+quota of requests per period enforced by a Limiter. The package provides two algorithms:
+NewTokenBucket allows bursts of up to the quota and refills tokens continuously while
+NewSlidingWindow counts requests over a sliding time window. The state of the limiters lives in
+a Store, the default store keeps the state in memory, external stores (e.g. Redis) can be used to
+share the limits across service instances by implementing the Store interface.
+
+The middleware sets the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset response headers
+and returns an ErrRateLimitExceeded error with a Retry-After header once the quota is exhausted:
+
+	service.Use(ratelimit.New(100, time.Minute, ratelimit.KeyBy(ratelimit.APIKey("X-API-Key"))))
+
+Rate limits may also be declared in the design using the "ratelimit" metadata on actions or
+resources, the value is the number of requests followed by the period:
+
+	Action("list", func() {
+		Metadata("ratelimit", "100/1m")
+		...
+	})
+
+The code generated by "goagen app" then applies the limits when mounting the actions. Use
+Configure to change the key function, algorithm and store used by these limits, it may be called
+before or after the controllers are mounted.
+*/
+package ratelimit
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa/middleware/security/jwt"
+)
+
+// KeyFunc computes the key that identifies the client making a request. Requests for which the
+// function returns an empty string are not limited.
+type KeyFunc func(ctx context.Context, req *http.Request) string
+
+// ClientIP returns a key function that identifies clients by their IP address.
+func ClientIP() KeyFunc {
+	return func(ctx context.Context, req *http.Request) string {
+		return "ip:" + clientIP(req)
+	}
+}
+
+// APIKey returns a key function that identifies clients by the API key given in the header or
+// query string parameter with the given name. Requests without API key are identified by the
+// client IP address.
+func APIKey(name string) KeyFunc {
+	return func(ctx context.Context, req *http.Request) string {
+		key := req.Header.Get(name)
+		if key == "" {
+			key = req.URL.Query().Get(name)
+		}
+		if key == "" {
+			return "ip:" + clientIP(req)
+		}
+		return "key:" + key
+	}
+}
+
+// JWTSubject returns a key function that identifies clients by the subject of the JWT token
+// validated by the jwt middleware. Requests without token are identified by the client IP address.
+func JWTSubject() KeyFunc {
+	return func(ctx context.Context, req *http.Request) string {
+		var sub string
+		if token := jwt.ContextJWT(ctx); token != nil {
+			switch claims := token.Claims.(type) {
+			case jwtgo.MapClaims:
+				sub, _ = claims["sub"].(string)
+			case *jwtgo.StandardClaims:
+				sub = claims.Subject
+			}
+		}
+		if sub == "" {
+			return "ip:" + clientIP(req)
+		}
+		return "sub:" + sub
+	}
+}
+
+// clientIP returns the IP address of the client that made the request.
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+type (
+	// Limiter decides whether a request identified by a key is allowed.
+	Limiter interface {
+		// Allow consumes one request from the quota of key and returns the result.
+		Allow(ctx context.Context, key string) (*Result, error)
+	}
+
+	// Result is the outcome of a call to Allow.
+	Result struct {
+		// Allowed is true if the request is within the quota.
+		Allowed bool
+		// Limit is the number of requests allowed per period.
+		Limit int
+		// Remaining is the number of requests left in the current period.
+		Remaining int
+		// Reset is the time until the quota is fully restored.
+		Reset time.Duration
+		// RetryAfter is the time until the next request is allowed if Allowed is false.
+		RetryAfter time.Duration
+	}
+
+	// TokenBucket is a limiter that implements the token bucket algorithm. Each key has a bucket
+	// of Limit tokens that refills at a rate of Limit tokens per Period, each request consumes a
+	// token. TokenBucket allows bursts of up to Limit requests.
+	TokenBucket struct {
+		// Limit is the size of the bucket.
+		Limit int
+		// Period is the time it takes to refill an empty bucket.
+		Period time.Duration
+		// Store keeps the state of the buckets.
+		Store Store
+		// Now returns the current time, time.Now is used if nil.
+		Now func() time.Time
+	}
+
+	// SlidingWindow is a limiter that implements the sliding window counter algorithm. The
+	// number of requests made during the last Period is estimated from the counts of the
+	// current and previous fixed windows weighted by their overlap with the sliding window.
+	SlidingWindow struct {
+		// Limit is the number of requests allowed per period.
+		Limit int
+		// Period is the size of the window.
+		Period time.Duration
+		// Store keeps the request counts.
+		Store Store
+		// Now returns the current time, time.Now is used if nil.
+		Now func() time.Time
+	}
+)
+
+// NewTokenBucket returns a token bucket limiter that allows limit requests per period.
+func NewTokenBucket(limit int, period time.Duration, store Store) Limiter {
+	return &TokenBucket{Limit: limit, Period: period, Store: store}
+}
+
+// NewSlidingWindow returns a sliding window limiter that allows limit requests per period.
+func NewSlidingWindow(limit int, period time.Duration, store Store) Limiter {
+	return &SlidingWindow{Limit: limit, Period: period, Store: store}
+}
+
+// Allow consumes a token from the bucket of key.
+func (l *TokenBucket) Allow(ctx context.Context, key string) (*Result, error) {
+	now := timeNow(l.Now)
+	rate := float64(l.Limit) / float64(l.Period)
+	res := &Result{Limit: l.Limit}
+	err := l.Store.Update(ctx, key, l.Period, func(s *State) {
+		tokens := float64(l.Limit)
+		if !s.Time.IsZero() {
+			tokens = math.Min(tokens, s.Value+float64(now.Sub(s.Time))*rate)
+		}
+		if tokens >= 1 {
+			tokens--
+			res.Allowed = true
+		} else {
+			res.RetryAfter = time.Duration((1 - tokens) / rate)
+		}
+		s.Value = tokens
+		s.Time = now
+		res.Remaining = int(tokens)
+		res.Reset = time.Duration((float64(l.Limit) - tokens) / rate)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Allow counts a request made by key in the current window.
+func (l *SlidingWindow) Allow(ctx context.Context, key string) (*Result, error) {
+	now := timeNow(l.Now)
+	start := now.Truncate(l.Period)
+	res := &Result{Limit: l.Limit}
+	err := l.Store.Update(ctx, key, 2*l.Period, func(s *State) {
+		switch {
+		case s.Time.Equal(start):
+		case s.Time.Add(l.Period).Equal(start):
+			s.Previous, s.Value = s.Value, 0
+		default:
+			s.Previous, s.Value = 0, 0
+		}
+		s.Time = start
+		elapsed := now.Sub(start)
+		weight := float64(l.Period-elapsed) / float64(l.Period)
+		count := s.Previous*weight + s.Value
+		if count+1 <= float64(l.Limit) {
+			s.Value++
+			count++
+			res.Allowed = true
+		} else {
+			res.RetryAfter = l.retryAfter(s, elapsed)
+		}
+		res.Remaining = int(math.Max(0, float64(l.Limit)-count))
+		res.Reset = l.Period - elapsed
+		if s.Value > 0 {
+			res.Reset += l.Period
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// retryAfter computes the time until the estimated count drops enough to allow a request.
+func (l *SlidingWindow) retryAfter(s *State, elapsed time.Duration) time.Duration {
+	if s.Value+1 > float64(l.Limit) || s.Previous == 0 {
+		// Quota exhausted in the current window alone, wait for the next one.
+		return l.Period - elapsed
+	}
+	// Solve Previous*(Period-t)/Period + Value + 1 <= Limit for t.
+	t := float64(l.Period) * (1 - (float64(l.Limit)-s.Value-1)/s.Previous)
+	return time.Duration(t) - elapsed
+}
+
+// timeNow returns the current time using now if not nil.
+func timeNow(now func() time.Time) time.Time {
+	if now != nil {
+		return now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,114 @@
+package ratelimit_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/goadesign/goa/middleware/ratelimit"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TokenBucket", func() {
+	var now time.Time
+	var limiter *ratelimit.TokenBucket
+
+	BeforeEach(func() {
+		now = time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+		limiter = &ratelimit.TokenBucket{
+			Limit:  2,
+			Period: 10 * time.Second,
+			Store:  ratelimit.NewMemoryStore(),
+			Now:    func() time.Time { return now },
+		}
+	})
+
+	allow := func(key string) *ratelimit.Result {
+		res, err := limiter.Allow(context.Background(), key)
+		Ω(err).ShouldNot(HaveOccurred())
+		return res
+	}
+
+	It("allows bursts up to the limit", func() {
+		res := allow("a")
+		Ω(res.Allowed).Should(BeTrue())
+		Ω(res.Limit).Should(Equal(2))
+		Ω(res.Remaining).Should(Equal(1))
+		Ω(res.Reset).Should(Equal(5 * time.Second))
+		res = allow("a")
+		Ω(res.Allowed).Should(BeTrue())
+		Ω(res.Remaining).Should(Equal(0))
+		res = allow("a")
+		Ω(res.Allowed).Should(BeFalse())
+		Ω(res.RetryAfter).Should(Equal(5 * time.Second))
+	})
+
+	It("refills the bucket over time", func() {
+		allow("a")
+		allow("a")
+		now = now.Add(5 * time.Second)
+		Ω(allow("a").Allowed).Should(BeTrue())
+		Ω(allow("a").Allowed).Should(BeFalse())
+	})
+
+	It("keeps a bucket per key", func() {
+		allow("a")
+		allow("a")
+		Ω(allow("b").Allowed).Should(BeTrue())
+	})
+})
+
+var _ = Describe("SlidingWindow", func() {
+	var now time.Time
+	var limiter *ratelimit.SlidingWindow
+
+	BeforeEach(func() {
+		now = time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+		limiter = &ratelimit.SlidingWindow{
+			Limit:  4,
+			Period: 10 * time.Second,
+			Store:  ratelimit.NewMemoryStore(),
+			Now:    func() time.Time { return now },
+		}
+	})
+
+	allow := func(key string) *ratelimit.Result {
+		res, err := limiter.Allow(context.Background(), key)
+		Ω(err).ShouldNot(HaveOccurred())
+		return res
+	}
+
+	It("limits the number of requests in the window", func() {
+		for i := 0; i < 4; i++ {
+			res := allow("a")
+			Ω(res.Allowed).Should(BeTrue())
+			Ω(res.Remaining).Should(Equal(3 - i))
+		}
+		res := allow("a")
+		Ω(res.Allowed).Should(BeFalse())
+		Ω(res.RetryAfter).Should(Equal(10 * time.Second))
+	})
+
+	It("weights the previous window", func() {
+		for i := 0; i < 4; i++ {
+			allow("a")
+		}
+		now = now.Add(15 * time.Second)
+		// The previous window counts for half: 2 requests left.
+		Ω(allow("a").Allowed).Should(BeTrue())
+		Ω(allow("a").Allowed).Should(BeTrue())
+		res := allow("a")
+		Ω(res.Allowed).Should(BeFalse())
+		Ω(res.RetryAfter).Should(Equal(2500 * time.Millisecond))
+		now = now.Add(res.RetryAfter)
+		Ω(allow("a").Allowed).Should(BeTrue())
+	})
+
+	It("resets after two windows", func() {
+		for i := 0; i < 4; i++ {
+			allow("a")
+		}
+		now = now.Add(20 * time.Second)
+		Ω(allow("a").Remaining).Should(Equal(3))
+	})
+})
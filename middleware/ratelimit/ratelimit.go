@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/internal/config"
+)
+
+type (
+	// Option configures the rate limiting middleware.
+	Option func(*options)
+
+	// Algorithm creates a limiter that allows limit requests per period for each key and
+	// stores its state in store. NewTokenBucket and NewSlidingWindow are algorithms.
+	Algorithm func(limit int, period time.Duration, store Store) Limiter
+
+	options struct {
+		key       KeyFunc
+		store     Store
+		algorithm Algorithm
+		prefix    string
+	}
+)
+
+var (
+	// ErrRateLimitExceeded is the error returned by the middleware when a client exceeds its
+	// quota.
+	ErrRateLimitExceeded = goa.NewErrorClass("rate_limit_exceeded", http.StatusTooManyRequests)
+)
+
+// KeyBy sets the function used to compute the key that identifies the client making the request.
+// The default is ClientIP.
+func KeyBy(fn KeyFunc) Option {
+	return func(o *options) {
+		o.key = fn
+	}
+}
+
+// Backend sets the store used to keep the state of the limiters. The default is a memory store.
+func Backend(store Store) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// UseAlgorithm sets the rate limiting algorithm. The default is NewTokenBucket.
+func UseAlgorithm(a Algorithm) Option {
+	return func(o *options) {
+		o.algorithm = a
+	}
+}
+
+// Prefix sets the prefix added to the keys in the store. Middlewares that share a store must use
+// different prefixes to get independent quotas.
+func Prefix(p string) Option {
+	return func(o *options) {
+		o.prefix = p
+	}
+}
+
+// New returns a middleware that allows limit requests per period for each client.
+func New(limit int, period time.Duration, opts ...Option) goa.Middleware {
+	o := newOptions(opts)
+	limiter := o.algorithm(limit, period, o.store)
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return enforce(ctx, rw, req, h, limiter, o.key, o.prefix)
+		}
+	}
+}
+
+// configs records the options given to Configure for each service.
+var configs config.Registry
+
+// Configure sets the options used by the rate limits declared in the design. The options apply to
+// the actions of the service whether they are mounted before or after Configure is called.
+func Configure(service *goa.Service, opts ...Option) {
+	configs.Set(service, opts)
+}
+
+// Handle wraps h so that it allows limit requests per period for each client using the options
+// given to Configure for service. name identifies the quota in the store. This function is
+// intended for the controller generated code. User code should not need to call it directly.
+func Handle(service *goa.Service, name string, limit int, period time.Duration, h goa.Handler) goa.Handler {
+	fallback := NewMemoryStore()
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		opts, _ := configs.Get(service).([]Option)
+		o := newOptions(append([]Option{Backend(fallback)}, opts...))
+		limiter := o.algorithm(limit, period, o.store)
+		return enforce(ctx, rw, req, h, limiter, o.key, o.prefix+name+":")
+	}
+}
+
+// newOptions applies the given options to the default options.
+func newOptions(opts []Option) *options {
+	o := &options{key: ClientIP()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.store == nil {
+		o.store = NewMemoryStore()
+	}
+	if o.algorithm == nil {
+		o.algorithm = NewTokenBucket
+	}
+	return o
+}
+
+// enforce runs the limiter and calls h if the request is allowed.
+func enforce(ctx context.Context, rw http.ResponseWriter, req *http.Request, h goa.Handler, l Limiter, key KeyFunc, prefix string) error {
+	k := key(ctx, req)
+	if k == "" {
+		return h(ctx, rw, req)
+	}
+	res, err := l.Allow(ctx, prefix+k)
+	if err != nil {
+		return err
+	}
+	header := rw.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+	if !res.Allowed {
+		retry := seconds(res.RetryAfter)
+		header.Set("Retry-After", strconv.Itoa(retry))
+		return ErrRateLimitExceeded(fmt.Sprintf("rate limit exceeded, retry in %ds", retry), "limit", res.Limit)
+	}
+	return h(ctx, rw, req)
+}
+
+// seconds rounds the duration up to the next second.
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
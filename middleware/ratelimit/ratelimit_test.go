@@ -0,0 +1,138 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/ratelimit"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var handler goa.Handler
+	var calls int
+
+	BeforeEach(func() {
+		calls = 0
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			calls++
+			return nil
+		}
+	})
+
+	serve := func(h goa.Handler, remoteAddr string) (*httptest.ResponseRecorder, error) {
+		req, err := http.NewRequest("GET", "/foo?api_key=secret", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.RemoteAddr = remoteAddr
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		return rw, h(ctx, rw, req)
+	}
+
+	It("sets the rate limit headers", func() {
+		h := ratelimit.New(2, time.Minute)(handler)
+		rw, err := serve(h, "10.0.0.1:1234")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(1))
+		Ω(rw.Header().Get("RateLimit-Limit")).Should(Equal("2"))
+		Ω(rw.Header().Get("RateLimit-Remaining")).Should(Equal("1"))
+		Ω(rw.Header().Get("RateLimit-Reset")).Should(Equal("30"))
+	})
+
+	It("rejects requests over the limit", func() {
+		h := ratelimit.New(1, time.Minute)(handler)
+		_, err := serve(h, "10.0.0.1:1234")
+		Ω(err).ShouldNot(HaveOccurred())
+		rw, err := serve(h, "10.0.0.1:1234")
+		Ω(err).Should(HaveOccurred())
+		Ω(calls).Should(Equal(1))
+		Ω(err).Should(BeAssignableToTypeOf(&goa.ErrorResponse{}))
+		Ω(err.(*goa.ErrorResponse).Code).Should(Equal("rate_limit_exceeded"))
+		Ω(err.(*goa.ErrorResponse).Status).Should(Equal(http.StatusTooManyRequests))
+		Ω(rw.Header().Get("Retry-After")).Should(Equal("60"))
+
+		_, err = serve(h, "10.0.0.2:1234")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("uses the key function", func() {
+		h := ratelimit.New(1, time.Minute, ratelimit.KeyBy(ratelimit.APIKey("api_key")))(handler)
+		_, err := serve(h, "10.0.0.1:1234")
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = serve(h, "10.0.0.2:1234")
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("does not limit requests with an empty key", func() {
+		none := func(context.Context, *http.Request) string { return "" }
+		h := ratelimit.New(1, time.Minute, ratelimit.KeyBy(none))(handler)
+		for i := 0; i < 3; i++ {
+			rw, err := serve(h, "10.0.0.1:1234")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rw.Header().Get("RateLimit-Limit")).Should(BeEmpty())
+		}
+		Ω(calls).Should(Equal(3))
+	})
+})
+
+var _ = Describe("Handle", func() {
+	var service *goa.Service
+	var handler goa.Handler
+
+	BeforeEach(func() {
+		service = &goa.Service{Name: "test", Context: context.Background()}
+		handler = func(context.Context, http.ResponseWriter, *http.Request) error { return nil }
+	})
+
+	serve := func(h goa.Handler) error {
+		req, err := http.NewRequest("GET", "/foo", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.RemoteAddr = "10.0.0.1:1234"
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(service.Context, rw, req, nil)
+		return h(ctx, rw, req)
+	}
+
+	It("limits each action independently", func() {
+		store := ratelimit.NewMemoryStore()
+		ratelimit.Configure(service, ratelimit.Backend(store))
+		show := ratelimit.Handle(service, "bottle.show", 1, time.Minute, handler)
+		list := ratelimit.Handle(service, "bottle.list", 1, time.Minute, handler)
+		Ω(serve(show)).ShouldNot(HaveOccurred())
+		Ω(serve(list)).ShouldNot(HaveOccurred())
+		Ω(serve(show)).Should(HaveOccurred())
+		Ω(store.Len()).Should(Equal(2))
+	})
+
+	It("uses the options configured after the actions are mounted", func() {
+		show := ratelimit.Handle(service, "bottle.show", 1, time.Minute, handler)
+		store := ratelimit.NewMemoryStore()
+		ratelimit.Configure(service, ratelimit.Backend(store))
+		Ω(serve(show)).ShouldNot(HaveOccurred())
+		Ω(store.Len()).Should(Equal(1))
+	})
+
+	It("works without configuration", func() {
+		show := ratelimit.Handle(service, "bottle.show", 1, time.Minute, handler)
+		Ω(serve(show)).ShouldNot(HaveOccurred())
+		Ω(serve(show)).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("JWTSubject", func() {
+	It("uses the token subject", func() {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		key := ratelimit.JWTSubject()
+		Ω(key(context.Background(), req)).Should(Equal("ip:10.0.0.1"))
+		token := &jwtgo.Token{Claims: jwtgo.MapClaims{"sub": "alice"}}
+		ctx := jwt.WithJWT(context.Background(), token)
+		Ω(key(ctx, req)).Should(Equal("sub:alice"))
+	})
+})
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/goadesign/goa/middleware/internal/expiring"
+)
+
+type (
+	// Store keeps the state of the limiters. Implementations backed by a shared database allow
+	// multiple instances of a service to enforce the same quotas.
+	Store interface {
+		// Update calls fn with the state stored under key and saves the modified state. The
+		// state is the zero value if the key does not exist or has expired. The update must be
+		// atomic: concurrent calls for the same key must not interleave. The key expires after
+		// ttl if it is not updated.
+		Update(ctx context.Context, key string, ttl time.Duration, fn func(*State)) error
+	}
+
+	// State is the state of a limiter for a single key.
+	State struct {
+		// Value is the number of tokens left for token buckets or the number of requests
+		// made in the current window for sliding windows.
+		Value float64
+		// Previous is the number of requests made in the previous window for sliding
+		// windows.
+		Previous float64
+		// Time is the time of the last update for token buckets or the start of the current
+		// window for sliding windows.
+		Time time.Time
+	}
+
+	// MemoryStore is a Store that keeps the state in memory.
+	MemoryStore struct {
+		entries *expiring.Map
+	}
+)
+
+// NewMemoryStore returns a store that keeps the state in memory. It is the default store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: expiring.New()}
+}
+
+// Update implements Store.
+func (s *MemoryStore) Update(ctx context.Context, key string, ttl time.Duration, fn func(*State)) error {
+	s.entries.Update(key, ttl, func(v interface{}) interface{} {
+		state, ok := v.(*State)
+		if !ok {
+			state = &State{}
+		}
+		fn(state)
+		return state
+	})
+	return nil
+}
+
+// Len returns the number of keys in the store.
+func (s *MemoryStore) Len() int {
+	return s.entries.Len()
+}
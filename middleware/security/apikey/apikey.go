@@ -0,0 +1,100 @@
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+// ErrAPIKeyFailed is the error returned when the request API key is missing or invalid.
+var ErrAPIKeyFailed = goa.NewErrorClass("api_key_failed", 401)
+
+type (
+	// Validator validates API keys and resolves them into the principal they identify, for
+	// example a user or a client application.
+	Validator interface {
+		// Validate returns the principal identified by key. Returning an error denies the
+		// request, errors that are not goa errors are wrapped with ErrAPIKeyFailed.
+		Validate(ctx context.Context, key string) (interface{}, error)
+	}
+
+	// ValidatorFunc is an adapter that allows the use of ordinary functions as validators.
+	ValidatorFunc func(ctx context.Context, key string) (interface{}, error)
+
+	// staticKeys is a validator that uses a fixed set of keys.
+	staticKeys map[[sha256.Size]byte]interface{}
+)
+
+// New returns a middleware to be used with the APIKeySecurity DSL definitions of goa. The
+// middleware reads the API key from the header or query string parameter defined by the scheme
+// and validates it with validator. Keys read from headers may be prefixed with "Bearer ".
+//
+// The principal returned by the validator is available to the handlers and to the authorization
+// policy via goa.ContextPrincipal.
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//	app.UseAPIKeyMiddleware(service, apikey.New(apikey.Keys(map[string]interface{}{
+//		"c2VjcmV0": "ci",
+//	}), app.NewAPIKeySecurity()))
+func New(validator Validator, scheme *goa.APIKeySecurity) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var key string
+			switch scheme.In {
+			case goa.LocHeader:
+				key = req.Header.Get(scheme.Name)
+				if len(key) > 7 && strings.EqualFold(key[:7], "bearer ") {
+					key = strings.TrimSpace(key[7:])
+				}
+			case goa.LocQuery:
+				key = req.URL.Query().Get(scheme.Name)
+			default:
+				return fmt.Errorf("whoops, security scheme with location (in) %q not supported", scheme.In)
+			}
+			if key == "" {
+				return ErrAPIKeyFailed(fmt.Sprintf("missing API key %q", scheme.Name))
+			}
+			principal, err := validator.Validate(ctx, key)
+			if err != nil {
+				if _, ok := err.(goa.ServiceError); !ok {
+					err = ErrAPIKeyFailed(err)
+				}
+				return err
+			}
+			return h(goa.WithPrincipal(ctx, principal), rw, req)
+		}
+	}
+}
+
+// Keys returns a validator that accepts the given keys and resolves them into the
+// corresponding principals. Keys are compared in constant time.
+func Keys(keys map[string]interface{}) Validator {
+	s := make(staticKeys, len(keys))
+	for k, p := range keys {
+		s[sha256.Sum256([]byte(k))] = p
+	}
+	return s
+}
+
+// Validate calls fn.
+func (fn ValidatorFunc) Validate(ctx context.Context, key string) (interface{}, error) {
+	return fn(ctx, key)
+}
+
+// Validate returns the principal identified by key.
+func (s staticKeys) Validate(ctx context.Context, key string) (interface{}, error) {
+	actual := sha256.Sum256([]byte(key))
+	for k, p := range s {
+		if subtle.ConstantTimeCompare(k[:], actual[:]) == 1 {
+			return p, nil
+		}
+	}
+	return nil, ErrAPIKeyFailed("invalid API key")
+}
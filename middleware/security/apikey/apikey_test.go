@@ -0,0 +1,115 @@
+package apikey_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/apikey"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var (
+		validator apikey.Validator
+		scheme    *goa.APIKeySecurity
+		req       *http.Request
+		principal interface{}
+		err       error
+	)
+
+	BeforeEach(func() {
+		validator = apikey.Keys(map[string]interface{}{"key": "ci", "other": "admin"})
+		scheme = &goa.APIKeySecurity{In: goa.LocHeader, Name: "X-API-Key"}
+		req, _ = http.NewRequest("GET", "http://example.com/", nil)
+		principal = nil
+	})
+
+	JustBeforeEach(func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			principal = goa.ContextPrincipal(ctx)
+			return nil
+		}
+		err = apikey.New(validator, scheme)(h)(context.Background(), httptest.NewRecorder(), req)
+	})
+
+	Context("with a key in a header", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Key", "key")
+		})
+
+		It("resolves the principal", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(principal).Should(Equal("ci"))
+		})
+	})
+
+	Context("with a bearer key in a header", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Key", "Bearer other")
+		})
+
+		It("strips the prefix", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(principal).Should(Equal("admin"))
+		})
+	})
+
+	Context("with a key in the query string", func() {
+		BeforeEach(func() {
+			scheme = &goa.APIKeySecurity{In: goa.LocQuery, Name: "api_key"}
+			req.URL.RawQuery = "api_key=key"
+		})
+
+		It("resolves the principal", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(principal).Should(Equal("ci"))
+		})
+	})
+
+	Context("with a missing key", func() {
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("with an invalid key", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Key", "invalid")
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusUnauthorized))
+			Ω(principal).Should(BeNil())
+		})
+	})
+
+	Context("with a validator function", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Key", "key")
+			validator = apikey.ValidatorFunc(func(ctx context.Context, key string) (interface{}, error) {
+				return nil, errors.New("revoked")
+			})
+		})
+
+		It("wraps plain errors", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("with an unsupported location", func() {
+		BeforeEach(func() {
+			scheme = &goa.APIKeySecurity{In: "cookie", Name: "key"}
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -1,7 +1,10 @@
 package basicauth
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
 	"net/http"
+	"strconv"
 
 	"context"
 
@@ -11,6 +14,17 @@ import (
 // ErrBasicAuthFailed means it wasn't able to authenticate you with your login/password.
 var ErrBasicAuthFailed = goa.NewErrorClass("basic_auth_failed", 401)
 
+// DefaultRealm is the realm used in the WWW-Authenticate response header when none is given to
+// NewWithValidator.
+const DefaultRealm = "Restricted"
+
+// Validator validates the username and password of a request. Returning an error denies the
+// request, errors that are not goa errors are wrapped with ErrBasicAuthFailed.
+type Validator func(ctx context.Context, username, password string) error
+
+// private type used to store the authenticated username in the request context.
+type usernameKey struct{}
+
 // New creates a static username/password auth middleware.
 //
 // Example:
@@ -18,15 +32,71 @@ var ErrBasicAuthFailed = goa.NewErrorClass("basic_auth_failed", 401)
 //
 // It doesn't get simpler than that.
 //
-// If you want to handle the username and password checks dynamically,
-// copy the source of `New`, it's 8 lines and you can tweak at will.
+// If you want to handle the username and password checks dynamically use NewWithValidator.
 func New(username, password string) goa.Middleware {
-	middleware, _ := goa.NewMiddleware(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-		u, p, ok := r.BasicAuth()
-		if !ok || u != username || p != password {
+	return NewWithValidator(DefaultRealm, Credentials(map[string]string{username: password}))
+}
+
+// NewWithValidator creates a basic auth middleware that calls validator with the request
+// credentials. Requests without credentials or with invalid credentials are rejected with
+// ErrBasicAuthFailed and a WWW-Authenticate response header that uses the given realm,
+// DefaultRealm if empty.
+//
+// The authenticated username is available to the handlers via ContextUsername and is the
+// principal given to the authorization policy.
+//
+// Example:
+//    validator, err := basicauth.Htpasswd("/etc/myservice/htpasswd")
+//    if err != nil {
+//        return err
+//    }
+//    app.UseBasicAuth(basicauth.NewWithValidator("My Service", validator))
+func NewWithValidator(realm string, validator Validator) goa.Middleware {
+	if realm == "" {
+		realm = DefaultRealm
+	}
+	challenge := `Basic realm=` + strconv.Quote(realm) + `, charset="UTF-8"`
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			u, p, ok := req.BasicAuth()
+			if !ok {
+				rw.Header().Set("WWW-Authenticate", challenge)
+				return ErrBasicAuthFailed("Authentication failed")
+			}
+			if err := validator(ctx, u, p); err != nil {
+				if _, ok := err.(goa.ServiceError); !ok {
+					err = ErrBasicAuthFailed("Authentication failed")
+				}
+				if err.(goa.ServiceError).ResponseStatus() == http.StatusUnauthorized {
+					rw.Header().Set("WWW-Authenticate", challenge)
+				}
+				return err
+			}
+			ctx = context.WithValue(ctx, usernameKey{}, u)
+			return h(goa.WithPrincipal(ctx, u), rw, req)
+		}
+	}
+}
+
+// Credentials returns a validator that accepts the given username and password pairs. The
+// comparisons are done in constant time.
+func Credentials(creds map[string]string) Validator {
+	hashed := make(map[[sha256.Size]byte][sha256.Size]byte, len(creds))
+	for u, p := range creds {
+		hashed[sha256.Sum256([]byte(u))] = sha256.Sum256([]byte(p))
+	}
+	return func(ctx context.Context, username, password string) error {
+		expected, ok := hashed[sha256.Sum256([]byte(username))]
+		actual := sha256.Sum256([]byte(password))
+		if subtle.ConstantTimeCompare(expected[:], actual[:]) != 1 || !ok {
 			return ErrBasicAuthFailed("Authentication failed")
 		}
 		return nil
-	})
-	return middleware
+	}
+}
+
+// ContextUsername returns the username authenticated by the middleware.
+func ContextUsername(ctx context.Context) string {
+	u, _ := ctx.Value(usernameKey{}).(string)
+	return u
 }
@@ -0,0 +1,152 @@
+package basicauth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/basicauth"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ = Describe("NewWithValidator", func() {
+	var (
+		validator basicauth.Validator
+		realm     string
+		req       *http.Request
+		rw        *httptest.ResponseRecorder
+		username  string
+		err       error
+	)
+
+	BeforeEach(func() {
+		validator = basicauth.Credentials(map[string]string{"admin": "password"})
+		realm = ""
+		req, _ = http.NewRequest("GET", "http://example.com/", nil)
+		req.SetBasicAuth("admin", "password")
+		username = ""
+	})
+
+	JustBeforeEach(func() {
+		rw = httptest.NewRecorder()
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			username = basicauth.ContextUsername(ctx)
+			return nil
+		}
+		err = basicauth.NewWithValidator(realm, validator)(h)(context.Background(), rw, req)
+	})
+
+	It("accepts valid credentials", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(username).Should(Equal("admin"))
+	})
+
+	Context("with an invalid password", func() {
+		BeforeEach(func() {
+			req.SetBasicAuth("admin", "wrong")
+		})
+
+		It("rejects the request with a challenge", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusUnauthorized))
+			Ω(rw.Header().Get("WWW-Authenticate")).Should(Equal(`Basic realm="Restricted", charset="UTF-8"`))
+		})
+	})
+
+	Context("with an unknown user", func() {
+		BeforeEach(func() {
+			req.SetBasicAuth("root", "password")
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("without credentials", func() {
+		BeforeEach(func() {
+			req.Header.Del("Authorization")
+			realm = "My Service"
+		})
+
+		It("challenges the client with the realm", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(rw.Header().Get("WWW-Authenticate")).Should(Equal(`Basic realm="My Service", charset="UTF-8"`))
+		})
+	})
+
+	Context("with a validator returning a forbidden error", func() {
+		BeforeEach(func() {
+			forbidden := goa.NewErrorClass("forbidden", 403)
+			validator = func(ctx context.Context, u, p string) error {
+				return forbidden("account disabled")
+			}
+		})
+
+		It("returns the error without challenge", func() {
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusForbidden))
+			Ω(rw.Header().Get("WWW-Authenticate")).Should(BeEmpty())
+		})
+	})
+
+	Context("with a validator returning a plain error", func() {
+		BeforeEach(func() {
+			validator = func(ctx context.Context, u, p string) error {
+				return errors.New("boom")
+			}
+		})
+
+		It("wraps the error", func() {
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("with an htpasswd file", func() {
+		BeforeEach(func() {
+			hash, herr := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+			Ω(herr).ShouldNot(HaveOccurred())
+			htpasswd := "# users\nadmin:" + string(hash) + "\n" +
+				// htpasswd -bs ci secret
+				"ci:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=\n"
+			validator, err = basicauth.ParseHtpasswd(strings.NewReader(htpasswd))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("accepts bcrypt passwords", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		Context("and SHA-1 passwords", func() {
+			BeforeEach(func() {
+				req.SetBasicAuth("ci", "secret")
+			})
+
+			It("accepts the request", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(username).Should(Equal("ci"))
+			})
+		})
+
+		Context("and an invalid password", func() {
+			BeforeEach(func() {
+				req.SetBasicAuth("admin", "secret")
+			})
+
+			It("rejects the request", func() {
+				Ω(err).Should(HaveOccurred())
+			})
+		})
+	})
+})
+
+var _ = Describe("ParseHtpasswd", func() {
+	It("rejects unsupported hash formats", func() {
+		_, err := basicauth.ParseHtpasswd(strings.NewReader("admin:$apr1$salt$hash\n"))
+		Ω(err).Should(HaveOccurred())
+	})
+})
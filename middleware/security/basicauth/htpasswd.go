@@ -0,0 +1,75 @@
+package basicauth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Htpasswd returns a validator that checks the credentials against the entries of the htpasswd
+// file at the given path. The file is read once. Passwords must be hashed with bcrypt (htpasswd
+// -B) or SHA-1 (htpasswd -s), other formats are rejected.
+func Htpasswd(path string) (Validator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseHtpasswd(f)
+}
+
+// ParseHtpasswd returns a validator that checks the credentials against the htpasswd entries
+// read from r, see Htpasswd.
+func ParseHtpasswd(r io.Reader) (Validator, error) {
+	hashes := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("htpasswd: invalid entry on line %d", n)
+		}
+		user, hash := line[:i], line[i+1:]
+		if !isBcrypt(hash) && !strings.HasPrefix(hash, "{SHA}") {
+			return nil, fmt.Errorf("htpasswd: unsupported hash format for user %q, use bcrypt or SHA-1", user)
+		}
+		hashes[user] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, username, password string) error {
+		hash, ok := hashes[username]
+		if !ok || !checkHash(hash, password) {
+			return ErrBasicAuthFailed("Authentication failed")
+		}
+		return nil
+	}, nil
+}
+
+// isBcrypt returns true if hash is a bcrypt hash.
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// checkHash returns true if password matches the htpasswd hash.
+func checkHash(hash, password string) bool {
+	if isBcrypt(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+	sum := sha1.Sum([]byte(password))
+	expected := []byte(strings.TrimPrefix(hash, "{SHA}"))
+	actual := []byte(base64.StdEncoding.EncodeToString(sum[:]))
+	return subtle.ConstantTimeCompare(expected, actual) == 1
+}
@@ -0,0 +1,271 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+type (
+	// Option configures the validation of the token claims done by the middleware.
+	Option func(*options)
+
+	// Claims exposes the registered claims of a validated token. The complete set of claims
+	// is available in Raw.
+	Claims struct {
+		// Issuer is the "iss" claim.
+		Issuer string
+		// Subject is the "sub" claim.
+		Subject string
+		// Audience is the "aud" claim, a single string audience is returned as a one
+		// element slice.
+		Audience []string
+		// ExpiresAt is the "exp" claim, zero if absent.
+		ExpiresAt time.Time
+		// NotBefore is the "nbf" claim, zero if absent.
+		NotBefore time.Time
+		// IssuedAt is the "iat" claim, zero if absent.
+		IssuedAt time.Time
+		// ID is the "jti" claim.
+		ID string
+		// Scopes are the scopes read from the scope claim configured in the middleware.
+		Scopes []string
+		// Raw contains all the claims of the token.
+		Raw jwt.MapClaims
+	}
+
+	options struct {
+		issuers        []string
+		audiences      []string
+		leeway         time.Duration
+		algorithms     []string
+		required       []string
+		scopeClaims    []string
+		scopeDelimiter string
+	}
+)
+
+// Issuer requires the "iss" claim to be one of the given values.
+func Issuer(issuers ...string) Option {
+	return func(o *options) {
+		o.issuers = append(o.issuers, issuers...)
+	}
+}
+
+// Audience requires the "aud" claim to contain at least one of the given values.
+func Audience(audiences ...string) Option {
+	return func(o *options) {
+		o.audiences = append(o.audiences, audiences...)
+	}
+}
+
+// Leeway sets the clock skew tolerated when validating the "exp", "nbf" and "iat" claims.
+func Leeway(d time.Duration) Option {
+	return func(o *options) {
+		o.leeway = d
+	}
+}
+
+// Algorithms restricts the signing algorithms accepted by the middleware, e.g. "RS256" or
+// "EdDSA". Tokens signed with other algorithms are rejected before their signature is checked.
+// The default accepts any algorithm matching the type of the validation keys.
+func Algorithms(algs ...string) Option {
+	return func(o *options) {
+		o.algorithms = append(o.algorithms, algs...)
+	}
+}
+
+// RequiredClaims requires the token to contain non null values for the given claims.
+func RequiredClaims(names ...string) Option {
+	return func(o *options) {
+		o.required = append(o.required, names...)
+	}
+}
+
+// ScopeClaim sets the names of the claims that contain the token scopes. The first claim present
+// with a non null value is used. The default is "scope" then "scopes".
+func ScopeClaim(names ...string) Option {
+	return func(o *options) {
+		o.scopeClaims = names
+	}
+}
+
+// ScopeDelimiter sets the separator used to split scopes given as a single string, a space by
+// default.
+func ScopeDelimiter(delim string) Option {
+	return func(o *options) {
+		o.scopeDelimiter = delim
+	}
+}
+
+// newOptions applies the given options on top of the defaults.
+func newOptions(opts ...Option) *options {
+	o := &options{scopeClaims: validScopeClaimKeys, scopeDelimiter: " "}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// validateClaims validates the time based claims of the token and the claims required by the
+// options.
+func validateClaims(claims jwt.MapClaims, o *options) error {
+	now := time.Now()
+	if exp, ok := numericDate(claims["exp"]); ok && now.After(exp.Add(o.leeway)) {
+		return fmt.Errorf("token is expired")
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(o.leeway).Before(nbf) {
+		return fmt.Errorf("token is not valid yet")
+	}
+	if iat, ok := numericDate(claims["iat"]); ok && now.Add(o.leeway).Before(iat) {
+		return fmt.Errorf("token used before issued")
+	}
+	for _, name := range o.required {
+		if claims[name] == nil {
+			return fmt.Errorf("missing required claim %q", name)
+		}
+	}
+	if len(o.issuers) > 0 {
+		iss, _ := claims["iss"].(string)
+		if !contains(o.issuers, iss) {
+			return fmt.Errorf("invalid issuer %q", iss)
+		}
+	}
+	if len(o.audiences) > 0 {
+		var valid bool
+		for _, aud := range audience(claims["aud"]) {
+			if contains(o.audiences, aud) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid audience")
+		}
+	}
+	return nil
+}
+
+// newClaims builds the typed claims of a validated token.
+func newClaims(claims jwt.MapClaims, scopes []string) *Claims {
+	c := &Claims{Audience: audience(claims["aud"]), Scopes: scopes, Raw: claims}
+	c.Issuer, _ = claims["iss"].(string)
+	c.Subject, _ = claims["sub"].(string)
+	c.ID, _ = claims["jti"].(string)
+	c.ExpiresAt, _ = numericDate(claims["exp"])
+	c.NotBefore, _ = numericDate(claims["nbf"])
+	c.IssuedAt, _ = numericDate(claims["iat"])
+	return c
+}
+
+// String returns the value of the claim with the given name if it is a string.
+func (c *Claims) String(name string) (string, bool) {
+	s, ok := c.Raw[name].(string)
+	return s, ok
+}
+
+// Strings returns the value of the claim with the given name if it is a string or a list of
+// strings.
+func (c *Claims) Strings(name string) ([]string, bool) {
+	switch v := c.Raw[name].(type) {
+	case string:
+		return []string{v}, true
+	case []interface{}:
+		res := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, false
+			}
+			res = append(res, s)
+		}
+		return res, true
+	}
+	return nil, false
+}
+
+// Bool returns the value of the claim with the given name if it is a boolean.
+func (c *Claims) Bool(name string) (bool, bool) {
+	b, ok := c.Raw[name].(bool)
+	return b, ok
+}
+
+// Float returns the value of the claim with the given name if it is a number.
+func (c *Claims) Float(name string) (float64, bool) {
+	switch v := c.Raw[name].(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// HasScope returns true if the token was granted the given scope.
+func (c *Claims) HasScope(scope string) bool {
+	i := sort.SearchStrings(c.Scopes, scope)
+	return i < len(c.Scopes) && c.Scopes[i] == scope
+}
+
+// numericDate converts a JWT NumericDate claim value into a time.
+func numericDate(v interface{}) (time.Time, bool) {
+	var secs float64
+	switch v := v.(type) {
+	case float64:
+		secs = v
+	case int64:
+		secs = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		secs = f
+	default:
+		return time.Time{}, false
+	}
+	return time.Unix(int64(secs), 0), true
+}
+
+// audience returns the values of the "aud" claim which may be a string or a list of strings.
+func audience(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var auds []string
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				auds = append(auds, s)
+			}
+		}
+		return auds
+	case []string:
+		return v
+	}
+	return nil
+}
+
+// contains returns true if vals contains val.
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeClaimNames returns the quoted scope claim names for use in error messages.
+func scopeClaimNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = "'" + n + "'"
+	}
+	return strings.Join(quoted, " or ")
+}
@@ -0,0 +1,258 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	jwtpkg "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Claims validation", func() {
+	var (
+		scheme   *goa.JWTSecurity
+		resolver jwt.KeyResolver
+		opts     []jwt.Option
+		claims   jwtpkg.MapClaims
+		method   jwtpkg.SigningMethod
+		signKey  interface{}
+		ctx      context.Context
+
+		validated *jwt.Claims
+		err       error
+	)
+
+	BeforeEach(func() {
+		scheme = &goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"}
+		resolver = jwt.NewSimpleResolver([]jwt.Key{"keys"})
+		opts = nil
+		now := time.Now().Unix()
+		claims = jwtpkg.MapClaims{
+			"iss":   "https://idp.goa.design",
+			"sub":   "user",
+			"aud":   []string{"api", "admin"},
+			"exp":   now + 60,
+			"iat":   now,
+			"jti":   "id",
+			"scope": "read write",
+			"admin": true,
+		}
+		method = jwtpkg.SigningMethodHS256
+		signKey = []byte("keys")
+		ctx = context.Background()
+		validated = nil
+	})
+
+	JustBeforeEach(func() {
+		token, serr := jwtpkg.NewWithClaims(method, claims).SignedString(signKey)
+		Ω(serr).ShouldNot(HaveOccurred())
+		req, _ := http.NewRequest("GET", "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			validated = jwt.ContextClaims(ctx)
+			return nil
+		}
+		err = jwt.New(resolver, nil, scheme, opts...)(h)(ctx, httptest.NewRecorder(), req)
+	})
+
+	It("exposes the typed claims", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(validated).ShouldNot(BeNil())
+		Ω(validated.Issuer).Should(Equal("https://idp.goa.design"))
+		Ω(validated.Subject).Should(Equal("user"))
+		Ω(validated.Audience).Should(Equal([]string{"api", "admin"}))
+		Ω(validated.ID).Should(Equal("id"))
+		Ω(validated.ExpiresAt.Unix()).Should(BeEquivalentTo(claims["exp"]))
+		Ω(validated.IssuedAt.Unix()).Should(BeEquivalentTo(claims["iat"]))
+		Ω(validated.NotBefore.IsZero()).Should(BeTrue())
+		Ω(validated.Scopes).Should(Equal([]string{"read", "write"}))
+		Ω(validated.HasScope("write")).Should(BeTrue())
+		Ω(validated.HasScope("delete")).Should(BeFalse())
+		admin, ok := validated.Bool("admin")
+		Ω(ok).Should(BeTrue())
+		Ω(admin).Should(BeTrue())
+		_, ok = validated.String("admin")
+		Ω(ok).Should(BeFalse())
+	})
+
+	Context("with an expired token", func() {
+		BeforeEach(func() {
+			claims["exp"] = time.Now().Add(-10 * time.Second).Unix()
+		})
+
+		It("rejects the token", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("token is expired"))
+		})
+
+		Context("within the leeway", func() {
+			BeforeEach(func() {
+				opts = []jwt.Option{jwt.Leeway(time.Minute)}
+			})
+
+			It("accepts the token", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a token not valid yet", func() {
+		BeforeEach(func() {
+			claims["nbf"] = time.Now().Add(10 * time.Second).Unix()
+		})
+
+		It("rejects the token", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+
+		Context("within the leeway", func() {
+			BeforeEach(func() {
+				opts = []jwt.Option{jwt.Leeway(time.Minute)}
+			})
+
+			It("accepts the token", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with an expected issuer", func() {
+		BeforeEach(func() {
+			opts = []jwt.Option{jwt.Issuer("https://other.goa.design", "https://idp.goa.design")}
+		})
+
+		It("accepts matching tokens", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		Context("and a different issuer", func() {
+			BeforeEach(func() {
+				claims["iss"] = "https://evil.goa.design"
+			})
+
+			It("rejects the token", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("invalid issuer"))
+			})
+		})
+	})
+
+	Context("with an expected audience", func() {
+		BeforeEach(func() {
+			opts = []jwt.Option{jwt.Audience("api")}
+		})
+
+		It("accepts tokens containing the audience", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		Context("and a single string audience", func() {
+			BeforeEach(func() {
+				claims["aud"] = "api"
+			})
+
+			It("accepts the token", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(validated.Audience).Should(Equal([]string{"api"}))
+			})
+		})
+
+		Context("and a different audience", func() {
+			BeforeEach(func() {
+				claims["aud"] = "other"
+			})
+
+			It("rejects the token", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("invalid audience"))
+			})
+		})
+	})
+
+	Context("with required claims", func() {
+		BeforeEach(func() {
+			opts = []jwt.Option{jwt.RequiredClaims("sub", "tenant")}
+		})
+
+		It("rejects tokens missing one", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`missing required claim "tenant"`))
+		})
+	})
+
+	Context("with allowed algorithms", func() {
+		BeforeEach(func() {
+			opts = []jwt.Option{jwt.Algorithms("HS512")}
+		})
+
+		It("rejects tokens signed with other algorithms", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+
+		Context("matching the token", func() {
+			BeforeEach(func() {
+				method = jwtpkg.SigningMethodHS512
+			})
+
+			It("accepts the token", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a custom scope claim", func() {
+		BeforeEach(func() {
+			claims["permissions"] = "read,write"
+			opts = []jwt.Option{jwt.ScopeClaim("permissions"), jwt.ScopeDelimiter(",")}
+			ctx = goa.WithRequiredScopes(ctx, []string{"write"})
+		})
+
+		It("reads the scopes from the claim", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(validated.Scopes).Should(Equal([]string{"read", "write"}))
+		})
+
+		Context("missing the required scope", func() {
+			BeforeEach(func() {
+				claims["permissions"] = "read"
+			})
+
+			It("rejects the token", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("required 'permissions' not present"))
+			})
+		})
+	})
+
+	Context("with an Ed25519 key", func() {
+		BeforeEach(func() {
+			pub, priv, gerr := ed25519.GenerateKey(rand.Reader)
+			Ω(gerr).ShouldNot(HaveOccurred())
+			resolver = jwt.NewSimpleResolver([]jwt.Key{"keys", pub})
+			method = jwt.SigningMethodEdDSA
+			signKey = priv
+		})
+
+		It("validates EdDSA tokens", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(validated.Subject).Should(Equal("user"))
+		})
+
+		Context("signed with another key", func() {
+			BeforeEach(func() {
+				_, signKey, _ = ed25519.GenerateKey(rand.Reader)
+			})
+
+			It("rejects the token", func() {
+				Ω(err).Should(HaveOccurred())
+			})
+		})
+	})
+})
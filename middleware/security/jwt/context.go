@@ -10,6 +10,7 @@ type contextKey int
 
 const (
 	jwtKey contextKey = iota + 1
+	claimsKey
 )
 
 // WithJWT creates a child context containing the given JWT.
@@ -25,3 +26,17 @@ func ContextJWT(ctx context.Context) *jwt.Token {
 	}
 	return token
 }
+
+// WithClaims creates a child context containing the given claims.
+func WithClaims(ctx context.Context, c *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, c)
+}
+
+// ContextClaims retrieves the claims of the JWT token validated by the security middleware.
+func ContextClaims(ctx context.Context) *Claims {
+	c, ok := ctx.Value(claimsKey).(*Claims)
+	if !ok {
+		return nil
+	}
+	return c
+}
@@ -0,0 +1,56 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// SigningMethodEdDSA is the EdDSA signing method (RFC 8037) using Ed25519 keys. It is registered
+// with the JWT library under the "EdDSA" algorithm name so that tokens signed with Ed25519 keys
+// can be validated by the middleware. Sign expects an ed25519.PrivateKey and Verify an
+// ed25519.PublicKey.
+var SigningMethodEdDSA jwt.SigningMethod = signingMethodEdDSA{}
+
+// ErrEdDSAVerification is returned by SigningMethodEdDSA when the signature is invalid.
+var ErrEdDSAVerification = errors.New("EdDSA verification failed")
+
+// signingMethodEdDSA implements the EdDSA signing method.
+type signingMethodEdDSA struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+// Alg returns the algorithm name.
+func (signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+// Verify checks the signature of signingString with the given ed25519.PublicKey.
+func (signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok || len(pub) != ed25519.PublicKeySize {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, []byte(signingString), sig) {
+		return ErrEdDSAVerification
+	}
+	return nil
+}
+
+// Sign signs signingString with the given ed25519.PrivateKey.
+func (signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok || len(priv) != ed25519.PrivateKeySize {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(priv, []byte(signingString))), nil
+}
@@ -0,0 +1,371 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultJWKSCacheTTL is the duration the keys fetched from a JWKS endpoint are cached
+	// when the response does not specify a lifetime with the Cache-Control or Expires headers.
+	DefaultJWKSCacheTTL = time.Hour
+
+	// DefaultJWKSMinRefreshInterval is the minimum duration between two fetches of the JWKS
+	// triggered by tokens signed with an unknown key.
+	DefaultJWKSMinRefreshInterval = time.Minute
+
+	// DefaultJWKSTimeout is the timeout of the HTTP client used to fetch the JWKS. Fetches
+	// happen in the request path so they must not be allowed to hang.
+	DefaultJWKSTimeout = 10 * time.Second
+)
+
+type (
+	// TokenKeyResolver is implemented by key resolvers that select keys using the header of the
+	// incoming token, for example its "kid" (key ID) field. The middleware calls SelectTokenKeys
+	// instead of SelectKeys for such resolvers.
+	TokenKeyResolver interface {
+		KeyResolver
+		// SelectTokenKeys returns the keys to be used to validate the incoming token given
+		// its header.
+		SelectTokenKeys(req *http.Request, header map[string]interface{}) []Key
+	}
+
+	// JWKSResolver is a key resolver that fetches the keys from a JSON Web Key Set (RFC 7517)
+	// endpoint. Keys are selected using the token "kid" header, the key set is fetched again when
+	// the cache expires or when a token references an unknown key ID. The cache lifetime is
+	// taken from the response Cache-Control max-age directive or Expires header.
+	//
+	// RSA and EC keys are resolved into *rsa.PublicKey and *ecdsa.PublicKey values, symmetric
+	// (oct) keys into []byte values and OKP keys using the Ed25519 curve into ed25519.PublicKey
+	// values. Keys whose "use" is not "sig" and keys of unsupported types are ignored.
+	JWKSResolver struct {
+		url                string
+		client             *http.Client
+		ttl                time.Duration
+		minRefreshInterval time.Duration
+
+		fetchMu sync.Mutex // serializes fetches
+
+		mu        sync.RWMutex
+		keys      map[string]Key
+		all       []Key
+		etag      string
+		expires   time.Time
+		fetchedAt time.Time
+	}
+
+	// JWKSOption configures a JWKSResolver.
+	JWKSOption func(*JWKSResolver)
+
+	// jwks is the JSON representation of a JSON Web Key Set.
+	jwks struct {
+		Keys []*jwk `json:"keys"`
+	}
+
+	// jwk is the JSON representation of a JSON Web Key.
+	jwk struct {
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		Kid string `json:"kid"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+		K   string `json:"k"`
+	}
+)
+
+// JWKSClient sets the HTTP client used to fetch the key set. The default client times out after
+// DefaultJWKSTimeout, custom clients should also set a timeout as fetches happen in the request
+// path.
+func JWKSClient(c *http.Client) JWKSOption {
+	return func(r *JWKSResolver) {
+		r.client = c
+	}
+}
+
+// JWKSCacheTTL sets the duration the keys are cached when the JWKS response does not specify
+// a lifetime, DefaultJWKSCacheTTL by default.
+func JWKSCacheTTL(d time.Duration) JWKSOption {
+	return func(r *JWKSResolver) {
+		r.ttl = d
+	}
+}
+
+// JWKSMinRefreshInterval sets the minimum duration between two fetches of the key set,
+// DefaultJWKSMinRefreshInterval by default. It rate limits the fetches triggered by tokens
+// referencing unknown keys and by responses that disable caching.
+func JWKSMinRefreshInterval(d time.Duration) JWKSOption {
+	return func(r *JWKSResolver) {
+		r.minRefreshInterval = d
+	}
+}
+
+// NewJWKSResolver returns a resolver that fetches the keys from the JWKS endpoint at the given
+// URL. It fetches the key set once and returns an error if the request fails or the response
+// cannot be parsed.
+//
+//	resolver, err := jwt.NewJWKSResolver("https://idp.example.com/.well-known/jwks.json")
+//	if err != nil {
+//		return err
+//	}
+//	app.UseJWTMiddleware(service, jwt.New(resolver, nil, app.NewJWTSecurity()))
+func NewJWKSResolver(url string, opts ...JWKSOption) (*JWKSResolver, error) {
+	r := &JWKSResolver{
+		url:                url,
+		client:             &http.Client{Timeout: DefaultJWKSTimeout},
+		ttl:                DefaultJWKSCacheTTL,
+		minRefreshInterval: DefaultJWKSMinRefreshInterval,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	if err := r.fetch(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SelectKeys returns all the keys of the key set.
+func (r *JWKSResolver) SelectKeys(req *http.Request) []Key {
+	r.refreshIfExpired()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.all
+}
+
+// SelectTokenKeys returns the key identified by the token "kid" header, fetching the key set
+// again if the key is unknown. It returns all the keys if the token has no "kid" header.
+func (r *JWKSResolver) SelectTokenKeys(req *http.Request, header map[string]interface{}) []Key {
+	kid, _ := header["kid"].(string)
+	if kid == "" {
+		return r.SelectKeys(req)
+	}
+	r.refreshIfExpired()
+	if key, ok := r.key(kid); ok {
+		return []Key{key}
+	}
+	if r.canRefresh() {
+		r.fetch()
+		if key, ok := r.key(kid); ok {
+			return []Key{key}
+		}
+	}
+	return nil
+}
+
+// Refresh fetches the key set regardless of the cache state.
+func (r *JWKSResolver) Refresh() error {
+	return r.fetch()
+}
+
+// key returns the key with the given ID.
+func (r *JWKSResolver) key(kid string) (Key, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// canRefresh returns true if the last fetch is older than the minimum refresh interval.
+func (r *JWKSResolver) canRefresh() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return time.Since(r.fetchedAt) >= r.minRefreshInterval
+}
+
+// refreshIfExpired fetches the key set if the cache expired. Errors are ignored so that the
+// stale keys keep being used while the endpoint is unavailable.
+func (r *JWKSResolver) refreshIfExpired() {
+	r.mu.RLock()
+	expired := time.Now().After(r.expires)
+	r.mu.RUnlock()
+	if expired && r.canRefresh() {
+		r.fetch()
+	}
+}
+
+// fetch retrieves the key set and replaces the cached keys.
+func (r *JWKSResolver) fetch() error {
+	r.mu.RLock()
+	started := r.fetchedAt
+	r.mu.RUnlock()
+	r.fetchMu.Lock()
+	defer r.fetchMu.Unlock()
+	r.mu.RLock()
+	etag, fetched := r.etag, r.fetchedAt != started
+	r.mu.RUnlock()
+	if fetched {
+		// Another goroutine fetched the key set while this one was waiting.
+		return nil
+	}
+
+	req, err := http.NewRequest("GET", r.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := r.client.Do(req)
+	now := time.Now()
+	if err != nil {
+		r.mu.Lock()
+		r.fetchedAt = now
+		r.mu.Unlock()
+		return fmt.Errorf("failed to fetch JWKS: %s", err)
+	}
+	defer resp.Body.Close()
+	expires := cacheExpiry(resp, now, r.ttl)
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		r.mu.Lock()
+		r.fetchedAt, r.expires = now, expires
+		r.mu.Unlock()
+		return nil
+	case http.StatusOK:
+	default:
+		r.mu.Lock()
+		r.fetchedAt = now
+		r.mu.Unlock()
+		return fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var set jwks
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		r.mu.Lock()
+		r.fetchedAt = now
+		r.mu.Unlock()
+		return fmt.Errorf("failed to decode JWKS: %s", err)
+	}
+	keys := make(map[string]Key, len(set.Keys))
+	var all []Key
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		if k.Kid != "" {
+			keys[k.Kid] = key
+		}
+		all = append(all, key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys, r.all = keys, all
+	r.etag = resp.Header.Get("ETag")
+	r.fetchedAt, r.expires = now, expires
+	return nil
+}
+
+// cacheExpiry computes the expiry of the response using the Cache-Control max-age directive
+// or the Expires header, it defaults to now + ttl.
+func cacheExpiry(resp *http.Response, now time.Time, ttl time.Duration) time.Time {
+	for _, directive := range strings.Split(resp.Header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-cache" || directive == "no-store":
+			return now
+		case strings.HasPrefix(directive, "max-age="):
+			if secs, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil {
+				return now.Add(time.Duration(secs) * time.Second)
+			}
+		}
+	}
+	if exp := resp.Header.Get("Expires"); exp != "" {
+		if t, err := http.ParseTime(exp); err == nil {
+			return t
+		}
+		return now
+	}
+	return now.Add(ttl)
+}
+
+// publicKey returns the key described by the JWK.
+func (k *jwk) publicKey() (Key, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("invalid EC key")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer.
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
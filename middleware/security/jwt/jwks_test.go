@@ -0,0 +1,254 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	jwtpkg "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JWKSResolver", func() {
+	var (
+		server *httptest.Server
+		mu     sync.Mutex
+		set    []map[string]string
+		header http.Header
+		hits   int
+		hang   chan struct{}
+
+		rsaKey *rsa.PrivateKey
+		ecKey  *ecdsa.PrivateKey
+		edKey  ed25519.PublicKey
+
+		resolver *jwt.JWKSResolver
+		opts     []jwt.JWKSOption
+		newErr   error
+	)
+
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	rsaJWK := func(kid string, k *rsa.PrivateKey) map[string]string {
+		return map[string]string{"kty": "RSA", "kid": kid, "use": "sig", "n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes())}
+	}
+
+	hitCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits
+	}
+
+	setKeys := func(keys ...map[string]string) {
+		mu.Lock()
+		defer mu.Unlock()
+		set = keys
+	}
+
+	sign := func(kid string, key *rsa.PrivateKey) string {
+		token := jwtpkg.NewWithClaims(jwtpkg.SigningMethodRS256, jwtpkg.MapClaims{"sub": "user"})
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		Ω(err).ShouldNot(HaveOccurred())
+		return s
+	}
+
+	BeforeEach(func() {
+		var err error
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Ω(err).ShouldNot(HaveOccurred())
+		ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Ω(err).ShouldNot(HaveOccurred())
+		edKey, _, err = ed25519.GenerateKey(rand.Reader)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		hits = 0
+		hang = nil
+		header = http.Header{}
+		opts = nil
+		setKeys(
+			rsaJWK("rsa", rsaKey),
+			map[string]string{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.Bytes()), "y": b64(ecKey.Y.Bytes())},
+			map[string]string{"kty": "oct", "kid": "oct", "k": b64([]byte("secret"))},
+			map[string]string{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(edKey)},
+			map[string]string{"kty": "RSA", "kid": "enc", "use": "enc", "n": b64(rsaKey.N.Bytes()), "e": "AQAB"},
+		)
+		server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			release := hang
+			mu.Unlock()
+			if release != nil {
+				<-release
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			hits++
+			for k, v := range header {
+				rw.Header()[k] = v
+			}
+			if etag := header.Get("ETag"); etag != "" && req.Header.Get("If-None-Match") == etag {
+				rw.WriteHeader(http.StatusNotModified)
+				return
+			}
+			json.NewEncoder(rw).Encode(map[string]interface{}{"keys": set})
+		}))
+	})
+
+	JustBeforeEach(func() {
+		resolver, newErr = jwt.NewJWKSResolver(server.URL, opts...)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("parses the RSA, EC, oct and OKP keys", func() {
+		Ω(newErr).ShouldNot(HaveOccurred())
+		Ω(resolver.SelectKeys(nil)).Should(HaveLen(4))
+		Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "rsa"})).Should(Equal([]jwt.Key{&rsaKey.PublicKey}))
+		Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "ec"})).Should(Equal([]jwt.Key{&ecKey.PublicKey}))
+		Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "oct"})).Should(Equal([]jwt.Key{[]byte("secret")}))
+		Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "ed"})).Should(Equal([]jwt.Key{edKey}))
+	})
+
+	It("ignores keys that are not used for signatures", func() {
+		Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "enc"})).Should(BeEmpty())
+	})
+
+	It("returns all the keys for tokens without key ID", func() {
+		Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{})).Should(HaveLen(4))
+	})
+
+	Context("with an unavailable endpoint", func() {
+		BeforeEach(func() {
+			server.Close()
+		})
+
+		It("fails", func() {
+			Ω(newErr).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a hanging endpoint", func() {
+		BeforeEach(func() {
+			opts = []jwt.JWKSOption{
+				jwt.JWKSClient(&http.Client{Timeout: 100 * time.Millisecond}),
+				jwt.JWKSMinRefreshInterval(0),
+			}
+		})
+
+		JustBeforeEach(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			hang = make(chan struct{})
+		})
+
+		AfterEach(func() {
+			close(hang)
+		})
+
+		It("gives up fetching the key set after the client timeout", func() {
+			done := make(chan []jwt.Key)
+			go func() {
+				done <- resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "unknown"})
+			}()
+			Eventually(done, time.Second).Should(Receive(BeEmpty()))
+			Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "rsa"})).Should(Equal([]jwt.Key{&rsaKey.PublicKey}))
+		})
+	})
+
+	Context("with a rotated key", func() {
+		var rotated *rsa.PrivateKey
+
+		BeforeEach(func() {
+			var err error
+			rotated, err = rsa.GenerateKey(rand.Reader, 2048)
+			Ω(err).ShouldNot(HaveOccurred())
+			opts = []jwt.JWKSOption{jwt.JWKSMinRefreshInterval(0)}
+		})
+
+		JustBeforeEach(func() {
+			setKeys(rsaJWK("rotated", rotated))
+		})
+
+		It("fetches the key set again", func() {
+			Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "rotated"})).Should(Equal([]jwt.Key{&rotated.PublicKey}))
+			Ω(hitCount()).Should(Equal(2))
+			Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "rsa"})).Should(BeEmpty())
+		})
+
+		It("validates tokens signed with the new key", func() {
+			scheme := &goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"}
+			req, _ := http.NewRequest("GET", "http://example.com/", nil)
+			req.Header.Set("Authorization", "Bearer "+sign("rotated", rotated))
+			var token *jwtpkg.Token
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				token = jwt.ContextJWT(ctx)
+				return nil
+			}
+			err := jwt.New(resolver, nil, scheme)(h)(context.Background(), httptest.NewRecorder(), req)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(token).ShouldNot(BeNil())
+			Ω(token.Header["kid"]).Should(Equal("rotated"))
+		})
+	})
+
+	Context("with tokens referencing unknown keys", func() {
+		It("rate limits the fetches", func() {
+			Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "unknown"})).Should(BeEmpty())
+			Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "unknown"})).Should(BeEmpty())
+			Ω(hitCount()).Should(Equal(1))
+		})
+	})
+
+	Context("with a Cache-Control max-age directive", func() {
+		BeforeEach(func() {
+			header.Set("Cache-Control", "public, max-age=0")
+			opts = []jwt.JWKSOption{jwt.JWKSMinRefreshInterval(0)}
+		})
+
+		It("fetches the key set once it expires", func() {
+			time.Sleep(time.Millisecond)
+			resolver.SelectKeys(nil)
+			Ω(hitCount()).Should(Equal(2))
+		})
+	})
+
+	Context("with a default cache lifetime", func() {
+		BeforeEach(func() {
+			opts = []jwt.JWKSOption{jwt.JWKSMinRefreshInterval(0)}
+		})
+
+		It("uses the cached keys", func() {
+			resolver.SelectKeys(nil)
+			Ω(hitCount()).Should(Equal(1))
+		})
+	})
+
+	Context("with an ETag", func() {
+		BeforeEach(func() {
+			header.Set("ETag", `"v1"`)
+			header.Set("Cache-Control", "no-cache")
+			opts = []jwt.JWKSOption{jwt.JWKSMinRefreshInterval(0)}
+		})
+
+		It("keeps the keys when the key set is not modified", func() {
+			Ω(resolver.Refresh()).ShouldNot(HaveOccurred())
+			Ω(hitCount()).Should(Equal(2))
+			Ω(resolver.SelectTokenKeys(nil, map[string]interface{}{"kid": "rsa"})).Should(Equal([]jwt.Key{&rsaKey.PublicKey}))
+		})
+	})
+})
@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"fmt"
 	"net/http"
@@ -25,7 +26,14 @@ import (
 //        against the scopes presented by the JWT in the claim "scope", or if
 //        that's not defined, "scopes".
 //
-// The `exp` (expiration) and `nbf` (not before) date checks are validated by the JWT library.
+// The `exp` (expiration), `nbf` (not before) and `iat` (issued at) date checks are always
+// validated. Additional claims validations are configured with options:
+//
+//     * Issuer and Audience validate the `iss` and `aud` claims
+//     * Leeway sets the clock skew tolerated by the date checks
+//     * Algorithms restricts the accepted signing algorithms
+//     * RequiredClaims lists claims that must be present
+//     * ScopeClaim and ScopeDelimiter configure how scopes are read from the claims
 //
 // validationKeys can be one of these:
 //
@@ -33,11 +41,15 @@ import (
 //     * string
 //     * an *rsa.PublicKey
 //     * an *ecdsa.PublicKey
+//     * an ed25519.PublicKey
 //     * a slice of any of the above
 //
 // Keys of type string or []byte are interpreted according to the signing method defined in the JWT
 // token's `typ` header element: `HS`, `RS`, `ES`, etc.
 //
+// The validated claims are available to the handlers with ContextClaims and are the principal
+// given to the authorization policy.
+//
 // You can define an optional function to do additional validations on the token once the signature
 // and the claims requirements are proven to be valid.  Example:
 //
@@ -59,7 +71,17 @@ import (
 //    jwtResolver, _ := jwt.NewSimpleResolver("secret")
 //    app.UseJWT(jwt.New(jwtResolver, validationHandler, app.NewJWTSecurity()))
 //
-func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSecurity) goa.Middleware {
+// or with claims validation:
+//
+//    app.UseJWT(jwt.New(jwtResolver, nil, app.NewJWTSecurity(),
+//        jwt.Issuer("https://idp.example.com"),
+//        jwt.Audience("my-api"),
+//        jwt.Algorithms("RS256"),
+//        jwt.Leeway(30*time.Second)))
+//
+func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSecurity, opts ...Option) goa.Middleware {
+	o := newOptions(opts...)
+	parser := &jwt.Parser{ValidMethods: o.algorithms, SkipClaimsValidation: true}
 	return func(nextHandler goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			var (
@@ -81,7 +103,7 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 				return fmt.Errorf("whoops, security scheme with location (in) %q not supported", scheme.In)
 			}
 
-			rsaKeys, ecdsaKeys, hmacKeys := partitionKeys(resolver.SelectKeys(req))
+			rsaKeys, ecdsaKeys, eddsaKeys, hmacKeys := partitionKeys(selectKeys(resolver, req, incomingToken))
 
 			var (
 				token     *jwt.Token
@@ -89,21 +111,28 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 			)
 
 			if len(rsaKeys) > 0 {
-				token, err = validateRSAKeys(rsaKeys, "RS", incomingToken)
+				token, err = validateRSAKeys(parser, rsaKeys, "RS", incomingToken)
 				if err == nil {
 					validated = true
 				}
 			}
 
 			if !validated && len(ecdsaKeys) > 0 {
-				token, err = validateECDSAKeys(ecdsaKeys, "ES", incomingToken)
+				token, err = validateECDSAKeys(parser, ecdsaKeys, "ES", incomingToken)
+				if err == nil {
+					validated = true
+				}
+			}
+
+			if !validated && len(eddsaKeys) > 0 {
+				token, err = validateEdDSAKeys(parser, eddsaKeys, "EdDSA", incomingToken)
 				if err == nil {
 					validated = true
 				}
 			}
 
 			if !validated && len(hmacKeys) > 0 {
-				token, err = validateHMACKeys(hmacKeys, "HS", incomingToken)
+				token, err = validateHMACKeys(parser, hmacKeys, "HS", incomingToken)
 				if err == nil {
 					validated = true
 				}
@@ -113,7 +142,15 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 				return ErrJWTError("JWT validation failed")
 			}
 
-			scopesInClaim, scopesInClaimList, err := parseClaimScopes(token)
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok {
+				return ErrJWTError("unsupported claims shape")
+			}
+			if err := validateClaims(claims, o); err != nil {
+				return ErrJWTError(err)
+			}
+
+			scopesInClaim, scopesInClaimList, err := parseClaimScopes(claims, o)
 			if err != nil {
 				goa.LogError(ctx, err.Error())
 				return ErrJWTError(err)
@@ -123,12 +160,14 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 
 			for _, scope := range requiredScopes {
 				if !scopesInClaim[scope] {
-					msg := "authorization failed: required 'scope' or 'scopes' not present in JWT claim"
+					msg := "authorization failed: required " + scopeClaimNames(o.scopeClaims) + " not present in JWT claim"
 					return ErrJWTError(msg, "required", requiredScopes, "scopes", scopesInClaimList)
 				}
 			}
 
 			ctx = WithJWT(ctx, token)
+			c := newClaims(claims, scopesInClaimList)
+			ctx = goa.WithPrincipal(WithClaims(ctx, c), c)
 			if validationFunc != nil {
 				nextHandler = validationFunc(nextHandler)
 			}
@@ -161,11 +200,24 @@ func extractTokenFromQueryParam(schemeName string, req *http.Request) (string, e
 	return incomingToken, nil
 }
 
+// selectKeys returns the keys used to validate the token. Resolvers that implement
+// TokenKeyResolver are given the token header.
+func selectKeys(resolver KeyResolver, req *http.Request, incomingToken string) []Key {
+	if tr, ok := resolver.(TokenKeyResolver); ok {
+		var p jwt.Parser
+		if token, _, err := p.ParseUnverified(incomingToken, jwt.MapClaims{}); err == nil {
+			return tr.SelectTokenKeys(req, token.Header)
+		}
+	}
+	return resolver.SelectKeys(req)
+}
+
 // partitionKeys sorts keys by their type.
-func partitionKeys(keys []Key) ([]*rsa.PublicKey, []*ecdsa.PublicKey, [][]byte) {
+func partitionKeys(keys []Key) ([]*rsa.PublicKey, []*ecdsa.PublicKey, []ed25519.PublicKey, [][]byte) {
 	var (
 		rsaKeys   []*rsa.PublicKey
 		ecdsaKeys []*ecdsa.PublicKey
+		eddsaKeys []ed25519.PublicKey
 		hmacKeys  [][]byte
 	)
 
@@ -175,6 +227,8 @@ func partitionKeys(keys []Key) ([]*rsa.PublicKey, []*ecdsa.PublicKey, [][]byte)
 			rsaKeys = append(rsaKeys, k)
 		case *ecdsa.PublicKey:
 			ecdsaKeys = append(ecdsaKeys, k)
+		case ed25519.PublicKey:
+			eddsaKeys = append(eddsaKeys, k)
 		case []byte:
 			hmacKeys = append(hmacKeys, k)
 		case string:
@@ -182,32 +236,29 @@ func partitionKeys(keys []Key) ([]*rsa.PublicKey, []*ecdsa.PublicKey, [][]byte)
 		}
 	}
 
-	return rsaKeys, ecdsaKeys, hmacKeys
+	return rsaKeys, ecdsaKeys, eddsaKeys, hmacKeys
 }
 
 // validScopeClaimKeys are the claims under which scopes may be found in a token
 var validScopeClaimKeys = []string{"scope", "scopes"}
 
-// parseClaimScopes parses the "scope" or "scopes" parameter in the Claims. It
-// supports two formats:
+// parseClaimScopes parses the "scope" or "scopes" parameter in the Claims, or
+// the claims configured with ScopeClaim. It supports two formats:
 //
 // * a list of strings
 //
-// * a single string with space-separated scopes (akin to OAuth2's "scope").
+// * a single string with space-separated scopes (akin to OAuth2's "scope"),
+// the separator can be changed with ScopeDelimiter.
 //
 // An empty string is an explicit claim of no scopes.
-func parseClaimScopes(token *jwt.Token) (map[string]bool, []string, error) {
+func parseClaimScopes(claims jwt.MapClaims, o *options) (map[string]bool, []string, error) {
 	scopesInClaim := make(map[string]bool)
 	var scopesInClaimList []string
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, nil, fmt.Errorf("unsupported claims shape")
-	}
-	for _, k := range validScopeClaimKeys {
+	for _, k := range o.scopeClaims {
 		if rawscopes, ok := claims[k]; ok && rawscopes != nil {
 			switch scopes := rawscopes.(type) {
 			case string:
-				for _, scope := range strings.Split(scopes, " ") {
+				for _, scope := range strings.Split(scopes, o.scopeDelimiter) {
 					scopesInClaim[scope] = true
 					scopesInClaimList = append(scopesInClaimList, scope)
 				}
@@ -228,14 +279,9 @@ func parseClaimScopes(token *jwt.Token) (map[string]bool, []string, error) {
 	return scopesInClaim, scopesInClaimList, nil
 }
 
-func validateRSAKeys(rsaKeys []*rsa.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
+func validateRSAKeys(p *jwt.Parser, rsaKeys []*rsa.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, pubkey := range rsaKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
-			if !strings.HasPrefix(token.Method.Alg(), algo) {
-				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
-			}
-			return pubkey, nil
-		})
+		token, err = parseToken(p, pubkey, algo, incomingToken)
 		if err == nil {
 			return
 		}
@@ -243,14 +289,9 @@ func validateRSAKeys(rsaKeys []*rsa.PublicKey, algo, incomingToken string) (toke
 	return
 }
 
-func validateECDSAKeys(ecdsaKeys []*ecdsa.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
+func validateECDSAKeys(p *jwt.Parser, ecdsaKeys []*ecdsa.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, pubkey := range ecdsaKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
-			if !strings.HasPrefix(token.Method.Alg(), algo) {
-				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
-			}
-			return pubkey, nil
-		})
+		token, err = parseToken(p, pubkey, algo, incomingToken)
 		if err == nil {
 			return
 		}
@@ -258,17 +299,33 @@ func validateECDSAKeys(ecdsaKeys []*ecdsa.PublicKey, algo, incomingToken string)
 	return
 }
 
-func validateHMACKeys(hmacKeys [][]byte, algo, incomingToken string) (token *jwt.Token, err error) {
+func validateEdDSAKeys(p *jwt.Parser, eddsaKeys []ed25519.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
+	for _, pubkey := range eddsaKeys {
+		token, err = parseToken(p, pubkey, algo, incomingToken)
+		if err == nil {
+			return
+		}
+	}
+	return
+}
+
+func validateHMACKeys(p *jwt.Parser, hmacKeys [][]byte, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, key := range hmacKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
-			if !strings.HasPrefix(token.Method.Alg(), algo) {
-				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
-			}
-			return key, nil
-		})
+		token, err = parseToken(p, key, algo, incomingToken)
 		if err == nil {
 			return
 		}
 	}
 	return
 }
+
+// parseToken parses the token and verifies its signature with key. The token signing method must
+// start with algo so that a key is never used with an algorithm of a different family.
+func parseToken(p *jwt.Parser, key interface{}, algo, incomingToken string) (*jwt.Token, error) {
+	return p.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
+		if !strings.HasPrefix(token.Method.Alg(), algo) {
+			return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
+		}
+		return key, nil
+	})
+}
@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"net/http"
 	"sync"
@@ -9,7 +10,8 @@ import (
 
 type (
 	// Key represents a public key used to validate the incoming token signatures.
-	// The value must be of type *rsa.PublicKey, *ecdsa.PublicKey, []byte or string.
+	// The value must be of type *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, []byte or
+	// string.
 	// Keys of type []byte or string are interpreted depending on the incoming request JWT token
 	// method (HMAC, RSA, etc.).
 	Key interface{}
@@ -46,7 +48,7 @@ func NewResolver(keys map[string][]Key, header string) (*GroupResolver, error) {
 	for name := range keys {
 		for _, keys := range keys[name] {
 			switch keys := keys.(type) {
-			case *rsa.PublicKey, *ecdsa.PublicKey, string, []byte:
+			case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, string, []byte:
 				keyMap[name] = append(keyMap[name], keys)
 			case []*rsa.PublicKey:
 				for _, key := range keys {
@@ -56,6 +58,10 @@ func NewResolver(keys map[string][]Key, header string) (*GroupResolver, error) {
 				for _, key := range keys {
 					keyMap[name] = append(keyMap[name], key)
 				}
+			case []ed25519.PublicKey:
+				for _, key := range keys {
+					keyMap[name] = append(keyMap[name], key)
+				}
 			case [][]byte:
 				for _, key := range keys {
 					keyMap[name] = append(keyMap[name], key)
@@ -83,13 +89,14 @@ func NewSimpleResolver(keys []Key) KeyResolver {
 
 // AddKeys can be used to add keys to the resolver which will be referenced
 // by the provided name. Acceptable types for keys include string, []string,
-// *rsa.PublicKey or []*rsa.PublicKey. Multiple keys are allowed for a single
+// *rsa.PublicKey, []*rsa.PublicKey, *ecdsa.PublicKey, []*ecdsa.PublicKey,
+// ed25519.PublicKey or []ed25519.PublicKey. Multiple keys are allowed for a single
 // key name to allow for key rotation.
 func (kr *GroupResolver) AddKeys(name string, keys Key) error {
 	kr.Lock()
 	defer kr.Unlock()
 	switch keys := keys.(type) {
-	case *rsa.PublicKey, *ecdsa.PublicKey, []byte, string:
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, []byte, string:
 		kr.keyMap[name] = append(kr.keyMap[name], keys)
 	case []*rsa.PublicKey:
 		for _, key := range keys {
@@ -99,6 +106,10 @@ func (kr *GroupResolver) AddKeys(name string, keys Key) error {
 		for _, key := range keys {
 			kr.keyMap[name] = append(kr.keyMap[name], key)
 		}
+	case []ed25519.PublicKey:
+		for _, key := range keys {
+			kr.keyMap[name] = append(kr.keyMap[name], key)
+		}
 	case [][]byte:
 		for _, key := range keys {
 			kr.keyMap[name] = append(kr.keyMap[name], key)
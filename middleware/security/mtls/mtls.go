@@ -0,0 +1,82 @@
+package mtls
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/goadesign/goa"
+)
+
+var (
+	// ErrMutualTLSFailed is the error returned when the request was not made over a TLS
+	// connection authenticated with a verified client certificate.
+	ErrMutualTLSFailed = goa.NewErrorClass("mtls_failed", 401)
+
+	// ErrPeerNotAllowed is the error returned when the client certificate identity is not
+	// allowed to make the request.
+	ErrPeerNotAllowed = goa.NewErrorClass("mtls_peer_not_allowed", 403)
+)
+
+// Validator validates the identity of a verified client certificate. Returning an error denies
+// the request, errors that are not goa errors are wrapped with ErrPeerNotAllowed.
+type Validator func(ctx context.Context, id *goa.PeerIdentity) error
+
+// New returns a middleware to be used with the MutualTLSSecurity DSL definitions of goa. The
+// middleware requires requests to be made over a TLS connection with a client certificate
+// verified against the client certificate authorities of the service, see goa.TLSConfig.
+//
+// If names is not empty the certificate common name or one of its DNS or URI subject alternative
+// names (e.g. a SPIFFE ID) must be equal to one of the given names.
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//	app.UseClientCertMiddleware(service, mtls.New("spiffe://example.org/frontend"))
+func New(names ...string) goa.Middleware {
+	if len(names) == 0 {
+		return NewWithValidator(nil)
+	}
+	allowed := make(map[string]bool, len(names))
+	for _, n := range names {
+		allowed[n] = true
+	}
+	return NewWithValidator(func(ctx context.Context, id *goa.PeerIdentity) error {
+		if allowed[id.CommonName] {
+			return nil
+		}
+		for _, n := range id.DNSNames {
+			if allowed[n] {
+				return nil
+			}
+		}
+		for _, u := range id.URIs {
+			if allowed[u.String()] {
+				return nil
+			}
+		}
+		return ErrPeerNotAllowed("client certificate identity is not allowed", "cn", id.CommonName)
+	})
+}
+
+// NewWithValidator returns a middleware that requires a verified client certificate and calls
+// validator with its identity. A nil validator accepts any verified certificate. The identity is
+// the principal given to the authorization policy.
+func NewWithValidator(validator Validator) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			id := goa.ContextPeerIdentity(ctx)
+			if id == nil {
+				return ErrMutualTLSFailed("missing or unverified client certificate")
+			}
+			if validator != nil {
+				if err := validator(ctx, id); err != nil {
+					if _, ok := err.(goa.ServiceError); !ok {
+						err = ErrPeerNotAllowed(err)
+					}
+					return err
+				}
+			}
+			return h(goa.WithPrincipal(ctx, id), rw, req)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package mtls_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/mtls"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var (
+		req     *http.Request
+		called  bool
+		handler goa.Handler
+	)
+
+	serve := func(m goa.Middleware) error {
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		return m(handler)(ctx, rw, req)
+	}
+
+	BeforeEach(func() {
+		called = false
+		handler = func(context.Context, http.ResponseWriter, *http.Request) error {
+			called = true
+			return nil
+		}
+		req, _ = http.NewRequest("GET", "https://goa.design/", nil)
+		spiffe, _ := url.Parse("spiffe://goa.design/frontend")
+		leaf := &x509.Certificate{
+			Subject:  pkix.Name{CommonName: "frontend"},
+			DNSNames: []string{"frontend.goa.design"},
+			URIs:     []*url.URL{spiffe},
+		}
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{leaf}}}
+	})
+
+	It("rejects requests without verified client certificate", func() {
+		req.TLS = nil
+		err := serve(mtls.New())
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+		Ω(called).Should(BeFalse())
+	})
+
+	It("accepts any verified client certificate", func() {
+		Ω(serve(mtls.New())).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("accepts allowed common names", func() {
+		Ω(serve(mtls.New("frontend"))).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("accepts allowed DNS names", func() {
+		Ω(serve(mtls.New("frontend.goa.design"))).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("accepts allowed URIs", func() {
+		Ω(serve(mtls.New("spiffe://goa.design/frontend"))).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("rejects identities that are not allowed", func() {
+		err := serve(mtls.New("backend"))
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(403))
+		Ω(called).Should(BeFalse())
+	})
+
+	It("calls the validator with the peer identity", func() {
+		var cn string
+		err := serve(mtls.NewWithValidator(func(ctx context.Context, id *goa.PeerIdentity) error {
+			cn = id.CommonName
+			return errors.New("denied")
+		}))
+		Ω(cn).Should(Equal("frontend"))
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(403))
+		Ω(called).Should(BeFalse())
+	})
+})
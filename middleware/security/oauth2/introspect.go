@@ -0,0 +1,230 @@
+package oauth2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is the maximum duration an introspection response is cached.
+	DefaultCacheTTL = time.Minute
+
+	// DefaultCacheSize is the maximum number of introspection responses kept in the cache.
+	DefaultCacheSize = 10000
+)
+
+type (
+	// Introspector validates opaque access tokens with the token introspection endpoint of an
+	// authorization server as described in RFC 7662. The responses are cached so that each
+	// token is introspected at most once per cache TTL, active tokens are never cached past
+	// their expiry.
+	Introspector struct {
+		endpoint     string
+		clientID     string
+		clientSecret string
+		client       *http.Client
+		ttl          time.Duration
+		size         int
+
+		mu    sync.Mutex
+		cache map[[sha256.Size]byte]*cacheEntry
+	}
+
+	// Option configures an Introspector.
+	Option func(*Introspector)
+
+	// Introspection is the response of the introspection endpoint.
+	Introspection struct {
+		// Active indicates whether the token is currently active.
+		Active bool `json:"active"`
+		// Scope is the space-separated list of scopes associated with the token.
+		Scope string `json:"scope,omitempty"`
+		// ClientID is the identifier of the client that requested the token.
+		ClientID string `json:"client_id,omitempty"`
+		// Username is the resource owner who authorized the token.
+		Username string `json:"username,omitempty"`
+		// TokenType is the type of the token, e.g. "Bearer".
+		TokenType string `json:"token_type,omitempty"`
+		// Exp is the expiry of the token in seconds since the epoch.
+		Exp int64 `json:"exp,omitempty"`
+		// Iat is the time the token was issued in seconds since the epoch.
+		Iat int64 `json:"iat,omitempty"`
+		// Nbf is the time before which the token must not be used in seconds since the epoch.
+		Nbf int64 `json:"nbf,omitempty"`
+		// Sub is the subject of the token.
+		Sub string `json:"sub,omitempty"`
+		// Aud is the intended audience of the token.
+		Aud Audience `json:"aud,omitempty"`
+		// Iss is the issuer of the token.
+		Iss string `json:"iss,omitempty"`
+		// Jti is the identifier of the token.
+		Jti string `json:"jti,omitempty"`
+	}
+
+	// Audience is the audience of a token. It decodes from either a string or a list of
+	// strings.
+	Audience []string
+
+	// cacheEntry is a cached introspection response.
+	cacheEntry struct {
+		resp    *Introspection
+		expires time.Time
+	}
+)
+
+// ClientCredentials sets the credentials used by the resource server to authenticate with the
+// introspection endpoint using HTTP basic authentication.
+func ClientCredentials(id, secret string) Option {
+	return func(i *Introspector) {
+		i.clientID = id
+		i.clientSecret = secret
+	}
+}
+
+// HTTPClient sets the HTTP client used to make the introspection requests, http.DefaultClient by
+// default.
+func HTTPClient(c *http.Client) Option {
+	return func(i *Introspector) {
+		i.client = c
+	}
+}
+
+// CacheTTL sets the maximum duration an introspection response is cached, DefaultCacheTTL by
+// default. A zero or negative value disables caching.
+func CacheTTL(d time.Duration) Option {
+	return func(i *Introspector) {
+		i.ttl = d
+	}
+}
+
+// CacheSize sets the maximum number of cached introspection responses, DefaultCacheSize by
+// default.
+func CacheSize(n int) Option {
+	return func(i *Introspector) {
+		i.size = n
+	}
+}
+
+// NewIntrospector returns an introspector that uses the token introspection endpoint at the
+// given URL.
+func NewIntrospector(endpoint string, opts ...Option) *Introspector {
+	i := &Introspector{
+		endpoint: endpoint,
+		client:   http.DefaultClient,
+		ttl:      DefaultCacheTTL,
+		size:     DefaultCacheSize,
+		cache:    make(map[[sha256.Size]byte]*cacheEntry),
+	}
+	for _, o := range opts {
+		o(i)
+	}
+	return i
+}
+
+// Introspect returns the introspection response for the given token, from the cache if
+// available. An error is returned only if the introspection endpoint could not be used, inactive
+// tokens are reported via the Active field of the response.
+func (i *Introspector) Introspect(ctx context.Context, token string) (*Introspection, error) {
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+	i.mu.Lock()
+	if e, ok := i.cache[key]; ok {
+		if now.Before(e.expires) {
+			i.mu.Unlock()
+			return e.resp, nil
+		}
+		delete(i.cache, key)
+	}
+	i.mu.Unlock()
+
+	resp, err := i.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	i.store(key, resp, now)
+	return resp, nil
+}
+
+// introspect makes the introspection request.
+func (i *Introspector) introspect(ctx context.Context, token string) (*Introspection, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequest("POST", i.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.clientID), url.QueryEscape(i.clientSecret))
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token introspection failed: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token introspection failed: unexpected status %d", resp.StatusCode)
+	}
+	var res Introspection
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("failed to decode token introspection response: %s", err)
+	}
+	return &res, nil
+}
+
+// store caches the response until the cache TTL or the token expiry, whichever comes first.
+func (i *Introspector) store(key [sha256.Size]byte, resp *Introspection, now time.Time) {
+	if i.ttl <= 0 || i.size <= 0 {
+		return
+	}
+	expires := now.Add(i.ttl)
+	if resp.Active && resp.Exp > 0 {
+		if exp := time.Unix(resp.Exp, 0); exp.Before(expires) {
+			expires = exp
+		}
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.cache) >= i.size {
+		for k, e := range i.cache {
+			if !now.Before(e.expires) {
+				delete(i.cache, k)
+			}
+		}
+		for k := range i.cache {
+			if len(i.cache) < i.size {
+				break
+			}
+			delete(i.cache, k)
+		}
+	}
+	i.cache[key] = &cacheEntry{resp: resp, expires: expires}
+}
+
+// Scopes returns the scopes associated with the token.
+func (r *Introspection) Scopes() []string {
+	return strings.Fields(r.Scope)
+}
+
+// UnmarshalJSON decodes a string or a list of strings.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var l []string
+	if err := json.Unmarshal(data, &l); err != nil {
+		return err
+	}
+	*a = Audience(l)
+	return nil
+}
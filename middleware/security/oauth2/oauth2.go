@@ -0,0 +1,127 @@
+package oauth2
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+var (
+	// ErrInvalidToken is the error returned when the request access token is missing, malformed,
+	// expired or not active.
+	ErrInvalidToken = goa.NewErrorClass("invalid_token", 401)
+
+	// ErrInsufficientScope is the error returned when the access token is missing scopes
+	// required by the action.
+	ErrInsufficientScope = goa.NewErrorClass("insufficient_scope", 403)
+
+	// ErrIntrospectionFailed is the error returned when the introspection endpoint cannot be
+	// used to validate the access token.
+	ErrIntrospectionFailed = goa.NewErrorClass("introspection_failed", 503)
+)
+
+// private type used to store the introspection response in the request context.
+type introspectionKey struct{}
+
+// New returns a middleware to be used with the OAuth2Security DSL definitions of goa. The
+// middleware reads the bearer access token from the Authorization header, validates it with
+// the introspector and checks that the token was granted the scopes required by the action as
+// defined with the Security DSL.
+//
+// The introspection response is available to the handlers via ContextIntrospection and is the
+// principal given to the authorization policy.
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//	introspector := oauth2.NewIntrospector("https://idp.example.com/oauth2/introspect",
+//		oauth2.ClientCredentials("resource-server", "secret"))
+//	app.UseOAuth2Middleware(service, oauth2.New(introspector))
+func New(introspector *Introspector) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			token, err := bearerToken(req)
+			if err != nil {
+				code := "invalid_request"
+				if req.Header.Get("Authorization") == "" {
+					code = ""
+				}
+				challenge(rw, code, "")
+				return err
+			}
+			res, err := introspector.Introspect(ctx, token)
+			if err != nil {
+				goa.LogError(ctx, "token introspection", "err", err)
+				return ErrIntrospectionFailed("failed to validate access token")
+			}
+			now := time.Now()
+			if !res.Active || res.Exp > 0 && !now.Before(time.Unix(res.Exp, 0)) ||
+				res.Nbf > 0 && now.Before(time.Unix(res.Nbf, 0)) {
+				challenge(rw, "invalid_token", "")
+				return ErrInvalidToken("access token is not active")
+			}
+			required := goa.ContextRequiredScopes(ctx)
+			if len(required) > 0 {
+				granted := res.Scopes()
+				sort.Strings(granted)
+				for _, scope := range required {
+					i := sort.SearchStrings(granted, scope)
+					if i == len(granted) || granted[i] != scope {
+						challenge(rw, "insufficient_scope", strings.Join(required, " "))
+						return ErrInsufficientScope("access token is missing required scopes",
+							"required", required, "scopes", granted)
+					}
+				}
+			}
+			return h(goa.WithPrincipal(WithIntrospection(ctx, res), res), rw, req)
+		}
+	}
+}
+
+// WithIntrospection creates a child context containing the given introspection response.
+func WithIntrospection(ctx context.Context, r *Introspection) context.Context {
+	return context.WithValue(ctx, introspectionKey{}, r)
+}
+
+// ContextIntrospection retrieves the introspection response of the access token validated by
+// the middleware.
+func ContextIntrospection(ctx context.Context) *Introspection {
+	r, ok := ctx.Value(introspectionKey{}).(*Introspection)
+	if !ok {
+		return nil
+	}
+	return r
+}
+
+// bearerToken extracts the access token from the Authorization header.
+func bearerToken(req *http.Request) (string, error) {
+	val := req.Header.Get("Authorization")
+	if val == "" {
+		return "", ErrInvalidToken("missing access token")
+	}
+	if len(val) < 7 || !strings.EqualFold(val[:7], "bearer ") {
+		return "", ErrInvalidToken("invalid or malformed Authorization header, expected 'Bearer token'")
+	}
+	token := strings.TrimSpace(val[7:])
+	if token == "" {
+		return "", ErrInvalidToken("missing access token")
+	}
+	return token, nil
+}
+
+// challenge sets the WWW-Authenticate response header as described in RFC 6750. The error code
+// is omitted when the request did not include any credentials.
+func challenge(rw http.ResponseWriter, code, scope string) {
+	val := "Bearer"
+	if code != "" {
+		val += ` error="` + code + `"`
+	}
+	if scope != "" {
+		val += `, scope="` + scope + `"`
+	}
+	rw.Header().Set("WWW-Authenticate", val)
+}
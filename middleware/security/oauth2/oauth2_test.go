@@ -0,0 +1,184 @@
+package oauth2_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/oauth2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var (
+		server   *httptest.Server
+		mu       sync.Mutex
+		hits     int
+		status   int
+		response map[string]interface{}
+		form     map[string]string
+		opts     []oauth2.Option
+
+		ctx   context.Context
+		req   *http.Request
+		rw    *httptest.ResponseRecorder
+		res   *oauth2.Introspection
+		mw    goa.Middleware
+		err   error
+		serve func() error
+	)
+
+	hitCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits
+	}
+
+	BeforeEach(func() {
+		hits = 0
+		status = http.StatusOK
+		response = map[string]interface{}{
+			"active":    true,
+			"scope":     "api:read api:write",
+			"client_id": "client",
+			"sub":       "user",
+			"aud":       "api",
+			"exp":       time.Now().Add(time.Hour).Unix(),
+		}
+		form = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			hits++
+			r.ParseForm()
+			id, secret, _ := r.BasicAuth()
+			form = map[string]string{"token": r.PostForm.Get("token"), "id": id, "secret": secret}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(response)
+		}))
+		opts = []oauth2.Option{oauth2.ClientCredentials("resource", "secret")}
+		ctx = context.Background()
+		req, _ = http.NewRequest("GET", "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		res = nil
+		serve = func() error {
+			rw = httptest.NewRecorder()
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				res = oauth2.ContextIntrospection(ctx)
+				return nil
+			}
+			return mw(h)(ctx, rw, req)
+		}
+	})
+
+	JustBeforeEach(func() {
+		mw = oauth2.New(oauth2.NewIntrospector(server.URL, opts...))
+		err = serve()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("introspects the access token", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(form).Should(Equal(map[string]string{"token": "token", "id": "resource", "secret": "secret"}))
+		Ω(res).ShouldNot(BeNil())
+		Ω(res.Active).Should(BeTrue())
+		Ω(res.Sub).Should(Equal("user"))
+		Ω(res.Aud).Should(Equal(oauth2.Audience{"api"}))
+		Ω(res.Scopes()).Should(Equal([]string{"api:read", "api:write"}))
+	})
+
+	It("caches the introspection response", func() {
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(hitCount()).Should(Equal(1))
+	})
+
+	Context("with caching disabled", func() {
+		BeforeEach(func() {
+			opts = append(opts, oauth2.CacheTTL(0))
+		})
+
+		It("introspects each request", func() {
+			Ω(serve()).ShouldNot(HaveOccurred())
+			Ω(hitCount()).Should(Equal(2))
+		})
+	})
+
+	Context("with a token expiring before the cache TTL", func() {
+		BeforeEach(func() {
+			response["exp"] = time.Now().Add(time.Second).Unix()
+			opts = append(opts, oauth2.CacheTTL(time.Hour))
+		})
+
+		It("does not use the cached response past the token expiry", func() {
+			time.Sleep(time.Until(time.Unix(response["exp"].(int64), 0)))
+			Ω(serve()).Should(HaveOccurred())
+			Ω(hitCount()).Should(Equal(2))
+		})
+	})
+
+	Context("with an inactive token", func() {
+		BeforeEach(func() {
+			response = map[string]interface{}{"active": false}
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusUnauthorized))
+			Ω(rw.Header().Get("WWW-Authenticate")).Should(Equal(`Bearer error="invalid_token"`))
+		})
+	})
+
+	Context("with a missing token", func() {
+		BeforeEach(func() {
+			req.Header.Del("Authorization")
+		})
+
+		It("rejects the request without introspecting", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusUnauthorized))
+			Ω(rw.Header().Get("WWW-Authenticate")).Should(Equal("Bearer"))
+			Ω(hitCount()).Should(Equal(0))
+		})
+	})
+
+	Context("with a failing introspection endpoint", func() {
+		BeforeEach(func() {
+			status = http.StatusInternalServerError
+		})
+
+		It("returns a service unavailable error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusServiceUnavailable))
+		})
+	})
+
+	Context("with required scopes", func() {
+		BeforeEach(func() {
+			ctx = goa.WithRequiredScopes(ctx, []string{"api:write"})
+		})
+
+		It("accepts tokens granted the scopes", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		Context("missing from the token", func() {
+			BeforeEach(func() {
+				ctx = goa.WithRequiredScopes(ctx, []string{"api:admin"})
+			})
+
+			It("rejects the request", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusForbidden))
+				Ω(rw.Header().Get("WWW-Authenticate")).Should(Equal(`Bearer error="insufficient_scope", scope="api:admin"`))
+			})
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/goadesign/goa/client"
+)
+
+// tracedDoer is a client Doer that creates client spans and propagates the trace context.
+type tracedDoer struct {
+	client.Doer
+	tracer *Tracer
+}
+
+// WrapDoer wraps the given Doer so that each request creates a client span child of the span
+// stored in the context if any. The span context is injected in the request headers using the
+// tracer propagator.
+func (t *Tracer) WrapDoer(d client.Doer) client.Doer {
+	return &tracedDoer{Doer: d, tracer: t}
+}
+
+// Decorator returns a client decorator that wraps the client Doer with WrapDoer.
+func (t *Tracer) Decorator() client.Decorator {
+	return t.WrapDoer
+}
+
+// Do creates the client span, injects its context in the request headers and runs the request.
+func (d *tracedDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	ctx, span := d.tracer.Start(ctx, req.Method+" "+req.URL.Host, SpanKindClient, SpanContext{})
+	defer span.End()
+
+	span.SetAttribute("http.request.method", req.Method)
+	span.SetAttribute("url.full", req.URL.String())
+	span.SetAttribute("server.address", req.URL.Hostname())
+	d.tracer.propagator.Inject(span.SpanContext(), req.Header)
+
+	resp, err := d.Doer.Do(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(StatusError, err.Error())
+		return nil, err
+	}
+	span.SetAttribute("http.response.status_code", resp.StatusCode)
+	if resp.StatusCode >= 400 {
+		span.SetStatus(StatusError, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
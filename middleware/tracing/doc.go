@@ -0,0 +1,22 @@
+/*
+Package tracing provides vendor neutral distributed tracing for goa services and clients.
+
+A Tracer creates spans and hands them to an Exporter once they end. The server middleware
+extracts the trace context from the incoming requests using the W3C traceparent and tracestate
+headers or the B3 headers, creates a server span named after the controller and action and
+records the HTTP attributes, the response status and the errors. The client Doer wrapper creates
+client spans and injects the trace context in the outgoing requests:
+
+	tracer := tracing.NewTracer(tracing.WithExporter(exporter))
+	service.Use(tracer.Middleware())
+	c := client.New(nil, tracer.Decorator())
+
+The span attributes follow the OpenTelemetry HTTP semantic conventions. The exporter of the
+github.com/goadesign/goa/middleware/tracing/goaotel package records the spans with an
+OpenTelemetry SDK tracer provider which exports them to OpenTelemetry collectors and backends. The
+InMemoryExporter keeps the spans in memory which makes it possible to assert them in tests.
+
+The middleware also stores the trace and span IDs in the request context using
+middleware.WithTrace so that the existing middlewares and the X-Ray client wrapper pick them up.
+*/
+package tracing
@@ -0,0 +1,37 @@
+package tracing
+
+import "sync"
+
+// InMemoryExporter is an exporter that keeps the spans in memory. It is intended for tests.
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*SpanData
+}
+
+// NewInMemoryExporter creates an exporter that keeps the spans in memory.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan records the span.
+func (e *InMemoryExporter) ExportSpan(s *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, s)
+}
+
+// Spans returns the spans exported so far in the order they ended.
+func (e *InMemoryExporter) Spans() []*SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	spans := make([]*SpanData, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// Reset discards the spans exported so far.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
@@ -0,0 +1,204 @@
+//go:build go1.16
+// +build go1.16
+
+/*
+Package goaotel bridges the goa tracing package with OpenTelemetry. The package requires Go 1.16
+or later and a version of the OpenTelemetry Go SDK that supports the Go toolchain in use.
+
+The Exporter records the spans created by the goa tracer with an OpenTelemetry SDK tracer
+provider. The spans keep their trace and span IDs and the provider span processors send them to
+OpenTelemetry collectors and backends:
+
+	exp, err := otlptracehttp.New(ctx)
+	// ...
+	res := resource.NewSchemaless(attribute.String("service.name", "cellar"))
+	exporter := goaotel.NewExporter(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	defer exporter.Shutdown(ctx)
+	tracer := tracing.NewTracer(tracing.WithExporter(exporter))
+	service.Use(tracer.Middleware())
+	service.Use(goaotel.Middleware())
+
+The Middleware stores the span context of the goa span in the request context using the
+OpenTelemetry API so that the spans created by code instrumented with OpenTelemetry are children
+of the goa server span. It must be mounted after the tracing middleware.
+*/
+package goaotel
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/goadesign/goa/middleware/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// Exporter is a tracing exporter that records the spans with an OpenTelemetry SDK tracer
+	// provider.
+	Exporter struct {
+		provider *sdktrace.TracerProvider
+		tracer   trace.Tracer
+	}
+
+	// idGenerator is the ID generator of the exporter tracer provider. It returns the IDs of
+	// the goa span being exported so that the OpenTelemetry span keeps them.
+	idGenerator struct{}
+
+	// private type used to store the span context of the exported span in the context.
+	spanContextKey struct{}
+)
+
+// ScopeName is the name of the instrumentation scope of the exported spans.
+const ScopeName = "github.com/goadesign/goa/middleware/tracing"
+
+// NewExporter creates an exporter that records the spans with a tracer provider created with the
+// given options. The exporter overrides the ID generator and the sampler of the provider: the
+// spans keep the IDs and the sampling decision of the goa tracer.
+func NewExporter(opts ...sdktrace.TracerProviderOption) *Exporter {
+	opts = append(opts, sdktrace.WithIDGenerator(idGenerator{}), sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	provider := sdktrace.NewTracerProvider(opts...)
+	return &Exporter{provider: provider, tracer: provider.Tracer(ScopeName)}
+}
+
+// ExportSpan records the span with the tracer provider.
+func (e *Exporter) ExportSpan(s *tracing.SpanData) {
+	ctx := context.WithValue(context.Background(), spanContextKey{}, s.SpanContext)
+	if s.ParentSpanID.IsValid() {
+		parent := s.SpanContext
+		parent.SpanID = s.ParentSpanID
+		ctx = trace.ContextWithSpanContext(ctx, SpanContext(parent))
+	}
+	_, span := e.tracer.Start(ctx, s.Name,
+		trace.WithTimestamp(s.StartTime),
+		trace.WithSpanKind(spanKind(s.Kind)),
+		trace.WithAttributes(attributes(s.Attributes)...),
+	)
+	for _, ev := range s.Events {
+		span.AddEvent(ev.Name, trace.WithTimestamp(ev.Time), trace.WithAttributes(attributes(ev.Attributes)...))
+	}
+	switch s.Status.Code {
+	case tracing.StatusOK:
+		span.SetStatus(codes.Ok, "")
+	case tracing.StatusError:
+		span.SetStatus(codes.Error, s.Status.Description)
+	}
+	span.End(trace.WithTimestamp(s.EndTime))
+}
+
+// ForceFlush exports the spans buffered by the span processors of the tracer provider.
+func (e *Exporter) ForceFlush(ctx context.Context) error {
+	return e.provider.ForceFlush(ctx)
+}
+
+// Shutdown flushes the spans and shuts down the tracer provider.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return e.provider.Shutdown(ctx)
+}
+
+// NewIDs returns the trace and span IDs of the exported root span.
+func (idGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	sc, _ := ctx.Value(spanContextKey{}).(tracing.SpanContext)
+	return trace.TraceID(sc.TraceID), trace.SpanID(sc.SpanID)
+}
+
+// NewSpanID returns the span ID of the exported child span.
+func (idGenerator) NewSpanID(ctx context.Context, _ trace.TraceID) trace.SpanID {
+	sc, _ := ctx.Value(spanContextKey{}).(tracing.SpanContext)
+	return trace.SpanID(sc.SpanID)
+}
+
+// SpanContext converts a goa span context into an OpenTelemetry span context.
+func SpanContext(sc tracing.SpanContext) trace.SpanContext {
+	var flags trace.TraceFlags
+	if sc.Sampled {
+		flags = trace.FlagsSampled
+	}
+	state, _ := trace.ParseTraceState(sc.TraceState)
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID(sc.TraceID),
+		SpanID:     trace.SpanID(sc.SpanID),
+		TraceFlags: flags,
+		TraceState: state,
+		Remote:     sc.Remote,
+	})
+}
+
+// FromSpanContext converts an OpenTelemetry span context into a goa span context. The result may
+// be used as parent when starting goa spans.
+func FromSpanContext(sc trace.SpanContext) tracing.SpanContext {
+	return tracing.SpanContext{
+		TraceID:    tracing.TraceID(sc.TraceID()),
+		SpanID:     tracing.SpanID(sc.SpanID()),
+		Sampled:    sc.IsSampled(),
+		TraceState: sc.TraceState().String(),
+		Remote:     sc.IsRemote(),
+	}
+}
+
+// spanKind converts a goa span kind.
+func spanKind(k tracing.SpanKind) trace.SpanKind {
+	switch k {
+	case tracing.SpanKindServer:
+		return trace.SpanKindServer
+	case tracing.SpanKindClient:
+		return trace.SpanKindClient
+	default:
+		return trace.SpanKindInternal
+	}
+}
+
+// attributes converts goa span attributes sorted by key. Values whose type has no OpenTelemetry
+// equivalent are formatted with fmt.Sprint.
+func attributes(attrs map[string]interface{}) []attribute.KeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]attribute.KeyValue, len(keys))
+	for i, k := range keys {
+		kvs[i] = keyValue(k, attrs[k])
+	}
+	return kvs
+}
+
+// keyValue converts a single attribute.
+func keyValue(k string, v interface{}) attribute.KeyValue {
+	switch val := v.(type) {
+	case string:
+		return attribute.String(k, val)
+	case bool:
+		return attribute.Bool(k, val)
+	case int:
+		return attribute.Int(k, val)
+	case int32:
+		return attribute.Int64(k, int64(val))
+	case int64:
+		return attribute.Int64(k, val)
+	case float32:
+		return attribute.Float64(k, float64(val))
+	case float64:
+		return attribute.Float64(k, val)
+	case []string:
+		return attribute.StringSlice(k, val)
+	case []bool:
+		return attribute.BoolSlice(k, val)
+	case []int:
+		return attribute.IntSlice(k, val)
+	case []int64:
+		return attribute.Int64Slice(k, val)
+	case []float64:
+		return attribute.Float64Slice(k, val)
+	case fmt.Stringer:
+		return attribute.Stringer(k, val)
+	default:
+		return attribute.String(k, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,144 @@
+//go:build go1.16
+// +build go1.16
+
+package goaotel_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/tracing"
+	"github.com/goadesign/goa/middleware/tracing/goaotel"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = Describe("Exporter", func() {
+	var (
+		recorder *tracetest.SpanRecorder
+		res      *resource.Resource
+		tracer   *tracing.Tracer
+	)
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		res = resource.NewSchemaless(attribute.String("service.name", "test"))
+		exporter := goaotel.NewExporter(sdktrace.WithSpanProcessor(recorder), sdktrace.WithResource(res))
+		tracer = tracing.NewTracer(tracing.WithExporter(exporter))
+	})
+
+	It("forwards the spans with their IDs", func() {
+		parent := tracing.SpanContext{
+			TraceID:    tracing.TraceID{1, 2, 3},
+			SpanID:     tracing.SpanID{4, 5, 6},
+			Sampled:    true,
+			TraceState: "vendor=value",
+			Remote:     true,
+		}
+		_, span := tracer.Start(context.Background(), "ctrl.action", tracing.SpanKindServer, parent)
+		span.SetAttribute("http.response.status_code", 500)
+		span.SetAttribute("goa.action", "action")
+		span.RecordError(errors.New("boom"))
+		span.SetStatus(tracing.StatusError, "Internal Server Error")
+		span.End()
+
+		spans := recorder.Ended()
+		Ω(spans).Should(HaveLen(1))
+		s := spans[0]
+		Ω(s.Name()).Should(Equal("ctrl.action"))
+		Ω(s.SpanKind()).Should(Equal(trace.SpanKindServer))
+		Ω(s.SpanContext().TraceID()).Should(Equal(trace.TraceID(parent.TraceID)))
+		Ω(s.SpanContext().SpanID()).Should(Equal(trace.SpanID(span.SpanContext().SpanID)))
+		Ω(s.SpanContext().IsSampled()).Should(BeTrue())
+		Ω(s.SpanContext().TraceState().Get("vendor")).Should(Equal("value"))
+		Ω(s.Parent().TraceID()).Should(Equal(trace.TraceID(parent.TraceID)))
+		Ω(s.Parent().SpanID()).Should(Equal(trace.SpanID(parent.SpanID)))
+		Ω(s.Attributes()).Should(Equal([]attribute.KeyValue{
+			attribute.String("goa.action", "action"),
+			attribute.Int("http.response.status_code", 500),
+		}))
+		Ω(s.Events()).Should(HaveLen(1))
+		Ω(s.Events()[0].Name).Should(Equal("exception"))
+		Ω(s.Events()[0].Attributes).Should(ContainElement(attribute.String("exception.message", "boom")))
+		Ω(s.Status()).Should(Equal(sdktrace.Status{Code: codes.Error, Description: "Internal Server Error"}))
+		Ω(s.Resource().Attributes()).Should(ContainElement(attribute.String("service.name", "test")))
+		Ω(s.EndTime()).ShouldNot(BeTemporally("<", s.StartTime()))
+	})
+
+	It("leaves the parent of root spans invalid", func() {
+		_, span := tracer.Start(context.Background(), "root", tracing.SpanKindInternal, tracing.SpanContext{})
+		span.End()
+
+		spans := recorder.Ended()
+		Ω(spans).Should(HaveLen(1))
+		Ω(spans[0].Parent().IsValid()).Should(BeFalse())
+		Ω(spans[0].SpanKind()).Should(Equal(trace.SpanKindInternal))
+	})
+})
+
+var _ = Describe("ForceFlush", func() {
+	It("flushes the batched spans", func() {
+		exp := tracetest.NewInMemoryExporter()
+		exporter := goaotel.NewExporter(sdktrace.WithBatcher(exp))
+		tracer := tracing.NewTracer(tracing.WithExporter(exporter))
+		_, span := tracer.Start(context.Background(), "span", tracing.SpanKindInternal, tracing.SpanContext{})
+		span.End()
+
+		Ω(exporter.ForceFlush(context.Background())).ShouldNot(HaveOccurred())
+		spans := exp.GetSpans()
+		Ω(spans).Should(HaveLen(1))
+		Ω(spans[0].SpanContext.SpanID()).Should(Equal(trace.SpanID(span.SpanContext().SpanID)))
+		Ω(exporter.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("SpanContext", func() {
+	It("round trips", func() {
+		sc := tracing.SpanContext{
+			TraceID:    tracing.TraceID{1},
+			SpanID:     tracing.SpanID{2},
+			Sampled:    true,
+			TraceState: "a=b,c=d",
+			Remote:     true,
+		}
+		Ω(goaotel.FromSpanContext(goaotel.SpanContext(sc))).Should(Equal(sc))
+	})
+})
+
+var _ = Describe("Middleware", func() {
+	It("makes the goa span the parent of OpenTelemetry spans", func() {
+		tracer := tracing.NewTracer()
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		req, _ := http.NewRequest("GET", "http://example.com/bottles/1", nil)
+		req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+		rw := httptest.NewRecorder()
+		service := &goa.Service{Name: "test", Context: context.Background()}
+		ctrl := service.NewController("BottleController")
+		ctx := goa.NewContext(goa.WithAction(ctrl.Context, "show"), rw, req, nil)
+
+		var server tracing.SpanContext
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			server = tracing.ContextSpan(ctx).SpanContext()
+			_, span := provider.Tracer("test").Start(ctx, "query")
+			span.End()
+			return nil
+		}
+		err := tracer.Middleware()(goaotel.Middleware()(h))(ctx, rw, req)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		spans := recorder.Ended()
+		Ω(spans).Should(HaveLen(1))
+		Ω(spans[0].SpanContext().TraceID().String()).Should(Equal("0af7651916cd43dd8448eb211c80319c"))
+		Ω(spans[0].Parent().SpanID()).Should(Equal(trace.SpanID(server.SpanID)))
+	})
+})
@@ -0,0 +1,16 @@
+//go:build go1.16
+// +build go1.16
+
+package goaotel_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGoaotel(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Goaotel Suite")
+}
@@ -0,0 +1,28 @@
+//go:build go1.16
+// +build go1.16
+
+package goaotel
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware returns a middleware that stores the span context of the goa span in the request
+// context using the OpenTelemetry API. Spans started with OpenTelemetry tracers from the request
+// context are children of the goa span. The middleware does nothing if the context contains no
+// goa span.
+func Middleware() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if s := tracing.ContextSpan(ctx); s != nil {
+				ctx = trace.ContextWithSpanContext(ctx, SpanContext(s.SpanContext()))
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
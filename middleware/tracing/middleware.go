@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/goadesign/goa"
+)
+
+// Middleware returns a middleware that creates a server span for each request. The span is a
+// child of the span described by the request trace context headers if any. The span is named
+// "<controller>.<action>" and records the request and response attributes as well as the error
+// returned by the handler or stored in the context. Spans of responses with a 5xx status code
+// have an error status.
+//
+// The middleware stores the span in the request context, use ContextSpan to retrieve it.
+func (t *Tracer) Middleware() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			parent, _ := t.propagator.Extract(req.Header)
+			name := goa.ContextController(ctx) + "." + goa.ContextAction(ctx)
+			ctx, span := t.Start(ctx, name, SpanKindServer, parent)
+			defer span.End()
+
+			span.SetAttribute("http.request.method", req.Method)
+			span.SetAttribute("url.path", req.URL.Path)
+			span.SetAttribute("url.scheme", scheme(req))
+			span.SetAttribute("server.address", req.Host)
+			span.SetAttribute("goa.controller", goa.ContextController(ctx))
+			span.SetAttribute("goa.action", goa.ContextAction(ctx))
+			if ua := req.UserAgent(); ua != "" {
+				span.SetAttribute("user_agent.original", ua)
+			}
+			if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+				span.SetAttribute("client.address", host)
+			}
+
+			err := h(ctx, rw, req)
+
+			status := 0
+			resp := goa.ContextResponse(ctx)
+			if resp != nil {
+				status = resp.Status
+				if resp.ErrorCode != "" {
+					span.SetAttribute("goa.error.code", resp.ErrorCode)
+				}
+			}
+			if cerr := goa.ContextError(ctx); cerr != nil {
+				span.RecordError(cerr)
+			}
+			if err != nil {
+				span.RecordError(err)
+				if resp == nil || !resp.Written() {
+					status = http.StatusInternalServerError
+					if serr, ok := err.(goa.ServiceError); ok {
+						status = serr.ResponseStatus()
+					}
+				}
+			}
+			if status != 0 {
+				span.SetAttribute("http.response.status_code", status)
+			}
+			if status >= 500 {
+				span.SetStatus(StatusError, http.StatusText(status))
+			}
+			return err
+		}
+	}
+}
+
+// scheme returns the scheme of the request URL.
+func scheme(req *http.Request) string {
+	if req.URL.Scheme != "" {
+		return req.URL.Scheme
+	}
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
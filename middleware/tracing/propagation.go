@@ -0,0 +1,191 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Propagator extracts the trace context from incoming requests and injects it in outgoing
+// requests.
+type Propagator interface {
+	// Extract returns the span context stored in the given headers. The boolean is false if
+	// the headers do not contain a valid span context.
+	Extract(http.Header) (SpanContext, bool)
+	// Inject stores the span context in the given headers.
+	Inject(SpanContext, http.Header)
+}
+
+const (
+	// TraceParentHeader is the W3C trace context header containing the trace and parent IDs.
+	TraceParentHeader = "traceparent"
+	// TraceStateHeader is the W3C trace context header containing the vendor specific state.
+	TraceStateHeader = "tracestate"
+	// B3Header is the B3 single header.
+	B3Header = "b3"
+	// B3TraceIDHeader is the B3 multi header containing the trace ID.
+	B3TraceIDHeader = "X-B3-TraceId"
+	// B3SpanIDHeader is the B3 multi header containing the span ID.
+	B3SpanIDHeader = "X-B3-SpanId"
+	// B3ParentSpanIDHeader is the B3 multi header containing the parent span ID.
+	B3ParentSpanIDHeader = "X-B3-ParentSpanId"
+	// B3SampledHeader is the B3 multi header containing the sampling decision.
+	B3SampledHeader = "X-B3-Sampled"
+	// B3FlagsHeader is the B3 multi header containing the debug flag.
+	B3FlagsHeader = "X-B3-Flags"
+)
+
+type (
+	// traceContext implements the W3C trace context propagation.
+	traceContext struct{}
+
+	// b3 implements the B3 propagation.
+	b3 struct{}
+
+	// composite extracts using the first propagator that succeeds and injects using all.
+	composite []Propagator
+)
+
+// TraceContext returns a propagator that implements the W3C Trace Context specification
+// (traceparent and tracestate headers).
+func TraceContext() Propagator {
+	return traceContext{}
+}
+
+// B3 returns a propagator that implements the Zipkin B3 specification. Extract accepts both the
+// single b3 header and the X-B3 multi headers, Inject writes the multi headers.
+func B3() Propagator {
+	return b3{}
+}
+
+// Composite returns a propagator that extracts the span context using the first propagator that
+// succeeds and injects it using all the propagators.
+func Composite(propagators ...Propagator) Propagator {
+	return composite(propagators)
+}
+
+// Extract parses the traceparent and tracestate headers.
+func (traceContext) Extract(h http.Header) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(h.Get(TraceParentHeader)), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	sc.TraceState = strings.Join(h[http.CanonicalHeaderKey(TraceStateHeader)], ",")
+	sc.Remote = true
+	return sc, true
+}
+
+// Inject writes the traceparent and tracestate headers.
+func (traceContext) Inject(sc SpanContext, h http.Header) {
+	if !sc.IsValid() {
+		return
+	}
+	var flags byte
+	if sc.Sampled {
+		flags = 1
+	}
+	h.Set(TraceParentHeader, fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, flags))
+	if sc.TraceState != "" {
+		h.Set(TraceStateHeader, sc.TraceState)
+	} else {
+		h.Del(TraceStateHeader)
+	}
+}
+
+// Extract parses the b3 single header or the X-B3 multi headers.
+func (b3) Extract(h http.Header) (SpanContext, bool) {
+	if single := h.Get(B3Header); single != "" {
+		// {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}
+		parts := strings.Split(single, "-")
+		if len(parts) < 2 {
+			return SpanContext{}, false
+		}
+		sampled := ""
+		if len(parts) > 2 {
+			sampled = parts[2]
+		}
+		return b3SpanContext(parts[0], parts[1], sampled)
+	}
+	sampled := h.Get(B3SampledHeader)
+	if h.Get(B3FlagsHeader) == "1" {
+		sampled = "d"
+	}
+	return b3SpanContext(h.Get(B3TraceIDHeader), h.Get(B3SpanIDHeader), sampled)
+}
+
+// Inject writes the X-B3 multi headers.
+func (b3) Inject(sc SpanContext, h http.Header) {
+	if !sc.IsValid() {
+		return
+	}
+	h.Set(B3TraceIDHeader, sc.TraceID.String())
+	h.Set(B3SpanIDHeader, sc.SpanID.String())
+	if sc.Sampled {
+		h.Set(B3SampledHeader, "1")
+	} else {
+		h.Set(B3SampledHeader, "0")
+	}
+}
+
+// Extract returns the span context extracted by the first propagator that succeeds.
+func (c composite) Extract(h http.Header) (SpanContext, bool) {
+	for _, p := range c {
+		if sc, ok := p.Extract(h); ok {
+			return sc, true
+		}
+	}
+	return SpanContext{}, false
+}
+
+// Inject injects the span context using all the propagators.
+func (c composite) Inject(sc SpanContext, h http.Header) {
+	for _, p := range c {
+		p.Inject(sc, h)
+	}
+}
+
+// b3SpanContext builds a span context from the B3 trace ID, span ID and sampling state. 64-bit
+// trace IDs are left padded with zeros. A missing sampling state defers the decision to the
+// receiver which records the trace.
+func b3SpanContext(traceID, spanID, sampled string) (SpanContext, bool) {
+	var sc SpanContext
+	if len(traceID) == 16 {
+		traceID = strings.Repeat("0", 16) + traceID
+	}
+	if !decodeHex(sc.TraceID[:], traceID) || !decodeHex(sc.SpanID[:], spanID) || !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	switch strings.ToLower(sampled) {
+	case "", "1", "d", "true":
+		sc.Sampled = true
+	case "0", "false":
+	default:
+		return SpanContext{}, false
+	}
+	sc.Remote = true
+	return sc, true
+}
+
+// decodeHex decodes the lowercase hex string s into dst. It returns false if s does not have
+// the right length or is not valid hex.
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
@@ -0,0 +1,131 @@
+package tracing_test
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa/middleware/tracing"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID  = "00f067aa0ba902b7"
+)
+
+var _ = Describe("TraceContext", func() {
+	var h http.Header
+
+	BeforeEach(func() {
+		h = http.Header{}
+	})
+
+	It("extracts the traceparent and tracestate headers", func() {
+		h.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+		h.Add("tracestate", "congo=t61rcWkgMzE")
+		h.Add("tracestate", "rojo=00f067aa0ba902b7")
+		sc, ok := tracing.TraceContext().Extract(h)
+		Ω(ok).Should(BeTrue())
+		Ω(sc.TraceID.String()).Should(Equal(traceID))
+		Ω(sc.SpanID.String()).Should(Equal(spanID))
+		Ω(sc.Sampled).Should(BeTrue())
+		Ω(sc.Remote).Should(BeTrue())
+		Ω(sc.TraceState).Should(Equal("congo=t61rcWkgMzE,rojo=00f067aa0ba902b7"))
+	})
+
+	It("rejects invalid traceparent headers", func() {
+		for _, v := range []string{
+			"",
+			"00-" + traceID + "-" + spanID,
+			"00-" + traceID + "-" + spanID + "-01-extra",
+			"ff-" + traceID + "-" + spanID + "-01",
+			"00-00000000000000000000000000000000-" + spanID + "-01",
+			"00-" + traceID + "-0000000000000000-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + spanID + "-01",
+			"00-" + traceID + "-" + spanID + "-zz",
+		} {
+			h.Set("traceparent", v)
+			_, ok := tracing.TraceContext().Extract(h)
+			Ω(ok).Should(BeFalse(), v)
+		}
+	})
+
+	It("accepts future versions with additional fields", func() {
+		h.Set("traceparent", "01-"+traceID+"-"+spanID+"-00-extra")
+		sc, ok := tracing.TraceContext().Extract(h)
+		Ω(ok).Should(BeTrue())
+		Ω(sc.Sampled).Should(BeFalse())
+	})
+
+	It("round trips", func() {
+		h.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+		h.Set("tracestate", "congo=t61rcWkgMzE")
+		sc, _ := tracing.TraceContext().Extract(h)
+		out := http.Header{}
+		tracing.TraceContext().Inject(sc, out)
+		Ω(out.Get("traceparent")).Should(Equal("00-" + traceID + "-" + spanID + "-01"))
+		Ω(out.Get("tracestate")).Should(Equal("congo=t61rcWkgMzE"))
+	})
+})
+
+var _ = Describe("B3", func() {
+	var h http.Header
+
+	BeforeEach(func() {
+		h = http.Header{}
+	})
+
+	It("extracts the single header", func() {
+		h.Set("b3", traceID+"-"+spanID+"-1-05e3ac9a4f6e3b90")
+		sc, ok := tracing.B3().Extract(h)
+		Ω(ok).Should(BeTrue())
+		Ω(sc.TraceID.String()).Should(Equal(traceID))
+		Ω(sc.SpanID.String()).Should(Equal(spanID))
+		Ω(sc.Sampled).Should(BeTrue())
+	})
+
+	It("extracts the multi headers and pads 64-bit trace IDs", func() {
+		h.Set("X-B3-TraceId", "a3ce929d0e0e4736")
+		h.Set("X-B3-SpanId", spanID)
+		h.Set("X-B3-Sampled", "0")
+		sc, ok := tracing.B3().Extract(h)
+		Ω(ok).Should(BeTrue())
+		Ω(sc.TraceID.String()).Should(Equal("0000000000000000a3ce929d0e0e4736"))
+		Ω(sc.Sampled).Should(BeFalse())
+	})
+
+	It("treats the debug flag as sampled", func() {
+		h.Set("X-B3-TraceId", traceID)
+		h.Set("X-B3-SpanId", spanID)
+		h.Set("X-B3-Sampled", "0")
+		h.Set("X-B3-Flags", "1")
+		sc, ok := tracing.B3().Extract(h)
+		Ω(ok).Should(BeTrue())
+		Ω(sc.Sampled).Should(BeTrue())
+	})
+
+	It("injects the multi headers", func() {
+		h.Set("b3", traceID+"-"+spanID+"-1")
+		sc, _ := tracing.B3().Extract(h)
+		out := http.Header{}
+		tracing.B3().Inject(sc, out)
+		Ω(out.Get("X-B3-TraceId")).Should(Equal(traceID))
+		Ω(out.Get("X-B3-SpanId")).Should(Equal(spanID))
+		Ω(out.Get("X-B3-Sampled")).Should(Equal("1"))
+	})
+})
+
+var _ = Describe("Composite", func() {
+	It("extracts using the first propagator that succeeds and injects using all", func() {
+		p := tracing.Composite(tracing.TraceContext(), tracing.B3())
+		h := http.Header{}
+		h.Set("X-B3-TraceId", traceID)
+		h.Set("X-B3-SpanId", spanID)
+		sc, ok := p.Extract(h)
+		Ω(ok).Should(BeTrue())
+		out := http.Header{}
+		p.Inject(sc, out)
+		Ω(out.Get("traceparent")).Should(Equal("00-" + traceID + "-" + spanID + "-01"))
+		Ω(out.Get("X-B3-TraceId")).Should(Equal(traceID))
+	})
+})
@@ -0,0 +1,328 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa/middleware"
+)
+
+type (
+	// TraceID identifies a trace.
+	TraceID [16]byte
+
+	// SpanID identifies a span.
+	SpanID [8]byte
+
+	// SpanContext is the part of a span that is propagated across process boundaries.
+	SpanContext struct {
+		// TraceID is the ID of the trace the span belongs to.
+		TraceID TraceID
+		// SpanID is the span ID.
+		SpanID SpanID
+		// Sampled is true if the span is recorded and exported.
+		Sampled bool
+		// TraceState contains the vendor specific trace state (W3C tracestate header).
+		TraceState string
+		// Remote is true if the span context was extracted from a request.
+		Remote bool
+	}
+
+	// SpanKind is the kind of a span.
+	SpanKind int
+
+	// StatusCode is the status of a span.
+	StatusCode int
+
+	// Status is the status of a span and its description.
+	Status struct {
+		Code        StatusCode
+		Description string
+	}
+
+	// Event is a timestamped event recorded on a span.
+	Event struct {
+		Name       string
+		Time       time.Time
+		Attributes map[string]interface{}
+	}
+
+	// SpanData is the information recorded on a span. Exporters receive the data of the
+	// sampled spans once they end.
+	SpanData struct {
+		Name         string
+		Kind         SpanKind
+		SpanContext  SpanContext
+		ParentSpanID SpanID
+		StartTime    time.Time
+		EndTime      time.Time
+		Attributes   map[string]interface{}
+		Events       []Event
+		Status       Status
+	}
+
+	// Span is a unit of work in a trace.
+	Span struct {
+		mu     sync.Mutex
+		data   SpanData
+		tracer *Tracer
+		ended  bool
+	}
+
+	// Exporter sends the spans to a tracing backend. ExportSpan is called synchronously when a
+	// sampled span ends, exporters that talk to remote backends should buffer the spans.
+	Exporter interface {
+		ExportSpan(*SpanData)
+	}
+
+	// Tracer creates spans, propagates their context and exports them.
+	Tracer struct {
+		exporter   Exporter
+		propagator Propagator
+		sampler    middleware.Sampler
+	}
+
+	// Option configures a Tracer.
+	Option func(*Tracer)
+
+	// private type used to store the span in the context.
+	spanKey struct{}
+)
+
+const (
+	// SpanKindInternal is the kind of spans that represent internal operations.
+	SpanKindInternal SpanKind = iota
+	// SpanKindServer is the kind of spans that represent the handling of a request.
+	SpanKindServer
+	// SpanKindClient is the kind of spans that represent outgoing requests.
+	SpanKindClient
+)
+
+const (
+	// StatusUnset is the default span status.
+	StatusUnset StatusCode = iota
+	// StatusOK indicates that the operation completed successfully.
+	StatusOK
+	// StatusError indicates that the operation failed.
+	StatusError
+)
+
+// WithExporter sets the exporter that receives the spans. Spans are discarded by default.
+func WithExporter(e Exporter) Option {
+	return func(t *Tracer) {
+		t.exporter = e
+	}
+}
+
+// WithPropagator sets the propagator used to extract and inject the trace context. The default
+// extracts the W3C and B3 headers and injects both.
+func WithPropagator(p Propagator) Option {
+	return func(t *Tracer) {
+		t.propagator = p
+	}
+}
+
+// WithSampler sets the sampler used to decide whether new traces are recorded. Requests with a
+// trace context use the sampling decision of the caller. All traces are recorded by default.
+func WithSampler(s middleware.Sampler) Option {
+	return func(t *Tracer) {
+		t.sampler = s
+	}
+}
+
+// NewTracer creates a tracer.
+func NewTracer(opts ...Option) *Tracer {
+	t := &Tracer{}
+	for _, o := range opts {
+		o(t)
+	}
+	if t.propagator == nil {
+		t.propagator = Composite(TraceContext(), B3())
+	}
+	if t.sampler == nil {
+		t.sampler = middleware.NewFixedSampler(100)
+	}
+	return t
+}
+
+// Start creates a span that is a child of the span in ctx if any or of parent if valid. It
+// returns a context containing the new span.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind, parent SpanContext) (context.Context, *Span) {
+	if s := ContextSpan(ctx); s != nil {
+		parent = s.SpanContext()
+	}
+	sc := SpanContext{SpanID: newSpanID()}
+	var parentID SpanID
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+		sc.TraceState = parent.TraceState
+		parentID = parent.SpanID
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.sampler.Sample()
+	}
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:         name,
+			Kind:         kind,
+			SpanContext:  sc,
+			ParentSpanID: parentID,
+			StartTime:    time.Now(),
+			Attributes:   make(map[string]interface{}),
+		},
+	}
+	ctx = context.WithValue(ctx, spanKey{}, span)
+	var parentStr string
+	if parentID.IsValid() {
+		parentStr = parentID.String()
+	}
+	ctx = middleware.WithTrace(ctx, sc.TraceID.String(), sc.SpanID.String(), parentStr)
+	return ctx, span
+}
+
+// ContextSpan returns the span stored in ctx if any, nil otherwise.
+func ContextSpan(ctx context.Context) *Span {
+	if s, ok := ctx.Value(spanKey{}).(*Span); ok {
+		return s
+	}
+	return nil
+}
+
+// SpanContext returns the span context.
+func (s *Span) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+// SetAttribute sets an attribute on the span.
+func (s *Span) SetAttribute(key string, val interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes[key] = val
+}
+
+// AddEvent records an event on the span.
+func (s *Span) AddEvent(name string, attributes map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Events = append(s.data.Events, Event{Name: name, Time: time.Now(), Attributes: attributes})
+}
+
+// RecordError records the error as an exception event. It does not change the span status.
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.AddEvent("exception", map[string]interface{}{
+		"exception.type":    fmt.Sprintf("%T", err),
+		"exception.message": err.Error(),
+	})
+}
+
+// SetStatus sets the span status.
+func (s *Span) SetStatus(code StatusCode, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Status = Status{Code: code, Description: description}
+}
+
+// End ends the span and exports it if it is sampled. Calls after the first have no effect.
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data.copy()
+	s.mu.Unlock()
+	if data.SpanContext.Sampled && s.tracer.exporter != nil {
+		s.tracer.exporter.ExportSpan(&data)
+	}
+}
+
+// copy returns a deep copy of the span data so that exporters may keep it while the span is
+// still being modified.
+func (d SpanData) copy() SpanData {
+	d.Attributes = copyAttributes(d.Attributes)
+	if d.Events != nil {
+		events := make([]Event, len(d.Events))
+		for i, e := range d.Events {
+			e.Attributes = copyAttributes(e.Attributes)
+			events[i] = e
+		}
+		d.Events = events
+	}
+	return d
+}
+
+// copyAttributes returns a copy of the given attributes.
+func copyAttributes(attrs map[string]interface{}) map[string]interface{} {
+	if attrs == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(attrs))
+	for k, v := range attrs {
+		c[k] = v
+	}
+	return c
+}
+
+// IsValid returns true if the span context has a valid trace and span ID.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsValid returns true if the trace ID is not all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String returns the hex representation of the trace ID.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid returns true if the span ID is not all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String returns the hex representation of the span ID.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// String returns the name of the span kind.
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// newTraceID returns a random trace ID.
+func newTraceID() (id TraceID) {
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return
+}
+
+// newSpanID returns a random span ID.
+func newSpanID() (id SpanID) {
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return
+}
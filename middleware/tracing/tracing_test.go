@@ -0,0 +1,187 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	"github.com/goadesign/goa/middleware/tracing"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var (
+		exporter *tracing.InMemoryExporter
+		tracer   *tracing.Tracer
+		req      *http.Request
+		rw       *httptest.ResponseRecorder
+		ctx      context.Context
+	)
+
+	BeforeEach(func() {
+		exporter = tracing.NewInMemoryExporter()
+		tracer = tracing.NewTracer(tracing.WithExporter(exporter))
+		var err error
+		req, err = http.NewRequest("GET", "http://example.com/bottles/1", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("User-Agent", "test")
+		rw = httptest.NewRecorder()
+		service := &goa.Service{Name: "test", Context: context.Background()}
+		ctrl := service.NewController("BottleController")
+		ctx = goa.NewContext(goa.WithAction(ctrl.Context, "show"), rw, req, nil)
+	})
+
+	serve := func(h goa.Handler) error {
+		return tracer.Middleware()(h)(ctx, rw, req)
+	}
+
+	It("creates a server span named after the controller and action", func() {
+		var inner *tracing.Span
+		err := serve(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			inner = tracing.ContextSpan(ctx)
+			Ω(middleware.ContextTraceID(ctx)).Should(Equal(inner.SpanContext().TraceID.String()))
+			goa.ContextResponse(ctx).WriteHeader(http.StatusOK)
+			return nil
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(inner).ShouldNot(BeNil())
+		spans := exporter.Spans()
+		Ω(spans).Should(HaveLen(1))
+		s := spans[0]
+		Ω(s.Name).Should(Equal("BottleController.show"))
+		Ω(s.Kind).Should(Equal(tracing.SpanKindServer))
+		Ω(s.SpanContext).Should(Equal(inner.SpanContext()))
+		Ω(s.ParentSpanID.IsValid()).Should(BeFalse())
+		Ω(s.Attributes).Should(HaveKeyWithValue("http.request.method", "GET"))
+		Ω(s.Attributes).Should(HaveKeyWithValue("url.path", "/bottles/1"))
+		Ω(s.Attributes).Should(HaveKeyWithValue("server.address", "example.com"))
+		Ω(s.Attributes).Should(HaveKeyWithValue("user_agent.original", "test"))
+		Ω(s.Attributes).Should(HaveKeyWithValue("client.address", "10.0.0.1"))
+		Ω(s.Attributes).Should(HaveKeyWithValue("http.response.status_code", http.StatusOK))
+		Ω(s.Status.Code).Should(Equal(tracing.StatusUnset))
+	})
+
+	It("continues the trace of the request", func() {
+		req.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+		Ω(serve(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return nil
+		})).ShouldNot(HaveOccurred())
+		s := exporter.Spans()[0]
+		Ω(s.SpanContext.TraceID.String()).Should(Equal(traceID))
+		Ω(s.ParentSpanID.String()).Should(Equal(spanID))
+	})
+
+	It("honors the caller sampling decision", func() {
+		req.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-00")
+		Ω(serve(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return nil
+		})).ShouldNot(HaveOccurred())
+		Ω(exporter.Spans()).Should(BeEmpty())
+	})
+
+	It("records the errors returned by the handler", func() {
+		serr := goa.ErrBadRequest("invalid")
+		err := serve(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return serr
+		})
+		Ω(err).Should(Equal(serr))
+		s := exporter.Spans()[0]
+		Ω(s.Attributes).Should(HaveKeyWithValue("http.response.status_code", http.StatusBadRequest))
+		Ω(s.Events).Should(HaveLen(1))
+		Ω(s.Events[0].Name).Should(Equal("exception"))
+		Ω(s.Status.Code).Should(Equal(tracing.StatusUnset))
+	})
+
+	It("sets the error status on server errors", func() {
+		Ω(serve(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return errors.New("boom")
+		})).Should(HaveOccurred())
+		s := exporter.Spans()[0]
+		Ω(s.Attributes).Should(HaveKeyWithValue("http.response.status_code", http.StatusInternalServerError))
+		Ω(s.Status.Code).Should(Equal(tracing.StatusError))
+	})
+
+	It("records the response error code", func() {
+		Ω(serve(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			resp := goa.ContextResponse(ctx)
+			resp.ErrorCode = "not_found"
+			resp.WriteHeader(http.StatusNotFound)
+			return nil
+		})).ShouldNot(HaveOccurred())
+		s := exporter.Spans()[0]
+		Ω(s.Attributes).Should(HaveKeyWithValue("goa.error.code", "not_found"))
+		Ω(s.Attributes).Should(HaveKeyWithValue("http.response.status_code", http.StatusNotFound))
+	})
+
+	It("uses the sampler for new traces", func() {
+		tracer = tracing.NewTracer(tracing.WithExporter(exporter), tracing.WithSampler(middleware.NewFixedSampler(0)))
+		Ω(serve(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return nil
+		})).ShouldNot(HaveOccurred())
+		Ω(exporter.Spans()).Should(BeEmpty())
+	})
+})
+
+type doerFunc func(context.Context, *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return f(ctx, req)
+}
+
+var _ = Describe("WrapDoer", func() {
+	It("creates a client span and injects the trace context", func() {
+		exporter := tracing.NewInMemoryExporter()
+		tracer := tracing.NewTracer(tracing.WithExporter(exporter))
+		var sent http.Header
+		doer := tracer.WrapDoer(doerFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			sent = req.Header
+			return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+		}))
+		ctx, parent := tracer.Start(context.Background(), "parent", tracing.SpanKindInternal, tracing.SpanContext{})
+		req, _ := http.NewRequest("POST", "http://svc.local/bottles", nil)
+		resp, err := doer.Do(ctx, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(http.StatusServiceUnavailable))
+		parent.End()
+
+		spans := exporter.Spans()
+		Ω(spans).Should(HaveLen(2))
+		s := spans[0]
+		Ω(s.Kind).Should(Equal(tracing.SpanKindClient))
+		Ω(s.SpanContext.TraceID).Should(Equal(parent.SpanContext().TraceID))
+		Ω(s.ParentSpanID).Should(Equal(parent.SpanContext().SpanID))
+		Ω(s.Status.Code).Should(Equal(tracing.StatusError))
+		Ω(sent.Get("traceparent")).Should(Equal("00-" + s.SpanContext.TraceID.String() + "-" + s.SpanContext.SpanID.String() + "-01"))
+		Ω(sent.Get("X-B3-SpanId")).Should(Equal(s.SpanContext.SpanID.String()))
+	})
+})
+
+var _ = Describe("Span", func() {
+	It("exports a copy of its data", func() {
+		exporter := tracing.NewInMemoryExporter()
+		tracer := tracing.NewTracer(tracing.WithExporter(exporter))
+		_, span := tracer.Start(context.Background(), "span", tracing.SpanKindInternal, tracing.SpanContext{})
+		span.SetAttribute("key", "before")
+		span.AddEvent("event", map[string]interface{}{"key": "before"})
+		span.End()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			span.SetAttribute("key", "after")
+			span.AddEvent("other", nil)
+		}()
+		spans := exporter.Spans()
+		Ω(spans).Should(HaveLen(1))
+		Ω(spans[0].Attributes).Should(Equal(map[string]interface{}{"key": "before"}))
+		Ω(spans[0].Events).Should(HaveLen(1))
+		<-done
+		Ω(spans[0].Attributes["key"]).Should(Equal("before"))
+		Ω(spans[0].Events).Should(HaveLen(1))
+	})
+})
@@ -0,0 +1,16 @@
+/*
+Package mock contains the runtime support for the mock servers generated by "goagen mock".
+
+The generated mock controllers implement the controller interfaces of the generated "app" package
+so that requests go through the generated handlers which decode and validate the request
+parameters and payloads. Each action then writes one of the responses defined in the design using
+the examples computed from the design at generation time.
+
+The response is the first successful response defined by the action unless the request sets the
+X-Mock-Response header to the name (e.g. "NotFound") or status code (e.g. "404") of another
+response. The media type view used to render the example is given by the "view" parameter if the
+action defines one.
+
+The functions exposed by this package are meant to be called by the generated code.
+*/
+package mock
@@ -0,0 +1,129 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+// ResponseHeader is the name of the request header used to select the mock response.
+const ResponseHeader = "X-Mock-Response"
+
+// Response describes a response that a mock action may write.
+type Response struct {
+	// Name is the name of the response in the design, e.g. "OK" or "NotFound".
+	Name string
+	// Status is the response HTTP status code.
+	Status int
+	// ContentType is the response media type identifier if any.
+	ContentType string
+	// Stream is true if the response body is a stream of server-sent events.
+	Stream bool
+	// Examples contains the JSON representations of the response body example indexed by view
+	// name. The key is the empty string if the response body is not a media type.
+	Examples map[string]string
+}
+
+// Respond writes one of the given responses. It selects the response using the X-Mock-Response
+// request header and the example using the "view" request parameter. Respond returns a bad request
+// error if the header does not match any of the responses.
+func Respond(ctx context.Context, responses []*Response) error {
+	req := goa.ContextRequest(ctx)
+	resp := goa.ContextResponse(ctx)
+	if req == nil || resp == nil {
+		return fmt.Errorf("no request data in context")
+	}
+	r, err := selectResponse(responses, req.Header.Get(ResponseHeader))
+	if err != nil {
+		return err
+	}
+	body, err := r.body(req.Params.Get("view"))
+	if err != nil {
+		return err
+	}
+	if r.Stream {
+		stream, err := goa.OpenEventStream(ctx, r.Status)
+		if err != nil {
+			return err
+		}
+		if body == nil {
+			return nil
+		}
+		return stream.Send(body)
+	}
+	if body == nil {
+		resp.WriteHeader(r.Status)
+		return nil
+	}
+	if r.ContentType != "" && resp.Header().Get("Content-Type") == "" {
+		resp.Header().Set("Content-Type", r.ContentType)
+	}
+	if resp.Service == nil {
+		return fmt.Errorf("no service in response data")
+	}
+	return resp.Service.Send(ctx, r.Status, body)
+}
+
+// Middleware is a security middleware that lets all requests through. The generated mock servers
+// use it for all the security schemes defined in the design.
+func Middleware(h goa.Handler) goa.Handler {
+	return h
+}
+
+// selectResponse returns the response with the given name or status code, the first successful
+// response if name is empty.
+func selectResponse(responses []*Response, name string) (*Response, error) {
+	if len(responses) == 0 {
+		return &Response{Name: "NoContent", Status: http.StatusNoContent}, nil
+	}
+	if name == "" {
+		for _, r := range responses {
+			if r.Status >= 200 && r.Status < 300 {
+				return r, nil
+			}
+		}
+		return responses[0], nil
+	}
+	status, _ := strconv.Atoi(name)
+	names := make([]string, len(responses))
+	for i, r := range responses {
+		if strings.EqualFold(r.Name, name) || r.Status == status {
+			return r, nil
+		}
+		names[i] = r.Name
+	}
+	return nil, goa.ErrBadRequest(fmt.Sprintf("unknown mock response %#v, must be one of %s", name, strings.Join(names, ", ")),
+		"header", ResponseHeader)
+}
+
+// body returns the decoded example for the given view, nil if the response has no body.
+func (r *Response) body(view string) (interface{}, error) {
+	ex, ok := r.Examples[view]
+	if !ok {
+		switch {
+		case len(r.Examples) == 0:
+			return nil, nil
+		case len(r.Examples) == 1:
+			for _, e := range r.Examples {
+				ex = e
+			}
+		default:
+			if ex, ok = r.Examples["default"]; !ok {
+				return nil, goa.ErrBadRequest(fmt.Sprintf("unknown view %#v", view), "param", "view")
+			}
+		}
+	}
+	dec := json.NewDecoder(bytes.NewBufferString(ex))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid %s response example: %s", r.Name, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,124 @@
+package mock_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/mock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Respond", func() {
+	var responses []*mock.Response
+	var header, view string
+
+	var rw *httptest.ResponseRecorder
+	var err error
+
+	BeforeEach(func() {
+		responses = []*mock.Response{
+			{
+				Name:        "OK",
+				Status:      200,
+				ContentType: "application/vnd.bottle+json",
+				Examples: map[string]string{
+					"default": `{"id":1,"name":"muscadet"}`,
+					"tiny":    `{"id":1}`,
+				},
+			},
+			{Name: "NotFound", Status: 404},
+		}
+		header, view = "", ""
+	})
+
+	JustBeforeEach(func() {
+		service := goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		rw = httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/bottles/1", nil)
+		if header != "" {
+			req.Header.Set(mock.ResponseHeader, header)
+		}
+		params := url.Values{}
+		if view != "" {
+			params.Set("view", view)
+		}
+		ctx := goa.NewContext(context.Background(), rw, req, params)
+		goa.ContextResponse(ctx).Service = service
+		err = mock.Respond(ctx, responses)
+	})
+
+	It("writes the first successful response", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("application/vnd.bottle+json"))
+		Ω(rw.Body.String()).Should(MatchJSON(`{"id":1,"name":"muscadet"}`))
+	})
+
+	Context("with a view", func() {
+		BeforeEach(func() {
+			view = "tiny"
+		})
+
+		It("renders the example in the view", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(MatchJSON(`{"id":1}`))
+		})
+	})
+
+	Context("with a response name", func() {
+		BeforeEach(func() {
+			header = "notfound"
+		})
+
+		It("writes the response", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(404))
+			Ω(rw.Body.Len()).Should(BeZero())
+		})
+	})
+
+	Context("with a response status code", func() {
+		BeforeEach(func() {
+			header = "404"
+		})
+
+		It("writes the response", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(404))
+		})
+	})
+
+	Context("with an unknown response", func() {
+		BeforeEach(func() {
+			header = "Teapot"
+		})
+
+		It("returns a bad request error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+			Ω(err.Error()).Should(ContainSubstring(`unknown mock response "Teapot", must be one of OK, NotFound`))
+		})
+	})
+
+	Context("with a server-sent events response", func() {
+		BeforeEach(func() {
+			responses = []*mock.Response{{
+				Name:     "OK",
+				Status:   200,
+				Stream:   true,
+				Examples: map[string]string{"default": `{"id":1}`},
+			}}
+		})
+
+		It("sends the example as an event", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("text/event-stream"))
+			Ω(rw.Body.String()).Should(ContainSubstring(`data: {"id":1}`))
+		})
+	})
+})
@@ -75,3 +75,11 @@ type JWTSecurity struct {
 	// Scopes defines a list of scopes for the security scheme, along with their description.
 	Scopes map[string]string
 }
+
+// MutualTLSSecurity represents the `mutualTLS` security scheme where clients authenticate with a
+// certificate verified during the TLS handshake. The verified identity is available through
+// ContextPeerIdentity.
+type MutualTLSSecurity struct {
+	// Description of the security scheme
+	Description string
+}
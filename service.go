@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -39,9 +40,22 @@ type (
 		Decoder *HTTPDecoder
 		// Response body encoder
 		Encoder *HTTPEncoder
+		// Interval between the heartbeats written to server-sent events streams, zero disables
+		// heartbeats.
+		EventStreamHeartbeat time.Duration
+		// H2C enables HTTP/2 over cleartext connections in ListenAndServe and Serve.
+		H2C bool
+		// TLS configures the TLS server started by ListenAndServeTLS, see TLSConfig.
+		TLS *TLSConfig
+		// Policy authorizes the requests made to actions that define authorization policies
+		// with the Authorize DSL, see Policy.
+		Policy Policy
 
 		middleware []Middleware       // Middleware chain
 		cancel     context.CancelFunc // Service context cancel signal trigger
+		lc         lifecycle          // In-flight requests and lifecycle hooks
+		health     health             // Registered health checks
+		h2c        bool               // Whether the server handler accepts h2c connections
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -105,6 +119,8 @@ func New(name string) *Service {
 			Decoder: NewHTTPDecoder(),
 			Encoder: NewHTTPEncoder(),
 
+			EventStreamHeartbeat: DefaultEventStreamHeartbeat,
+
 			cancel: cancel,
 		}
 		notFoundHandler         Handler
@@ -171,6 +187,7 @@ func New(name string) *Service {
 
 // CancelAll sends a cancel signals to all request handlers via the context.
 // See https://golang.org/pkg/context/ for details on how to handle the signal.
+// Use Shutdown to stop the service gracefully.
 func (service *Service) CancelAll() {
 	service.cancel()
 }
@@ -198,22 +215,42 @@ func (service *Service) LogError(msg string, keyvals ...interface{}) {
 }
 
 // ListenAndServe starts a HTTP server and sets up a listener on the given host/port.
+// It runs the start hooks first and returns nil once the service has been shut down with Shutdown.
 func (service *Service) ListenAndServe(addr string) error {
-	service.LogInfo("listen", "transport", "http", "addr", addr)
+	if err := service.start(); err != nil {
+		return err
+	}
+	service.LogInfo("listen", "transport", "http", "addr", addr, "h2c", service.H2C)
 	service.Server.Addr = addr
-	return service.Server.ListenAndServe()
+	service.enableH2C()
+	return service.served(service.Server.ListenAndServe())
 }
 
 // ListenAndServeTLS starts a HTTPS server and sets up a listener on the given host/port.
+// The server applies the TLS settings and reloads the certificate when the files change, see
+// NewTLSConfig. It runs the start hooks first and returns nil once the service has been shut down with Shutdown.
 func (service *Service) ListenAndServeTLS(addr, certFile, keyFile string) error {
-	service.LogInfo("listen", "transport", "https", "addr", addr)
+	cfg, err := service.NewTLSConfig(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	if err := service.start(); err != nil {
+		return err
+	}
+	service.LogInfo("listen", "transport", "https", "addr", addr, "mtls", cfg.ClientCAs != nil)
 	service.Server.Addr = addr
-	return service.Server.ListenAndServeTLS(certFile, keyFile)
+	service.Server.TLSConfig = cfg
+	return service.served(service.Server.ListenAndServeTLS("", ""))
 }
 
 // Serve accepts incoming HTTP connections on the listener l, invoking the service mux handler for each.
+// It runs the start hooks first and returns nil once the service has been shut down with Shutdown.
 func (service *Service) Serve(l net.Listener) error {
-	return service.Server.Serve(l)
+	if err := service.start(); err != nil {
+		return err
+	}
+	service.enableH2C()
+	return service.served(service.Server.Serve(l))
 }
 
 // NewController returns a controller for the given resource. This method is mainly intended for
@@ -302,6 +339,10 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 	var initHandler sync.Once
 
 	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		// Track in-flight requests for graceful shutdown
+		ctrl.Service.beginRequest()
+		defer ctrl.Service.endRequest(req)
+
 		// Build handler middleware chains on first invocation
 		initHandler.Do(func() {
 			handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -340,7 +381,16 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 		}
 
 		// Invoke handler
-		if err := handler(ctx, ContextResponse(ctx), req); err != nil {
+		err := handler(ctx, ContextResponse(ctx), req)
+		if stream := ContextResponse(ctx).stream; stream != nil {
+			// The response is streamed, it's too late to write an error response.
+			stream.Close()
+			if err != nil {
+				LogError(ctx, "uncaught error", "err", err)
+			}
+			return
+		}
+		if err != nil {
 			LogError(ctx, "uncaught error", "err", err)
 			respBody := fmt.Sprintf("Internal error: %s", err) // Sprintf catches panics
 			ctrl.Service.Send(ctx, 500, respBody)
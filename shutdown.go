@@ -0,0 +1,412 @@
+package goa
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/http/httpguts"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+type (
+	// Hook is a function run when the service starts or shuts down.
+	Hook func(context.Context) error
+
+	// lifecycle tracks the in-flight requests, the hijacked connections and the hooks of a
+	// service. The zero value is ready to use.
+	lifecycle struct {
+		mu         sync.Mutex
+		inflight   int
+		hijacked   map[net.Conn]struct{}
+		h2c        map[net.Conn]*h2cConn
+		onStart    []Hook
+		onShutdown []Hook
+		prepared   bool
+		shutdown   bool
+		drained    chan struct{} // closed when the in-flight requests and h2c connections complete during shutdown
+		done       chan struct{} // closed when Shutdown completes
+	}
+
+	// h2cConn records the state of a connection upgraded to HTTP/2 by the h2c handler.
+	h2cConn struct {
+		requests int  // number of in-flight requests
+		closing  bool // true once a response asked the HTTP/2 server to close the connection
+	}
+
+	// h2cWriter sets the "Connection: close" header on the responses committed during shutdown
+	// so that the HTTP/2 server sends a GOAWAY frame and closes the connection once its streams
+	// complete.
+	h2cWriter struct {
+		http.ResponseWriter
+		service   *Service
+		conn      net.Conn
+		committed bool
+	}
+
+	// connKey is the key used to store the request connection in the request context.
+	connKey struct{}
+)
+
+// OnStart registers a hook run by ListenAndServe, ListenAndServeTLS and Serve before they start
+// accepting connections. Hooks run in registration order with the service context, the first
+// error aborts the startup and is returned by the serve method.
+func (service *Service) OnStart(h Hook) {
+	service.lc.mu.Lock()
+	defer service.lc.mu.Unlock()
+	service.lc.onStart = append(service.lc.onStart, h)
+}
+
+// OnShutdown registers a hook run by Shutdown once the in-flight requests have completed and
+// before the service context is canceled. Hooks run in reverse registration order.
+func (service *Service) OnShutdown(h Hook) {
+	service.lc.mu.Lock()
+	defer service.lc.mu.Unlock()
+	service.lc.onShutdown = append(service.lc.onShutdown, h)
+}
+
+// ShuttingDown returns true once Shutdown has been called. Readiness checks should report the
+// service as not ready so that load balancers stop sending requests.
+func (service *Service) ShuttingDown() bool {
+	service.lc.mu.Lock()
+	defer service.lc.mu.Unlock()
+	return service.lc.shutdown
+}
+
+// Shutdown gracefully shuts down the service. It stops accepting new connections, waits for the
+// in-flight requests to complete, closes the websocket connections, runs the shutdown hooks and
+// finally cancels the service context. HTTP/2 cleartext connections are drained: idle connections
+// are closed and the others are closed by the HTTP/2 server once their in-flight streams complete. If ctx expires first the remaining connections are closed
+// forcefully and Shutdown returns the context error once the hooks have run.
+//
+// The serve methods return nil once Shutdown completes. Calling Shutdown more than once waits
+// for the first call to complete.
+func (service *Service) Shutdown(ctx context.Context) error {
+	lc := &service.lc
+	lc.mu.Lock()
+	if lc.shutdown {
+		done := lc.done
+		lc.mu.Unlock()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	lc.shutdown = true
+	lc.done = make(chan struct{})
+	lc.drained = make(chan struct{})
+	lc.checkDrained()
+	done, drained := lc.done, lc.drained
+	hooks := lc.onShutdown
+	lc.mu.Unlock()
+	defer close(done)
+
+	service.LogInfo("shutdown", "inflight", service.inflight())
+	var err error
+	if service.Server != nil {
+		if err = service.Server.Shutdown(ctx); err != nil {
+			service.Server.Close()
+		}
+	}
+	service.closeHijacked()
+	service.closeIdleH2C()
+	if err == nil {
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	if err != nil {
+		service.closeH2C()
+	}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if herr := hooks[i](ctx); herr != nil {
+			service.LogError("shutdown hook", "err", herr)
+			if err == nil {
+				err = herr
+			}
+		}
+	}
+	if service.cancel != nil {
+		service.cancel()
+	}
+	return err
+}
+
+// ShutdownOnSignal shuts the service down gracefully when the process receives one of the given
+// signals, SIGTERM and SIGINT if none is given. timeout bounds the duration of the shutdown.
+func (service *Service) ShutdownOnSignal(timeout time.Duration, signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		sig := <-ch
+		signal.Stop(ch)
+		service.LogInfo("signal", "sig", sig.String())
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := service.Shutdown(ctx); err != nil {
+			service.LogError("shutdown", "err", err)
+		}
+	}()
+}
+
+// start prepares the server and runs the start hooks.
+func (service *Service) start() error {
+	lc := &service.lc
+	lc.mu.Lock()
+	if !lc.prepared {
+		lc.prepared = true
+		service.trackConns()
+	}
+	hooks := lc.onStart
+	lc.mu.Unlock()
+	for _, h := range hooks {
+		if err := h(service.Context); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// served converts the error returned by the http server serve methods: it waits for Shutdown to
+// complete and returns nil if the server was shut down gracefully.
+func (service *Service) served(err error) error {
+	if err != http.ErrServerClosed {
+		return err
+	}
+	service.lc.mu.Lock()
+	done := service.lc.done
+	service.lc.mu.Unlock()
+	if done == nil {
+		return err
+	}
+	<-done
+	return nil
+}
+
+// trackConns installs the server hooks that record the hijacked connections so that Shutdown
+// can close them.
+func (service *Service) trackConns() {
+	srv := service.Server
+	connContext, connState := srv.ConnContext, srv.ConnState
+	srv.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+		if connContext != nil {
+			ctx = connContext(ctx, c)
+		}
+		return context.WithValue(ctx, connKey{}, c)
+	}
+	srv.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateHijacked {
+			service.lc.mu.Lock()
+			if _, ok := service.lc.h2c[c]; !ok {
+				if service.lc.hijacked == nil {
+					service.lc.hijacked = make(map[net.Conn]struct{})
+				}
+				service.lc.hijacked[c] = struct{}{}
+			}
+			service.lc.mu.Unlock()
+		}
+		if connState != nil {
+			connState(c, state)
+		}
+	}
+}
+
+// h2cHandler returns a handler that serves h over HTTP/1 and HTTP/2 cleartext connections. The
+// connections upgraded to HTTP/2 are recorded until the HTTP/2 server is done with them so that
+// Shutdown drains them instead of closing them like the other hijacked connections.
+func (service *Service) h2cHandler(h http.Handler) http.Handler {
+	upgrade := h2c.NewHandler(service.drainH2C(h), &http2.Server{})
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		c, ok := req.Context().Value(connKey{}).(net.Conn)
+		if !ok || !isH2C(req) {
+			upgrade.ServeHTTP(rw, req)
+			return
+		}
+		lc := &service.lc
+		lc.mu.Lock()
+		if lc.h2c == nil {
+			lc.h2c = make(map[net.Conn]*h2cConn)
+		}
+		lc.h2c[c] = &h2cConn{}
+		lc.mu.Unlock()
+		defer func() {
+			lc.mu.Lock()
+			defer lc.mu.Unlock()
+			delete(lc.h2c, c)
+			lc.checkDrained()
+		}()
+		upgrade.ServeHTTP(rw, req)
+	})
+}
+
+// drainH2C wraps the handler of the HTTP/2 streams so that the responses committed during
+// shutdown close their h2c connection gracefully. A connection whose in-flight responses were
+// all committed before the shutdown started is closed once its last request completes.
+func (service *Service) drainH2C(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		lc := &service.lc
+		c, _ := req.Context().Value(connKey{}).(net.Conn)
+		lc.mu.Lock()
+		hc := lc.h2c[c]
+		if hc == nil || req.ProtoMajor != 2 {
+			lc.mu.Unlock()
+			h.ServeHTTP(rw, req)
+			return
+		}
+		hc.requests++
+		lc.mu.Unlock()
+		w := &h2cWriter{ResponseWriter: rw, service: service, conn: c}
+		defer func() {
+			w.commit()
+			lc.mu.Lock()
+			hc.requests--
+			idle := lc.shutdown && hc.requests == 0 && !hc.closing
+			lc.mu.Unlock()
+			if idle {
+				c.Close()
+			}
+		}()
+		h.ServeHTTP(w, req)
+	})
+}
+
+// isH2C returns true if the h2c handler upgrades the connection of req to HTTP/2, either because
+// the client has prior knowledge of HTTP/2 support or because it asks for an upgrade.
+func isH2C(req *http.Request) bool {
+	if req.Method == "PRI" && req.URL.Path == "*" && req.Proto == "HTTP/2.0" {
+		return true
+	}
+	return httpguts.HeaderValuesContainsToken(req.Header["Upgrade"], "h2c")
+}
+
+// WriteHeader commits the response and writes the status code.
+func (w *h2cWriter) WriteHeader(code int) {
+	w.commit()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write commits the response and writes the body.
+func (w *h2cWriter) Write(b []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *h2cWriter) Flush() {
+	w.commit()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// commit asks the HTTP/2 server to close the connection after the response if the service is
+// shutting down. It must be called before the response headers are written.
+func (w *h2cWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+	lc := &w.service.lc
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if !lc.shutdown {
+		return
+	}
+	w.Header().Set("Connection", "close")
+	if hc := lc.h2c[w.conn]; hc != nil {
+		hc.closing = true
+	}
+}
+
+// beginRequest records an in-flight request.
+func (service *Service) beginRequest() {
+	service.lc.mu.Lock()
+	defer service.lc.mu.Unlock()
+	service.lc.inflight++
+}
+
+// endRequest records the completion of a request and forgets its connection if it was hijacked.
+func (service *Service) endRequest(req *http.Request) {
+	lc := &service.lc
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.inflight--
+	if c, ok := req.Context().Value(connKey{}).(net.Conn); ok {
+		delete(lc.hijacked, c)
+	}
+	lc.checkDrained()
+}
+
+// checkDrained closes the drained channel during shutdown once there are no in-flight requests
+// and no h2c connections left. lc.mu must be held.
+func (lc *lifecycle) checkDrained() {
+	if !lc.shutdown || lc.inflight > 0 || len(lc.h2c) > 0 {
+		return
+	}
+	select {
+	case <-lc.drained:
+	default:
+		close(lc.drained)
+	}
+}
+
+// inflight returns the number of in-flight requests.
+func (service *Service) inflight() int {
+	service.lc.mu.Lock()
+	defer service.lc.mu.Unlock()
+	return service.lc.inflight
+}
+
+// closeHijacked closes the hijacked connections, typically websockets.
+func (service *Service) closeHijacked() {
+	service.lc.mu.Lock()
+	conns := make([]net.Conn, 0, len(service.lc.hijacked))
+	for c := range service.lc.hijacked {
+		conns = append(conns, c)
+	}
+	service.lc.mu.Unlock()
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+// closeIdleH2C closes the h2c connections that have no in-flight request.
+func (service *Service) closeIdleH2C() {
+	service.lc.mu.Lock()
+	var conns []net.Conn
+	for c, hc := range service.lc.h2c {
+		if hc.requests == 0 && !hc.closing {
+			conns = append(conns, c)
+		}
+	}
+	service.lc.mu.Unlock()
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+// closeH2C closes the h2c connections that did not complete in time.
+func (service *Service) closeH2C() {
+	service.lc.mu.Lock()
+	conns := make([]net.Conn, 0, len(service.lc.h2c))
+	for c := range service.lc.h2c {
+		conns = append(conns, c)
+	}
+	service.lc.mu.Unlock()
+	for _, c := range conns {
+		c.Close()
+	}
+}
@@ -0,0 +1,158 @@
+package goa_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Shutdown", func() {
+	var (
+		s        *goa.Service
+		l        net.Listener
+		served   chan error
+		release  chan struct{}
+		started  chan struct{}
+		hookRuns []string
+
+		releaseOnce sync.Once
+	)
+
+	unblock := func() {
+		releaseOnce.Do(func() { close(release) })
+	}
+
+	BeforeEach(func() {
+		s = goa.New("test")
+		s.WithLogger(goa.NewLogger(log.New(ioutil.Discard, "", 0)))
+		release = make(chan struct{})
+		releaseOnce = sync.Once{}
+		started = make(chan struct{}, 1)
+		hookRuns = nil
+		ctrl := s.NewController("test")
+		s.Mux.Handle("GET", "/slow", ctrl.MuxHandler("slow", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			started <- struct{}{}
+			<-release
+			rw.WriteHeader(http.StatusNoContent)
+			return nil
+		}, nil))
+		s.Mux.Handle("GET", "/ws", ctrl.MuxHandler("ws", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			conn, buf, err := goa.ContextResponse(ctx).ResponseWriter.(http.Hijacker).Hijack()
+			if err != nil {
+				return err
+			}
+			buf.WriteString("HTTP/1.1 101 Switching Protocols\r\n\r\n")
+			buf.Flush()
+			started <- struct{}{}
+			ioutil.ReadAll(conn)
+			return nil
+		}, nil))
+		s.OnShutdown(func(context.Context) error {
+			hookRuns = append(hookRuns, "first")
+			return nil
+		})
+		s.OnShutdown(func(context.Context) error {
+			hookRuns = append(hookRuns, "second")
+			return nil
+		})
+		var err error
+		l, err = net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		served = make(chan error, 1)
+		srv, lis, ch := s, l, served
+		go func() { ch <- srv.Serve(lis) }()
+	})
+
+	AfterEach(func() {
+		unblock()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		s.Shutdown(ctx)
+	})
+
+	It("waits for the in-flight requests", func() {
+		resp := make(chan int, 1)
+		go func() {
+			r, err := http.Get("http://" + l.Addr().String() + "/slow")
+			if err != nil {
+				resp <- 0
+				return
+			}
+			r.Body.Close()
+			resp <- r.StatusCode
+		}()
+		Eventually(started).Should(Receive())
+
+		shutdown := make(chan error, 1)
+		go func() { shutdown <- s.Shutdown(context.Background()) }()
+		Eventually(s.ShuttingDown).Should(BeTrue())
+		Consistently(shutdown, 100*time.Millisecond).ShouldNot(Receive())
+
+		unblock()
+		Eventually(resp).Should(Receive(Equal(http.StatusNoContent)))
+		Eventually(shutdown).Should(Receive(BeNil()))
+		Eventually(served).Should(Receive(BeNil()))
+		Ω(hookRuns).Should(Equal([]string{"second", "first"}))
+	})
+
+	It("closes the hijacked connections", func() {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		Ω(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+		_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: test\r\n\r\n"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Eventually(started).Should(Receive())
+		status, err := bufio.NewReader(conn).ReadString('\n')
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(status).Should(ContainSubstring("101"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		Ω(s.Shutdown(ctx)).ShouldNot(HaveOccurred())
+		Eventually(served).Should(Receive(BeNil()))
+	})
+
+	It("cancels the service context", func() {
+		Ω(s.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+		Ω(s.Context.Err()).Should(Equal(context.Canceled))
+	})
+
+	It("gives up when the context expires", func() {
+		go http.Get("http://" + l.Addr().String() + "/slow")
+		Eventually(started).Should(Receive())
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		Ω(s.Shutdown(ctx)).Should(Equal(context.DeadlineExceeded))
+		Ω(hookRuns).Should(HaveLen(2))
+	})
+})
+
+var _ = Describe("OnStart", func() {
+	It("aborts the startup when a hook fails", func() {
+		s := goa.New("test")
+		var calls int
+		s.OnStart(func(context.Context) error {
+			calls++
+			return errors.New("boom")
+		})
+		s.OnStart(func(context.Context) error {
+			calls++
+			return nil
+		})
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer l.Close()
+		Ω(s.Serve(l)).Should(MatchError("boom"))
+		Ω(calls).Should(Equal(1))
+	})
+})
@@ -0,0 +1,157 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultEventStreamHeartbeat is the default interval between the heartbeats written to
+// server-sent events streams.
+const DefaultEventStreamHeartbeat = 15 * time.Second
+
+// EventStream writes server-sent events to the response. Each event data is the JSON
+// representation of a value, the stream is flushed after each event so that clients receive
+// events as soon as they are sent. EventStream also writes periodic heartbeats (comment lines) to
+// keep the connection open through proxies that close idle connections.
+//
+// An event stream is closed automatically once the action handler returns.
+type EventStream struct {
+	resp    *ResponseData
+	flusher http.Flusher
+	encoder *HTTPEncoder
+
+	lock   sync.Mutex
+	closed bool
+	done   chan struct{}
+}
+
+// OpenEventStream writes the response status code and the server-sent events headers and returns
+// the event stream that can be used to send events. OpenEventStream returns the stream opened
+// previously if there is one. It returns an error if the response was already written or if the
+// underlying response writer does not support flushing.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func OpenEventStream(ctx context.Context, status int) (*EventStream, error) {
+	resp := ContextResponse(ctx)
+	if resp == nil {
+		return nil, fmt.Errorf("no response data in context")
+	}
+	if resp.stream != nil {
+		return resp.stream, nil
+	}
+	if resp.Written() {
+		return nil, fmt.Errorf("response already written")
+	}
+	flusher, ok := resp.ResponseWriter.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("response writer does not support flushing")
+	}
+	h := resp.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // Disable response buffering in nginx
+	resp.WriteHeader(status)
+	flusher.Flush()
+
+	stream := &EventStream{resp: resp, flusher: flusher, done: make(chan struct{})}
+	heartbeat := DefaultEventStreamHeartbeat
+	if resp.Service != nil {
+		stream.encoder = resp.Service.Encoder
+		heartbeat = resp.Service.EventStreamHeartbeat
+	}
+	if heartbeat > 0 {
+		var cancel <-chan struct{}
+		if req := ContextRequest(ctx); req != nil && req.Request != nil {
+			cancel = req.Context().Done()
+		}
+		go stream.heartbeat(heartbeat, cancel)
+	}
+	resp.stream = stream
+	return stream, nil
+}
+
+// Send writes an event whose data is the JSON representation of v.
+func (s *EventStream) Send(v interface{}) error {
+	return s.SendEvent("", v)
+}
+
+// SendEvent writes an event with the given name whose data is the JSON representation of v. The
+// event is sent without name if name is empty.
+func (s *EventStream) SendEvent(name string, v interface{}) error {
+	data, err := s.encode(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %s", err)
+	}
+	var buf bytes.Buffer
+	if name != "" {
+		buf.WriteString("event: " + name + "\n")
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	return s.write(buf.Bytes())
+}
+
+// Close stops the heartbeats, events cannot be sent once the stream is closed.
+func (s *EventStream) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.done)
+}
+
+// encode serializes v using the service JSON encoder if there is one.
+func (s *EventStream) encode(v interface{}) ([]byte, error) {
+	if s.encoder == nil {
+		return json.Marshal(v)
+	}
+	var buf bytes.Buffer
+	if err := s.encoder.Encode(v, &buf, "application/json"); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// write writes b to the response and flushes it.
+func (s *EventStream) write(b []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return fmt.Errorf("event stream is closed")
+	}
+	if _, err := s.resp.Write(b); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// heartbeat writes a comment line every interval until the stream is closed or cancel is closed.
+func (s *EventStream) heartbeat(interval time.Duration, cancel <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+		case <-s.done:
+			return
+		case <-cancel:
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventStream", func() {
+	var ctx context.Context
+	var rw *httptest.ResponseRecorder
+	var service *goa.Service
+
+	BeforeEach(func() {
+		req, err := http.NewRequest("GET", "/events", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = httptest.NewRecorder()
+		service = goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		service.EventStreamHeartbeat = 0
+		ctx = goa.NewContext(context.Background(), rw, req, url.Values{})
+		goa.ContextResponse(ctx).Service = service
+	})
+
+	Context("OpenEventStream", func() {
+		It("writes the status and the event stream headers", func() {
+			stream, err := goa.OpenEventStream(ctx, 200)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(stream).ShouldNot(BeNil())
+			Ω(rw.Code).Should(Equal(200))
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("text/event-stream"))
+			Ω(rw.Header().Get("Cache-Control")).Should(Equal("no-cache"))
+			Ω(rw.Flushed).Should(BeTrue())
+		})
+
+		It("returns the stream already opened", func() {
+			stream, err := goa.OpenEventStream(ctx, 200)
+			Ω(err).ShouldNot(HaveOccurred())
+			again, err := goa.OpenEventStream(ctx, 200)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(again).Should(BeIdenticalTo(stream))
+		})
+
+		It("fails if the response was already written", func() {
+			goa.ContextResponse(ctx).WriteHeader(400)
+			_, err := goa.OpenEventStream(ctx, 200)
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("fails if the response writer cannot flush", func() {
+			req, _ := http.NewRequest("GET", "/events", nil)
+			ctx := goa.NewContext(context.Background(), &TestResponseWriter{ParentHeader: http.Header{}}, req, url.Values{})
+			_, err := goa.OpenEventStream(ctx, 200)
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("Send", func() {
+		var stream *goa.EventStream
+
+		BeforeEach(func() {
+			var err error
+			stream, err = goa.OpenEventStream(ctx, 200)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("writes the event data", func() {
+			Ω(stream.Send(map[string]int{"count": 1})).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(Equal("data: {\"count\":1}\n\n"))
+		})
+
+		It("writes the event name", func() {
+			Ω(stream.SendEvent("update", "foo")).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(Equal("event: update\ndata: \"foo\"\n\n"))
+		})
+
+		It("fails once the stream is closed", func() {
+			stream.Close()
+			Ω(stream.Send("foo")).Should(HaveOccurred())
+		})
+	})
+
+	Context("with heartbeats", func() {
+		BeforeEach(func() {
+			service.EventStreamHeartbeat = time.Millisecond
+		})
+
+		It("writes heartbeats until the stream is closed", func() {
+			stream, err := goa.OpenEventStream(ctx, 200)
+			Ω(err).ShouldNot(HaveOccurred())
+			time.Sleep(20 * time.Millisecond)
+			stream.Close()
+			Ω(rw.Body.String()).Should(HavePrefix(": heartbeat\n\n"))
+		})
+	})
+})
@@ -0,0 +1,216 @@
+package goa
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCertReloadInterval is the default interval at which the certificate files given to
+// ListenAndServeTLS are checked for changes.
+const DefaultCertReloadInterval = 10 * time.Second
+
+type (
+	// TLSConfig configures the TLS server started by ListenAndServeTLS and the configuration
+	// returned by NewTLSConfig.
+	TLSConfig struct {
+		// MinVersion is the minimum accepted TLS version, tls.VersionTLS12 by default.
+		MinVersion uint16
+		// CipherSuites restricts the cipher suites negotiated with TLS 1.2 and earlier, the
+		// Go defaults are used if empty. TLS 1.3 cipher suites are not configurable.
+		CipherSuites []uint16
+		// ClientCAFile is the path to a PEM file containing the certificate authorities
+		// used to verify client certificates. Setting ClientCAFile or ClientCAs enables
+		// mutual TLS.
+		ClientCAFile string
+		// ClientCAs is the pool of certificate authorities used to verify client
+		// certificates in addition to the ones loaded from ClientCAFile.
+		ClientCAs *x509.CertPool
+		// ClientAuth is the client certificate policy used when client certificate
+		// authorities are configured, tls.RequireAndVerifyClientCert by default.
+		ClientAuth tls.ClientAuthType
+		// ReloadInterval is the interval at which the certificate and key files are checked
+		// for changes, DefaultCertReloadInterval by default. A negative value disables
+		// reloading.
+		ReloadInterval time.Duration
+	}
+
+	// PeerIdentity describes the verified client certificate of a mutual TLS connection.
+	PeerIdentity struct {
+		// Certificate is the client leaf certificate.
+		Certificate *x509.Certificate
+		// Chain is the verified certificate chain starting with the leaf certificate.
+		Chain []*x509.Certificate
+		// CommonName is the certificate subject common name.
+		CommonName string
+		// DNSNames lists the certificate DNS subject alternative names.
+		DNSNames []string
+		// EmailAddresses lists the certificate email subject alternative names.
+		EmailAddresses []string
+		// URIs lists the certificate URI subject alternative names, for example SPIFFE IDs.
+		URIs []*url.URL
+	}
+
+	// certReloader serves a certificate loaded from disk and reloads it when the files change.
+	certReloader struct {
+		certFile string
+		keyFile  string
+		interval time.Duration
+		service  *Service
+
+		mu      sync.Mutex
+		cert    *tls.Certificate
+		certMod time.Time
+		keyMod  time.Time
+		checked time.Time
+	}
+)
+
+// NewTLSConfig builds a TLS configuration that applies the service TLS settings. The server
+// certificate is loaded from certFile and keyFile and reloaded when the files change. The
+// configuration in Server.TLSConfig, if any, is used as a starting point. NewTLSConfig is called
+// by ListenAndServeTLS, use it with Serve to serve TLS on a custom listener:
+//
+//	cfg, err := service.NewTLSConfig("cert.pem", "key.pem")
+//	if err != nil {
+//		return err
+//	}
+//	service.Serve(tls.NewListener(l, cfg))
+func (service *Service) NewTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	opts := service.TLS
+	if opts == nil {
+		opts = &TLSConfig{}
+	}
+	cfg := &tls.Config{}
+	if service.Server != nil && service.Server.TLSConfig != nil {
+		cfg = service.Server.TLSConfig.Clone()
+	}
+	if opts.MinVersion != 0 {
+		cfg.MinVersion = opts.MinVersion
+	} else if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+	if len(opts.CipherSuites) > 0 {
+		cfg.CipherSuites = opts.CipherSuites
+	}
+
+	if certFile != "" || keyFile != "" {
+		interval := opts.ReloadInterval
+		if interval == 0 {
+			interval = DefaultCertReloadInterval
+		}
+		r := &certReloader{certFile: certFile, keyFile: keyFile, interval: interval, service: service}
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+		cfg.Certificates = nil
+		cfg.GetCertificate = r.GetCertificate
+	}
+
+	pool := opts.ClientCAs
+	if opts.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(opts.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %s", err)
+		}
+		if pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in client CA file %#v", opts.ClientCAFile)
+		}
+	}
+	if pool != nil {
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = opts.ClientAuth
+		if cfg.ClientAuth == tls.NoClientCert {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return cfg, nil
+}
+
+// ContextPeerIdentity returns the identity of the verified client certificate of the request
+// connection, nil if the request was not made over mutual TLS.
+func ContextPeerIdentity(ctx context.Context) *PeerIdentity {
+	req := ContextRequest(ctx)
+	if req == nil || req.Request == nil || req.TLS == nil {
+		return nil
+	}
+	chains := req.TLS.VerifiedChains
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return nil
+	}
+	leaf := chains[0][0]
+	return &PeerIdentity{
+		Certificate:    leaf,
+		Chain:          chains[0],
+		CommonName:     leaf.Subject.CommonName,
+		DNSNames:       leaf.DNSNames,
+		EmailAddresses: leaf.EmailAddresses,
+		URIs:           leaf.URIs,
+	}
+}
+
+// enableH2C configures the server to accept HTTP/2 over cleartext connections if H2C is set. The
+// server handler is wrapped only once so that the service may be served multiple times.
+func (service *Service) enableH2C() {
+	if !service.H2C || service.h2c {
+		return
+	}
+	service.h2c = true
+	service.Server.Handler = service.h2cHandler(service.Server.Handler)
+}
+
+// GetCertificate returns the current certificate, reloading it first if the files changed since
+// the last check. Reload errors are logged and the previous certificate is kept.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.interval > 0 && time.Since(r.checked) >= r.interval {
+		if err := r.reload(); err != nil {
+			r.service.LogError("certificate reload", "cert", r.certFile, "err", err)
+		}
+	}
+	return r.cert, nil
+}
+
+// load loads the certificate, it must be called before the reloader is used concurrently.
+func (r *certReloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reload()
+}
+
+// reload loads the certificate if the files modification times changed. The caller must hold
+// the lock.
+func (r *certReloader) reload() error {
+	r.checked = time.Now()
+	ci, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+	ki, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+	if r.cert != nil && ci.ModTime().Equal(r.certMod) && ki.ModTime().Equal(r.keyMod) {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	if r.cert != nil {
+		r.service.LogInfo("certificate reloaded", "cert", r.certFile)
+	}
+	r.cert = &cert
+	r.certMod, r.keyMod = ci.ModTime(), ki.ModTime()
+	return nil
+}
@@ -0,0 +1,345 @@
+package goa_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/http2"
+)
+
+var _ = Describe("TLS", func() {
+	var (
+		s   *goa.Service
+		dir string
+		ca  *testCert
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "goa-tls")
+		Ω(err).ShouldNot(HaveOccurred())
+		s = goa.New("test")
+		s.WithLogger(goa.NewLogger(log.New(ioutil.Discard, "", 0)))
+		ca = newTestCert("ca", nil)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Context("NewTLSConfig", func() {
+		var certFile, keyFile string
+
+		BeforeEach(func() {
+			certFile, keyFile = newTestCert("server", ca).write(dir, "server")
+		})
+
+		It("defaults to TLS 1.2", func() {
+			cfg, err := s.NewTLSConfig(certFile, keyFile)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(cfg.MinVersion).Should(Equal(uint16(tls.VersionTLS12)))
+			Ω(cfg.ClientCAs).Should(BeNil())
+			Ω(cfg.ClientAuth).Should(Equal(tls.NoClientCert))
+		})
+
+		It("applies the version and cipher policy", func() {
+			suites := []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}
+			s.TLS = &goa.TLSConfig{MinVersion: tls.VersionTLS13, CipherSuites: suites}
+			cfg, err := s.NewTLSConfig(certFile, keyFile)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(cfg.MinVersion).Should(Equal(uint16(tls.VersionTLS13)))
+			Ω(cfg.CipherSuites).Should(Equal(suites))
+		})
+
+		It("requires client certificates when client CAs are configured", func() {
+			caFile, _ := ca.write(dir, "ca")
+			s.TLS = &goa.TLSConfig{ClientCAFile: caFile}
+			cfg, err := s.NewTLSConfig(certFile, keyFile)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(cfg.ClientCAs).ShouldNot(BeNil())
+			Ω(cfg.ClientAuth).Should(Equal(tls.RequireAndVerifyClientCert))
+		})
+
+		It("fails with a missing certificate", func() {
+			_, err := s.NewTLSConfig(filepath.Join(dir, "missing.pem"), keyFile)
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("reloads the certificate when the files change", func() {
+			s.TLS = &goa.TLSConfig{ReloadInterval: time.Nanosecond}
+			cfg, err := s.NewTLSConfig(certFile, keyFile)
+			Ω(err).ShouldNot(HaveOccurred())
+			cert, err := cfg.GetCertificate(nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(cert.Leaf.Subject.CommonName).Should(Equal("server"))
+
+			newTestCert("renewed", ca).write(dir, "server")
+			later := time.Now().Add(time.Minute)
+			Ω(os.Chtimes(certFile, later, later)).ShouldNot(HaveOccurred())
+			Ω(os.Chtimes(keyFile, later, later)).ShouldNot(HaveOccurred())
+			cert, err = cfg.GetCertificate(nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(cert.Leaf.Subject.CommonName).Should(Equal("renewed"))
+		})
+
+		It("keeps the previous certificate if the new one is invalid", func() {
+			s.TLS = &goa.TLSConfig{ReloadInterval: time.Nanosecond}
+			cfg, err := s.NewTLSConfig(certFile, keyFile)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(ioutil.WriteFile(certFile, []byte("invalid"), 0600)).ShouldNot(HaveOccurred())
+			later := time.Now().Add(time.Minute)
+			Ω(os.Chtimes(certFile, later, later)).ShouldNot(HaveOccurred())
+			cert, err := cfg.GetCertificate(nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(cert.Leaf.Subject.CommonName).Should(Equal("server"))
+		})
+	})
+
+	Context("serving mutual TLS", func() {
+		var (
+			l        net.Listener
+			identity chan *goa.PeerIdentity
+			client   *http.Client
+		)
+
+		BeforeEach(func() {
+			certFile, keyFile := newTestCert("127.0.0.1", ca).write(dir, "server")
+			caFile, _ := ca.write(dir, "ca")
+			s.TLS = &goa.TLSConfig{ClientCAFile: caFile}
+			cfg, err := s.NewTLSConfig(certFile, keyFile)
+			Ω(err).ShouldNot(HaveOccurred())
+			identity = make(chan *goa.PeerIdentity, 1)
+			ctrl := s.NewController("test")
+			id := identity
+			s.Mux.Handle("GET", "/", ctrl.MuxHandler("whoami", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				id <- goa.ContextPeerIdentity(ctx)
+				rw.WriteHeader(http.StatusNoContent)
+				return nil
+			}, nil))
+			l, err = net.Listen("tcp", "127.0.0.1:0")
+			Ω(err).ShouldNot(HaveOccurred())
+			srv, lis := s, tls.NewListener(l, cfg)
+			go srv.Serve(lis)
+
+			roots := x509.NewCertPool()
+			roots.AddCert(ca.cert)
+			client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+		})
+
+		AfterEach(func() {
+			s.Shutdown(context.Background())
+		})
+
+		It("exposes the verified client identity", func() {
+			clientCert := newTestCert("client", ca)
+			client.Transport.(*http.Transport).TLSClientConfig.Certificates = []tls.Certificate{clientCert.tlsCert()}
+			resp, err := client.Get("https://" + l.Addr().String())
+			Ω(err).ShouldNot(HaveOccurred())
+			resp.Body.Close()
+			Ω(resp.StatusCode).Should(Equal(http.StatusNoContent))
+			var id *goa.PeerIdentity
+			Eventually(identity).Should(Receive(&id))
+			Ω(id).ShouldNot(BeNil())
+			Ω(id.CommonName).Should(Equal("client"))
+			Ω(id.URIs).Should(HaveLen(1))
+			Ω(id.URIs[0].String()).Should(Equal("spiffe://goa.design/client"))
+		})
+
+		It("rejects clients without certificate", func() {
+			_, err := client.Get("https://" + l.Addr().String())
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("ContextPeerIdentity", func() {
+		It("returns nil for requests made without TLS", func() {
+			req, _ := http.NewRequest("GET", "/", nil)
+			ctx := goa.NewContext(context.Background(), nil, req, nil)
+			Ω(goa.ContextPeerIdentity(ctx)).Should(BeNil())
+		})
+	})
+
+	Context("with H2C", func() {
+		var (
+			l       net.Listener
+			started chan struct{}
+			release chan struct{}
+			client  *http.Client
+		)
+
+		BeforeEach(func() {
+			s.H2C = true
+			started = make(chan struct{}, 1)
+			release = make(chan struct{})
+			client = &http.Client{Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+					return net.Dial(network, addr)
+				},
+			}}
+			ctrl := s.NewController("test")
+			s.Mux.Handle("GET", "/", ctrl.MuxHandler("proto", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				rw.WriteHeader(http.StatusNoContent)
+				return nil
+			}, nil))
+			rel := release
+			s.Mux.Handle("GET", "/slow", ctrl.MuxHandler("slow", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				started <- struct{}{}
+				<-rel
+				rw.WriteHeader(http.StatusNoContent)
+				return nil
+			}, nil))
+			var err error
+			l, err = net.Listen("tcp", "127.0.0.1:0")
+			Ω(err).ShouldNot(HaveOccurred())
+			srv, lis := s, l
+			go srv.Serve(lis)
+		})
+
+		AfterEach(func() {
+			select {
+			case <-release:
+			default:
+				close(release)
+			}
+			s.Shutdown(context.Background())
+		})
+
+		It("serves HTTP/2 over cleartext connections", func() {
+			resp, err := client.Get("http://" + l.Addr().String())
+			Ω(err).ShouldNot(HaveOccurred())
+			resp.Body.Close()
+			Ω(resp.ProtoMajor).Should(Equal(2))
+		})
+
+		It("drains the in-flight requests on shutdown", func() {
+			first, err := client.Get("http://" + l.Addr().String())
+			Ω(err).ShouldNot(HaveOccurred())
+			first.Body.Close()
+			resp := make(chan int, 1)
+			go func() {
+				r, err := client.Get("http://" + l.Addr().String() + "/slow")
+				if err != nil {
+					resp <- 0
+					return
+				}
+				r.Body.Close()
+				resp <- r.StatusCode
+			}()
+			Eventually(started).Should(Receive())
+
+			shutdown := make(chan error, 1)
+			go func() { shutdown <- s.Shutdown(context.Background()) }()
+			Eventually(s.ShuttingDown).Should(BeTrue())
+			Consistently(shutdown, 100*time.Millisecond).ShouldNot(Receive())
+			Consistently(resp, 100*time.Millisecond).ShouldNot(Receive())
+
+			close(release)
+			Eventually(resp).Should(Receive(Equal(http.StatusNoContent)))
+			Eventually(shutdown, 5*time.Second).Should(Receive(BeNil()))
+		})
+
+		It("closes the h2c connections when the shutdown context expires", func() {
+			resp := make(chan int, 1)
+			go func() {
+				r, err := client.Get("http://" + l.Addr().String() + "/slow")
+				if err != nil {
+					resp <- 0
+					return
+				}
+				r.Body.Close()
+				resp <- r.StatusCode
+			}()
+			Eventually(started).Should(Receive())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			Ω(s.Shutdown(ctx)).Should(Equal(context.DeadlineExceeded))
+			Eventually(resp).Should(Receive(Equal(0)))
+		})
+
+		It("still serves HTTP/1.1", func() {
+			resp, err := http.Get("http://" + l.Addr().String())
+			Ω(err).ShouldNot(HaveOccurred())
+			resp.Body.Close()
+			Ω(resp.ProtoMajor).Should(Equal(1))
+		})
+	})
+})
+
+// testCert is a certificate and its key generated for the tests.
+type testCert struct {
+	cert *x509.Certificate
+	der  []byte
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert creates a certificate with the given common name signed by parent, the certificate
+// is a self-signed CA if parent is nil.
+func newTestCert(cn string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Ω(err).ShouldNot(HaveOccurred())
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	Ω(err).ShouldNot(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+		if ip := net.ParseIP(cn); ip != nil {
+			tmpl.IPAddresses = []net.IP{ip}
+		}
+		u, _ := url.Parse("spiffe://goa.design/" + cn)
+		tmpl.URIs = []*url.URL{u}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	Ω(err).ShouldNot(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Ω(err).ShouldNot(HaveOccurred())
+	return &testCert{cert: cert, der: der, key: key}
+}
+
+// write writes the PEM encoded certificate and key to dir and returns the file paths.
+func (c *testCert) write(dir, name string) (string, string) {
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+"-key.pem")
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0600)).ShouldNot(HaveOccurred())
+	Ω(ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)).ShouldNot(HaveOccurred())
+	return certFile, keyFile
+}
+
+// tlsCert returns the certificate and key as a tls.Certificate.
+func (c *testCert) tlsCert() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key, Leaf: c.cert}
+}